@@ -57,10 +57,24 @@ const (
 	VInitArtifactPushUser  = "init.artifact.push_username"
 	VInitArtifactPushToken = "init.artifact.push_token"
 
+	// Init Agent config keys
+
+	VInitAgentAllowedRegistries = "init.agent.allowed_registries"
+
+	// Init Credential Store config keys
+
+	VInitCredentialStoreProvider = "init.credential_store.provider"
+	VInitCredentialStoreAddress  = "init.credential_store.address"
+	VInitCredentialStorePath     = "init.credential_store.path"
+	VInitCredentialStoreToken    = "init.credential_store.token"
+
 	// Package config keys
 
-	VPkgOCIConcurrency = "package.oci_concurrency"
-	VPkgPublicKey      = "package.public_key"
+	VPkgOCIConcurrency        = "package.oci_concurrency"
+	VPkgPublicKey             = "package.public_key"
+	VPkgCertificateIdentity   = "package.certificate_identity"
+	VPkgCertificateOIDCIssuer = "package.certificate_oidc_issuer"
+	VPkgCertificateChain      = "package.certificate_chain"
 
 	// Package create config keys
 
@@ -72,9 +86,11 @@ const (
 	VPkgCreateMaxPackageSize     = "package.create.max_package_size"
 	VPkgCreateSigningKey         = "package.create.signing_key"
 	VPkgCreateSigningKeyPassword = "package.create.signing_key_password"
+	VPkgCreateKeyless            = "package.create.keyless"
 	VPkgCreateDifferential       = "package.create.differential"
 	VPkgCreateRegistryOverride   = "package.create.registry_override"
 	VPkgCreateFlavor             = "package.create.flavor"
+	VPkgCreateReproducible       = "package.create.reproducible"
 
 	// Package deploy config keys
 
@@ -84,12 +100,20 @@ const (
 	VPkgDeploySget         = "package.deploy.sget"
 	VPkgDeploySkipWebhooks = "package.deploy.skip_webhooks"
 	VPkgDeployTimeout      = "package.deploy.timeout"
+	VPkgDeployAtomic       = "package.deploy.atomic"
+	VPkgDeployConcurrency  = "package.deploy.concurrency"
+	VPkgDeployDryRunOutput = "package.deploy.dry_run_output"
 	VPkgRetries            = "package.deploy.retries"
 
+	// Package remove config keys
+
+	VPkgRemoveKeepImages = "package.remove.keep_images"
+
 	// Package publish config keys
 
 	VPkgPublishSigningKey         = "package.publish.signing_key"
 	VPkgPublishSigningKeyPassword = "package.publish.signing_key_password"
+	VPkgPublishKeyless            = "package.publish.keyless"
 
 	// Package pull config keys
 
@@ -189,4 +213,5 @@ func setDefaults() {
 
 	// Deploy opts that are non-zero values
 	v.SetDefault(VPkgDeployTimeout, config.JackalDefaultTimeout)
+	v.SetDefault(VPkgDeployConcurrency, config.JackalDefaultConcurrency)
 }
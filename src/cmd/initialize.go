@@ -192,6 +192,7 @@ func init() {
 	initCmd.Flags().BoolVar(&config.CommonOptions.Confirm, "confirm", false, lang.CmdInitFlagConfirm)
 	initCmd.Flags().StringVar(&pkgConfig.PkgOpts.OptionalComponents, "components", v.GetString(common.VInitComponents), lang.CmdInitFlagComponents)
 	initCmd.Flags().StringVar(&pkgConfig.InitOpts.StorageClass, "storage-class", v.GetString(common.VInitStorageClass), lang.CmdInitFlagStorageClass)
+	initCmd.Flags().StringSliceVar(&pkgConfig.InitOpts.AllowedRegistries, "agent-allowed-registries", v.GetStringSlice(common.VInitAgentAllowedRegistries), lang.CmdInitFlagAgentAllowedRegistries)
 
 	// Flags for using an external Git server
 	initCmd.Flags().StringVar(&pkgConfig.InitOpts.GitServer.Address, "git-url", v.GetString(common.VInitGitURL), lang.CmdInitFlagGitURL)
@@ -214,6 +215,12 @@ func init() {
 	initCmd.Flags().StringVar(&pkgConfig.InitOpts.ArtifactServer.PushUsername, "artifact-push-username", v.GetString(common.VInitArtifactPushUser), lang.CmdInitFlagArtifactPushUser)
 	initCmd.Flags().StringVar(&pkgConfig.InitOpts.ArtifactServer.PushToken, "artifact-push-token", v.GetString(common.VInitArtifactPushToken), lang.CmdInitFlagArtifactPushToken)
 
+	// Flags for keeping the state credentials in an external store
+	initCmd.Flags().StringVar(&pkgConfig.InitOpts.CredentialStore.Provider, "credential-store", v.GetString(common.VInitCredentialStoreProvider), lang.CmdInitFlagCredentialStore)
+	initCmd.Flags().StringVar(&pkgConfig.InitOpts.CredentialStore.Address, "credential-store-address", v.GetString(common.VInitCredentialStoreAddress), lang.CmdInitFlagCredentialStoreAddress)
+	initCmd.Flags().StringVar(&pkgConfig.InitOpts.CredentialStore.Path, "credential-store-path", v.GetString(common.VInitCredentialStorePath), lang.CmdInitFlagCredentialStorePath)
+	initCmd.Flags().StringVar(&pkgConfig.InitOpts.CredentialStoreToken, "credential-store-token", v.GetString(common.VInitCredentialStoreToken), lang.CmdInitFlagCredentialStoreToken)
+
 	// Flags that control how a deployment proceeds
 	// Always require adopt-existing-resources flag (no viper)
 	initCmd.Flags().BoolVar(&pkgConfig.DeployOpts.AdoptExistingResources, "adopt-existing-resources", false, lang.CmdPackageDeployFlagAdoptExistingResources)
@@ -222,6 +229,9 @@ func init() {
 
 	initCmd.Flags().IntVar(&pkgConfig.PkgOpts.Retries, "retries", v.GetInt(common.VPkgRetries), lang.CmdPackageFlagRetries)
 	initCmd.Flags().StringVarP(&pkgConfig.PkgOpts.PublicKeyPath, "key", "k", v.GetString(common.VPkgPublicKey), lang.CmdPackageFlagFlagPublicKey)
+	initCmd.Flags().StringVar(&pkgConfig.PkgOpts.CertificateIdentity, "certificate-identity", v.GetString(common.VPkgCertificateIdentity), lang.CmdPackageFlagFlagCertificateIdentity)
+	initCmd.Flags().StringVar(&pkgConfig.PkgOpts.CertificateOIDCIssuer, "certificate-oidc-issuer", v.GetString(common.VPkgCertificateOIDCIssuer), lang.CmdPackageFlagFlagCertificateOIDCIssuer)
+	initCmd.Flags().StringVar(&pkgConfig.PkgOpts.CertificateChainPath, "certificate-chain", v.GetString(common.VPkgCertificateChain), lang.CmdPackageFlagFlagCertificateChain)
 
 	initCmd.Flags().SortFlags = true
 }
@@ -6,6 +6,7 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -239,8 +240,10 @@ var packagePullCmd = &cobra.Command{
 	Run: func(_ *cobra.Command, args []string) {
 		pkgConfig.PkgOpts.PackageSource = args[0]
 
+		src := identifyAndFallbackToClusterSource()
+
 		// Configure the packager
-		pkgClient := packager.NewOrDie(&pkgConfig)
+		pkgClient := packager.NewOrDie(&pkgConfig, packager.WithSource(src))
 		defer pkgClient.ClearTempPaths()
 
 		// Pull the package
@@ -248,6 +251,124 @@ var packagePullCmd = &cobra.Command{
 			message.Fatalf(err, lang.CmdPackagePullErr, err.Error())
 		}
 	},
+	ValidArgsFunction: getPackageCompletionArgs,
+}
+
+var packageDiffCmd = &cobra.Command{
+	Use:     "diff { PACKAGE_SOURCE | PACKAGE_NAME } { PACKAGE_SOURCE | PACKAGE_NAME }",
+	Short:   lang.CmdPackageDiffShort,
+	Long:    lang.CmdPackageDiffLong,
+	Example: lang.CmdPackageDiffExample,
+	Args:    cobra.ExactArgs(2),
+	Run: func(_ *cobra.Command, args []string) {
+		pkgConfig.PkgOpts.PackageSource = args[0]
+		pkgConfig.DiffOpts.ComparePackageSource = args[1]
+
+		// Diffing is read-only so variables are never prompted for
+		config.CommonOptions.Confirm = true
+
+		// Ensure uppercase keys from viper and CLI --set
+		v := common.GetViper()
+
+		// Merge the viper config file variables and provided CLI flag variables (CLI takes precedence))
+		pkgConfig.PkgOpts.SetVariables = helpers.TransformAndMergeMap(
+			v.GetStringMapString(common.VPkgDeploySet), pkgConfig.PkgOpts.SetVariables, strings.ToUpper)
+
+		src := identifyAndFallbackToClusterSource()
+
+		// Configure the packager
+		pkgClient := packager.NewOrDie(&pkgConfig, packager.WithSource(src))
+		defer pkgClient.ClearTempPaths()
+
+		// Diff the packages
+		if err := pkgClient.Diff(); err != nil {
+			message.Fatalf(err, lang.CmdPackageDiffErr, err.Error())
+		}
+	},
+	ValidArgsFunction: getPackageCompletionArgs,
+}
+
+var packageHistoryCmd = &cobra.Command{
+	Use:     "history PACKAGE_NAME",
+	Short:   lang.CmdPackageHistoryShort,
+	Long:    lang.CmdPackageHistoryLong,
+	Example: lang.CmdPackageHistoryExample,
+	Args:    cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		pkgConfig.PkgOpts.PackageSource = args[0]
+
+		src, err := sources.NewClusterSource(&pkgConfig.PkgOpts)
+		if err != nil {
+			message.Fatalf(err, lang.CmdPackageInvalidSource, pkgConfig.PkgOpts.PackageSource, err.Error())
+		}
+
+		// Configure the packager
+		pkgClient := packager.NewOrDie(&pkgConfig, packager.WithSource(src))
+		defer pkgClient.ClearTempPaths()
+
+		if err := pkgClient.History(); err != nil {
+			message.Fatalf(err, lang.CmdPackageHistoryErr, err.Error())
+		}
+	},
+	ValidArgsFunction: getPackageCompletionArgs,
+}
+
+var packageRollbackCmd = &cobra.Command{
+	Use:     "rollback PACKAGE_NAME --generation N --confirm",
+	Short:   lang.CmdPackageRollbackShort,
+	Long:    lang.CmdPackageRollbackLong,
+	Example: lang.CmdPackageRollbackExample,
+	Args:    cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		pkgConfig.PkgOpts.PackageSource = args[0]
+
+		src, err := sources.NewClusterSource(&pkgConfig.PkgOpts)
+		if err != nil {
+			message.Fatalf(err, lang.CmdPackageInvalidSource, pkgConfig.PkgOpts.PackageSource, err.Error())
+		}
+
+		// Configure the packager
+		pkgClient := packager.NewOrDie(&pkgConfig, packager.WithSource(src))
+		defer pkgClient.ClearTempPaths()
+
+		if err := pkgClient.Rollback(); err != nil {
+			message.Fatalf(err, lang.CmdPackageRollbackErr, err.Error())
+		}
+	},
+	ValidArgsFunction: getPackageCompletionArgs,
+}
+
+// packageStatusDriftExitCode is the exit code of package status when drift is detected, distinct from the exit code of a failed check.
+const packageStatusDriftExitCode = 2
+
+var packageStatusCmd = &cobra.Command{
+	Use:     "status PACKAGE_NAME",
+	Short:   lang.CmdPackageStatusShort,
+	Long:    lang.CmdPackageStatusLong,
+	Example: lang.CmdPackageStatusExample,
+	Args:    cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		pkgConfig.PkgOpts.PackageSource = args[0]
+
+		src, err := sources.NewClusterSource(&pkgConfig.PkgOpts)
+		if err != nil {
+			message.Fatalf(err, lang.CmdPackageInvalidSource, pkgConfig.PkgOpts.PackageSource, err.Error())
+		}
+
+		// Configure the packager
+		pkgClient := packager.NewOrDie(&pkgConfig, packager.WithSource(src))
+		defer pkgClient.ClearTempPaths()
+
+		status, err := pkgClient.Status()
+		if err != nil {
+			message.Fatalf(err, lang.CmdPackageStatusErr, err.Error())
+		}
+		if status.Drifted() {
+			pkgClient.ClearTempPaths()
+			os.Exit(packageStatusDriftExitCode)
+		}
+	},
+	ValidArgsFunction: getPackageCompletionArgs,
 }
 
 func choosePackage(args []string) string {
@@ -318,6 +439,10 @@ func init() {
 	packageCmd.AddCommand(packageListCmd)
 	packageCmd.AddCommand(packagePublishCmd)
 	packageCmd.AddCommand(packagePullCmd)
+	packageCmd.AddCommand(packageDiffCmd)
+	packageCmd.AddCommand(packageHistoryCmd)
+	packageCmd.AddCommand(packageRollbackCmd)
+	packageCmd.AddCommand(packageStatusCmd)
 
 	bindPackageFlags(v)
 	bindCreateFlags(v)
@@ -327,12 +452,19 @@ func init() {
 	bindRemoveFlags(v)
 	bindPublishFlags(v)
 	bindPullFlags(v)
+	bindDiffFlags(v)
+	bindHistoryFlags()
+	bindRollbackFlags()
+	bindStatusFlags()
 }
 
 func bindPackageFlags(v *viper.Viper) {
 	packageFlags := packageCmd.PersistentFlags()
 	packageFlags.IntVar(&config.CommonOptions.OCIConcurrency, "oci-concurrency", v.GetInt(common.VPkgOCIConcurrency), lang.CmdPackageFlagConcurrency)
 	packageFlags.StringVarP(&pkgConfig.PkgOpts.PublicKeyPath, "key", "k", v.GetString(common.VPkgPublicKey), lang.CmdPackageFlagFlagPublicKey)
+	packageFlags.StringVar(&pkgConfig.PkgOpts.CertificateIdentity, "certificate-identity", v.GetString(common.VPkgCertificateIdentity), lang.CmdPackageFlagFlagCertificateIdentity)
+	packageFlags.StringVar(&pkgConfig.PkgOpts.CertificateOIDCIssuer, "certificate-oidc-issuer", v.GetString(common.VPkgCertificateOIDCIssuer), lang.CmdPackageFlagFlagCertificateOIDCIssuer)
+	packageFlags.StringVar(&pkgConfig.PkgOpts.CertificateChainPath, "certificate-chain", v.GetString(common.VPkgCertificateChain), lang.CmdPackageFlagFlagCertificateChain)
 }
 
 func bindCreateFlags(v *viper.Viper) {
@@ -357,9 +489,11 @@ func bindCreateFlags(v *viper.Viper) {
 	createFlags.IntVarP(&pkgConfig.CreateOpts.MaxPackageSizeMB, "max-package-size", "m", v.GetInt(common.VPkgCreateMaxPackageSize), lang.CmdPackageCreateFlagMaxPackageSize)
 	createFlags.StringToStringVar(&pkgConfig.CreateOpts.RegistryOverrides, "registry-override", v.GetStringMapString(common.VPkgCreateRegistryOverride), lang.CmdPackageCreateFlagRegistryOverride)
 	createFlags.StringVarP(&pkgConfig.CreateOpts.Flavor, "flavor", "f", v.GetString(common.VPkgCreateFlavor), lang.CmdPackageCreateFlagFlavor)
+	createFlags.BoolVar(&pkgConfig.CreateOpts.Reproducible, "reproducible", v.GetBool(common.VPkgCreateReproducible), lang.CmdPackageCreateFlagReproducible)
 
 	createFlags.StringVar(&pkgConfig.CreateOpts.SigningKeyPath, "signing-key", v.GetString(common.VPkgCreateSigningKey), lang.CmdPackageCreateFlagSigningKey)
 	createFlags.StringVar(&pkgConfig.CreateOpts.SigningKeyPassword, "signing-key-pass", v.GetString(common.VPkgCreateSigningKeyPassword), lang.CmdPackageCreateFlagSigningKeyPassword)
+	createFlags.BoolVar(&pkgConfig.CreateOpts.Keyless, "keyless", v.GetBool(common.VPkgCreateKeyless), lang.CmdPackageCreateFlagKeyless)
 
 	createFlags.StringVarP(&pkgConfig.CreateOpts.SigningKeyPath, "key", "k", v.GetString(common.VPkgCreateSigningKey), lang.CmdPackageCreateFlagDeprecatedKey)
 	createFlags.StringVar(&pkgConfig.CreateOpts.SigningKeyPassword, "key-pass", v.GetString(common.VPkgCreateSigningKeyPassword), lang.CmdPackageCreateFlagDeprecatedKeyPassword)
@@ -381,6 +515,12 @@ func bindDeployFlags(v *viper.Viper) {
 	deployFlags.BoolVar(&pkgConfig.DeployOpts.AdoptExistingResources, "adopt-existing-resources", false, lang.CmdPackageDeployFlagAdoptExistingResources)
 	deployFlags.BoolVar(&pkgConfig.DeployOpts.SkipWebhooks, "skip-webhooks", v.GetBool(common.VPkgDeploySkipWebhooks), lang.CmdPackageDeployFlagSkipWebhooks)
 	deployFlags.DurationVar(&pkgConfig.DeployOpts.Timeout, "timeout", v.GetDuration(common.VPkgDeployTimeout), lang.CmdPackageDeployFlagTimeout)
+	deployFlags.IntVar(&pkgConfig.DeployOpts.Concurrency, "concurrency", v.GetInt(common.VPkgDeployConcurrency), lang.CmdPackageDeployFlagConcurrency)
+	deployFlags.BoolVar(&pkgConfig.DeployOpts.Atomic, "atomic", v.GetBool(common.VPkgDeployAtomic), lang.CmdPackageDeployFlagAtomic)
+
+	// Always require dry-run flag (no viper)
+	deployFlags.BoolVar(&pkgConfig.DeployOpts.DryRun, "dry-run", false, lang.CmdPackageDeployFlagDryRun)
+	deployFlags.StringVar(&pkgConfig.DeployOpts.DryRunOutputDir, "dry-run-output", v.GetString(common.VPkgDeployDryRunOutput), lang.CmdPackageDeployFlagDryRunOutput)
 
 	deployFlags.IntVar(&pkgConfig.PkgOpts.Retries, "retries", v.GetInt(common.VPkgRetries), lang.CmdPackageFlagRetries)
 	deployFlags.StringToStringVar(&pkgConfig.PkgOpts.SetVariables, "set", v.GetStringMapString(common.VPkgDeploySet), lang.CmdPackageDeployFlagSet)
@@ -428,6 +568,7 @@ func bindRemoveFlags(v *viper.Viper) {
 	removeFlags := packageRemoveCmd.Flags()
 	removeFlags.BoolVar(&config.CommonOptions.Confirm, "confirm", false, lang.CmdPackageRemoveFlagConfirm)
 	removeFlags.StringVar(&pkgConfig.PkgOpts.OptionalComponents, "components", v.GetString(common.VPkgDeployComponents), lang.CmdPackageRemoveFlagComponents)
+	removeFlags.BoolVar(&pkgConfig.RemoveOpts.KeepImages, "keep-images", v.GetBool(common.VPkgRemoveKeepImages), lang.CmdPackageRemoveFlagKeepImages)
 	_ = packageRemoveCmd.MarkFlagRequired("confirm")
 }
 
@@ -435,9 +576,36 @@ func bindPublishFlags(v *viper.Viper) {
 	publishFlags := packagePublishCmd.Flags()
 	publishFlags.StringVar(&pkgConfig.PublishOpts.SigningKeyPath, "signing-key", v.GetString(common.VPkgPublishSigningKey), lang.CmdPackagePublishFlagSigningKey)
 	publishFlags.StringVar(&pkgConfig.PublishOpts.SigningKeyPassword, "signing-key-pass", v.GetString(common.VPkgPublishSigningKeyPassword), lang.CmdPackagePublishFlagSigningKeyPassword)
+	publishFlags.BoolVar(&pkgConfig.PublishOpts.Keyless, "keyless", v.GetBool(common.VPkgPublishKeyless), lang.CmdPackagePublishFlagKeyless)
 }
 
 func bindPullFlags(v *viper.Viper) {
 	pullFlags := packagePullCmd.Flags()
 	pullFlags.StringVarP(&pkgConfig.PullOpts.OutputDirectory, "output-directory", "o", v.GetString(common.VPkgPullOutputDir), lang.CmdPackagePullFlagOutputDirectory)
 }
+
+func bindDiffFlags(v *viper.Viper) {
+	diffFlags := packageDiffCmd.Flags()
+	diffFlags.StringVarP(&pkgConfig.DiffOpts.OutputFormat, "output", "o", "", lang.CmdPackageDiffFlagOutput)
+	diffFlags.StringToStringVar(&pkgConfig.PkgOpts.SetVariables, "set", v.GetStringMapString(common.VPkgDeploySet), lang.CmdPackageDiffFlagSet)
+	diffFlags.StringVar(&pkgConfig.PkgOpts.OptionalComponents, "components", v.GetString(common.VPkgDeployComponents), lang.CmdPackageDiffFlagComponents)
+}
+
+func bindHistoryFlags() {
+	historyFlags := packageHistoryCmd.Flags()
+	historyFlags.StringVarP(&pkgConfig.HistoryOpts.OutputFormat, "output", "o", "", lang.CmdPackageHistoryFlagOutput)
+}
+
+func bindRollbackFlags() {
+	rollbackFlags := packageRollbackCmd.Flags()
+	rollbackFlags.IntVar(&pkgConfig.RollbackOpts.Generation, "generation", 0, lang.CmdPackageRollbackFlagGeneration)
+	// Always require confirm flag (no viper)
+	rollbackFlags.BoolVar(&config.CommonOptions.Confirm, "confirm", false, lang.CmdPackageRollbackFlagConfirm)
+	_ = packageRollbackCmd.MarkFlagRequired("generation")
+	_ = packageRollbackCmd.MarkFlagRequired("confirm")
+}
+
+func bindStatusFlags() {
+	statusFlags := packageStatusCmd.Flags()
+	statusFlags.StringVarP(&pkgConfig.StatusOpts.OutputFormat, "output", "o", "", lang.CmdPackageStatusFlagOutput)
+}
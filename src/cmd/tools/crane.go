@@ -17,9 +17,11 @@ import (
 	"github.com/racer159/jackal/src/cmd/common"
 	"github.com/racer159/jackal/src/config"
 	"github.com/racer159/jackal/src/config/lang"
+	"github.com/racer159/jackal/src/internal/packager/images"
 	"github.com/racer159/jackal/src/pkg/cluster"
 	"github.com/racer159/jackal/src/pkg/message"
 	"github.com/racer159/jackal/src/pkg/transform"
+	"github.com/racer159/jackal/src/pkg/utils"
 	"github.com/racer159/jackal/src/types"
 	"github.com/spf13/cobra"
 )
@@ -77,6 +79,20 @@ func init() {
 	// Always require confirm flag (no viper)
 	pruneCmd.Flags().BoolVar(&config.CommonOptions.Confirm, "confirm", false, lang.CmdToolsRegistryPruneFlagConfirm)
 
+	gcDryRun := false
+	gcCmd := &cobra.Command{
+		Use:   "gc",
+		Short: lang.CmdToolsRegistryGCShort,
+		Long:  lang.CmdToolsRegistryGCLong,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return gcImages(gcDryRun)
+		},
+	}
+
+	// Always require confirm flag (no viper)
+	gcCmd.Flags().BoolVar(&config.CommonOptions.Confirm, "confirm", false, lang.CmdToolsRegistryGCFlagConfirm)
+	gcCmd.Flags().BoolVar(&gcDryRun, "dry-run", false, lang.CmdToolsRegistryGCFlagDryRun)
+
 	craneLogin := craneCmd.NewCmdAuthLogin()
 	craneLogin.Example = ""
 
@@ -92,6 +108,7 @@ func init() {
 	registryCmd.AddCommand(jackalCraneInternalWrapper(craneCmd.NewCmdDelete, &craneOptions, lang.CmdToolsRegistryDeleteExample, 0))
 	registryCmd.AddCommand(jackalCraneInternalWrapper(craneCmd.NewCmdDigest, &craneOptions, lang.CmdToolsRegistryDigestExample, 0))
 	registryCmd.AddCommand(pruneCmd)
+	registryCmd.AddCommand(gcCmd)
 	registryCmd.AddCommand(craneCmd.NewCmdVersion())
 
 	registryCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, lang.CmdToolsRegistryFlagVerbose)
@@ -357,3 +374,85 @@ func doPruneImagesForPackages(jackalState *types.JackalState, jackalPackages []t
 
 	return nil
 }
+
+func gcImages(dryRun bool) error {
+	// Try to connect to a Jackal initialized cluster
+	c, err := cluster.NewCluster()
+	if err != nil {
+		return err
+	}
+
+	// Load the state
+	jackalState, err := c.LoadJackalState()
+	if err != nil {
+		return err
+	}
+
+	// Load the currently deployed packages and their history, as their previous generations can still be rolled back to
+	jackalPackages, errs := c.GetDeployedJackalPackages()
+	if len(errs) > 0 {
+		return lang.ErrUnableToGetPackages
+	}
+	var history []types.DeployedPackageGeneration
+	for _, pkg := range jackalPackages {
+		generations, err := c.GetPackageHistory(pkg.Name)
+		if err != nil {
+			return err
+		}
+		history = append(history, generations...)
+	}
+
+	gc := images.RegistryGC{
+		RegInfo:  jackalState.RegistryInfo,
+		Insecure: config.CommonOptions.Insecure,
+		Packages: jackalPackages,
+		History:  history,
+		DryRun:   dryRun,
+		Confirm: func(report *images.GCReport) bool {
+			printGCReport(report)
+
+			confirm := config.CommonOptions.Confirm
+			if confirm {
+				message.Note(lang.CmdConfirmProvided)
+			} else {
+				prompt := &survey.Confirm{
+					Message: lang.CmdConfirmContinue,
+				}
+				if err := survey.AskOne(prompt, &confirm); err != nil {
+					message.Fatalf(nil, lang.ErrConfirmCancel, err)
+				}
+			}
+			return confirm
+		},
+	}
+
+	report, err := gc.Run()
+	if err != nil {
+		return err
+	}
+
+	reclaimed := utils.ByteFormat(float64(report.ReclaimedBytes), 2)
+	switch {
+	case len(report.Manifests) == 0:
+		message.Note(lang.CmdToolsRegistryGCNoImages)
+	case dryRun:
+		printGCReport(report)
+		message.Notef(lang.CmdToolsRegistryGCDryRun, len(report.Manifests), reclaimed)
+	case report.Deleted:
+		message.Debug(report.BlobGCOutput)
+		message.Successf(lang.CmdToolsRegistryGCSuccess, len(report.Manifests), reclaimed)
+	}
+
+	return nil
+}
+
+func printGCReport(report *images.GCReport) {
+	message.Note(lang.CmdToolsRegistryGCImageList)
+
+	header := []string{"Repository", "Digest", "Tags", "Size"}
+	table := [][]string{}
+	for _, manifest := range report.Manifests {
+		table = append(table, []string{manifest.Repository, manifest.Digest, strings.Join(manifest.Tags, ", "), utils.ByteFormat(float64(manifest.Size), 2)})
+	}
+	message.Table(header, table)
+}
@@ -9,6 +9,8 @@ import (
 	"os"
 
 	"slices"
+	"strings"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/defenseunicorns/pkg/helpers"
@@ -16,12 +18,15 @@ import (
 	"github.com/racer159/jackal/src/cmd/common"
 	"github.com/racer159/jackal/src/config"
 	"github.com/racer159/jackal/src/config/lang"
+	"github.com/racer159/jackal/src/internal/packager/cache"
 	"github.com/racer159/jackal/src/internal/packager/git"
 	"github.com/racer159/jackal/src/internal/packager/helm"
+	"github.com/racer159/jackal/src/internal/packager/rotate"
 	"github.com/racer159/jackal/src/pkg/cluster"
 	"github.com/racer159/jackal/src/pkg/message"
 	"github.com/racer159/jackal/src/pkg/packager/sources"
 	"github.com/racer159/jackal/src/pkg/pki"
+	"github.com/racer159/jackal/src/pkg/utils"
 	"github.com/racer159/jackal/src/pkg/zoci"
 	"github.com/racer159/jackal/src/types"
 	"github.com/sigstore/cosign/v2/pkg/cosign"
@@ -31,6 +36,8 @@ import (
 var subAltNames []string
 var outputDirectory string
 var updateCredsInitOpts types.JackalInitOptions
+var clearCacheOlderThan time.Duration
+var rotateCredsOlderThan time.Duration
 
 var deprecatedGetGitCredsCmd = &cobra.Command{
 	Use:    "get-git-password",
@@ -132,6 +139,7 @@ var updateCredsCmd = &cobra.Command{
 			}
 
 			// Save the final Jackal State
+			newState.CredentialRotation.Record(time.Now(), args...)
 			err = c.SaveJackalState(newState)
 			if err != nil {
 				message.Fatalf(err, lang.ErrSaveState)
@@ -166,12 +174,41 @@ var updateCredsCmd = &cobra.Command{
 	},
 }
 
+var rotateCredsCmd = &cobra.Command{
+	Use:     "rotate-creds",
+	Short:   lang.CmdToolsRotateCredsShort,
+	Long:    lang.CmdToolsRotateCredsLong,
+	Example: lang.CmdToolsRotateCredsExample,
+	Args:    cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			args = rotate.Services
+		} else if !slices.Contains(rotate.Services, args[0]) {
+			cmd.Help()
+			message.Fatalf(nil, lang.CmdToolsRotateCredsInvalidServiceErr, strings.Join(rotate.Services, ", "))
+		}
+
+		c := cluster.NewClusterOrDie()
+		if err := rotate.Credentials(c, args, rotateCredsOlderThan); err != nil {
+			message.Fatal(err, lang.CmdToolsRotateCredsErr)
+		}
+	},
+}
+
 var clearCacheCmd = &cobra.Command{
 	Use:     "clear-cache",
 	Aliases: []string{"c"},
 	Short:   lang.CmdToolsClearCacheShort,
 	Run: func(_ *cobra.Command, _ []string) {
 		message.Notef(lang.CmdToolsClearCacheDir, config.GetAbsCachePath())
+		if clearCacheOlderThan > 0 {
+			removed, freed, err := cache.Prune(clearCacheOlderThan)
+			if err != nil {
+				message.Fatalf(err, lang.CmdToolsClearCacheErr, config.GetAbsCachePath())
+			}
+			message.Successf(lang.CmdToolsClearCachePruneSuccess, removed, utils.ByteFormat(float64(freed), 2), config.GetAbsCachePath())
+			return
+		}
 		if err := os.RemoveAll(config.GetAbsCachePath()); err != nil {
 			message.Fatalf(err, lang.CmdToolsClearCacheErr, config.GetAbsCachePath())
 		}
@@ -323,8 +360,12 @@ func init() {
 
 	updateCredsCmd.Flags().SortFlags = true
 
+	toolsCmd.AddCommand(rotateCredsCmd)
+	rotateCredsCmd.Flags().DurationVar(&rotateCredsOlderThan, "older-than", 0, lang.CmdToolsRotateCredsOlderThanFlag)
+
 	toolsCmd.AddCommand(clearCacheCmd)
 	clearCacheCmd.Flags().StringVar(&config.CommonOptions.CachePath, "jackal-cache", config.JackalDefaultCachePath, lang.CmdToolsClearCacheFlagCachePath)
+	clearCacheCmd.Flags().DurationVar(&clearCacheOlderThan, "older-than", 0, lang.CmdToolsClearCacheFlagOlderThan)
 
 	toolsCmd.AddCommand(downloadInitCmd)
 	downloadInitCmd.Flags().StringVarP(&outputDirectory, "output-directory", "o", "", lang.CmdToolsDownloadInitFlagOutputDirectory)
@@ -37,7 +37,8 @@ const (
 	JackalManagedByLabel     = "app.kubernetes.io/managed-by"
 	JackalCleanupScriptsPath = "/opt/jackal"
 
-	JackalPackagePrefix = "jackal-package-"
+	JackalPackagePrefix        = "jackal-package-"
+	JackalPackageHistoryPrefix = "jackal-history-"
 
 	JackalDeployStage = "Deploy"
 	JackalCreateStage = "Create"
@@ -92,8 +93,9 @@ var (
 	JackalDefaultCachePath = filepath.Join("~", ".jackal-cache")
 
 	// Default Time Vars
-	JackalDefaultTimeout = 15 * time.Minute
-	JackalDefaultRetries = 3
+	JackalDefaultTimeout     = 15 * time.Minute
+	JackalDefaultRetries     = 3
+	JackalDefaultConcurrency = 1
 )
 
 // GetArch returns the arch based on a priority list with options for overriding.
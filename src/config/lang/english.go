@@ -161,9 +161,10 @@ $ jackal init --artifact-push-password={PASSWORD} --artifact-push-username={USER
 
 	CmdInitFlagSet = "Specify deployment variables to set on the command line (KEY=value)"
 
-	CmdInitFlagConfirm      = "Confirm deployment without prompts. Use ONLY with trusted packages. Bypasses prompts to review SBOM, configure variables, select clandestine assets, and review potential disruptions."
-	CmdInitFlagComponents   = "Specify which clandestine assets to install. E.g., --components=git-server,logging"
-	CmdInitFlagStorageClass = "Specify the storage class for the registry and git server. E.g., --storage-class=standard"
+	CmdInitFlagConfirm                = "Confirm deployment without prompts. Use ONLY with trusted packages. Bypasses prompts to review SBOM, configure variables, select clandestine assets, and review potential disruptions."
+	CmdInitFlagComponents             = "Specify which clandestine assets to install. E.g., --components=git-server,logging"
+	CmdInitFlagStorageClass           = "Specify the storage class for the registry and git server. E.g., --storage-class=standard"
+	CmdInitFlagAgentAllowedRegistries = "Registries other than the Jackal registry that pods may pull images from when the 'jackal-agent-policy' component is deployed. E.g., --agent-allowed-registries=registry.internal:5000"
 
 	CmdInitFlagGitURL      = "External git server URL for this Jackal domain"
 	CmdInitFlagGitPushUser = "Username for accessing the git server used by Jackal. User must be able to create repositories via 'git push'"
@@ -183,6 +184,11 @@ $ jackal init --artifact-push-password={PASSWORD} --artifact-push-username={USER
 	CmdInitFlagArtifactPushUser  = "[alpha] Username for accessing the artifact repository used by Jackal. User must be able to upload package artifacts."
 	CmdInitFlagArtifactPushToken = "[alpha] API Token for the push-user to access the artifact repository"
 
+	CmdInitFlagCredentialStore        = "Keep the credentials of the Jackal state in an external store rather than the jackal-state secret, one of: vault"
+	CmdInitFlagCredentialStoreAddress = "Address of the credential store (e.g. the Vault server, defaults to VAULT_ADDR)"
+	CmdInitFlagCredentialStorePath    = "Path within the credential store (e.g. the Vault KV v2 mount and path, defaults to secret/jackal)"
+	CmdInitFlagCredentialStoreToken   = "Token the Jackal Agent and credential rotation job authenticate to the credential store with from within the cluster (needs read and write access to the store path)"
+
 	// jackal internal
 	CmdInternalShort = "Covert arsenal utilized by Jackal operatives"
 
@@ -226,10 +232,13 @@ $ jackal init --artifact-push-password={PASSWORD} --artifact-push-username={USER
 	CmdInternalCrc32Short = "Generates an enigmatic decimal CRC32 for the provided text"
 
 	// jackal package
-	CmdPackageShort             = "Jackal package maneuvers for constructing, deploying, and scrutinizing packages"
-	CmdPackageFlagConcurrency   = "Number of concurrent maneuvers to perform when interacting with a covert package remotely."
-	CmdPackageFlagFlagPublicKey = "Path to a cryptic public key file for validating signed packages"
-	CmdPackageFlagRetries       = "Number of attempts to execute Jackal maneuvers such as git/image pushes or Helm installs"
+	CmdPackageShort                         = "Jackal package maneuvers for constructing, deploying, and scrutinizing packages"
+	CmdPackageFlagConcurrency               = "Number of concurrent maneuvers to perform when interacting with a covert package remotely, and of images to smuggle into the registry at once."
+	CmdPackageFlagFlagPublicKey             = "Path or KMS URI (awskms://, gcpkms://, azurekms://, hashivault://) of a cryptic public key for validating signed packages"
+	CmdPackageFlagFlagCertificateIdentity   = "Identity (e.g. an email or workflow URI) the certificate of a keyless package signature must be issued to, vouched for by --certificate-oidc-issuer"
+	CmdPackageFlagFlagCertificateOIDCIssuer = "OIDC issuer that must have vouched for the --certificate-identity of a keyless package signature"
+	CmdPackageFlagFlagCertificateChain      = "Path to a PEM certificate chain (ending with the root) package signing certificates must chain to instead of the public Fulcio roots, for private certificate authorities operating off the grid"
+	CmdPackageFlagRetries                   = "Number of attempts to execute Jackal maneuvers such as git/image pushes or Helm installs"
 
 	CmdPackageCreateShort = "Conceals a Jackal package from a designated directory or the present directory"
 	CmdPackageCreateLong  = "Compiles an archive of resources and covert dependencies outlined by the 'jackal.yaml' in the specified directory.\n" +
@@ -276,8 +285,10 @@ $ jackal package mirror-resources <your-package.tar.zst> \
 	CmdPackageCreateFlagSbom                  = "Secretly review SBOM contents after creating the package"
 	CmdPackageCreateFlagSbomOut               = "Specify a covert output directory for the SBOMs from the created Jackal package, concealed from prying eyes"
 	CmdPackageCreateFlagSkipSbom              = "Skillfully evade generating SBOM for this package, staying one step ahead"
+	CmdPackageCreateFlagReproducible          = "Leave no fingerprints: build a byte-for-byte reproducible package stamped with SOURCE_DATE_EPOCH (or the Unix epoch) instead of the time, user and terminal of this build (signatures still differ)"
 	CmdPackageCreateFlagMaxPackageSize        = "Define the maximum size of the package in megabytes, packages exceeding this threshold will be fragmented and distributed across multiple agents to avoid detection. Use 0 to disable fragmentation."
-	CmdPackageCreateFlagSigningKey            = "Path to an encrypted private key file for signing packages, hidden from plain sight"
+	CmdPackageCreateFlagSigningKey            = "Path to an encrypted private key file or KMS URI (awskms://, gcpkms://, azurekms://, hashivault://) for signing packages, hidden from plain sight"
+	CmdPackageCreateFlagKeyless               = "Sign the package without a key, using a short-lived Fulcio certificate for your OIDC identity recorded in the Rekor transparency log"
 	CmdPackageCreateFlagSigningKeyPassword    = "Unlock code for the encrypted private key file used for signing packages, divulged only to the initiated"
 	CmdPackageCreateFlagDeprecatedKey         = "[Deprecated] Path to an encrypted private key file for signing packages (use --signing-key instead), a relic from the past"
 	CmdPackageCreateFlagDeprecatedKeyPassword = "[Deprecated] Unlock code for the encrypted private key file used for signing packages (use --signing-key-pass instead), a deprecated passphrase"
@@ -295,6 +306,10 @@ $ jackal package mirror-resources <your-package.tar.zst> \
 	CmdPackageDeployFlagSget                           = "[Deprecated] Path to a public sget key file for remote packages signed via cosign. This flag will be removed in v1.0.0. Please use the --key flag instead, a relic of the past"
 	CmdPackageDeployFlagSkipWebhooks                   = "[alpha] Evade detection by skipping the waiting period for external webhooks to execute as each package component is deployed, slipping through the cracks"
 	CmdPackageDeployFlagTimeout                        = "Timeout for executing covert Helm operations such as installs and rollbacks, staying ahead of the pursuit"
+	CmdPackageDeployFlagConcurrency                    = "Maximum number of components to deploy at the same time when the package declares component dependencies with dependsOn, moving in parallel under cover"
+	CmdPackageDeployFlagAtomic                         = "If any component fails to deploy, roll back every Helm release touched during this deployment and restore the previous package secret, leaving no trace behind"
+	CmdPackageDeployFlagDryRun                         = "Rehearse the deployment without touching the cluster. Renders every selected component with its variables and the Jackal agent's image and git URL mutations, and writes the final manifests and the images and repos that would be pushed to --dry-run-output"
+	CmdPackageDeployFlagDryRunOutput                   = "Safe house for the dry run intelligence report (defaults to jackal-dry-run-<package name>)"
 	CmdPackageDeployValidateArchitectureErr            = "This package architecture is %s, but the target cluster only supports the %s architecture(s). These architectures must be compatible when \"images\" are present, a critical mismatch detected"
 	CmdPackageDeployValidateLastNonBreakingVersionWarn = "The version of this Jackal binary '%s' is lower than the LastNonBreakingVersion of '%s'. You may need to upgrade your Jackal version to at least '%s' to deploy this package, a shadow from the past haunting the present"
 	CmdPackageDeployInvalidCLIVersionWarn              = "CLIVersion is set to '%s' which could compromise security during package creation and deployment. To avoid any risks, please set the value to a valid semantic version for this version of Jackal, a subtle warning ignored at your own peril"
@@ -309,6 +324,7 @@ $ jackal package mirror-resources <your-package.tar.zst> \
 
 	CmdPackageRemoveShort          = "Eliminate a Jackal package that has been deployed already (operates in stealth mode)"
 	CmdPackageRemoveFlagConfirm    = "MANDATORY. Confirm the removal action to avoid arousing suspicion"
+	CmdPackageRemoveFlagKeepImages = "Leave the package's images in the registry instead of removing those that no other package references"
 	CmdPackageRemoveFlagComponents = "Comma-separated list of components to remove. This list will be adhered to regardless of a component's 'required' or 'default' status. Gloating component names with '*' and deselecting components with a leading '-' are also supported, operating under the radar"
 	CmdPackageRemoveTarballErr     = "Invalid tarball path provided, a false lead"
 	CmdPackageRemoveExtractErr     = "Unable to extract the package contents, thwarted by unforeseen obstacles"
@@ -324,7 +340,8 @@ $ jackal package publish my-package.tar oci://my-registry.com/my-namespace
 # Disseminate a skeleton package to a remote registry
 $ jackal package publish ./path/to/dir oci://my-registry.com/my-namespace
 `
-	CmdPackagePublishFlagSigningKey         = "Path to an encrypted private key file for signing or re-signing packages with a new key, kept under lock and key"
+	CmdPackagePublishFlagSigningKey         = "Path to an encrypted private key file or KMS URI (awskms://, gcpkms://, azurekms://, hashivault://) for signing or re-signing packages with a new key, kept under lock and key"
+	CmdPackagePublishFlagKeyless            = "Sign or re-sign the package without a key, using a short-lived Fulcio certificate for your OIDC identity recorded in the Rekor transparency log"
 	CmdPackagePublishFlagSigningKeyPassword = "Unlock code for the encrypted private key file used for publishing packages, disclosed only to those in the know"
 	CmdPackagePublishErr                    = "Failed to publish package: %s, foiled by unforeseen circumstances"
 
@@ -337,10 +354,60 @@ $ jackal package pull oci://ghcr.io/racer159/packages/dos-games:1.0.0
 $ jackal package pull oci://ghcr.io/racer159/packages/dos-games:1.0.0 -a arm64
 
 # Exfiltrate a skeleton package
-$ jackal package pull oci://ghcr.io/racer159/packages/dos-games:1.0.0 -a skeleton`
+$ jackal package pull oci://ghcr.io/racer159/packages/dos-games:1.0.0 -a skeleton
+
+# Exfiltrate a package that is deployed within the cluster, rebuilt from the Jackal registry and git server
+$ jackal package pull dos-games`
 	CmdPackagePullFlagOutputDirectory = "Specify the safe house for the exfiltrated Jackal package, under the radar"
 	CmdPackagePullErr                 = "Failed to exfiltrate package: %s, foiled by unforeseen circumstances"
 
+	CmdPackageDiffShort   = "Compare two Jackal packages, or a package against one deployed in the cluster, to expose every change before it lands"
+	CmdPackageDiffLong    = "Reports added and removed components, image digests, chart versions, variables and constants, Big Bang versions and enabled Big Bang packages, and the differences between the rendered manifests of each component. Deployed packages are read back from their Helm releases."
+	CmdPackageDiffExample = `
+# Compare two package tarballs
+$ jackal package diff jackal-package-dos-games-amd64-1.0.0.tar.zst jackal-package-dos-games-amd64-1.1.0.tar.zst
+
+# Compare the package deployed in the cluster against a new version before upgrading
+$ jackal package diff dos-games oci://ghcr.io/racer159/packages/dos-games:1.1.0
+
+# Print the differences as JSON
+$ jackal package diff dos-games jackal-package-dos-games-amd64-1.1.0.tar.zst -o json`
+	CmdPackageDiffFlagOutput     = "Output format for the intelligence report (table|json)"
+	CmdPackageDiffFlagSet        = "Impose variables discreetly on the command line (KEY=value) when rendering the packages"
+	CmdPackageDiffFlagComponents = "Comma-separated list of optional components to render and compare, in addition to the required and default components"
+	CmdPackageDiffErr            = "Failed to diff packages: %s, foiled by unforeseen circumstances"
+
+	CmdPackageHistoryShort   = "Recount the previous generations of a package deployed within the cluster (operates in stealth mode)"
+	CmdPackageHistoryLong    = "Lists the generations of a deployed package that are kept in the cluster, with the components, variables (sensitive values redacted) and Helm release revisions of each. Only the most recent 10 generations are kept."
+	CmdPackageHistoryExample = `
+# Recount the deploy history of a package
+$ jackal package history dos-games
+
+# Print the full deploy history as JSON
+$ jackal package history dos-games -o json`
+	CmdPackageHistoryFlagOutput = "Output format for the dossier (table|json)"
+	CmdPackageHistoryErr        = "Failed to get the deploy history: %s, foiled by unforeseen circumstances"
+
+	CmdPackageRollbackShort   = "Return a deployed package to a previous generation without leaving a trace"
+	CmdPackageRollbackLong    = "Reinstates the Helm release revisions recorded for a previous generation of a deployed package and uninstalls any releases added since. Images, repos, files, data injections and actions are not re-run. The rollback is recorded as a new generation."
+	CmdPackageRollbackExample = `
+# Roll a package back to generation 2
+$ jackal package rollback dos-games --generation 2 --confirm`
+	CmdPackageRollbackFlagGeneration = "MANDATORY. The generation to roll back to, as shown by 'jackal package history'"
+	CmdPackageRollbackFlagConfirm    = "MANDATORY. Confirm the rollback action to avoid arousing suspicion"
+	CmdPackageRollbackErr            = "Failed to roll back the package: %s, a setback encountered"
+
+	CmdPackageStatusShort   = "Surveil a deployed package for drift between what was deployed and the live state of the cluster"
+	CmdPackageStatusLong    = "Compares the resources in the Helm releases of each deployed component against the live objects in the cluster, and the images of the pods they own against the images in the package. Exits with code 2 if any drift is detected, and with code 1 if the check itself fails."
+	CmdPackageStatusExample = `
+# Surveil a deployed package for drift
+$ jackal package status dos-games
+
+# Print the drift as JSON, for use in CI
+$ jackal package status dos-games -o json`
+	CmdPackageStatusFlagOutput = "Output format for the surveillance report (table|json)"
+	CmdPackageStatusErr        = "Failed to check the package for drift: %s, foiled by unforeseen circumstances"
+
 	CmdPackageChoose                = "Select or fabricate the package file, under the radar"
 	CmdPackageChooseErr             = "Selection of package path canceled: %s, foiled by unforeseen circumstances"
 	CmdPackageClusterSourceFallback = "%q doesn't align with any current sources, assuming it's a package deployed within a cluster, a covert operation detected"
@@ -463,6 +530,17 @@ $ jackal tools registry digest reg.example.com/stefanprodan/podinfo:6.4.0
 	CmdToolsRegistryPruneCalculate   = "Under-the-radar calculation of images to prune"
 	CmdToolsRegistryPruneDelete      = "Covert deletion of unused images"
 
+	CmdToolsRegistryGCShort = "Quietly removes the images no deployed package (or its deploy history) references and reclaims their storage from the registry"
+	CmdToolsRegistryGCLong  = "Quietly removes the images no deployed package (or its deploy history) references and reclaims their storage from the registry.\n" +
+		"Images are matched by the digests recorded when they were pushed, so both the checksum and non-checksum tags of a referenced image are kept. " +
+		"For the internal registry the blob garbage collection is also run, which should not overlap with a package deploy."
+	CmdToolsRegistryGCFlagConfirm = "Confirm the covert garbage collection to prevent accidental discoveries"
+	CmdToolsRegistryGCFlagDryRun  = "Report the images that would be removed and the storage reclaimed without leaving a trace"
+	CmdToolsRegistryGCImageList   = "The following unreferenced images will be removed from the registry:"
+	CmdToolsRegistryGCNoImages    = "There are no unreferenced images to remove, covert operations completed"
+	CmdToolsRegistryGCDryRun      = "Dry run: removing %d unreferenced images would reclaim %s"
+	CmdToolsRegistryGCSuccess     = "Removed %d unreferenced images, reclaiming %s"
+
 	CmdToolsRegistryInvalidPlatformErr = "Invalid platform '%s': %s, concealed by unforeseen circumstances"
 	CmdToolsRegistryFlagVerbose        = "Enable debug logs, operating under the radar"
 	CmdToolsRegistryFlagInsecure       = "Allow image references to be fetched without TLS, under the radar"
@@ -526,7 +604,9 @@ jackal tools yq e '.a.b = "cool"' -i file.yaml
 	CmdToolsClearCacheDir           = "Cache directory meticulously configured to: %s"
 	CmdToolsClearCacheErr           = "Encountered an unexpected obstacle while attempting to clear the cache directory %s, concealing our tracks"
 	CmdToolsClearCacheSuccess       = "Successfully erased all traces of the cache from %s, leaving no evidence behind"
-	CmdToolsClearCacheFlagCachePath = "Specify the location of the Jackal artifact cache (images and git repositories), exercising utmost caution"
+	CmdToolsClearCacheFlagCachePath = "Specify the location of the Jackal artifact cache (images, Helm repositories and built components), exercising utmost caution"
+	CmdToolsClearCacheFlagOlderThan = "Only erase cached images, components and packages that have not been used within this duration (e.g. 168h), keeping the active ones in place"
+	CmdToolsClearCachePruneSuccess  = "Quietly pruned %d unused items (%s) from %s"
 
 	CmdToolsCraneNotEnoughArgumentsErr   = "Your current operation lacks the necessary elements for success, requiring a more refined approach"
 	CmdToolsCraneConnectedButBadStateErr = "Despite establishing a connection to a K8s cluster, our attempts to gather Jackal state were thwarted - proceeding without state information: %s"
@@ -635,6 +715,32 @@ $ jackal tools update-creds artifact --artifact-push-username={USERNAME} --artif
 	CmdToolsUpdateCredsUnableUpdateAgent    = "The covert operation to update Jackal Agent TLS secrets encountered unexpected obstacles: %s. We must proceed with caution."
 	CmdToolsUpdateCredsUnableUpdateCreds    = "Our endeavor to update Jackal credentials ended in failure, leaving us exposed to potential threats. We must regroup and reassess our tactics."
 
+	CmdToolsRotateCredsShort   = "Rotates the generated credentials of the Jackal registry, git server and artifact server without any interrogation, fit for a scheduled mission."
+	CmdToolsRotateCredsLong    = "Rotates the credentials Jackal generated for its internal registry, git server and artifact server. The new credentials are verified against each service before the Jackal state and pull secrets are switched over, and workloads using the pull secrets are restarted. If verification fails the services are switched back to the previous credentials, and if a rotation is interrupted the next one keeps whichever credentials each service accepts. Use a service key to rotate a specific service."
+	CmdToolsRotateCredsExample = `
+# Rotate the credentials of every internal Jackal service:
+$ jackal tools rotate-creds
+
+# Rotate the credentials of a specific Jackal service:
+$ jackal tools rotate-creds registry
+$ jackal tools rotate-creds git
+$ jackal tools rotate-creds artifact
+
+# Only rotate credentials that were last rotated more than 30 days ago (e.g. from a daily CronJob):
+$ jackal tools rotate-creds --older-than 720h
+`
+
+	CmdToolsRotateCredsOlderThanFlag     = "Only rotate the credentials of services that were last rotated longer ago than this duration (e.g. 720h). Credentials with no recorded rotation are always rotated."
+	CmdToolsRotateCredsInvalidServiceErr = "Intruder alert! The service key provided cannot be rotated - valid keys include: %s. Exercise caution."
+	CmdToolsRotateCredsExternal          = "The %s credentials are managed outside of Jackal, leaving them to their handlers."
+	CmdToolsRotateCredsNotDue            = "The %s credentials were rotated at %s and are not yet due for rotation."
+	CmdToolsRotateCredsNothingDue        = "No credentials are due for rotation, the operation stands down."
+	CmdToolsRotateCredsVerifying         = "Verifying the new credentials against each service before the old ones are retired"
+	CmdToolsRotateCredsRecovering        = "A previous rotation of the %s credentials was interrupted, determining which credentials the services answer to"
+	CmdToolsRotateCredsRestarted         = "Restarted %d workloads using the rotated credentials"
+	CmdToolsRotateCredsSuccess           = "Rotated the %s credentials"
+	CmdToolsRotateCredsErr               = "Our attempt to rotate Jackal credentials was foiled. We must regroup and reassess our tactics."
+
 	// jackal version
 	CmdVersionShort = "Reveals the version of the enigmatic Jackal binary currently in operation, offering a glimpse into its mysterious origins."
 	CmdVersionLong  = "Unveils the version of the enigmatic Jackal release from which the current binary emerged, shedding light on its cryptic nature."
@@ -655,11 +761,16 @@ const (
 	AgentInfoShutdown       = "Executing graceful shutdown sequence... Initiating self-erasure protocols..."
 	AgentInfoPort           = "Concealed server operational, clandestinely listening on port: %s"
 
+	AgentWarnNotOCIType     = "Standing down: only helm repositories of type 'oci' can be redirected to the Jackal registry, leaving the repository of type (%s) untouched"
+	AgentWarnNotOCIHelmRepo = "Standing down: only OCI helm chart sources can be redirected to the Jackal registry, leaving the helm repository (%s) untouched"
+
 	AgentErrBadRequest             = "Interception failed: unable to decipher request payload: %s"
 	AgentErrBindHandler            = "Intruder alert: Unable to bind the covert webhook handler, risk of exposure imminent."
 	AgentErrCouldNotDeserializeReq = "Decryption failed: unable to deserialize incoming request: %s"
 	AgentErrGetState               = "Covert operation failed: unable to retrieve Jackal state from encrypted file: %w"
 	AgentErrHostnameMatch          = "Hostile entity detected: failed to execute hostname matching protocol: %w"
+	AgentErrImageNotAllowed        = "Access denied: the image %q in container %q is not in the Jackal registry (%s) or an allowed registry (%s)"
+	AgentErrImageNotMappable       = "Access denied: the image %q in container %q cannot be mapped into the Jackal registry: %s"
 	AgentErrImageSwap              = "Initiating diversionary tactics: Unable to substitute the host for (%s)"
 	AgentErrInvalidMethod          = "Red alert: Invalid method detected, only POST requests are authorized"
 	AgentErrInvalidOp              = "Undercover operation compromised: Invalid operation detected: %s"
@@ -669,6 +780,7 @@ const (
 	AgentErrNilReq                 = "Stealth compromised: Malformed admission review detected: request is missing"
 	AgentErrShutdown               = "Initiating emergency protocol: Unable to execute graceful shutdown of undercover operations"
 	AgentErrStart                  = "Abort mission: Failed to initiate covert web server"
+	AgentErrTransformOCIURL        = "Abort mission: Unable to redirect the OCI source (%s) to the Jackal registry: %w"
 	AgentErrUnableTransform        = "Abort mission: Unable to transform provided request; review jackal http proxy logs for decryption assistance"
 )
 
@@ -701,7 +813,13 @@ const (
 	PkgValidateErrComponent               = "Error: Component %q has been flagged for potential exposure: %w"
 	PkgValidateErrComponentReqDefault     = "Error: Component %q cannot simultaneously serve as both essential and default, increasing the risk of exposure."
 	PkgValidateErrComponentReqGrouped     = "Error: Component %q cannot operate both as an essential element and part of a group, heightening risk of exposure."
+	PkgValidateErrComponentDependsOn      = "Error: Component %q depends on %q, an operative that is not part of this package."
+	PkgValidateErrComponentDependsOnSelf  = "Error: Component %q cannot depend on itself, a sure way to blow its cover."
+	PkgValidateErrComponentDependsOnCycle = "Error: Component dependencies have been compromised: %w"
 	PkgValidateErrComponentYOLO           = "Error: Component %q is incompatible with the online-only package flag (metadata.yolo): %w"
+	PkgValidateErrDataInjection           = "Error: Data injection compromised: %w"
+	PkgValidateErrDataInjectionTarget     = "Error: Data injection into %q requires either a selector and container to infiltrate, or a persistentVolumeClaim and loaderImage, but not both."
+	PkgValidateErrDataInjectionImage      = "Error: Data injection into persistentVolumeClaim %q requires a loaderImage from the component's images, got %q."
 	PkgValidateErrGroupMultipleDefaults   = "Error: Group %q has been compromised - multiple default configurations detected (%q, %q)"
 	PkgValidateErrGroupOneComponent       = "Error: Group %q has been compromised - solitary component detected (%q)"
 	PkgValidateErrConstant                = "Error: Covert operation compromised: %w"
@@ -15,15 +15,14 @@ import (
 	"github.com/Masterminds/semver/v3"
 	"github.com/defenseunicorns/pkg/helpers"
 	fluxHelmCtrl "github.com/fluxcd/helm-controller/api/v2beta1"
-	fluxSrcCtrl "github.com/fluxcd/source-controller/api/v1beta2"
+	"github.com/racer159/jackal/src/extensions/flux"
 	"github.com/racer159/jackal/src/internal/packager/helm"
 	"github.com/racer159/jackal/src/pkg/layout"
 	"github.com/racer159/jackal/src/pkg/message"
-	"github.com/racer159/jackal/src/pkg/utils"
 	"github.com/racer159/jackal/src/types"
 	"github.com/racer159/jackal/src/types/extensions"
+	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/chartutil"
-	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/yaml"
 )
@@ -83,15 +82,16 @@ func Run(YOLO bool, tmpPaths *layout.ComponentPaths, c types.JackalComponent) (t
 	bbRepo := fmt.Sprintf("%s@%s", cfg.Repo, cfg.Version)
 
 	// Configure helm to pull down the Big Bang chart.
+	bbChart := types.JackalChart{
+		Name:        bb,
+		Namespace:   bb,
+		URL:         bbRepo,
+		Version:     cfg.Version,
+		ValuesFiles: cfg.ValuesFiles,
+		GitPath:     "./chart",
+	}
 	helmCfg := helm.New(
-		types.JackalChart{
-			Name:        bb,
-			Namespace:   bb,
-			URL:         bbRepo,
-			Version:     cfg.Version,
-			ValuesFiles: cfg.ValuesFiles,
-			GitPath:     "./chart",
-		},
+		bbChart,
 		path.Join(tmpPaths.Temp, bb),
 		path.Join(tmpPaths.Temp, bb, "values"),
 		helm.WithPackageConfig(&types.PackagerConfig{}),
@@ -105,18 +105,25 @@ func Run(YOLO bool, tmpPaths *layout.ComponentPaths, c types.JackalComponent) (t
 
 	// Template the chart so we can see what GitRepositories are being referenced in the
 	// manifests created with the provided Helm.
-	template, _, err := helmCfg.TemplateChart()
+	template, values, err := helmCfg.TemplateChart()
 	if err != nil {
 		return c, fmt.Errorf("unable to template Big Bang Chart: %w", err)
 	}
 
+	// Report what the values files change from the chart's defaults so upgrades between Big Bang versions can be checked offline.
+	bbChartArchive, err := loader.Load(helm.StandardName(path.Join(tmpPaths.Temp, bb), bbChart) + ".tgz")
+	if err != nil {
+		return c, fmt.Errorf("unable to load Big Bang Chart: %w", err)
+	}
+	printValuesReport(cfg.Version, bbChartArchive, values)
+
 	// Add the Big Bang repo to the list of repos to be pulled down by Jackal.
 	if !YOLO {
 		bbRepo := fmt.Sprintf("%s@%s", cfg.Repo, cfg.Version)
 		c.Repos = append(c.Repos, bbRepo)
 	}
 	// Parse the template for GitRepository objects and add them to the list of repos to be pulled down by Jackal.
-	gitRepos, hrDependencies, hrValues, err := findBBResources(template)
+	gitRepos, hrDependencies, hrValues, err := flux.FindResources(template)
 	if err != nil {
 		return c, fmt.Errorf("unable to find Big Bang resources: %w", err)
 	}
@@ -126,16 +133,6 @@ func Run(YOLO bool, tmpPaths *layout.ComponentPaths, c types.JackalComponent) (t
 		}
 	}
 
-	// Generate a list of HelmReleases that need to be deployed in order.
-	dependencies := []utils.Dependency{}
-	for _, hrDep := range hrDependencies {
-		dependencies = append(dependencies, hrDep)
-	}
-	namespacedHelmReleaseNames, err := utils.SortDependencies(dependencies)
-	if err != nil {
-		return c, fmt.Errorf("unable to sort Big Bang HelmReleases: %w", err)
-	}
-
 	// ten minutes in seconds
 	maxTotalSeconds := 10 * 60
 
@@ -145,31 +142,23 @@ func Run(YOLO bool, tmpPaths *layout.ComponentPaths, c types.JackalComponent) (t
 	}
 
 	// Add wait actions for each of the helm releases in generally the order they should be deployed.
-	for _, hrNamespacedName := range namespacedHelmReleaseNames {
-		hr := hrDependencies[hrNamespacedName]
-		action := types.JackalComponentAction{
-			Description:     fmt.Sprintf("Big Bang Helm Release `%s` to be ready", hrNamespacedName),
-			MaxTotalSeconds: &maxTotalSeconds,
-			Wait: &types.JackalComponentActionWait{
-				Cluster: &types.JackalComponentActionWaitCluster{
-					Kind:       "HelmRelease",
-					Identifier: hr.Metadata.Name,
-					Namespace:  hr.Metadata.Namespace,
-					Condition:  "ready",
-				},
-			},
-		}
+	waitActions, err := flux.WaitActions(hrDependencies, maxTotalSeconds)
+	if err != nil {
+		return c, fmt.Errorf("unable to sort Big Bang HelmReleases: %w", err)
+	}
+	for _, action := range waitActions {
+		action.Description = "Big Bang " + action.Description
 
 		// In Big Bang the metrics-server is a special case that only deploy if needed.
 		// The check it, we need to look for the existence of APIService instead of the HelmRelease, which
 		// may not ever be created. See links below for more details.
 		// https://repo1.dso.mil/big-bang/bigbang/-/blob/1.54.0/chart/templates/metrics-server/helmrelease.yaml
-		if hr.Metadata.Name == "metrics-server" {
+		if action.Wait.Cluster.Identifier == "metrics-server" {
 			action.Description = "K8s metric server to exist or be deployed by Big Bang"
 			action.Wait.Cluster = &types.JackalComponentActionWaitCluster{
 				Kind: "APIService",
 				// https://github.com/kubernetes-sigs/metrics-server#compatibility-matrix
-				Identifier: "v1beta1.metrics.k8s.io",
+				Identifier: metricsServerAPIService,
 			}
 		}
 
@@ -215,7 +204,7 @@ func Run(YOLO bool, tmpPaths *layout.ComponentPaths, c types.JackalComponent) (t
 	// Select the images needed to support the repos for this configuration of Big Bang.
 	if !YOLO {
 		for _, hr := range hrDependencies {
-			namespacedName := getNamespacedNameFromMeta(hr.Metadata)
+			namespacedName := flux.NamespacedNameFromMeta(hr.Metadata)
 			gitRepo := gitRepos[hr.NamespacedSource]
 			values := hrValues[namespacedName]
 
@@ -342,114 +331,6 @@ func isValidVersion(version string) (bool, error) {
 	return c.Check(specifiedVersion), nil
 }
 
-// findBBResources takes a list of yaml objects (as a string) and
-// parses it for GitRepository objects that it then parses
-// to return the list of git repos and tags needed.
-func findBBResources(t string) (gitRepos map[string]string, helmReleaseDeps map[string]HelmReleaseDependency, helmReleaseValues map[string]map[string]interface{}, err error) {
-	// Break the template into separate resources.
-	yamls, _ := utils.SplitYAMLToString([]byte(t))
-
-	gitRepos = map[string]string{}
-	helmReleaseDeps = map[string]HelmReleaseDependency{}
-	helmReleaseValues = map[string]map[string]interface{}{}
-	secrets := map[string]corev1.Secret{}
-	configMaps := map[string]corev1.ConfigMap{}
-
-	for _, y := range yamls {
-		var (
-			h fluxHelmCtrl.HelmRelease
-			g fluxSrcCtrl.GitRepository
-			s corev1.Secret
-			c corev1.ConfigMap
-		)
-
-		if err := yaml.Unmarshal([]byte(y), &h); err != nil {
-			continue
-		}
-
-		// If the resource is a HelmRelease, parse it for the dependencies.
-		if h.Kind == fluxHelmCtrl.HelmReleaseKind {
-			var deps []string
-			for _, d := range h.Spec.DependsOn {
-				depNamespacedName := getNamespacedNameFromStr(d.Namespace, d.Name)
-				deps = append(deps, depNamespacedName)
-			}
-
-			namespacedName := getNamespacedNameFromMeta(h.ObjectMeta)
-			srcNamespacedName := getNamespacedNameFromStr(h.Spec.Chart.Spec.SourceRef.Namespace,
-				h.Spec.Chart.Spec.SourceRef.Name)
-
-			helmReleaseDeps[namespacedName] = HelmReleaseDependency{
-				Metadata:               h.ObjectMeta,
-				NamespacedDependencies: deps,
-				NamespacedSource:       srcNamespacedName,
-				ValuesFrom:             h.Spec.ValuesFrom,
-			}
-
-			// Skip the rest as this is not a GitRepository.
-			continue
-		}
-
-		if err := yaml.Unmarshal([]byte(y), &g); err != nil {
-			continue
-		}
-
-		// If the resource is a GitRepository, parse it for the URL and tag.
-		if g.Kind == fluxSrcCtrl.GitRepositoryKind && g.Spec.URL != "" {
-			ref := "master"
-
-			switch {
-			case g.Spec.Reference.Commit != "":
-				ref = g.Spec.Reference.Commit
-
-			case g.Spec.Reference.SemVer != "":
-				ref = g.Spec.Reference.SemVer
-
-			case g.Spec.Reference.Tag != "":
-				ref = g.Spec.Reference.Tag
-
-			case g.Spec.Reference.Branch != "":
-				ref = g.Spec.Reference.Branch
-			}
-
-			// Set the URL and tag in the repo map
-			namespacedName := getNamespacedNameFromMeta(g.ObjectMeta)
-			gitRepos[namespacedName] = fmt.Sprintf("%s@%s", g.Spec.URL, ref)
-		}
-
-		if err := yaml.Unmarshal([]byte(y), &s); err != nil {
-			continue
-		}
-
-		// If the resource is a Secret, parse it so it can be used later for value templating.
-		if s.Kind == "Secret" {
-			namespacedName := getNamespacedNameFromMeta(s.ObjectMeta)
-			secrets[namespacedName] = s
-		}
-
-		if err := yaml.Unmarshal([]byte(y), &c); err != nil {
-			continue
-		}
-
-		// If the resource is a Secret, parse it so it can be used later for value templating.
-		if c.Kind == "ConfigMap" {
-			namespacedName := getNamespacedNameFromMeta(c.ObjectMeta)
-			configMaps[namespacedName] = c
-		}
-	}
-
-	for _, hr := range helmReleaseDeps {
-		namespacedName := getNamespacedNameFromMeta(hr.Metadata)
-		values, err := composeValues(hr, secrets, configMaps)
-		if err != nil {
-			return nil, nil, nil, err
-		}
-		helmReleaseValues[namespacedName] = values
-	}
-
-	return gitRepos, helmReleaseDeps, helmReleaseValues, nil
-}
-
 // addBigBangManifests creates the manifests component for deploying Big Bang.
 func addBigBangManifests(YOLO bool, manifestDir string, cfg *extensions.BigBang) (types.JackalManifest, error) {
 	// Create a manifest component that we add to the jackal package for bigbang.
@@ -3,7 +3,9 @@ package bigbang
 import (
 	"testing"
 
+	"github.com/racer159/jackal/src/types"
 	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
 )
 
 func TestRequiredBigBangVersions(t *testing.T) {
@@ -35,3 +37,121 @@ func TestRequiredBigBangVersions(t *testing.T) {
 	}
 	require.Equal(t, vv, false)
 }
+
+func TestPackageToggles(t *testing.T) {
+	defaults := map[string]any{
+		"istio":    map[string]any{"enabled": true},
+		"kiali":    map[string]any{"enabled": false},
+		"tempo":    map[string]any{"enabled": false},
+		"registry": "registry1.dso.mil",
+		"addons": map[string]any{
+			"argocd":        map[string]any{"enabled": false},
+			"minioOperator": map[string]any{"enabled": true},
+		},
+	}
+	values := map[string]any{
+		"istio": map[string]any{"enabled": false},
+		"kiali": map[string]any{"enabled": true},
+		"addons": map[string]any{
+			"argocd": map[string]any{"enabled": true},
+		},
+	}
+
+	enabled, disabled := packageToggles(defaults, values)
+	require.Equal(t, []string{"addons.argocd", "kiali"}, enabled)
+	require.Equal(t, []string{"istio"}, disabled)
+}
+
+func TestUnknownValues(t *testing.T) {
+	defaults := map[string]any{
+		"istio": map[string]any{
+			"enabled": true,
+			"values":  map[string]any{},
+		},
+		"addons": map[string]any{
+			"argocd": map[string]any{"enabled": false},
+		},
+	}
+	values := map[string]any{
+		"istio": map[string]any{
+			"enabled": true,
+			// Passthrough values are not checked
+			"values": map[string]any{"anything": "goes"},
+		},
+		"istioOperator": map[string]any{"enabled": true},
+		"addons": map[string]any{
+			"argocd": map[string]any{"enabled": true, "sso": map[string]any{"enabled": true}},
+		},
+	}
+
+	require.Equal(t, []string{"addons.argocd.sso", "istioOperator"}, unknownValues(defaults, values, ""))
+}
+
+func TestUnknownChartValues(t *testing.T) {
+	values := map[string]any{
+		"istio": map[string]any{
+			"enabled": true,
+			"values":  map[string]any{"anything": "goes"},
+		},
+		"istioOperator": map[string]any{"enabled": true},
+		"addons": map[string]any{
+			"argocd": map[string]any{"enabled": true, "sso": map[string]any{"enabled": true}},
+		},
+	}
+	defaults := map[string]any{
+		"istio": map[string]any{
+			"enabled": true,
+			"values":  map[string]any{},
+		},
+		"addons": map[string]any{
+			"argocd": map[string]any{"enabled": false},
+		},
+	}
+
+	// The schema allows keys that are not in the default values
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"istio": {
+				"type": "object",
+				"properties": {
+					"enabled": {"type": "boolean"},
+					"values": {"type": "object"}
+				},
+				"additionalProperties": false
+			},
+			"addons": {
+				"type": "object",
+				"additionalProperties": {
+					"type": "object",
+					"properties": {
+						"enabled": {"type": "boolean"},
+						"sso": {"type": "object"}
+					}
+				}
+			}
+		}
+	}`)
+	require.Equal(t, []string{"istioOperator"}, unknownChartValues(&chart.Chart{Values: defaults, Schema: schema}, values))
+
+	// Without a schema (or with one that cannot be parsed) the default values are used
+	require.Equal(t, []string{"addons.argocd.sso", "istioOperator"}, unknownChartValues(&chart.Chart{Values: defaults}, values))
+	require.Equal(t, []string{"addons.argocd.sso", "istioOperator"}, unknownChartValues(&chart.Chart{Values: defaults, Schema: []byte("{")}, values))
+}
+
+func TestEnabledPackages(t *testing.T) {
+	c := types.JackalComponent{}
+	for _, cluster := range []types.JackalComponentActionWaitCluster{
+		{Kind: "HelmRelease", Identifier: "istio", Namespace: "bigbang"},
+		{Kind: "APIService", Identifier: metricsServerAPIService},
+		{Kind: "Deployment", Identifier: "podinfo", Namespace: "podinfo"},
+	} {
+		cluster := cluster
+		c.Actions.OnDeploy.OnSuccess = append(c.Actions.OnDeploy.OnSuccess, types.JackalComponentAction{
+			Wait: &types.JackalComponentActionWait{Cluster: &cluster},
+		})
+	}
+	c.Actions.OnDeploy.OnSuccess = append(c.Actions.OnDeploy.OnSuccess, types.JackalComponentAction{Cmd: "true"})
+
+	require.Equal(t, []string{"bigbang.istio", "bigbang.metrics-server"}, EnabledPackages(c))
+}
@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package bigbang contains the logic for installing Big Bang and Flux
+package bigbang
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/racer159/jackal/src/extensions/flux"
+	"github.com/racer159/jackal/src/pkg/message"
+	"github.com/racer159/jackal/src/types"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// metricsServerAPIService is the APIService that is waited on in place of the metrics-server HelmRelease.
+const metricsServerAPIService = "v1beta1.metrics.k8s.io"
+
+// EnabledPackages returns the namespaced names of the Big Bang HelmReleases that a component built with the Big Bang extension deploys.
+func EnabledPackages(c types.JackalComponent) []string {
+	packages := []string{}
+	for _, action := range c.Actions.OnDeploy.OnSuccess {
+		if action.Wait == nil || action.Wait.Cluster == nil {
+			continue
+		}
+
+		wait := action.Wait.Cluster
+		switch {
+		case wait.Kind == "HelmRelease":
+			packages = append(packages, flux.NamespacedName(wait.Namespace, wait.Identifier))
+		case wait.Kind == "APIService" && wait.Identifier == metricsServerAPIService:
+			packages = append(packages, flux.NamespacedName(bb, "metrics-server"))
+		}
+	}
+
+	sort.Strings(packages)
+	return packages
+}
+
+// packageToggles returns the Big Bang packages (including addons) that the merged values files enable or disable compared to the chart's defaults.
+func packageToggles(defaults, values map[string]any) (enabled, disabled []string) {
+	check := func(prefix string, defaults, values map[string]any) {
+		for name, defaultValue := range defaults {
+			defaultPackage, ok := defaultValue.(map[string]any)
+			if !ok {
+				continue
+			}
+			defaultEnabled, ok := defaultPackage["enabled"].(bool)
+			if !ok {
+				continue
+			}
+
+			isEnabled := defaultEnabled
+			if valuesPackage, ok := values[name].(map[string]any); ok {
+				if valuesEnabled, ok := valuesPackage["enabled"].(bool); ok {
+					isEnabled = valuesEnabled
+				}
+			}
+
+			switch {
+			case isEnabled && !defaultEnabled:
+				enabled = append(enabled, prefix+name)
+			case !isEnabled && defaultEnabled:
+				disabled = append(disabled, prefix+name)
+			}
+		}
+	}
+
+	check("", defaults, values)
+
+	defaultAddons, _ := defaults["addons"].(map[string]any)
+	valuesAddons, _ := values["addons"].(map[string]any)
+	check("addons.", defaultAddons, valuesAddons)
+
+	sort.Strings(enabled)
+	sort.Strings(disabled)
+	return enabled, disabled
+}
+
+// unknownValues returns the keys set in the values files that do not exist in the chart's default values.
+//
+// Maps that are empty in the chart's defaults (like each package's passthrough values) accept any keys.
+func unknownValues(defaults, values map[string]any, prefix string) []string {
+	unknown := []string{}
+	if len(defaults) == 0 {
+		return unknown
+	}
+
+	for key, value := range values {
+		defaultValue, ok := defaults[key]
+		if !ok {
+			unknown = append(unknown, prefix+key)
+			continue
+		}
+
+		defaultMap, defaultIsMap := defaultValue.(map[string]any)
+		valueMap, valueIsMap := value.(map[string]any)
+		if defaultIsMap && valueIsMap {
+			unknown = append(unknown, unknownValues(defaultMap, valueMap, prefix+key+".")...)
+		}
+	}
+
+	sort.Strings(unknown)
+	return unknown
+}
+
+// unknownSchemaValues returns the keys set in the values files that the chart's values schema does not allow.
+//
+// Objects in the schema that do not list their properties, or that allow additional properties, accept any keys.
+func unknownSchemaValues(schema, values map[string]any, prefix string) []string {
+	unknown := []string{}
+	properties, ok := schema["properties"].(map[string]any)
+	if !ok {
+		return unknown
+	}
+	additional, additionalIsSchema := schema["additionalProperties"].(map[string]any)
+	additionalAllowed, _ := schema["additionalProperties"].(bool)
+	_, hasPatterns := schema["patternProperties"]
+
+	for key, value := range values {
+		propertySchema, ok := properties[key].(map[string]any)
+		if !ok {
+			switch {
+			case additionalIsSchema:
+				propertySchema = additional
+			case additionalAllowed || hasPatterns:
+				continue
+			default:
+				unknown = append(unknown, prefix+key)
+				continue
+			}
+		}
+
+		if valueMap, ok := value.(map[string]any); ok {
+			unknown = append(unknown, unknownSchemaValues(propertySchema, valueMap, prefix+key+".")...)
+		}
+	}
+
+	sort.Strings(unknown)
+	return unknown
+}
+
+// unknownChartValues returns the keys set in the values files that the chart does not have, using the chart's values schema when it has one
+// and its default values otherwise.
+func unknownChartValues(bbChart *chart.Chart, values map[string]any) []string {
+	if len(bbChart.Schema) > 0 {
+		schema := map[string]any{}
+		if err := json.Unmarshal(bbChart.Schema, &schema); err != nil {
+			message.Debugf("Unable to parse the values schema of the Big Bang chart, falling back to its default values: %s", err.Error())
+		} else {
+			return unknownSchemaValues(schema, values, "")
+		}
+	}
+
+	return unknownValues(bbChart.Values, values, "")
+}
+
+// printValuesReport prints the Big Bang packages toggled by the values files and warns about values keys the chart does not have.
+func printValuesReport(version string, bbChart *chart.Chart, values map[string]any) {
+	enabled, disabled := packageToggles(bbChart.Values, values)
+
+	message.HeaderInfof("📋 BIG BANG %s VALUES", version)
+	if len(enabled) > 0 {
+		message.Notef("Enabled by the values files: %s", strings.Join(enabled, ", "))
+	}
+	if len(disabled) > 0 {
+		message.Notef("Disabled by the values files: %s", strings.Join(disabled, ", "))
+	}
+	if len(enabled) == 0 && len(disabled) == 0 {
+		message.Note("The values files do not enable or disable any Big Bang packages")
+	}
+
+	for _, key := range unknownChartValues(bbChart, values) {
+		message.Warnf("The values key %q does not exist in the Big Bang %s chart and will be ignored", key, version)
+	}
+}
@@ -0,0 +1,300 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package flux contains the logic for packaging and deploying platforms delivered as Flux HelmReleases
+package flux
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/defenseunicorns/pkg/helpers"
+	fluxSrcCtrl "github.com/fluxcd/source-controller/api/v1beta2"
+	"github.com/racer159/jackal/src/internal/packager/helm"
+	"github.com/racer159/jackal/src/pkg/layout"
+	"github.com/racer159/jackal/src/pkg/message"
+	"github.com/racer159/jackal/src/pkg/utils"
+	"github.com/racer159/jackal/src/types"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+const fluxSystem = "flux-system"
+
+// Run mutates a component that should deploy Flux sources to a set of manifests that contain them,
+// along with the images and git repos of the HelmReleases they enable
+func Run(YOLO bool, tmpPaths *layout.ComponentPaths, c types.JackalComponent) (types.JackalComponent, error) {
+	cfg := c.Extensions.Flux
+	manifests := []types.JackalManifest{}
+
+	// Only install Flux if the package asks for it, the cluster may already have it.
+	if cfg.FluxInstall != "" {
+		fluxManifest, images, err := BuildInstall(tmpPaths.Temp, cfg.FluxInstall, cfg.FluxPatchFiles)
+		if err != nil {
+			return c, err
+		}
+
+		// Add the flux manifests to the list of manifests to be pulled down by Jackal.
+		manifests = append(manifests, fluxManifest)
+
+		if !YOLO {
+			// Add the images to the list of images to be pulled down by Jackal.
+			c.Images = append(c.Images, images...)
+		}
+	}
+
+	namespace := cfg.Namespace
+	if namespace == "" {
+		namespace = fluxSystem
+	}
+	sources := types.JackalManifest{
+		Name:      "flux-sources",
+		Namespace: namespace,
+	}
+
+	// Pull the Flux sources into the component and read them for the HelmReleases they deploy.
+	contents := []string{}
+	for idx, file := range cfg.Manifests {
+		dst := filepath.Join(tmpPaths.Temp, fmt.Sprintf("flux-ext-%d-%s", idx, filepath.Base(file)))
+		if helpers.IsURL(file) {
+			if err := utils.DownloadToFile(file, dst, c.DeprecatedCosignKeyPath); err != nil {
+				return c, fmt.Errorf("unable to download Flux manifest %s: %w", file, err)
+			}
+		} else if err := helpers.CreatePathAndCopy(file, dst); err != nil {
+			return c, fmt.Errorf("unable to copy Flux manifest %s: %w", file, err)
+		}
+
+		content, err := os.ReadFile(dst)
+		if err != nil {
+			return c, err
+		}
+		contents = append(contents, string(content))
+		sources.Files = append(sources.Files, dst)
+	}
+
+	repos, helmReleaseDeps, images, err := discover(strings.Join(contents, "\n---\n"))
+	if err != nil {
+		return c, fmt.Errorf("unable to find Flux resources: %w", err)
+	}
+
+	if !YOLO {
+		c.Repos = append(c.Repos, repos...)
+		c.Images = helpers.Unique(append(c.Images, images...))
+	}
+
+	// ten minutes in seconds
+	maxTotalSeconds := 10 * 60
+
+	defaultMaxTotalSeconds := c.Actions.OnDeploy.Defaults.MaxTotalSeconds
+	if defaultMaxTotalSeconds > maxTotalSeconds {
+		maxTotalSeconds = defaultMaxTotalSeconds
+	}
+
+	// Add wait actions for each of the enabled helm releases in generally the order they should be deployed.
+	waitActions, err := WaitActions(helmReleaseDeps, maxTotalSeconds)
+	if err != nil {
+		return c, err
+	}
+	c.Actions.OnDeploy.OnSuccess = append(c.Actions.OnDeploy.OnSuccess, waitActions...)
+
+	// Prepend the Flux manifests to the list of manifests to be pulled down by Jackal.
+	// This is done so that Flux and its sources are deployed first.
+	manifests = append(manifests, sources)
+	c.Manifests = append(manifests, c.Manifests...)
+
+	return c, nil
+}
+
+// Skeletonize mutates a component so that the Flux manifests and patch files can be contained inside a skeleton package
+func Skeletonize(tmpPaths *layout.ComponentPaths, c types.JackalComponent) (types.JackalComponent, error) {
+	skeletonize := func(files []string, prefix string) error {
+		for idx, file := range files {
+			if helpers.IsURL(file) {
+				continue
+			}
+
+			// Define the name as the file name without the extension.
+			baseName := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+
+			// Add the skeleton name prefix.
+			skelName := fmt.Sprintf("%s-%d-%s.yaml", prefix, idx, baseName)
+
+			rel := filepath.Join(layout.TempDir, skelName)
+			dst := filepath.Join(tmpPaths.Base, rel)
+
+			if err := helpers.CreatePathAndCopy(file, dst); err != nil {
+				return err
+			}
+
+			files[idx] = rel
+		}
+		return nil
+	}
+
+	if err := skeletonize(c.Extensions.Flux.Manifests, "flux-skel-manifest"); err != nil {
+		return c, err
+	}
+	if err := skeletonize(c.Extensions.Flux.FluxPatchFiles, "flux-skel-patch"); err != nil {
+		return c, err
+	}
+
+	return c, nil
+}
+
+// Compose mutates a component so that its local paths are relative to the provided path
+//
+// additionally, it will merge any overrides
+func Compose(c *types.JackalComponent, override types.JackalComponent, relativeTo string) {
+	if override.Extensions.Flux == nil {
+		return
+	}
+
+	fix := func(files []string) {
+		for idx, file := range files {
+			if helpers.IsURL(file) {
+				continue
+			}
+			files[idx] = filepath.Join(relativeTo, file)
+		}
+	}
+	fix(override.Extensions.Flux.Manifests)
+	fix(override.Extensions.Flux.FluxPatchFiles)
+
+	if c.Extensions.Flux == nil {
+		c.Extensions.Flux = override.Extensions.Flux
+	} else {
+		c.Extensions.Flux.Manifests = append(c.Extensions.Flux.Manifests, override.Extensions.Flux.Manifests...)
+		c.Extensions.Flux.FluxPatchFiles = append(c.Extensions.Flux.FluxPatchFiles, override.Extensions.Flux.FluxPatchFiles...)
+	}
+}
+
+// discover finds the git repos, enabled HelmReleases and images of a set of Flux resources.
+//
+// The chart of each enabled HelmRelease is rendered with its values so that the HelmReleases created by umbrella charts are found as well.
+func discover(t string) (repos []string, helmReleaseDeps map[string]HelmReleaseDependency, images []string, err error) {
+	helmReleaseDeps = map[string]HelmReleaseDependency{}
+	gitRepos := map[string]string{}
+
+	// Charts from the same repo are only downloaded once.
+	gitPaths := map[string]string{}
+	defer func() {
+		for _, gitPath := range gitPaths {
+			_ = os.RemoveAll(gitPath)
+		}
+	}()
+
+	pending := []string{t}
+	for len(pending) > 0 {
+		foundRepos, foundReleases, foundValues, err := FindResources(pending[0])
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		pending = pending[1:]
+
+		for name, repo := range foundRepos {
+			gitRepos[name] = repo
+		}
+
+		names := []string{}
+		for name := range foundReleases {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			hr := foundReleases[name]
+			if _, ok := helmReleaseDeps[name]; ok {
+				continue
+			}
+
+			// Suspended HelmReleases are not deployed so their images and repos are not needed.
+			if hr.Suspend {
+				message.Debugf("Skipping the suspended HelmRelease %s", name)
+				continue
+			}
+			helmReleaseDeps[name] = hr
+
+			if hr.SourceKind != fluxSrcCtrl.GitRepositoryKind {
+				message.Warnf("Unable to find images for the HelmRelease %s, only %s sources are supported", name, fluxSrcCtrl.GitRepositoryKind)
+				continue
+			}
+
+			repo, ok := gitRepos[hr.NamespacedSource]
+			if !ok {
+				return nil, nil, nil, fmt.Errorf("unable to find the %s %s for the HelmRelease %s", fluxSrcCtrl.GitRepositoryKind, hr.NamespacedSource, name)
+			}
+			repos = append(repos, repo)
+
+			gitPath, ok := gitPaths[repo]
+			if !ok {
+				spinner := message.NewProgressSpinner("Downloading %s", repo)
+				gitPath, err = helm.DownloadChartFromGitToTemp(repo, spinner)
+				if err != nil {
+					spinner.Stop()
+					return nil, nil, nil, err
+				}
+				spinner.Success()
+				gitPaths[repo] = gitPath
+			}
+
+			rendered, chartImages, err := findChartResources(filepath.Join(gitPath, hr.ChartPath), hr, foundValues[name])
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("unable to find images for the HelmRelease %s: %w", name, err)
+			}
+			images = append(images, chartImages...)
+			pending = append(pending, rendered)
+		}
+	}
+
+	return helpers.Unique(repos), helmReleaseDeps, helpers.Unique(images), nil
+}
+
+// findChartResources renders the chart of a HelmRelease and returns its resources along with the images it uses.
+func findChartResources(chartPath string, hr HelmReleaseDependency, values chartutil.Values) (rendered string, images []string, err error) {
+	spinner := message.NewProgressSpinner("Discovering images in %s", hr.Name())
+	defer spinner.Stop()
+
+	images, err = helm.FindAnnotatedImagesForChart(chartPath, values)
+	if err != nil {
+		return "", nil, err
+	}
+
+	loadedChart, err := loader.Load(chartPath)
+	if err != nil {
+		return "", nil, err
+	}
+
+	client := action.NewInstall(&action.Configuration{Log: message.Debugf})
+	client.DryRun = true
+	client.Replace = true // Skip the name check.
+	client.ClientOnly = true
+	client.IncludeCRDs = true
+	client.ReleaseName = hr.Metadata.Name
+	client.Namespace = hr.Metadata.Namespace
+
+	release, err := client.Run(loadedChart, values)
+	if err != nil {
+		// Charts that can only be rendered against a cluster still contribute their annotated images.
+		message.Warnf("Unable to render the chart for the HelmRelease %s, only its annotated images were found: %s", hr.Name(), err.Error())
+		spinner.Success()
+		return "", images, nil
+	}
+
+	rendered = release.Manifest
+	for _, hook := range release.Hooks {
+		rendered += fmt.Sprintf("\n---\n%s", hook.Manifest)
+	}
+
+	renderedImages, err := FindImages(rendered)
+	if err != nil {
+		return "", nil, err
+	}
+
+	spinner.Success()
+
+	return rendered, append(images, renderedImages...), nil
+}
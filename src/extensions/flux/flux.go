@@ -0,0 +1,382 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package flux contains the logic for packaging and deploying platforms delivered as Flux HelmReleases
+package flux
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/defenseunicorns/pkg/helpers"
+	fluxHelmCtrl "github.com/fluxcd/helm-controller/api/v2beta1"
+	fluxSrcCtrl "github.com/fluxcd/source-controller/api/v1beta2"
+	"github.com/racer159/jackal/src/internal/packager/kustomize"
+	"github.com/racer159/jackal/src/pkg/utils"
+	"github.com/racer159/jackal/src/types"
+	"helm.sh/helm/v3/pkg/chartutil"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	krustytypes "sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// HelmReleaseDependency is a struct that represents a Flux Helm Release from an HR DependsOn list.
+type HelmReleaseDependency struct {
+	Metadata               metav1.ObjectMeta
+	NamespacedDependencies []string
+	NamespacedSource       string
+	SourceKind             string
+	ChartPath              string
+	Suspend                bool
+	ValuesFrom             []fluxHelmCtrl.ValuesReference
+	Values                 chartutil.Values
+}
+
+// Name returns a namespaced name for the HelmRelease for dependency sorting.
+func (h HelmReleaseDependency) Name() string {
+	return NamespacedNameFromMeta(h.Metadata)
+}
+
+// Dependencies returns a list of namespaced dependencies for the HelmRelease for dependency sorting.
+func (h HelmReleaseDependency) Dependencies() []string {
+	return h.NamespacedDependencies
+}
+
+// BuildInstall builds a kustomization that installs Flux and returns it as a manifest along with the images Flux needs.
+func BuildInstall(baseDir string, resource string, patchFiles []string) (manifest types.JackalManifest, images []string, err error) {
+	localPath := path.Join(baseDir, "flux-install.yaml")
+	kustomizePath := path.Join(baseDir, "kustomization.yaml")
+
+	fluxKustomization := krustytypes.Kustomization{
+		Resources: []string{resource},
+	}
+
+	for _, path := range patchFiles {
+		absFluxPatchPath, _ := filepath.Abs(path)
+		fluxKustomization.Patches = append(fluxKustomization.Patches, krustytypes.Patch{Path: absFluxPatchPath})
+	}
+
+	if err := utils.WriteYaml(kustomizePath, fluxKustomization, helpers.ReadWriteUser); err != nil {
+		return manifest, images, fmt.Errorf("unable to write kustomization: %w", err)
+	}
+
+	// Perform Kustomization now to get the flux.yaml file.
+	if err := kustomize.Build(baseDir, localPath, true); err != nil {
+		return manifest, images, fmt.Errorf("unable to build kustomization: %w", err)
+	}
+
+	// Add the flux.yaml file to the component manifests.
+	manifest = types.JackalManifest{
+		Name:      "flux-system",
+		Namespace: "flux-system",
+		Files:     []string{localPath},
+	}
+
+	// Read the flux.yaml file to get the images.
+	contents, err := os.ReadFile(localPath)
+	if err != nil {
+		return manifest, images, fmt.Errorf("unable to read flux manifest: %w", err)
+	}
+	if images, err = FindImages(string(contents)); err != nil {
+		return manifest, images, fmt.Errorf("unable to read flux images: %w", err)
+	}
+
+	return manifest, images, nil
+}
+
+// FindImages finds the images of the workloads in a set of yaml objects (as a string).
+func FindImages(t string) (images []string, err error) {
+	// Break the manifest into separate resources.
+	yamls, _ := utils.SplitYAML([]byte(t))
+
+	// Loop through each resource and find the images.
+	for _, yaml := range yamls {
+		pod, err := podSpec(yaml)
+		if err != nil {
+			return nil, err
+		}
+		if pod == nil {
+			continue
+		}
+
+		for _, container := range pod.InitContainers {
+			images = append(images, container.Image)
+		}
+
+		for _, container := range pod.Containers {
+			images = append(images, container.Image)
+		}
+	}
+
+	return helpers.Unique(images), nil
+}
+
+// podSpec returns the pod spec of a workload resource, or nil if the resource does not run pods.
+func podSpec(resource *unstructured.Unstructured) (*corev1.PodSpec, error) {
+	var workload interface{}
+	switch resource.GetKind() {
+	case "Deployment":
+		workload = &appsv1.Deployment{}
+	case "StatefulSet":
+		workload = &appsv1.StatefulSet{}
+	case "DaemonSet":
+		workload = &appsv1.DaemonSet{}
+	case "ReplicaSet":
+		workload = &appsv1.ReplicaSet{}
+	case "Job":
+		workload = &batchv1.Job{}
+	case "CronJob":
+		workload = &batchv1.CronJob{}
+	case "Pod":
+		workload = &corev1.Pod{}
+	default:
+		return nil, nil
+	}
+
+	// Convert the unstructured content into the workload.
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(resource.UnstructuredContent(), workload); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", resource.GetKind(), err)
+	}
+
+	switch w := workload.(type) {
+	case *appsv1.Deployment:
+		return &w.Spec.Template.Spec, nil
+	case *appsv1.StatefulSet:
+		return &w.Spec.Template.Spec, nil
+	case *appsv1.DaemonSet:
+		return &w.Spec.Template.Spec, nil
+	case *appsv1.ReplicaSet:
+		return &w.Spec.Template.Spec, nil
+	case *batchv1.Job:
+		return &w.Spec.Template.Spec, nil
+	case *batchv1.CronJob:
+		return &w.Spec.JobTemplate.Spec.Template.Spec, nil
+	case *corev1.Pod:
+		return &w.Spec, nil
+	}
+	return nil, nil
+}
+
+// FindResources takes a list of yaml objects (as a string) and
+// parses it for GitRepository objects that it then parses
+// to return the list of git repos and tags needed.
+func FindResources(t string) (gitRepos map[string]string, helmReleaseDeps map[string]HelmReleaseDependency, helmReleaseValues map[string]map[string]interface{}, err error) {
+	// Break the template into separate resources.
+	yamls, _ := utils.SplitYAMLToString([]byte(t))
+
+	gitRepos = map[string]string{}
+	helmReleaseDeps = map[string]HelmReleaseDependency{}
+	helmReleaseValues = map[string]map[string]interface{}{}
+	secrets := map[string]corev1.Secret{}
+	configMaps := map[string]corev1.ConfigMap{}
+
+	for _, y := range yamls {
+		var (
+			h fluxHelmCtrl.HelmRelease
+			g fluxSrcCtrl.GitRepository
+			s corev1.Secret
+			c corev1.ConfigMap
+		)
+
+		if err := yaml.Unmarshal([]byte(y), &h); err != nil {
+			continue
+		}
+
+		// If the resource is a HelmRelease, parse it for the dependencies.
+		if h.Kind == fluxHelmCtrl.HelmReleaseKind {
+			// Dependencies and sources without a namespace are in the namespace of the HelmRelease
+			var deps []string
+			for _, d := range h.Spec.DependsOn {
+				depNamespace := d.Namespace
+				if depNamespace == "" {
+					depNamespace = h.Namespace
+				}
+				deps = append(deps, NamespacedName(depNamespace, d.Name))
+			}
+
+			sourceRef := h.Spec.Chart.Spec.SourceRef
+			srcNamespace := sourceRef.Namespace
+			if srcNamespace == "" {
+				srcNamespace = h.Namespace
+			}
+
+			namespacedName := NamespacedNameFromMeta(h.ObjectMeta)
+			helmReleaseDeps[namespacedName] = HelmReleaseDependency{
+				Metadata:               h.ObjectMeta,
+				NamespacedDependencies: deps,
+				NamespacedSource:       NamespacedName(srcNamespace, sourceRef.Name),
+				SourceKind:             sourceRef.Kind,
+				ChartPath:              h.Spec.Chart.Spec.Chart,
+				Suspend:                h.Spec.Suspend,
+				ValuesFrom:             h.Spec.ValuesFrom,
+				Values:                 h.GetValues(),
+			}
+
+			// Skip the rest as this is not a GitRepository.
+			continue
+		}
+
+		if err := yaml.Unmarshal([]byte(y), &g); err != nil {
+			continue
+		}
+
+		// If the resource is a GitRepository, parse it for the URL and tag.
+		if g.Kind == fluxSrcCtrl.GitRepositoryKind && g.Spec.URL != "" {
+			ref := "master"
+
+			if g.Spec.Reference != nil {
+				switch {
+				case g.Spec.Reference.Commit != "":
+					ref = g.Spec.Reference.Commit
+
+				case g.Spec.Reference.SemVer != "":
+					ref = g.Spec.Reference.SemVer
+
+				case g.Spec.Reference.Tag != "":
+					ref = g.Spec.Reference.Tag
+
+				case g.Spec.Reference.Branch != "":
+					ref = g.Spec.Reference.Branch
+				}
+			}
+
+			// Set the URL and tag in the repo map
+			namespacedName := NamespacedNameFromMeta(g.ObjectMeta)
+			gitRepos[namespacedName] = fmt.Sprintf("%s@%s", g.Spec.URL, ref)
+		}
+
+		if err := yaml.Unmarshal([]byte(y), &s); err != nil {
+			continue
+		}
+
+		// If the resource is a Secret, parse it so it can be used later for value templating.
+		if s.Kind == "Secret" {
+			namespacedName := NamespacedNameFromMeta(s.ObjectMeta)
+			secrets[namespacedName] = s
+		}
+
+		if err := yaml.Unmarshal([]byte(y), &c); err != nil {
+			continue
+		}
+
+		// If the resource is a Secret, parse it so it can be used later for value templating.
+		if c.Kind == "ConfigMap" {
+			namespacedName := NamespacedNameFromMeta(c.ObjectMeta)
+			configMaps[namespacedName] = c
+		}
+	}
+
+	for _, hr := range helmReleaseDeps {
+		namespacedName := NamespacedNameFromMeta(hr.Metadata)
+		values, err := ComposeValues(hr, secrets, configMaps)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		helmReleaseValues[namespacedName] = values
+	}
+
+	return gitRepos, helmReleaseDeps, helmReleaseValues, nil
+}
+
+// ComposeValues composes values from a Flux HelmRelease and Secrets Map
+// (loosely based on upstream https://github.com/fluxcd/helm-controller/blob/main/controllers/helmrelease_controller.go#L551)
+func ComposeValues(hr HelmReleaseDependency, secrets map[string]corev1.Secret, configMaps map[string]corev1.ConfigMap) (valuesMap chartutil.Values, err error) {
+	valuesMap = chartutil.Values{}
+
+	for _, v := range hr.ValuesFrom {
+		var valuesData string
+		namespacedName := NamespacedName(hr.Metadata.Namespace, v.Name)
+
+		switch v.Kind {
+		case "ConfigMap":
+			cm, ok := configMaps[namespacedName]
+			if !ok {
+				return nil, fmt.Errorf("could not find values %s '%s'", v.Kind, namespacedName)
+			}
+
+			valuesData, ok = cm.Data[v.GetValuesKey()]
+			if !ok {
+				return nil, fmt.Errorf("missing key '%s' in %s '%s'", v.GetValuesKey(), v.Kind, namespacedName)
+			}
+		case "Secret":
+			sec, ok := secrets[namespacedName]
+			if !ok {
+				return nil, fmt.Errorf("could not find values %s '%s'", v.Kind, namespacedName)
+			}
+
+			valuesData, ok = sec.StringData[v.GetValuesKey()]
+			if !ok {
+				data, ok := sec.Data[v.GetValuesKey()]
+				if !ok {
+					return nil, fmt.Errorf("missing key '%s' in %s '%s'", v.GetValuesKey(), v.Kind, namespacedName)
+				}
+				valuesData = string(data)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported ValuesReference kind '%s'", v.Kind)
+		}
+
+		values, err := chartutil.ReadValues([]byte(valuesData))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read values from key '%s' in %s '%s': %w", v.GetValuesKey(), v.Kind, hr.Name(), err)
+		}
+
+		valuesMap = helpers.MergeMapRecursive(valuesMap, values)
+	}
+
+	// Inline values take precedence over values from references
+	return helpers.MergeMapRecursive(valuesMap, hr.Values), nil
+}
+
+// WaitActions returns actions that wait for each of the HelmReleases to be ready in generally the order they should be deployed.
+func WaitActions(helmReleaseDeps map[string]HelmReleaseDependency, maxTotalSeconds int) ([]types.JackalComponentAction, error) {
+	// Generate a list of HelmReleases that need to be deployed in order.
+	dependencies := []utils.Dependency{}
+	for _, hrDep := range helmReleaseDeps {
+		dependencies = append(dependencies, hrDep)
+	}
+	namespacedHelmReleaseNames, err := utils.SortDependencies(dependencies)
+	if err != nil {
+		return nil, fmt.Errorf("unable to sort HelmReleases: %w", err)
+	}
+
+	actions := []types.JackalComponentAction{}
+	for _, hrNamespacedName := range namespacedHelmReleaseNames {
+		hr, ok := helmReleaseDeps[hrNamespacedName]
+		if !ok {
+			// Dependencies outside of the set are not waited on
+			continue
+		}
+		actions = append(actions, types.JackalComponentAction{
+			Description:     fmt.Sprintf("Helm Release `%s` to be ready", hrNamespacedName),
+			MaxTotalSeconds: &maxTotalSeconds,
+			Wait: &types.JackalComponentActionWait{
+				Cluster: &types.JackalComponentActionWaitCluster{
+					Kind:       "HelmRelease",
+					Identifier: hr.Metadata.Name,
+					Namespace:  hr.Metadata.Namespace,
+					Condition:  "ready",
+				},
+			},
+		})
+	}
+
+	return actions, nil
+}
+
+// NamespacedNameFromMeta returns the namespaced name of an object.
+func NamespacedNameFromMeta(o metav1.ObjectMeta) string {
+	return NamespacedName(o.Namespace, o.Name)
+}
+
+// NamespacedName returns the namespaced name of an object.
+func NamespacedName(namespace, name string) string {
+	return fmt.Sprintf("%s.%s", namespace, name)
+}
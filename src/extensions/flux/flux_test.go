@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package flux contains the logic for packaging and deploying platforms delivered as Flux HelmReleases
+package flux
+
+import (
+	"testing"
+
+	"github.com/racer159/jackal/src/types"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+const platform = `
+apiVersion: source.toolkit.fluxcd.io/v1beta2
+kind: GitRepository
+metadata:
+  name: platform
+  namespace: flux-system
+spec:
+  url: https://github.com/racer159/platform.git
+  ref:
+    tag: 1.2.3
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: podinfo-values
+  namespace: podinfo
+data:
+  values.yaml: cmVwbGljYUNvdW50OiAyCmltYWdlOgogIHRhZzogNi41LjAK
+---
+apiVersion: helm.toolkit.fluxcd.io/v2beta1
+kind: HelmRelease
+metadata:
+  name: podinfo
+  namespace: podinfo
+spec:
+  dependsOn:
+  - name: redis
+  chart:
+    spec:
+      chart: ./charts/podinfo
+      sourceRef:
+        kind: GitRepository
+        name: platform
+        namespace: flux-system
+  valuesFrom:
+  - kind: Secret
+    name: podinfo-values
+  values:
+    image:
+      tag: 6.6.0
+---
+apiVersion: helm.toolkit.fluxcd.io/v2beta1
+kind: HelmRelease
+metadata:
+  name: redis
+  namespace: podinfo
+spec:
+  suspend: true
+  chart:
+    spec:
+      chart: ./charts/redis
+      sourceRef:
+        kind: HelmRepository
+        name: bitnami
+`
+
+func TestFindResources(t *testing.T) {
+	t.Parallel()
+
+	gitRepos, helmReleaseDeps, helmReleaseValues, err := FindResources(platform)
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]string{"flux-system.platform": "https://github.com/racer159/platform.git@1.2.3"}, gitRepos)
+
+	podinfo := helmReleaseDeps["podinfo.podinfo"]
+	// Dependencies without a namespace are in the namespace of the HelmRelease
+	require.Equal(t, []string{"podinfo.redis"}, podinfo.NamespacedDependencies)
+	require.Equal(t, "flux-system.platform", podinfo.NamespacedSource)
+	require.Equal(t, "GitRepository", podinfo.SourceKind)
+	require.Equal(t, "./charts/podinfo", podinfo.ChartPath)
+	require.False(t, podinfo.Suspend)
+
+	redis := helmReleaseDeps["podinfo.redis"]
+	require.Equal(t, "podinfo.bitnami", redis.NamespacedSource)
+	require.True(t, redis.Suspend)
+
+	// Inline values take precedence over values from a Secret's data
+	require.Equal(t, map[string]interface{}{
+		"replicaCount": float64(2),
+		"image":        map[string]interface{}{"tag": "6.6.0"},
+	}, map[string]interface{}(chartutil.Values(helmReleaseValues["podinfo.podinfo"])))
+}
+
+func TestFindImages(t *testing.T) {
+	t.Parallel()
+
+	rendered := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: podinfo
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: busybox:1.36
+      containers:
+      - name: podinfo
+        image: ghcr.io/stefanprodan/podinfo:6.6.0
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: cleanup
+spec:
+  schedule: "@daily"
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: cleanup
+            image: busybox:1.36
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: podinfo
+`
+
+	images, err := FindImages(rendered)
+	require.NoError(t, err)
+	require.Equal(t, []string{"busybox:1.36", "ghcr.io/stefanprodan/podinfo:6.6.0"}, images)
+}
+
+func TestWaitActions(t *testing.T) {
+	t.Parallel()
+
+	_, helmReleaseDeps, _, err := FindResources(platform)
+	require.NoError(t, err)
+
+	// Only wait on the releases that are enabled
+	delete(helmReleaseDeps, "podinfo.redis")
+
+	actions, err := WaitActions(helmReleaseDeps, 600)
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+	require.Equal(t, &types.JackalComponentActionWaitCluster{
+		Kind:       "HelmRelease",
+		Identifier: "podinfo",
+		Namespace:  "podinfo",
+		Condition:  "ready",
+	}, actions[0].Wait.Cluster)
+	require.Equal(t, 600, *actions[0].MaxTotalSeconds)
+}
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package hooks contains the mutation hooks for the Jackal agent.
+package hooks
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/racer159/jackal/src/config/lang"
+	"github.com/racer159/jackal/src/internal/agent/mutate"
+	"github.com/racer159/jackal/src/internal/agent/operations"
+	"github.com/racer159/jackal/src/pkg/message"
+	"github.com/racer159/jackal/src/types"
+	v1 "k8s.io/api/admission/v1"
+)
+
+// ArgoGenerator represents a subset of an Argo ApplicationSet generator needed for Jackal Git URL mutations
+type ArgoGenerator struct {
+	Git *struct {
+		RepoURL string `json:"repoURL"`
+	} `json:"git,omitempty"`
+	Matrix *struct {
+		Generators []ArgoGenerator `json:"generators"`
+	} `json:"matrix,omitempty"`
+	Merge *struct {
+		Generators []ArgoGenerator `json:"generators"`
+	} `json:"merge,omitempty"`
+}
+
+// ArgoApplicationSet represents a subset of the Argo ApplicationSet object needed for Jackal Git URL mutations
+type ArgoApplicationSet struct {
+	Spec struct {
+		Generators []ArgoGenerator `json:"generators"`
+	} `json:"spec"`
+}
+
+// NewApplicationSetMutationHook creates a new instance of the ArgoCD ApplicationSet mutation hook.
+func NewApplicationSetMutationHook() operations.Hook {
+	message.Debug("hooks.NewApplicationSetMutationHook()")
+	return operations.Hook{
+		Create: mutateApplicationSet,
+		Update: mutateApplicationSet,
+	}
+}
+
+// mutateApplicationSet mutates the git generator urls to point to the git server defined in the JackalState.
+//
+// The template is left alone as the Applications it generates are mutated by the Application hook.
+func mutateApplicationSet(r *v1.AdmissionRequest) (result *operations.Result, err error) {
+
+	var (
+		jackalState *types.JackalState
+
+		isUpdate = r.Operation == v1.Update
+	)
+
+	// Form the jackalState.GitServer.Address from the jackalState
+	if jackalState, err = getJackalState(); err != nil {
+		return nil, fmt.Errorf(lang.AgentErrGetState, err)
+	}
+
+	message.Debugf("Using the url of (%s) to mutate the ArgoCD ApplicationSet", jackalState.GitServer.Address)
+
+	// parse to simple struct to read the git urls
+	src := &ArgoApplicationSet{}
+
+	if err = json.Unmarshal(r.Object.Raw, &src); err != nil {
+		return nil, fmt.Errorf(lang.ErrUnmarshal, err)
+	}
+
+	message.Debugf("Data %v", string(r.Object.Raw))
+
+	return &operations.Result{
+		Allowed:  true,
+		PatchOps: populateArgoGeneratorPatchOperations(jackalState, "/spec/generators", src.Spec.Generators, isUpdate),
+	}, nil
+}
+
+// Patch updates of the git generators of an Argo ApplicationSet, including the generators nested in matrix and merge generators.
+func populateArgoGeneratorPatchOperations(jackalState *types.JackalState, path string, generators []ArgoGenerator, isUpdate bool) []operations.PatchOperation {
+	var patches []operations.PatchOperation
+	for idx, generator := range generators {
+		generatorPath := fmt.Sprintf("%s/%d", path, idx)
+
+		if generator.Git != nil && generator.Git.RepoURL != "" {
+			if patchedURL, ok := mutate.ArgoGeneratorURL(jackalState, generator.Git.RepoURL, isUpdate); ok {
+				patches = append(patches, operations.ReplacePatchOperation(generatorPath+"/git/repoURL", patchedURL))
+			}
+		}
+
+		if generator.Matrix != nil {
+			patches = append(patches, populateArgoGeneratorPatchOperations(jackalState, generatorPath+"/matrix/generators", generator.Matrix.Generators, isUpdate)...)
+		}
+		if generator.Merge != nil {
+			patches = append(patches, populateArgoGeneratorPatchOperations(jackalState, generatorPath+"/merge/generators", generator.Merge.Generators, isUpdate)...)
+		}
+	}
+	return patches
+}
@@ -9,12 +9,10 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"github.com/defenseunicorns/pkg/helpers"
 	"github.com/racer159/jackal/src/config/lang"
+	"github.com/racer159/jackal/src/internal/agent/mutate"
 	"github.com/racer159/jackal/src/internal/agent/operations"
-	"github.com/racer159/jackal/src/internal/agent/state"
 	"github.com/racer159/jackal/src/pkg/message"
-	"github.com/racer159/jackal/src/pkg/transform"
 	"github.com/racer159/jackal/src/types"
 	v1 "k8s.io/api/admission/v1"
 )
@@ -22,7 +20,9 @@ import (
 // ArgoRepository represents a subset of the Argo Repository object needed for Jackal Git URL mutations
 type ArgoRepository struct {
 	Data struct {
-		URL string `json:"url"`
+		URL       string `json:"url"`
+		Type      string `json:"type"`
+		EnableOCI string `json:"enableOCI"`
 	}
 }
 
@@ -41,14 +41,12 @@ func mutateRepository(r *v1.AdmissionRequest) (result *operations.Result, err er
 	var (
 		jackalState *types.JackalState
 		patches     []operations.PatchOperation
-		isPatched   bool
 
-		isCreate = r.Operation == v1.Create
 		isUpdate = r.Operation == v1.Update
 	)
 
 	// Form the jackalState.GitServer.Address from the jackalState
-	if jackalState, err = state.GetJackalStateFromAgentPod(); err != nil {
+	if jackalState, err = getJackalState(); err != nil {
 		return nil, fmt.Errorf(lang.AgentErrGetState, err)
 	}
 
@@ -65,27 +63,23 @@ func mutateRepository(r *v1.AdmissionRequest) (result *operations.Result, err er
 		message.Fatalf("Error decoding URL from Repository Secret %s", src.Data.URL)
 	}
 	src.Data.URL = string(decodedURL)
-	patchedURL := src.Data.URL
-
-	// Check if this is an update operation and the hostname is different from what we have in the jackalState
-	// NOTE: We mutate on updates IF AND ONLY IF the hostname in the request is different from the hostname in the jackalState
-	// NOTE: We are checking if the hostname is different before because we do not want to potentially mutate a URL that has already been mutated.
-	if isUpdate {
-		isPatched, err = helpers.DoHostnamesMatch(jackalState.GitServer.Address, src.Data.URL)
-		if err != nil {
-			return nil, fmt.Errorf(lang.AgentErrHostnameMatch, err)
+
+	var patchedURL string
+
+	// OCI Helm repositories are served from the Jackal registry
+	if isOCIHelmRepository(src) {
+		if patchedURL, err = mutate.OCIURL(jackalState, src.Data.URL, isUpdate); err != nil {
+			return nil, err
 		}
+		return &operations.Result{
+			Allowed:  true,
+			PatchOps: populateArgoRepositoryRegistryPatchOperations(patchedURL, jackalState.RegistryInfo.PullPassword),
+		}, nil
 	}
 
-	// Mutate the repoURL if necessary
-	if isCreate || (isUpdate && !isPatched) {
-		// Mutate the git URL so that the hostname matches the hostname in the Jackal state
-		transformedURL, err := transform.GitURL(jackalState.GitServer.Address, patchedURL, jackalState.GitServer.PushUsername)
-		if err != nil {
-			message.Warnf("Unable to transform the url, using the original url we have: %s", patchedURL)
-		}
-		patchedURL = transformedURL.String()
-		message.Debugf("original url of (%s) got mutated to (%s)", src.Data.URL, patchedURL)
+	// Mutate the git URL so that the hostname matches the hostname in the Jackal state
+	if patchedURL, err = mutate.GitURL(jackalState, src.Data.URL, isUpdate); err != nil {
+		return nil, err
 	}
 
 	// Patch updates of the repo spec
@@ -106,3 +100,20 @@ func populateArgoRepositoryPatchOperations(repoURL string, jackalGitPullPassword
 
 	return patches
 }
+
+// isOCIHelmRepository returns whether an Argo Repository Secret is for a Helm repository that is served over OCI.
+func isOCIHelmRepository(src *ArgoRepository) bool {
+	repoType, _ := base64.StdEncoding.DecodeString(src.Data.Type)
+	enableOCI, _ := base64.StdEncoding.DecodeString(src.Data.EnableOCI)
+	return string(repoType) == "helm" && string(enableOCI) == "true"
+}
+
+// Patch updates of an Argo Repository Secret for an OCI Helm repository.
+func populateArgoRepositoryRegistryPatchOperations(repoURL string, jackalRegistryPullPassword string) []operations.PatchOperation {
+	var patches []operations.PatchOperation
+	patches = append(patches, operations.ReplacePatchOperation("/data/url", base64.StdEncoding.EncodeToString([]byte(repoURL))))
+	patches = append(patches, operations.ReplacePatchOperation("/data/username", base64.StdEncoding.EncodeToString([]byte(types.JackalRegistryPullUser))))
+	patches = append(patches, operations.ReplacePatchOperation("/data/password", base64.StdEncoding.EncodeToString([]byte(jackalRegistryPullPassword))))
+
+	return patches
+}
@@ -0,0 +1,252 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package hooks contains the mutation hooks for the Jackal agent.
+package hooks
+
+import (
+	"testing"
+
+	"github.com/racer159/jackal/src/internal/agent/operations"
+	"github.com/racer159/jackal/src/pkg/transform"
+	"github.com/racer159/jackal/src/types"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/admission/v1"
+)
+
+// newArgoState returns a Jackal state with an external git server and registry.
+func newArgoState() *types.JackalState {
+	return &types.JackalState{
+		GitServer:    types.GitServerInfo{Address: "https://git.example.com", PushUsername: "jackal-git-user"},
+		RegistryInfo: types.RegistryInfo{Address: "127.0.0.1:31999"},
+	}
+}
+
+// jackalGitURL returns the url a git repository is mirrored to in the git server of a Jackal state.
+func jackalGitURL(t *testing.T, jackalState *types.JackalState, repoURL string) string {
+	t.Helper()
+	gitURL, err := transform.GitURL(jackalState.GitServer.Address, repoURL, jackalState.GitServer.PushUsername)
+	require.NoError(t, err)
+	return gitURL.String()
+}
+
+func TestMutateApplication(t *testing.T) {
+	jackalState := newArgoState()
+	setJackalState(t, jackalState)
+
+	podinfoURL := "https://github.com/stefanprodan/podinfo.git"
+	mirroredURL := jackalGitURL(t, jackalState, podinfoURL)
+
+	tests := []struct {
+		name     string
+		op       v1.Operation
+		app      *ArgoApplication
+		expected []operations.PatchOperation
+	}{
+		{
+			name: "create a git source",
+			op:   v1.Create,
+			app:  newArgoApplication(Source{RepoURL: podinfoURL}),
+			expected: []operations.PatchOperation{
+				operations.ReplacePatchOperation("/spec/source/repoURL", mirroredURL),
+			},
+		},
+		{
+			name: "update a git source from another server",
+			op:   v1.Update,
+			app:  newArgoApplication(Source{RepoURL: podinfoURL}),
+			expected: []operations.PatchOperation{
+				operations.ReplacePatchOperation("/spec/source/repoURL", mirroredURL),
+			},
+		},
+		{
+			name: "update a git source already pointed at the Jackal git server",
+			op:   v1.Update,
+			app:  newArgoApplication(Source{RepoURL: mirroredURL}),
+			expected: []operations.PatchOperation{
+				operations.ReplacePatchOperation("/spec/source/repoURL", mirroredURL),
+			},
+		},
+		{
+			name: "create a Helm repository source",
+			op:   v1.Create,
+			app:  newArgoApplication(Source{RepoURL: "https://stefanprodan.github.io/podinfo", Chart: "podinfo"}),
+		},
+		{
+			name: "create multiple sources",
+			op:   v1.Create,
+			app: newArgoApplication(Source{}, []Source{
+				{RepoURL: "ghcr.io/stefanprodan/charts", Chart: "podinfo"},
+				{RepoURL: "https://stefanprodan.github.io/podinfo", Chart: "podinfo"},
+				{RepoURL: podinfoURL},
+			}...),
+			expected: []operations.PatchOperation{
+				operations.ReplacePatchOperation("/spec/sources/0/repoURL", "127.0.0.1:31999/stefanprodan/charts"),
+				operations.ReplacePatchOperation("/spec/sources/2/repoURL", mirroredURL),
+			},
+		},
+		{
+			name: "update multiple sources already pointed at Jackal",
+			op:   v1.Update,
+			app: newArgoApplication(Source{}, []Source{
+				{RepoURL: "127.0.0.1:31999/stefanprodan/charts", Chart: "podinfo"},
+				{RepoURL: mirroredURL},
+			}...),
+			expected: []operations.PatchOperation{
+				operations.ReplacePatchOperation("/spec/sources/0/repoURL", "127.0.0.1:31999/stefanprodan/charts"),
+				operations.ReplacePatchOperation("/spec/sources/1/repoURL", mirroredURL),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := mutateApplication(newAdmissionRequest(t, tt.op, tt.app))
+			require.NoError(t, err)
+			require.True(t, result.Allowed)
+			require.Equal(t, tt.expected, result.PatchOps)
+		})
+	}
+}
+
+// newArgoApplication returns an Argo Application with the given source and sources.
+func newArgoApplication(source Source, sources ...Source) *ArgoApplication {
+	app := &ArgoApplication{}
+	app.Spec.Source = source
+	app.Spec.Sources = sources
+	return app
+}
+
+func TestPopulateArgoGeneratorPatchOperations(t *testing.T) {
+	jackalState := newArgoState()
+
+	podinfoURL := "https://github.com/stefanprodan/podinfo.git"
+	mirroredPodinfoURL := jackalGitURL(t, jackalState, podinfoURL)
+	fluxURL := "https://github.com/fluxcd/flux2-kustomize-helm-example.git"
+	mirroredFluxURL := jackalGitURL(t, jackalState, fluxURL)
+
+	gitGenerator := func(repoURL string) ArgoGenerator {
+		return ArgoGenerator{Git: &struct {
+			RepoURL string `json:"repoURL"`
+		}{RepoURL: repoURL}}
+	}
+	matrixGenerator := func(generators ...ArgoGenerator) ArgoGenerator {
+		return ArgoGenerator{Matrix: &struct {
+			Generators []ArgoGenerator `json:"generators"`
+		}{Generators: generators}}
+	}
+	mergeGenerator := func(generators ...ArgoGenerator) ArgoGenerator {
+		return ArgoGenerator{Merge: &struct {
+			Generators []ArgoGenerator `json:"generators"`
+		}{Generators: generators}}
+	}
+
+	tests := []struct {
+		name       string
+		generators []ArgoGenerator
+		isUpdate   bool
+		expected   []operations.PatchOperation
+	}{
+		{
+			name:       "git generator",
+			generators: []ArgoGenerator{gitGenerator(podinfoURL)},
+			expected: []operations.PatchOperation{
+				operations.ReplacePatchOperation("/spec/generators/0/git/repoURL", mirroredPodinfoURL),
+			},
+		},
+		{
+			name:       "templated git generator",
+			generators: []ArgoGenerator{gitGenerator("{{ .url }}")},
+		},
+		{
+			name:       "generator without a git repository",
+			generators: []ArgoGenerator{{}, gitGenerator(podinfoURL)},
+			expected: []operations.PatchOperation{
+				operations.ReplacePatchOperation("/spec/generators/1/git/repoURL", mirroredPodinfoURL),
+			},
+		},
+		{
+			name: "matrix generator",
+			generators: []ArgoGenerator{
+				matrixGenerator(gitGenerator(podinfoURL), gitGenerator(fluxURL)),
+			},
+			expected: []operations.PatchOperation{
+				operations.ReplacePatchOperation("/spec/generators/0/matrix/generators/0/git/repoURL", mirroredPodinfoURL),
+				operations.ReplacePatchOperation("/spec/generators/0/matrix/generators/1/git/repoURL", mirroredFluxURL),
+			},
+		},
+		{
+			name: "merge generator nested in a matrix generator",
+			generators: []ArgoGenerator{
+				gitGenerator(podinfoURL),
+				matrixGenerator(
+					gitGenerator("{{ .url }}"),
+					mergeGenerator(gitGenerator(fluxURL), gitGenerator(podinfoURL)),
+				),
+			},
+			expected: []operations.PatchOperation{
+				operations.ReplacePatchOperation("/spec/generators/0/git/repoURL", mirroredPodinfoURL),
+				operations.ReplacePatchOperation("/spec/generators/1/matrix/generators/1/merge/generators/0/git/repoURL", mirroredFluxURL),
+				operations.ReplacePatchOperation("/spec/generators/1/matrix/generators/1/merge/generators/1/git/repoURL", mirroredPodinfoURL),
+			},
+		},
+		{
+			name: "update from another server",
+			generators: []ArgoGenerator{
+				mergeGenerator(gitGenerator(podinfoURL)),
+			},
+			isUpdate: true,
+			expected: []operations.PatchOperation{
+				operations.ReplacePatchOperation("/spec/generators/0/merge/generators/0/git/repoURL", mirroredPodinfoURL),
+			},
+		},
+		{
+			name: "update already pointed at the Jackal git server",
+			generators: []ArgoGenerator{
+				matrixGenerator(gitGenerator(mirroredPodinfoURL), mergeGenerator(gitGenerator(mirroredFluxURL))),
+			},
+			isUpdate: true,
+			expected: []operations.PatchOperation{
+				operations.ReplacePatchOperation("/spec/generators/0/matrix/generators/0/git/repoURL", mirroredPodinfoURL),
+				operations.ReplacePatchOperation("/spec/generators/0/matrix/generators/1/merge/generators/0/git/repoURL", mirroredFluxURL),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patches := populateArgoGeneratorPatchOperations(jackalState, "/spec/generators", tt.generators, tt.isUpdate)
+			require.Equal(t, tt.expected, patches)
+		})
+	}
+}
+
+func TestMutateApplicationSet(t *testing.T) {
+	jackalState := newArgoState()
+	setJackalState(t, jackalState)
+
+	podinfoURL := "https://github.com/stefanprodan/podinfo.git"
+	appSet := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"generators": []interface{}{
+				map[string]interface{}{"matrix": map[string]interface{}{
+					"generators": []interface{}{
+						map[string]interface{}{"git": map[string]interface{}{"repoURL": podinfoURL}},
+						map[string]interface{}{"list": map[string]interface{}{"elements": []interface{}{}}},
+					},
+				}},
+			},
+		},
+	}
+
+	for _, op := range []v1.Operation{v1.Create, v1.Update} {
+		t.Run(string(op), func(t *testing.T) {
+			result, err := mutateApplicationSet(newAdmissionRequest(t, op, appSet))
+			require.NoError(t, err)
+			require.True(t, result.Allowed)
+			require.Equal(t, []operations.PatchOperation{
+				operations.ReplacePatchOperation("/spec/generators/0/matrix/generators/0/git/repoURL", jackalGitURL(t, jackalState, podinfoURL)),
+			}, result.PatchOps)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package hooks contains the mutation hooks for the Jackal agent.
+package hooks
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/racer159/jackal/src/config/lang"
+	"github.com/racer159/jackal/src/internal/agent/mutate"
+	"github.com/racer159/jackal/src/internal/agent/operations"
+	"github.com/racer159/jackal/src/pkg/message"
+	"github.com/racer159/jackal/src/types"
+	v1 "k8s.io/api/admission/v1"
+)
+
+// HelmRepository contains the URL of a Flux HelmRepository and the secret that corresponds to it.
+type HelmRepository struct {
+	Spec struct {
+		URL       string    `json:"url"`
+		Type      string    `json:"type,omitempty"`
+		SecretRef SecretRef `json:"secretRef,omitempty"`
+	} `json:"spec"`
+}
+
+// NewHelmRepositoryMutationHook creates a new instance of the Flux HelmRepository mutation hook.
+func NewHelmRepositoryMutationHook() operations.Hook {
+	message.Debug("hooks.NewHelmRepositoryMutationHook()")
+	return operations.Hook{
+		Create: mutateHelmRepo,
+		Update: mutateHelmRepo,
+	}
+}
+
+// mutateHelmRepo mutates the OCI helm repository url to point to the registry defined in the JackalState.
+func mutateHelmRepo(r *v1.AdmissionRequest) (result *operations.Result, err error) {
+
+	var (
+		jackalState *types.JackalState
+		patches     []operations.PatchOperation
+
+		isUpdate = r.Operation == v1.Update
+	)
+
+	// parse to simple struct to read the repository url
+	src := &HelmRepository{}
+	if err = json.Unmarshal(r.Object.Raw, &src); err != nil {
+		return nil, fmt.Errorf(lang.ErrUnmarshal, err)
+	}
+
+	// Form the registry address from the jackalState
+	if jackalState, err = getJackalState(); err != nil {
+		return nil, fmt.Errorf(lang.AgentErrGetState, err)
+	}
+
+	message.Debugf("Using the registry (%s) to mutate the flux helm repository", jackalState.RegistryInfo.InClusterAddress())
+
+	// Only OCI helm repositories can be served from the Jackal registry
+	patchedURL, ok, err := mutate.FluxHelmRepositoryURL(jackalState, src.Spec.URL, src.Spec.Type, isUpdate)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return &operations.Result{Allowed: true}, nil
+	}
+
+	// Patch updates of the repo spec
+	patches = populateOCIPatchOperations(patchedURL, src.Spec.SecretRef.Name, jackalState.RegistryInfo.InternalRegistry)
+
+	return &operations.Result{
+		Allowed:  true,
+		PatchOps: patches,
+	}, nil
+}
@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package hooks contains the mutation hooks for the Jackal agent.
+package hooks
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/racer159/jackal/src/config/lang"
+	"github.com/racer159/jackal/src/internal/agent/mutate"
+	"github.com/racer159/jackal/src/internal/agent/operations"
+	"github.com/racer159/jackal/src/pkg/message"
+	"github.com/racer159/jackal/src/types"
+	v1 "k8s.io/api/admission/v1"
+)
+
+// OCIRepository contains the URL and reference of a Flux OCIRepository and the secret that corresponds to it.
+type OCIRepository struct {
+	Spec struct {
+		URL       string              `json:"url"`
+		Reference mutate.OCIReference `json:"ref,omitempty"`
+		SecretRef SecretRef           `json:"secretRef,omitempty"`
+	} `json:"spec"`
+}
+
+// NewOCIRepositoryMutationHook creates a new instance of the Flux OCIRepository mutation hook.
+func NewOCIRepositoryMutationHook() operations.Hook {
+	message.Debug("hooks.NewOCIRepositoryMutationHook()")
+	return operations.Hook{
+		Create: mutateOCIRepo,
+		Update: mutateOCIRepo,
+	}
+}
+
+// mutateOCIRepo mutates the OCI repository url and tag to point to the artifact in the registry defined in the JackalState.
+func mutateOCIRepo(r *v1.AdmissionRequest) (result *operations.Result, err error) {
+
+	var (
+		jackalState *types.JackalState
+		patches     []operations.PatchOperation
+
+		isUpdate = r.Operation == v1.Update
+	)
+
+	// Form the registry address from the jackalState
+	if jackalState, err = getJackalState(); err != nil {
+		return nil, fmt.Errorf(lang.AgentErrGetState, err)
+	}
+
+	message.Debugf("Using the registry (%s) to mutate the flux OCI repository", jackalState.RegistryInfo.InClusterAddress())
+
+	// parse to simple struct to read the repository url and reference
+	src := &OCIRepository{}
+	if err = json.Unmarshal(r.Object.Raw, &src); err != nil {
+		return nil, fmt.Errorf(lang.ErrUnmarshal, err)
+	}
+
+	// Mutate the repository URL (and tag) so that they point at the artifact in the Jackal registry
+	patchedURL, patchedTag, err := mutate.FluxOCIRepository(jackalState, src.Spec.URL, src.Spec.Reference, isUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Patch updates of the repo spec
+	patches = populateOCIPatchOperations(patchedURL, src.Spec.SecretRef.Name, jackalState.RegistryInfo.InternalRegistry)
+	if patchedTag != "" {
+		patches = append(patches, operations.ReplacePatchOperation("/spec/ref/tag", patchedTag))
+	}
+
+	return &operations.Result{
+		Allowed:  true,
+		PatchOps: patches,
+	}, nil
+}
@@ -8,13 +8,11 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"github.com/defenseunicorns/pkg/helpers"
 	"github.com/racer159/jackal/src/config"
 	"github.com/racer159/jackal/src/config/lang"
+	"github.com/racer159/jackal/src/internal/agent/mutate"
 	"github.com/racer159/jackal/src/internal/agent/operations"
-	"github.com/racer159/jackal/src/internal/agent/state"
 	"github.com/racer159/jackal/src/pkg/message"
-	"github.com/racer159/jackal/src/pkg/transform"
 	"github.com/racer159/jackal/src/types"
 	v1 "k8s.io/api/admission/v1"
 )
@@ -47,14 +45,12 @@ func mutateGitRepo(r *v1.AdmissionRequest) (result *operations.Result, err error
 	var (
 		jackalState *types.JackalState
 		patches     []operations.PatchOperation
-		isPatched   bool
 
-		isCreate = r.Operation == v1.Create
 		isUpdate = r.Operation == v1.Update
 	)
 
 	// Form the jackalState.GitServer.Address from the jackalState
-	if jackalState, err = state.GetJackalStateFromAgentPod(); err != nil {
+	if jackalState, err = getJackalState(); err != nil {
 		return nil, fmt.Errorf(lang.AgentErrGetState, err)
 	}
 
@@ -65,27 +61,11 @@ func mutateGitRepo(r *v1.AdmissionRequest) (result *operations.Result, err error
 	if err = json.Unmarshal(r.Object.Raw, &src); err != nil {
 		return nil, fmt.Errorf(lang.ErrUnmarshal, err)
 	}
-	patchedURL := src.Spec.URL
-
-	// Check if this is an update operation and the hostname is different from what we have in the jackalState
-	// NOTE: We mutate on updates IF AND ONLY IF the hostname in the request is different than the hostname in the jackalState
-	// NOTE: We are checking if the hostname is different before because we do not want to potentially mutate a URL that has already been mutated.
-	if isUpdate {
-		isPatched, err = helpers.DoHostnamesMatch(jackalState.GitServer.Address, src.Spec.URL)
-		if err != nil {
-			return nil, fmt.Errorf(lang.AgentErrHostnameMatch, err)
-		}
-	}
 
-	// Mutate the git URL if necessary
-	if isCreate || (isUpdate && !isPatched) {
-		// Mutate the git URL so that the hostname matches the hostname in the Jackal state
-		transformedURL, err := transform.GitURL(jackalState.GitServer.Address, patchedURL, jackalState.GitServer.PushUsername)
-		if err != nil {
-			message.Warnf("Unable to transform the git url, using the original url we have: %s", patchedURL)
-		}
-		patchedURL = transformedURL.String()
-		message.Debugf("original git URL of (%s) got mutated to (%s)", src.Spec.URL, patchedURL)
+	// Mutate the git URL so that the hostname matches the hostname in the Jackal state
+	patchedURL, err := mutate.GitURL(jackalState, src.Spec.URL, isUpdate)
+	if err != nil {
+		return nil, err
 	}
 
 	// Patch updates of the repo spec
@@ -112,3 +92,24 @@ func populatePatchOperations(repoURL string, secretName string) []operations.Pat
 
 	return patches
 }
+
+// Patch updates of an OCI source spec.
+func populateOCIPatchOperations(repoURL string, secretName string, insecure bool) []operations.PatchOperation {
+	var patches []operations.PatchOperation
+	patches = append(patches, operations.ReplacePatchOperation("/spec/url", repoURL))
+
+	// If a prior secret exists, replace it
+	if secretName != "" {
+		patches = append(patches, operations.ReplacePatchOperation("/spec/secretRef/name", config.JackalImagePullSecretName))
+	} else {
+		// Otherwise, add the new secret
+		patches = append(patches, operations.AddPatchOperation("/spec/secretRef", SecretRef{Name: config.JackalImagePullSecretName}))
+	}
+
+	// The internal registry is served over plain HTTP within the cluster
+	if insecure {
+		patches = append(patches, operations.AddPatchOperation("/spec/insecure", true))
+	}
+
+	return patches
+}
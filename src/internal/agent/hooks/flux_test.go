@@ -0,0 +1,229 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package hooks contains the mutation hooks for the Jackal agent.
+package hooks
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/racer159/jackal/src/config"
+	"github.com/racer159/jackal/src/internal/agent/mutate"
+	"github.com/racer159/jackal/src/internal/agent/operations"
+	"github.com/racer159/jackal/src/types"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// setJackalState replaces the state the hooks read from the agent pod for the duration of a test.
+func setJackalState(t *testing.T, jackalState *types.JackalState) {
+	t.Helper()
+	original := getJackalState
+	getJackalState = func() (*types.JackalState, error) {
+		return jackalState, nil
+	}
+	t.Cleanup(func() {
+		getJackalState = original
+	})
+}
+
+// newAdmissionRequest returns an admission request for the given operation on an object.
+func newAdmissionRequest(t *testing.T, op v1.Operation, object interface{}) *v1.AdmissionRequest {
+	t.Helper()
+	raw, err := json.Marshal(object)
+	require.NoError(t, err)
+	return &v1.AdmissionRequest{Operation: op, Object: runtime.RawExtension{Raw: raw}}
+}
+
+func TestPopulateOCIPatchOperations(t *testing.T) {
+	tests := []struct {
+		name       string
+		secretName string
+		insecure   bool
+		expected   []operations.PatchOperation
+	}{
+		{
+			name: "no prior secret",
+			expected: []operations.PatchOperation{
+				operations.ReplacePatchOperation("/spec/url", "oci://127.0.0.1:31999/stefanprodan/charts"),
+				operations.AddPatchOperation("/spec/secretRef", SecretRef{Name: config.JackalImagePullSecretName}),
+			},
+		},
+		{
+			name:       "prior secret",
+			secretName: "ghcr-creds",
+			expected: []operations.PatchOperation{
+				operations.ReplacePatchOperation("/spec/url", "oci://127.0.0.1:31999/stefanprodan/charts"),
+				operations.ReplacePatchOperation("/spec/secretRef/name", config.JackalImagePullSecretName),
+			},
+		},
+		{
+			name:     "internal registry",
+			insecure: true,
+			expected: []operations.PatchOperation{
+				operations.ReplacePatchOperation("/spec/url", "oci://127.0.0.1:31999/stefanprodan/charts"),
+				operations.AddPatchOperation("/spec/secretRef", SecretRef{Name: config.JackalImagePullSecretName}),
+				operations.AddPatchOperation("/spec/insecure", true),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patches := populateOCIPatchOperations("oci://127.0.0.1:31999/stefanprodan/charts", tt.secretName, tt.insecure)
+			require.Equal(t, tt.expected, patches)
+		})
+	}
+}
+
+func TestMutateHelmRepo(t *testing.T) {
+	setJackalState(t, &types.JackalState{RegistryInfo: types.RegistryInfo{Address: "127.0.0.1:31999"}})
+
+	helmRepo := func(url, repoType string) *HelmRepository {
+		repo := &HelmRepository{}
+		repo.Spec.URL = url
+		repo.Spec.Type = repoType
+		return repo
+	}
+
+	tests := []struct {
+		name     string
+		op       v1.Operation
+		repo     *HelmRepository
+		expected []operations.PatchOperation
+	}{
+		{
+			name: "create",
+			op:   v1.Create,
+			repo: helmRepo("oci://ghcr.io/stefanprodan/charts", "oci"),
+			expected: []operations.PatchOperation{
+				operations.ReplacePatchOperation("/spec/url", "oci://127.0.0.1:31999/stefanprodan/charts"),
+				operations.AddPatchOperation("/spec/secretRef", SecretRef{Name: config.JackalImagePullSecretName}),
+			},
+		},
+		{
+			name: "update from another registry",
+			op:   v1.Update,
+			repo: helmRepo("oci://ghcr.io/stefanprodan/charts", "oci"),
+			expected: []operations.PatchOperation{
+				operations.ReplacePatchOperation("/spec/url", "oci://127.0.0.1:31999/stefanprodan/charts"),
+				operations.AddPatchOperation("/spec/secretRef", SecretRef{Name: config.JackalImagePullSecretName}),
+			},
+		},
+		{
+			name: "update already pointed at the Jackal registry",
+			op:   v1.Update,
+			repo: helmRepo("oci://127.0.0.1:31999/stefanprodan/charts", "oci"),
+			expected: []operations.PatchOperation{
+				operations.ReplacePatchOperation("/spec/url", "oci://127.0.0.1:31999/stefanprodan/charts"),
+				operations.AddPatchOperation("/spec/secretRef", SecretRef{Name: config.JackalImagePullSecretName}),
+			},
+		},
+		{
+			name: "not an OCI repository",
+			op:   v1.Create,
+			repo: helmRepo("https://stefanprodan.github.io/podinfo", ""),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := mutateHelmRepo(newAdmissionRequest(t, tt.op, tt.repo))
+			require.NoError(t, err)
+			require.True(t, result.Allowed)
+			require.Equal(t, tt.expected, result.PatchOps)
+		})
+	}
+}
+
+func TestMutateOCIRepo(t *testing.T) {
+	jackalState := &types.JackalState{RegistryInfo: types.RegistryInfo{Address: "127.0.0.1:31999"}}
+	setJackalState(t, jackalState)
+
+	ociRepo := func(url string, ref mutate.OCIReference, secretName string) *OCIRepository {
+		repo := &OCIRepository{}
+		repo.Spec.URL = url
+		repo.Spec.Reference = ref
+		repo.Spec.SecretRef.Name = secretName
+		return repo
+	}
+
+	tests := []struct {
+		name     string
+		op       v1.Operation
+		internal bool
+		repo     *OCIRepository
+		expected []operations.PatchOperation
+	}{
+		{
+			name: "create with a tag",
+			op:   v1.Create,
+			repo: ociRepo("oci://ghcr.io/stefanprodan/manifests/podinfo", mutate.OCIReference{Tag: "6.4.0"}, ""),
+			expected: []operations.PatchOperation{
+				operations.ReplacePatchOperation("/spec/url", "oci://127.0.0.1:31999/stefanprodan/manifests/podinfo"),
+				operations.AddPatchOperation("/spec/secretRef", SecretRef{Name: config.JackalImagePullSecretName}),
+				operations.ReplacePatchOperation("/spec/ref/tag", "6.4.0-jackal-2823281104"),
+			},
+		},
+		{
+			name: "create with a digest",
+			op:   v1.Create,
+			repo: ociRepo("oci://ghcr.io/stefanprodan/manifests/podinfo", mutate.OCIReference{Tag: "6.4.0", Digest: "sha256:0000000000000000000000000000000000000000000000000000000000000000"}, "ghcr-creds"),
+			expected: []operations.PatchOperation{
+				operations.ReplacePatchOperation("/spec/url", "oci://127.0.0.1:31999/stefanprodan/manifests/podinfo"),
+				operations.ReplacePatchOperation("/spec/secretRef/name", config.JackalImagePullSecretName),
+			},
+		},
+		{
+			name: "create with a semver range",
+			op:   v1.Create,
+			repo: ociRepo("oci://ghcr.io/stefanprodan/manifests/podinfo", mutate.OCIReference{SemVer: ">= 6.x"}, ""),
+			expected: []operations.PatchOperation{
+				operations.ReplacePatchOperation("/spec/url", "oci://127.0.0.1:31999/stefanprodan/manifests/podinfo"),
+				operations.AddPatchOperation("/spec/secretRef", SecretRef{Name: config.JackalImagePullSecretName}),
+			},
+		},
+		{
+			name:     "create with the internal registry",
+			op:       v1.Create,
+			internal: true,
+			repo:     ociRepo("oci://ghcr.io/stefanprodan/manifests/podinfo", mutate.OCIReference{}, ""),
+			expected: []operations.PatchOperation{
+				operations.ReplacePatchOperation("/spec/url", "oci://"+types.JackalInClusterContainerRegistryAddress+"/stefanprodan/manifests/podinfo"),
+				operations.AddPatchOperation("/spec/secretRef", SecretRef{Name: config.JackalImagePullSecretName}),
+				operations.AddPatchOperation("/spec/insecure", true),
+			},
+		},
+		{
+			name: "update from another registry",
+			op:   v1.Update,
+			repo: ociRepo("oci://ghcr.io/stefanprodan/manifests/podinfo", mutate.OCIReference{Tag: "6.4.0"}, ""),
+			expected: []operations.PatchOperation{
+				operations.ReplacePatchOperation("/spec/url", "oci://127.0.0.1:31999/stefanprodan/manifests/podinfo"),
+				operations.AddPatchOperation("/spec/secretRef", SecretRef{Name: config.JackalImagePullSecretName}),
+				operations.ReplacePatchOperation("/spec/ref/tag", "6.4.0-jackal-2823281104"),
+			},
+		},
+		{
+			name: "update already pointed at the Jackal registry",
+			op:   v1.Update,
+			repo: ociRepo("oci://127.0.0.1:31999/stefanprodan/manifests/podinfo", mutate.OCIReference{Tag: "6.4.0-jackal-2823281104"}, config.JackalImagePullSecretName),
+			expected: []operations.PatchOperation{
+				operations.ReplacePatchOperation("/spec/url", "oci://127.0.0.1:31999/stefanprodan/manifests/podinfo"),
+				operations.ReplacePatchOperation("/spec/secretRef/name", config.JackalImagePullSecretName),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jackalState.RegistryInfo.InternalRegistry = tt.internal
+			result, err := mutateOCIRepo(newAdmissionRequest(t, tt.op, tt.repo))
+			require.NoError(t, err)
+			require.True(t, result.Allowed)
+			require.Equal(t, tt.expected, result.PatchOps)
+		})
+	}
+}
@@ -7,18 +7,25 @@ package hooks
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/racer159/jackal/src/config"
 	"github.com/racer159/jackal/src/config/lang"
+	"github.com/racer159/jackal/src/internal/agent/mutate"
 	"github.com/racer159/jackal/src/internal/agent/operations"
 	"github.com/racer159/jackal/src/internal/agent/state"
 	"github.com/racer159/jackal/src/pkg/message"
 	"github.com/racer159/jackal/src/pkg/transform"
+	"github.com/racer159/jackal/src/types"
 	v1 "k8s.io/api/admission/v1"
 
 	corev1 "k8s.io/api/core/v1"
 )
 
+// getJackalState returns the Jackal state mounted into the agent pods, tests replace it to mutate resources without an agent pod.
+var getJackalState = state.GetJackalStateFromAgentPod
+
 // NewPodMutationHook creates a new instance of pods mutation hook.
 func NewPodMutationHook() operations.Hook {
 	message.Debug("hooks.NewMutationHook()")
@@ -28,6 +35,18 @@ func NewPodMutationHook() operations.Hook {
 	}
 }
 
+// NewPodValidationHook creates a new instance of pods validation hook.
+func NewPodValidationHook() operations.Hook {
+	message.Debug("hooks.NewPodValidationHook()")
+	return operations.Hook{
+		Create: validatePod,
+		Update: validateEphemeralContainers,
+	}
+}
+
+// ephemeralContainersSubResource is the subresource ephemeral containers are added to existing pods through (e.g. by kubectl debug).
+const ephemeralContainersSubResource = "ephemeralcontainers"
+
 func parsePod(object []byte) (*corev1.Pod, error) {
 	message.Debugf("pods.parsePod(%s)", string(object))
 	var pod corev1.Pod
@@ -47,6 +66,10 @@ func mutatePod(r *v1.AdmissionRequest) (*operations.Result, error) {
 		return &operations.Result{Msg: err.Error()}, nil
 	}
 
+	if r.SubResource == ephemeralContainersSubResource {
+		return mutateEphemeralContainers(r, pod)
+	}
+
 	if pod.Labels != nil && pod.Labels["jackal-agent"] == "patched" {
 		// We've already played with this pod, just keep swimming 🐟
 		return &operations.Result{
@@ -59,50 +82,147 @@ func mutatePod(r *v1.AdmissionRequest) (*operations.Result, error) {
 	jackalSecret := []corev1.LocalObjectReference{{Name: config.JackalImagePullSecretName}}
 	patchOperations = append(patchOperations, operations.ReplacePatchOperation("/spec/imagePullSecrets", jackalSecret))
 
-	jackalState, err := state.GetJackalStateFromAgentPod()
+	jackalState, err := getJackalState()
 	if err != nil {
 		return nil, fmt.Errorf(lang.AgentErrGetState, err)
 	}
-	containerRegistryURL := jackalState.RegistryInfo.Address
 
-	// update the image host for each init container
-	for idx, container := range pod.Spec.InitContainers {
-		path := fmt.Sprintf("/spec/initContainers/%d/image", idx)
-		replacement, err := transform.ImageTransformHost(containerRegistryURL, container.Image)
-		if err != nil {
-			message.Warnf(lang.AgentErrImageSwap, container.Image)
-			continue // Continue, because we might as well attempt to mutate the other containers for this pod
-		}
-		patchOperations = append(patchOperations, operations.ReplacePatchOperation(path, replacement))
+	// update the image host for each container, mutating the images that can be mapped and leaving the rest
+	for _, image := range mutate.PodImages(jackalState.RegistryInfo.Address, pod.Spec) {
+		path := fmt.Sprintf("/spec/%s/%d/image", image.Field, image.Index)
+		patchOperations = append(patchOperations, operations.ReplacePatchOperation(path, image.Image))
 	}
 
-	// update the image host for each ephemeral container
-	for idx, container := range pod.Spec.EphemeralContainers {
-		path := fmt.Sprintf("/spec/ephemeralContainers/%d/image", idx)
-		replacement, err := transform.ImageTransformHost(containerRegistryURL, container.Image)
-		if err != nil {
-			message.Warnf(lang.AgentErrImageSwap, container.Image)
-			continue // Continue, because we might as well attempt to mutate the other containers for this pod
-		}
-		patchOperations = append(patchOperations, operations.ReplacePatchOperation(path, replacement))
+	// Add a label noting the jackal mutation
+	patchOperations = append(patchOperations, operations.ReplacePatchOperation("/metadata/labels/jackal-agent", "patched"))
+
+	return &operations.Result{
+		Allowed:  true,
+		PatchOps: patchOperations,
+	}, nil
+}
+
+// mutateEphemeralContainers mutates the images of the ephemeral containers an update to the ephemeralcontainers subresource adds to a pod.
+func mutateEphemeralContainers(r *v1.AdmissionRequest, pod *corev1.Pod) (*operations.Result, error) {
+	added, err := addedEphemeralContainers(r, pod)
+	if err != nil {
+		return &operations.Result{Msg: err.Error()}, nil
 	}
 
-	// update the image host for each normal container
-	for idx, container := range pod.Spec.Containers {
-		path := fmt.Sprintf("/spec/containers/%d/image", idx)
-		replacement, err := transform.ImageTransformHost(containerRegistryURL, container.Image)
-		if err != nil {
-			message.Warnf(lang.AgentErrImageSwap, container.Image)
-			continue // Continue, because we might as well attempt to mutate the other containers for this pod
-		}
-		patchOperations = append(patchOperations, operations.ReplacePatchOperation(path, replacement))
+	jackalState, err := getJackalState()
+	if err != nil {
+		return nil, fmt.Errorf(lang.AgentErrGetState, err)
 	}
 
-	// Add a label noting the jackal mutation
-	patchOperations = append(patchOperations, operations.ReplacePatchOperation("/metadata/labels/jackal-agent", "patched"))
+	var patchOperations []operations.PatchOperation
+	for _, image := range mutate.PodImages(jackalState.RegistryInfo.Address, corev1.PodSpec{EphemeralContainers: added}) {
+		path := fmt.Sprintf("/spec/ephemeralContainers/%d/image", len(pod.Spec.EphemeralContainers)-len(added)+image.Index)
+		patchOperations = append(patchOperations, operations.ReplacePatchOperation(path, image.Image))
+	}
 
 	return &operations.Result{
 		Allowed:  true,
 		PatchOps: patchOperations,
 	}, nil
 }
+
+// addedEphemeralContainers returns the ephemeral containers an update to the ephemeralcontainers subresource adds to a pod.
+//
+// Ephemeral containers can only be appended and never changed, so the ones the pod already had were admitted when they were added.
+func addedEphemeralContainers(r *v1.AdmissionRequest, pod *corev1.Pod) ([]corev1.EphemeralContainer, error) {
+	oldPod, err := parsePod(r.OldObject.Raw)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := len(oldPod.Spec.EphemeralContainers)
+	if existing > len(pod.Spec.EphemeralContainers) {
+		return nil, nil
+	}
+	return pod.Spec.EphemeralContainers[existing:], nil
+}
+
+func validatePod(r *v1.AdmissionRequest) (*operations.Result, error) {
+	message.Debugf("hooks.validatePod()(*v1.AdmissionRequest) - %#v , %s/%s: %#v", r.Kind, r.Namespace, r.Name, r.Operation)
+
+	pod, err := parsePod(r.Object.Raw)
+	if err != nil {
+		return &operations.Result{Msg: err.Error()}, nil
+	}
+
+	jackalState, err := getJackalState()
+	if err != nil {
+		return nil, fmt.Errorf(lang.AgentErrGetState, err)
+	}
+
+	if violations := podImageViolations(pod, jackalState); len(violations) > 0 {
+		return &operations.Result{Msg: strings.Join(violations, "; ")}, nil
+	}
+
+	return &operations.Result{Allowed: true}, nil
+}
+
+// validateEphemeralContainers validates the images of the ephemeral containers an update to the ephemeralcontainers subresource adds to a pod.
+func validateEphemeralContainers(r *v1.AdmissionRequest) (*operations.Result, error) {
+	message.Debugf("hooks.validateEphemeralContainers()(*v1.AdmissionRequest) - %#v , %s/%s: %#v", r.Kind, r.Namespace, r.Name, r.Operation)
+
+	// Only new pods are validated otherwise, so that updates to the labels or finalizers of existing pods are never blocked
+	if r.SubResource != ephemeralContainersSubResource {
+		return &operations.Result{Allowed: true}, nil
+	}
+
+	pod, err := parsePod(r.Object.Raw)
+	if err != nil {
+		return &operations.Result{Msg: err.Error()}, nil
+	}
+	added, err := addedEphemeralContainers(r, pod)
+	if err != nil {
+		return &operations.Result{Msg: err.Error()}, nil
+	}
+
+	jackalState, err := getJackalState()
+	if err != nil {
+		return nil, fmt.Errorf(lang.AgentErrGetState, err)
+	}
+
+	if violations := podImageViolations(&corev1.Pod{Spec: corev1.PodSpec{EphemeralContainers: added}}, jackalState); len(violations) > 0 {
+		return &operations.Result{Msg: strings.Join(violations, "; ")}, nil
+	}
+
+	return &operations.Result{Allowed: true}, nil
+}
+
+// podImageViolations returns a denial message for each container image in a pod that will not be pulled from the Jackal registry or an allowed registry.
+func podImageViolations(pod *corev1.Pod, jackalState *types.JackalState) []string {
+	containerRegistryURL := jackalState.RegistryInfo.Address
+	allowedRegistries := jackalState.AgentPolicy.AllowedRegistries
+
+	var violations []string
+	checkImage := func(name, image string) {
+		// Images that cannot be mapped were left untouched by the mutating webhook and would never be pulled
+		if _, err := transform.ImageTransformHost(containerRegistryURL, image); err != nil {
+			violations = append(violations, fmt.Sprintf(lang.AgentErrImageNotMappable, image, name, err.Error()))
+			return
+		}
+
+		// The image was parsed above, so this cannot fail
+		ref, _ := transform.ParseImageRef(image)
+		if ref.Host == containerRegistryURL || slices.Contains(allowedRegistries, ref.Host) {
+			return
+		}
+
+		violations = append(violations, fmt.Sprintf(lang.AgentErrImageNotAllowed, image, name, containerRegistryURL, strings.Join(allowedRegistries, ", ")))
+	}
+
+	for _, container := range pod.Spec.InitContainers {
+		checkImage(container.Name, container.Image)
+	}
+	for _, container := range pod.Spec.EphemeralContainers {
+		checkImage(container.Name, container.Image)
+	}
+	for _, container := range pod.Spec.Containers {
+		checkImage(container.Name, container.Image)
+	}
+
+	return violations
+}
@@ -0,0 +1,133 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package hooks provides HTTP handlers for the mutating webhook.
+package hooks
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/racer159/jackal/src/config/lang"
+	"github.com/racer159/jackal/src/internal/agent/operations"
+	"github.com/racer159/jackal/src/pkg/transform"
+	"github.com/racer159/jackal/src/types"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestPodImageViolations(t *testing.T) {
+	jackalState := &types.JackalState{
+		RegistryInfo: types.RegistryInfo{Address: "127.0.0.1:31999"},
+		AgentPolicy:  types.AgentPolicy{AllowedRegistries: []string{"registry.internal:5000"}},
+	}
+	notAllowed := func(image, name string) string {
+		return fmt.Sprintf(lang.AgentErrImageNotAllowed, image, name, "127.0.0.1:31999", "registry.internal:5000")
+	}
+
+	tests := []struct {
+		name     string
+		pod      corev1.PodSpec
+		expected []string
+	}{
+		{
+			name: "jackal registry",
+			pod: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "app", Image: "127.0.0.1:31999/stefanprodan/podinfo:6.4.0-jackal-2985051089"}},
+			},
+		},
+		{
+			name: "allowed registry",
+			pod: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "init", Image: "registry.internal:5000/library/busybox:1.36"}},
+				Containers:     []corev1.Container{{Name: "app", Image: "127.0.0.1:31999/library/nginx@sha256:0000000000000000000000000000000000000000000000000000000000000000"}},
+			},
+		},
+		{
+			name: "image that was not mutated",
+			pod: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "app", Image: "nginx:1.25"}},
+			},
+			expected: []string{notAllowed("nginx:1.25", "app")},
+		},
+		{
+			name: "every kind of container",
+			pod: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "init", Image: "ghcr.io/stefanprodan/podinfo:6.4.0"}},
+				EphemeralContainers: []corev1.EphemeralContainer{
+					{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debug", Image: "docker.io/library/busybox:1.36"}},
+				},
+				Containers: []corev1.Container{
+					{Name: "allowed", Image: "registry.internal:5000/library/nginx:1.25"},
+					{Name: "app", Image: "quay.io/prometheus/node-exporter:v1.7.0"},
+				},
+			},
+			expected: []string{
+				notAllowed("ghcr.io/stefanprodan/podinfo:6.4.0", "init"),
+				notAllowed("docker.io/library/busybox:1.36", "debug"),
+				notAllowed("quay.io/prometheus/node-exporter:v1.7.0", "app"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := podImageViolations(&corev1.Pod{Spec: tt.pod}, jackalState)
+			require.Equal(t, tt.expected, violations)
+		})
+	}
+
+	// An image that cannot be parsed cannot be mapped into the Jackal registry
+	violations := podImageViolations(&corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "INVALID::image"}}}}, jackalState)
+	require.Len(t, violations, 1)
+	require.Contains(t, violations[0], `the image "INVALID::image" in container "app" cannot be mapped into the Jackal registry`)
+}
+
+func TestEphemeralContainers(t *testing.T) {
+	jackalState := &types.JackalState{RegistryInfo: types.RegistryInfo{Address: "127.0.0.1:31999"}}
+	setJackalState(t, jackalState)
+
+	debugContainer := func(name, image string) corev1.EphemeralContainer {
+		return corev1.EphemeralContainer{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: name, Image: image}}
+	}
+	mutated, err := transform.ImageTransformHost("127.0.0.1:31999", "busybox:1.36")
+	require.NoError(t, err)
+
+	oldPod := &corev1.Pod{Spec: corev1.PodSpec{
+		Containers:          []corev1.Container{{Name: "app", Image: "127.0.0.1:31999/stefanprodan/podinfo:6.4.0-jackal-2985051089"}},
+		EphemeralContainers: []corev1.EphemeralContainer{debugContainer("debugger-1", mutated)},
+	}}
+	pod := oldPod.DeepCopy()
+	pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, debugContainer("debugger-2", "busybox:1.36"))
+
+	r := newAdmissionRequest(t, v1.Update, pod)
+	r.SubResource = ephemeralContainersSubResource
+	r.OldObject = newAdmissionRequest(t, v1.Update, oldPod).Object
+
+	// Only the ephemeral container the update adds is mutated
+	result, err := mutatePod(r)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+	require.Equal(t, []operations.PatchOperation{operations.ReplacePatchOperation("/spec/ephemeralContainers/1/image", mutated)}, result.PatchOps)
+
+	// Only the ephemeral container the update adds is validated
+	result, err = validateEphemeralContainers(r)
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+	require.Equal(t, fmt.Sprintf(lang.AgentErrImageNotAllowed, "busybox:1.36", "debugger-2", "127.0.0.1:31999", ""), result.Msg)
+
+	pod.Spec.EphemeralContainers[1].Image = mutated
+	r.Object = newAdmissionRequest(t, v1.Update, pod).Object
+	result, err = validateEphemeralContainers(r)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+
+	// Updates to the pod itself are never validated
+	r.SubResource = ""
+	pod.Spec.EphemeralContainers[1].Image = "busybox:1.36"
+	r.Object = newAdmissionRequest(t, v1.Update, pod).Object
+	result, err = validateEphemeralContainers(r)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+}
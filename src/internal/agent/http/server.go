@@ -14,15 +14,19 @@ import (
 	"github.com/racer159/jackal/src/pkg/message"
 )
 
-// NewAdmissionServer creates a http.Server for the mutating webhook admission handler.
+// NewAdmissionServer creates a http.Server for the mutating and validating webhook admission handlers.
 func NewAdmissionServer(port string) *http.Server {
 	message.Debugf("http.NewServer(%s)", port)
 
 	// Instances hooks
 	podsMutation := hooks.NewPodMutationHook()
 	fluxGitRepositoryMutation := hooks.NewGitRepositoryMutationHook()
+	fluxHelmRepositoryMutation := hooks.NewHelmRepositoryMutationHook()
+	fluxOCIRepositoryMutation := hooks.NewOCIRepositoryMutationHook()
 	argocdApplicationMutation := hooks.NewApplicationMutationHook()
+	argocdApplicationSetMutation := hooks.NewApplicationSetMutationHook()
 	argocdRepositoryMutation := hooks.NewRepositoryMutationHook()
+	podsValidation := hooks.NewPodValidationHook()
 
 	// Routers
 	ah := newAdmissionHandler()
@@ -30,8 +34,12 @@ func NewAdmissionServer(port string) *http.Server {
 	mux.Handle("/healthz", healthz())
 	mux.Handle("/mutate/pod", ah.Serve(podsMutation))
 	mux.Handle("/mutate/flux-gitrepository", ah.Serve(fluxGitRepositoryMutation))
+	mux.Handle("/mutate/flux-helmrepository", ah.Serve(fluxHelmRepositoryMutation))
+	mux.Handle("/mutate/flux-ocirepository", ah.Serve(fluxOCIRepositoryMutation))
 	mux.Handle("/mutate/argocd-application", ah.Serve(argocdApplicationMutation))
+	mux.Handle("/mutate/argocd-applicationset", ah.Serve(argocdApplicationSetMutation))
 	mux.Handle("/mutate/argocd-repository", ah.Serve(argocdRepositoryMutation))
+	mux.Handle("/validate/pod", ah.Serve(podsValidation))
 	mux.Handle("/metrics", promhttp.Handler())
 
 	return &http.Server{
@@ -0,0 +1,231 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package mutate contains the image and URL rewrites the Jackal agent makes to the resources it admits.
+package mutate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/defenseunicorns/pkg/helpers"
+	"github.com/racer159/jackal/src/config/lang"
+	"github.com/racer159/jackal/src/pkg/message"
+	"github.com/racer159/jackal/src/pkg/transform"
+	"github.com/racer159/jackal/src/types"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// AgentLabel is the label used to exclude pods and namespaces from mutation by the Jackal agent.
+const AgentLabel = "jackal.dev/agent"
+
+// IsIgnored returns whether the labels exclude a pod or namespace from mutation by the Jackal agent.
+func IsIgnored(labels map[string]string) bool {
+	return labels[AgentLabel] == "skip" || labels[AgentLabel] == "ignore"
+}
+
+// ContainerImage is the image a container of a pod spec is pulled from in the Jackal registry.
+type ContainerImage struct {
+	// Field is the pod spec field the container is listed in (initContainers, ephemeralContainers or containers)
+	Field string
+	Index int
+	Image string
+}
+
+// PodImages returns the image in the Jackal registry for each container of a pod spec.
+//
+// Containers whose image cannot be mapped are left out so that the other containers can still be mutated.
+func PodImages(registryAddress string, spec corev1.PodSpec) []ContainerImage {
+	var images []ContainerImage
+	add := func(field string, idx int, image string) {
+		replacement, err := transform.ImageTransformHost(registryAddress, image)
+		if err != nil {
+			message.Warnf(lang.AgentErrImageSwap, image)
+			return
+		}
+		images = append(images, ContainerImage{Field: field, Index: idx, Image: replacement})
+	}
+
+	for idx, container := range spec.InitContainers {
+		add("initContainers", idx, container.Image)
+	}
+	for idx, container := range spec.EphemeralContainers {
+		add("ephemeralContainers", idx, container.Image)
+	}
+	for idx, container := range spec.Containers {
+		add("containers", idx, container.Image)
+	}
+
+	return images
+}
+
+// GitURL returns a git URL pointed at the Jackal git server.
+//
+// On updates a URL that already points at the git server is returned as-is, and a URL that cannot be transformed is kept.
+func GitURL(jackalState *types.JackalState, repoURL string, isUpdate bool) (string, error) {
+	// NOTE: We mutate on updates IF AND ONLY IF the hostname in the request is different from the hostname in the jackalState
+	// NOTE: We are checking if the hostname is different before because we do not want to potentially mutate a URL that has already been mutated.
+	if isUpdate {
+		isPatched, err := helpers.DoHostnamesMatch(jackalState.GitServer.Address, repoURL)
+		if err != nil {
+			return "", fmt.Errorf(lang.AgentErrHostnameMatch, err)
+		}
+		if isPatched {
+			return repoURL, nil
+		}
+	}
+
+	// Mutate the git URL so that the hostname matches the hostname in the Jackal state
+	transformedURL, err := transform.GitURL(jackalState.GitServer.Address, repoURL, jackalState.GitServer.PushUsername)
+	if err != nil {
+		message.Warnf("Unable to transform the git url, using the original url we have: %s", repoURL)
+		return repoURL, nil
+	}
+	message.Debugf("original git URL of (%s) got mutated to (%s)", repoURL, transformedURL.String())
+
+	return transformedURL.String(), nil
+}
+
+// OCIURL returns an OCI URL pointed at the Jackal registry, keeping the scheme (or lack of one) it had.
+//
+// On updates a URL that already points at the registry is returned as-is.
+func OCIURL(jackalState *types.JackalState, repoURL string, isUpdate bool) (string, error) {
+	registryAddress := jackalState.RegistryInfo.InClusterAddress()
+	ociURL := helpers.OCIURLPrefix + strings.TrimPrefix(repoURL, helpers.OCIURLPrefix)
+
+	// Only mutate on updates if the registry in the request is not already the Jackal registry
+	if isUpdate {
+		isPatched, err := helpers.DoHostnamesMatch(helpers.OCIURLPrefix+registryAddress, ociURL)
+		if err != nil {
+			return "", fmt.Errorf(lang.AgentErrHostnameMatch, err)
+		}
+		if isPatched {
+			return repoURL, nil
+		}
+	}
+
+	patchedURL, err := transform.OCIURLTransformHost(registryAddress, ociURL)
+	if err != nil {
+		return "", fmt.Errorf(lang.AgentErrTransformOCIURL, repoURL, err)
+	}
+	if !strings.HasPrefix(repoURL, helpers.OCIURLPrefix) {
+		patchedURL = strings.TrimPrefix(patchedURL, helpers.OCIURLPrefix)
+	}
+	message.Debugf("original OCI URL of (%s) got mutated to (%s)", repoURL, patchedURL)
+
+	return patchedURL, nil
+}
+
+// FluxHelmRepositoryURL returns the URL of a Flux HelmRepository pointed at the Jackal registry.
+//
+// Only OCI helm repositories can be served from the Jackal registry, any other repository is not patched.
+func FluxHelmRepositoryURL(jackalState *types.JackalState, repoURL string, repoType string, isUpdate bool) (string, bool, error) {
+	if repoType != "oci" {
+		message.Warnf(lang.AgentWarnNotOCIType, repoType)
+		return "", false, nil
+	}
+
+	patchedURL, err := OCIURL(jackalState, repoURL, isUpdate)
+	if err != nil {
+		return "", false, err
+	}
+	return patchedURL, true, nil
+}
+
+// OCIReference contains the reference of the artifact a Flux OCIRepository pulls.
+type OCIReference struct {
+	Digest string `json:"digest,omitempty"`
+	SemVer string `json:"semver,omitempty"`
+	Tag    string `json:"tag,omitempty"`
+}
+
+// FluxOCIRepository returns the URL of a Flux OCIRepository pointed at the Jackal registry and, when the
+// repository is pinned to a tag, the checksummed tag the artifact was pushed to the registry with.
+//
+// Digests and semver ranges resolve against the artifact as-is, as artifacts are also pushed without a checksummed tag.
+// On updates a repository that already points at the registry is returned as-is without a tag.
+func FluxOCIRepository(jackalState *types.JackalState, repoURL string, ref OCIReference, isUpdate bool) (string, string, error) {
+	registryAddress := jackalState.RegistryInfo.InClusterAddress()
+
+	// Only mutate on updates if the registry in the request is not already the Jackal registry
+	if isUpdate {
+		isPatched, err := helpers.DoHostnamesMatch(helpers.OCIURLPrefix+registryAddress, repoURL)
+		if err != nil {
+			return "", "", fmt.Errorf(lang.AgentErrHostnameMatch, err)
+		}
+		if isPatched {
+			return repoURL, "", nil
+		}
+	}
+
+	patchedURL, err := OCIURL(jackalState, repoURL, false)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Flux gives precedence to the digest and then the semver range over the tag
+	if ref.Digest != "" || ref.SemVer != "" || ref.Tag == "" {
+		return patchedURL, "", nil
+	}
+
+	transformed, err := transform.ImageTransformHost(registryAddress, fmt.Sprintf("%s:%s", strings.TrimPrefix(repoURL, helpers.OCIURLPrefix), ref.Tag))
+	if err != nil {
+		return "", "", fmt.Errorf(lang.AgentErrTransformOCIURL, repoURL, err)
+	}
+	transformedRef, err := transform.ParseImageRef(transformed)
+	if err != nil {
+		return "", "", fmt.Errorf(lang.AgentErrTransformOCIURL, repoURL, err)
+	}
+
+	return patchedURL, transformedRef.Tag, nil
+}
+
+// ArgoSourceURL returns the repoURL of an Argo CD source pointed at the Jackal git server, or the Jackal registry for OCI Helm charts.
+//
+// Sources that cannot be served by Jackal (Helm chart repositories) are not patched.
+func ArgoSourceURL(jackalState *types.JackalState, repoURL string, chart string, isUpdate bool) (string, bool) {
+	var (
+		patchedURL string
+		err        error
+	)
+
+	switch {
+	case chart == "":
+		patchedURL, err = GitURL(jackalState, repoURL, isUpdate)
+	case isOCIHelmRepoURL(repoURL):
+		patchedURL, err = OCIURL(jackalState, repoURL, isUpdate)
+	default:
+		message.Warnf(lang.AgentWarnNotOCIHelmRepo, repoURL)
+		return "", false
+	}
+
+	if err != nil {
+		message.Warnf("Unable to mutate the repoURL %s: %s", repoURL, err.Error())
+		return "", false
+	}
+
+	return patchedURL, true
+}
+
+// ArgoGeneratorURL returns the repoURL of an Argo CD git generator pointed at the Jackal git server.
+//
+// Templated URLs are resolved from another generator and are not patched until they are rendered into Applications.
+func ArgoGeneratorURL(jackalState *types.JackalState, repoURL string, isUpdate bool) (string, bool) {
+	if strings.Contains(repoURL, "{{") {
+		message.Debugf("Skipping the templated repoURL %s", repoURL)
+		return "", false
+	}
+
+	patchedURL, err := GitURL(jackalState, repoURL, isUpdate)
+	if err != nil {
+		message.Warnf("Unable to mutate the repoURL %s: %s", repoURL, err.Error())
+		return "", false
+	}
+
+	return patchedURL, true
+}
+
+// isOCIHelmRepoURL returns whether the repoURL of an Argo Helm source is an OCI registry, which Argo expects without a scheme.
+func isOCIHelmRepoURL(repoURL string) bool {
+	return strings.HasPrefix(repoURL, helpers.OCIURLPrefix) || !strings.Contains(repoURL, "://")
+}
@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package mutate contains the image and URL rewrites the Jackal agent makes to the resources it admits.
+package mutate
+
+import (
+	"testing"
+
+	"github.com/racer159/jackal/src/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsOCIHelmRepoURL(t *testing.T) {
+	tests := []struct {
+		repoURL  string
+		expected bool
+	}{
+		{repoURL: "oci://ghcr.io/stefanprodan/charts", expected: true},
+		{repoURL: "ghcr.io/stefanprodan/charts", expected: true},
+		{repoURL: "127.0.0.1:31999/stefanprodan/charts", expected: true},
+		{repoURL: "https://stefanprodan.github.io/podinfo", expected: false},
+		{repoURL: "http://charts.example.com", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.repoURL, func(t *testing.T) {
+			require.Equal(t, tt.expected, isOCIHelmRepoURL(tt.repoURL))
+		})
+	}
+}
+
+func TestOCIURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		repoURL  string
+		isUpdate bool
+		internal bool
+		expected string
+	}{
+		{
+			name:     "create without a scheme",
+			repoURL:  "ghcr.io/stefanprodan/charts",
+			expected: "127.0.0.1:31999/stefanprodan/charts",
+		},
+		{
+			name:     "create with a scheme",
+			repoURL:  "oci://ghcr.io/stefanprodan/charts",
+			expected: "oci://127.0.0.1:31999/stefanprodan/charts",
+		},
+		{
+			name:     "create with the internal registry",
+			repoURL:  "ghcr.io/stefanprodan/charts",
+			internal: true,
+			expected: types.JackalInClusterContainerRegistryAddress + "/stefanprodan/charts",
+		},
+		{
+			name:     "update from another registry",
+			repoURL:  "ghcr.io/stefanprodan/charts",
+			isUpdate: true,
+			expected: "127.0.0.1:31999/stefanprodan/charts",
+		},
+		{
+			name:     "update already pointed at the Jackal registry",
+			repoURL:  "127.0.0.1:31999/stefanprodan/charts",
+			isUpdate: true,
+			expected: "127.0.0.1:31999/stefanprodan/charts",
+		},
+		{
+			name:     "update already pointed at the Jackal registry with a scheme",
+			repoURL:  "oci://127.0.0.1:31999/stefanprodan/charts",
+			isUpdate: true,
+			expected: "oci://127.0.0.1:31999/stefanprodan/charts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jackalState := &types.JackalState{RegistryInfo: types.RegistryInfo{Address: "127.0.0.1:31999"}}
+			jackalState.RegistryInfo.InternalRegistry = tt.internal
+			patchedURL, err := OCIURL(jackalState, tt.repoURL, tt.isUpdate)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, patchedURL)
+		})
+	}
+}
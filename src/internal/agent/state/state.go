@@ -5,9 +5,11 @@
 package state
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 
+	"github.com/racer159/jackal/src/pkg/credentials"
 	"github.com/racer159/jackal/src/types"
 )
 
@@ -15,12 +17,22 @@ const jackalStatePath = "/etc/jackal-state/state"
 
 // GetJackalStateFromAgentPod reads the state json file that was mounted into the agent pods.
 func GetJackalStateFromAgentPod() (state *types.JackalState, err error) {
+	return readJackalState(jackalStatePath)
+}
+
+// readJackalState reads a state json file, resolving its credentials with the credential store environment of the pod.
+func readJackalState(path string) (state *types.JackalState, err error) {
 	// Read the state file
-	stateFile, err := os.ReadFile(jackalStatePath)
+	stateFile, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 
 	// Unmarshal the json file into a Go struct
-	return state, json.Unmarshal(stateFile, &state)
+	if err := json.Unmarshal(stateFile, &state); err != nil {
+		return nil, err
+	}
+
+	// Replace any references to credentials held outside of the state with their values
+	return state, credentials.Resolve(context.TODO(), state)
 }
@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package state provides helpers for interacting with the Jackal agent state.
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/racer159/jackal/src/pkg/credentials"
+	"github.com/racer159/jackal/src/pkg/credentials/credentialstest"
+	"github.com/racer159/jackal/src/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadJackalStateWithCredentialStore(t *testing.T) {
+	srv, _ := credentialstest.NewVaultKV(t, "agent-token")
+	store := types.CredentialStore{Provider: "vault", Address: srv.URL}
+
+	// The environment of the jackal-credential-store secret that the agent deployment loads
+	env, err := credentials.ClusterEnv(store, "agent-token")
+	require.NoError(t, err)
+	for _, key := range []string{"VAULT_ADDR", "VAULT_TOKEN", "VAULT_NAMESPACE"} {
+		t.Setenv(key, env[key])
+	}
+
+	state := &types.JackalState{
+		CredentialStore: store,
+		RegistryInfo:    types.RegistryInfo{PushUsername: "jackal-push", PushPassword: "push-value", PullPassword: "pull-value"},
+		GitServer:       types.GitServerInfo{PushPassword: "git-value"},
+	}
+	stored, err := credentials.Externalize(context.Background(), state)
+	require.NoError(t, err)
+	data, err := json.Marshal(stored)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "push-value")
+
+	// The state file mounted from the jackal-state secret
+	path := filepath.Join(t.TempDir(), "state")
+	require.NoError(t, os.WriteFile(path, data, 0600))
+
+	loaded, err := readJackalState(path)
+	require.NoError(t, err)
+	require.Equal(t, state, loaded)
+
+	// Without the credential store secret the agent cannot resolve the credentials
+	t.Setenv("VAULT_TOKEN", "")
+	_, err = readJackalState(path)
+	require.ErrorIs(t, err, credentials.ErrUnresolved)
+
+	t.Setenv("VAULT_TOKEN", "wrong-token")
+	_, err = readJackalState(path)
+	require.ErrorIs(t, err, credentials.ErrUnresolved)
+}
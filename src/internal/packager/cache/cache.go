@@ -0,0 +1,299 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package cache contains functions for the content-addressed build cache shared across package creates.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/defenseunicorns/pkg/helpers"
+	"github.com/racer159/jackal/src/config"
+	"github.com/racer159/jackal/src/pkg/layout"
+	"github.com/racer159/jackal/src/pkg/message"
+	"github.com/racer159/jackal/src/pkg/transform"
+	"github.com/racer159/jackal/src/types"
+)
+
+// ComponentsDir is the directory within the cache that holds component tarballs by their key.
+const ComponentsDir = "components"
+
+// pinnedRefRegex matches git refs that are not expected to move: commit SHAs, tags and version-like names.
+var pinnedRefRegex = regexp.MustCompile(`^([0-9a-f]{40}|refs/tags/.+|v?[0-9].*)$`)
+
+// componentInputs are the inputs of a component that determine the content of its tarball.
+type componentInputs struct {
+	CLIVersion   string                `json:"cliVersion"`
+	Architecture string                `json:"architecture"`
+	Reproducible bool                  `json:"reproducible,omitempty"`
+	Component    types.JackalComponent `json:"component"`
+}
+
+// ComponentKey returns the key of a component in the build cache, which is the digest of its definition with every local source replaced by the digest of its content.
+//
+// Components with inputs that can change without their definition changing (actions, mutable git refs and unverified remote files) are not cacheable.
+//
+// Reproducible builds archive components differently so they are cached separately.
+func ComponentKey(component types.JackalComponent, arch string, reproducible bool) (key string, cacheable bool, err error) {
+	if reason := uncacheableReason(component); reason != "" {
+		message.Debugf("Component %q is not cacheable: %s", component.Name, reason)
+		return "", false, nil
+	}
+
+	// Work on a copy so that the component that is packaged keeps its paths.
+	b, err := json.Marshal(component)
+	if err != nil {
+		return "", false, err
+	}
+	var inputs componentInputs
+	if err := json.Unmarshal(b, &inputs.Component); err != nil {
+		return "", false, err
+	}
+	inputs.CLIVersion = config.CLIVersion
+	inputs.Architecture = arch
+	inputs.Reproducible = reproducible
+
+	c := &inputs.Component
+	for idx, file := range c.Files {
+		if c.Files[idx].Source, err = sourceDigest(file.Source); err != nil {
+			return "", false, err
+		}
+	}
+	for idx, chart := range c.Charts {
+		if chart.LocalPath != "" {
+			if c.Charts[idx].LocalPath, err = sourceDigest(chart.LocalPath); err != nil {
+				return "", false, err
+			}
+		}
+		for valuesIdx, valuesFile := range chart.ValuesFiles {
+			if c.Charts[idx].ValuesFiles[valuesIdx], err = sourceDigest(valuesFile); err != nil {
+				return "", false, err
+			}
+		}
+	}
+	for idx, manifest := range c.Manifests {
+		for fileIdx, file := range manifest.Files {
+			if c.Manifests[idx].Files[fileIdx], err = sourceDigest(file); err != nil {
+				return "", false, err
+			}
+		}
+		for kustomizeIdx, kustomization := range manifest.Kustomizations {
+			if c.Manifests[idx].Kustomizations[kustomizeIdx], err = sourceDigest(kustomization); err != nil {
+				return "", false, err
+			}
+		}
+	}
+	for idx, data := range c.DataInjections {
+		if c.DataInjections[idx].Source, err = sourceDigest(data.Source); err != nil {
+			return "", false, err
+		}
+	}
+
+	b, err = json.Marshal(inputs)
+	if err != nil {
+		return "", false, err
+	}
+	digest := sha256.Sum256(b)
+	return hex.EncodeToString(digest[:]), true, nil
+}
+
+// uncacheableReason returns why a component cannot be cached, or an empty string if it can be.
+func uncacheableReason(component types.JackalComponent) string {
+	onCreate := component.Actions.OnCreate
+	if len(onCreate.Before)+len(onCreate.After)+len(onCreate.OnSuccess) > 0 {
+		return "it has onCreate actions"
+	}
+	if component.DeprecatedCosignKeyPath != "" {
+		return "it verifies downloads with a cosign key"
+	}
+
+	for _, file := range component.Files {
+		if helpers.IsURL(file.Source) && file.Shasum == "" {
+			return fmt.Sprintf("the file %s has no shasum", file.Source)
+		}
+	}
+	for _, chart := range component.Charts {
+		if chart.LocalPath == "" && chart.Version == "" {
+			return fmt.Sprintf("the chart %s has no version", chart.Name)
+		}
+		for _, valuesFile := range chart.ValuesFiles {
+			if helpers.IsURL(valuesFile) {
+				return fmt.Sprintf("the values file %s is remote", valuesFile)
+			}
+		}
+	}
+	for _, manifest := range component.Manifests {
+		for _, file := range manifest.Files {
+			// Remote manifests are only stable when they are verified by a checksum
+			if helpers.IsURL(file) && !hasChecksum(file) {
+				return fmt.Sprintf("the manifest %s has no shasum", file)
+			}
+		}
+		for _, kustomization := range manifest.Kustomizations {
+			if helpers.IsURL(kustomization) {
+				return fmt.Sprintf("the kustomization %s is remote", kustomization)
+			}
+		}
+	}
+	for _, data := range component.DataInjections {
+		if helpers.IsURL(data.Source) {
+			return fmt.Sprintf("the data injection %s is remote", data.Source)
+		}
+	}
+	for _, repo := range component.Repos {
+		_, ref, err := transform.GitURLSplitRef(repo)
+		if err != nil || !pinnedRefRegex.MatchString(ref) {
+			return fmt.Sprintf("the repo %s is not pinned to a tag or commit", repo)
+		}
+	}
+
+	return ""
+}
+
+// hasChecksum returns whether a remote URL carries the @checksum suffix that downloads are verified against.
+func hasChecksum(src string) bool {
+	parsed, err := url.Parse(src)
+	if err != nil {
+		return false
+	}
+	count := strings.Count(src, "@")
+	return count > 1 || (count == 1 && parsed.User == nil)
+}
+
+// sourceDigest returns the digest of the content of a local source, remote sources are returned as they are.
+func sourceDigest(source string) (string, error) {
+	if helpers.IsURL(source) {
+		return source, nil
+	}
+
+	manifest, err := layout.NewDataInjectionManifest(source)
+	if err != nil {
+		return "", fmt.Errorf("unable to hash %s: %w", source, err)
+	}
+	b, err := json.Marshal(manifest)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(digest[:]), nil
+}
+
+// componentPath returns the path of a component tarball in the cache.
+func componentPath(key string) string {
+	return filepath.Join(config.GetAbsCachePath(), ComponentsDir, key+".tar")
+}
+
+// GetComponent returns the path of the cached tarball for a component key, marking it as recently used.
+func GetComponent(key string) (string, bool) {
+	path := componentPath(key)
+	if helpers.InvalidPath(path) {
+		return "", false
+	}
+	Touch(path)
+	return path, true
+}
+
+// PutComponent stores a component tarball in the cache under its key.
+func PutComponent(key string, tarball string) error {
+	return Link(tarball, componentPath(key))
+}
+
+// Link places a file at the destination by hard linking it where possible, falling back to an atomic copy.
+func Link(src, dst string) error {
+	if err := helpers.CreateDirectory(filepath.Dir(dst), helpers.ReadWriteExecuteUser); err != nil {
+		return err
+	}
+	_ = os.Remove(dst)
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	// Copy to a temporary file first so that an interrupted copy is never mistaken for a complete one.
+	out, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(out.Name())
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(out.Name(), dst)
+}
+
+// Touch marks a cached file as recently used so that it survives pruning.
+func Touch(path string) {
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err != nil {
+		message.Debugf("Unable to mark %s as used: %s", path, err.Error())
+	}
+}
+
+// Prune removes every file in the cache that has not been used within the given duration, returning the number of files and bytes removed.
+func Prune(olderThan time.Duration) (removed int, freed int64, err error) {
+	root := config.GetAbsCachePath()
+	cutoff := time.Now().Add(-olderThan)
+
+	dirs := []string{}
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			if path != root {
+				dirs = append(dirs, path)
+			}
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(cutoff) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		removed++
+		freed += info.Size()
+		return nil
+	})
+	if err != nil {
+		return removed, freed, err
+	}
+
+	// Remove the directories left empty, deepest first.
+	for idx := len(dirs) - 1; idx >= 0; idx-- {
+		if entries, err := os.ReadDir(dirs[idx]); err == nil && len(entries) == 0 {
+			_ = os.Remove(dirs[idx])
+		}
+	}
+
+	return removed, freed, nil
+}
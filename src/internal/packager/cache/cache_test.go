@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package cache contains functions for the content-addressed build cache shared across package creates.
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/racer159/jackal/src/config"
+	"github.com/racer159/jackal/src/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponentKey(t *testing.T) {
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "deployment.yaml")
+	require.NoError(t, os.WriteFile(manifest, []byte("kind: Deployment"), 0600))
+
+	component := types.JackalComponent{
+		Name:   "podinfo",
+		Images: []string{"ghcr.io/stefanprodan/podinfo:6.4.0"},
+		Repos:  []string{"https://github.com/stefanprodan/podinfo.git@6.4.0"},
+		Manifests: []types.JackalManifest{
+			{Name: "podinfo", Files: []string{manifest}},
+		},
+	}
+
+	key, cacheable, err := ComponentKey(component, "amd64", false)
+	require.NoError(t, err)
+	require.True(t, cacheable)
+
+	// The key does not depend on where the sources are, only on what they contain
+	moved := filepath.Join(t.TempDir(), "deployment.yaml")
+	require.NoError(t, os.WriteFile(moved, []byte("kind: Deployment"), 0600))
+	movedComponent := component
+	movedComponent.Manifests = []types.JackalManifest{{Name: "podinfo", Files: []string{moved}}}
+	movedKey, _, err := ComponentKey(movedComponent, "amd64", false)
+	require.NoError(t, err)
+	require.Equal(t, key, movedKey)
+	require.Equal(t, manifest, component.Manifests[0].Files[0])
+
+	otherArchKey, _, err := ComponentKey(component, "arm64", false)
+	require.NoError(t, err)
+	require.NotEqual(t, key, otherArchKey)
+
+	reproducibleKey, _, err := ComponentKey(component, "amd64", true)
+	require.NoError(t, err)
+	require.NotEqual(t, key, reproducibleKey)
+
+	require.NoError(t, os.WriteFile(manifest, []byte("kind: StatefulSet"), 0600))
+	changedKey, _, err := ComponentKey(component, "amd64", false)
+	require.NoError(t, err)
+	require.NotEqual(t, key, changedKey)
+
+	tests := []struct {
+		name   string
+		mutate func(c *types.JackalComponent)
+	}{
+		{
+			name: "branch ref",
+			mutate: func(c *types.JackalComponent) {
+				c.Repos = []string{"https://github.com/stefanprodan/podinfo.git@main"}
+			},
+		},
+		{
+			name: "all refs",
+			mutate: func(c *types.JackalComponent) {
+				c.Repos = []string{"https://github.com/stefanprodan/podinfo.git"}
+			},
+		},
+		{
+			name: "remote file without shasum",
+			mutate: func(c *types.JackalComponent) {
+				c.Files = []types.JackalFile{{Source: "https://example.com/file.txt", Target: "file.txt"}}
+			},
+		},
+		{
+			name: "remote manifest without shasum",
+			mutate: func(c *types.JackalComponent) {
+				c.Manifests = []types.JackalManifest{{Name: "remote", Files: []string{"https://example.com/manifest.yaml"}}}
+			},
+		},
+		{
+			name: "create actions",
+			mutate: func(c *types.JackalComponent) {
+				c.Actions.OnCreate.Before = []types.JackalComponentAction{{Cmd: "make"}}
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			c := component
+			tt.mutate(&c)
+			_, cacheable, err := ComponentKey(c, "amd64", false)
+			require.NoError(t, err)
+			require.False(t, cacheable)
+		})
+	}
+
+	pinned := component
+	pinned.Manifests = []types.JackalManifest{{Name: "remote", Files: []string{"https://example.com/manifest.yaml@3a4a8a2ad1c3b8a0a5cbd1e07ea8e4b9aaad5d4bd1e8cb1be1a4b0c6cf2a4f8e"}}}
+	_, cacheable, err = ComponentKey(pinned, "amd64", false)
+	require.NoError(t, err)
+	require.True(t, cacheable)
+}
+
+func TestPrune(t *testing.T) {
+	config.CommonOptions.CachePath = t.TempDir()
+
+	old := filepath.Join(config.GetAbsCachePath(), ComponentsDir, "old.tar")
+	recent := filepath.Join(config.GetAbsCachePath(), "images", "sha256:recent")
+	for _, path := range []string{old, recent} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+		require.NoError(t, os.WriteFile(path, []byte("cached"), 0600))
+	}
+	lastUsed := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(old, lastUsed, lastUsed))
+
+	removed, freed, err := Prune(24 * time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, 1, removed)
+	require.Equal(t, int64(len("cached")), freed)
+	require.NoFileExists(t, old)
+	require.NoDirExists(t, filepath.Dir(old))
+	require.FileExists(t, recent)
+
+	// Reusing a cached component marks it as used
+	require.NoError(t, os.MkdirAll(filepath.Dir(old), 0700))
+	require.NoError(t, os.WriteFile(old, []byte("cached"), 0600))
+	require.NoError(t, os.Chtimes(old, lastUsed, lastUsed))
+	path, ok := GetComponent("old")
+	require.True(t, ok)
+	require.Equal(t, old, path)
+	removed, _, err = Prune(24 * time.Hour)
+	require.NoError(t, err)
+	require.Zero(t, removed)
+}
@@ -156,6 +156,34 @@ func (g *Git) UpdateGitUser(oldAdminPass string, username string, userpass strin
 	return err
 }
 
+// VerifyGiteaLogin checks that the Jackal git server accepts a username with a password or token.
+func (g *Git) VerifyGiteaLogin(username, secret string) error {
+	message.Debugf("git.VerifyGiteaLogin()")
+
+	c, err := cluster.NewCluster()
+	if err != nil {
+		return err
+	}
+	tunnel, err := c.NewTunnel(cluster.JackalNamespaceName, k8s.SvcResource, cluster.JackalGitServerName, "", 0, cluster.JackalGitServerPort)
+	if err != nil {
+		return err
+	}
+	_, err = tunnel.Connect()
+	if err != nil {
+		return err
+	}
+	defer tunnel.Close()
+
+	userEndpoint := fmt.Sprintf("%s/api/v1/user", tunnel.HTTPEndpoint())
+	userRequest, _ := netHttp.NewRequest("GET", userEndpoint, nil)
+	return tunnel.Wrap(func() error {
+		if _, _, err := g.DoHTTPThings(userRequest, username, secret); err != nil {
+			return fmt.Errorf("the git server rejected the credentials of %s: %w", username, err)
+		}
+		return nil
+	})
+}
+
 // CreatePackageRegistryToken uses the Gitea API to create a package registry token.
 func (g *Git) CreatePackageRegistryToken() (CreateTokenResponse, error) {
 	message.Debugf("git.CreatePackageRegistryToken()")
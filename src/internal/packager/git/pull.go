@@ -5,11 +5,15 @@
 package git
 
 import (
+	"errors"
 	"fmt"
 	"path"
 	"strings"
 
+	"github.com/go-git/go-git/v5"
+	goConfig "github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/racer159/jackal/src/config"
 	"github.com/racer159/jackal/src/pkg/transform"
 	"github.com/racer159/jackal/src/pkg/utils"
@@ -78,3 +82,65 @@ func (g *Git) Pull(gitURL, targetFolder string, shallow bool) error {
 
 	return nil
 }
+
+// PullFromServer clones a repo that was previously pushed to the configured git server back into the target folder.
+//
+// The original upstream is restored as the online remote so that the repo can be pushed again with PushRepo.
+func (g *Git) PullFromServer(gitURL, targetFolder string) error {
+	g.Spinner.Updatef("Processing git repo %s", gitURL)
+
+	gitURLNoRef, _, err := transform.GitURLSplitRef(gitURL)
+	if err != nil {
+		return err
+	}
+
+	// Construct a path unique to this git repo
+	repoFolder, err := transform.GitURLtoFolderName(gitURL)
+	if err != nil {
+		return err
+	}
+
+	g.GitPath = path.Join(targetFolder, repoFolder)
+
+	serverURL, err := transform.GitURL(g.Server.Address, gitURLNoRef, g.Server.PushUsername)
+	if err != nil {
+		return fmt.Errorf("unable to transform the git url: %w", err)
+	}
+
+	gitCred := &http.BasicAuth{
+		Username: g.Server.PullUsername,
+		Password: g.Server.PullPassword,
+	}
+
+	repo, err := git.PlainClone(g.GitPath, false, &git.CloneOptions{
+		URL:        serverURL.String(),
+		Auth:       gitCred,
+		Progress:   g.Spinner,
+		RemoteName: offlineRemoteName,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to clone %s from the git server: %w", serverURL.String(), err)
+	}
+
+	// Fetch every branch and tag that was pushed to the server, not just the default branch.
+	err = repo.Fetch(&git.FetchOptions{
+		RemoteName: offlineRemoteName,
+		Auth:       gitCred,
+		Progress:   g.Spinner,
+		RefSpecs:   []goConfig.RefSpec{"refs/*:refs/*"},
+		Tags:       git.AllTags,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return err
+	}
+
+	_, err = repo.CreateRemote(&goConfig.RemoteConfig{
+		Name: onlineRemoteName,
+		URLs: []string{gitURLNoRef},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create online remote: %w", err)
+	}
+
+	return nil
+}
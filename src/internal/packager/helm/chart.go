@@ -7,6 +7,7 @@ package helm
 import (
 	"errors"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/defenseunicorns/pkg/helpers"
@@ -211,6 +212,37 @@ func (h *Helm) RemoveChart(namespace string, name string, spinner *message.Spinn
 	return err
 }
 
+// GetReleaseRevision returns the latest revision of a release, or 0 if the release is not installed.
+func (h *Helm) GetReleaseRevision(namespace string, name string, spinner *message.Spinner) (int, error) {
+	if err := h.createActionConfig(namespace, spinner); err != nil {
+		return 0, fmt.Errorf("unable to initialize the K8s client: %w", err)
+	}
+
+	histClient := action.NewHistory(h.actionConfig)
+	histClient.Max = 1
+	releases, err := histClient.Run(name)
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("unable to get the history of the %s helm release: %w", name, err)
+	}
+	if len(releases) == 0 {
+		return 0, nil
+	}
+
+	return releases[len(releases)-1].Version, nil
+}
+
+// RollbackRelease rolls a release back to the given revision.
+func (h *Helm) RollbackRelease(namespace string, name string, revision int, spinner *message.Spinner) error {
+	if err := h.createActionConfig(namespace, spinner); err != nil {
+		return fmt.Errorf("unable to initialize the K8s client: %w", err)
+	}
+
+	return h.rollbackChart(name, revision)
+}
+
 // UpdateReleaseValues updates values for a given chart release
 // (note: this only works on single-deep charts, charts with dependencies (like loki-stack) will not work)
 func (h *Helm) UpdateReleaseValues(updatedValues map[string]interface{}) error {
@@ -267,6 +299,101 @@ func (h *Helm) UpdateReleaseValues(updatedValues map[string]interface{}) error {
 	return fmt.Errorf("unable to find the %s helm release", h.chart.ReleaseName)
 }
 
+// SaveChartFromRelease writes the chart and supplied values of the latest revision of a deployed release
+// back to the chart and values paths so that the chart can be deployed again as part of a package.
+func (h *Helm) SaveChartFromRelease(releaseName string) error {
+	spinner := message.NewProgressSpinner("Exporting helm release %s", releaseName)
+	defer spinner.Stop()
+
+	lastRelease, err := h.getLatestRelease(releaseName, spinner)
+	if err != nil {
+		return err
+	}
+
+	saved, err := chartutil.Save(lastRelease.Chart, h.chartPath)
+	if err != nil {
+		return fmt.Errorf("unable to save the chart for release %s: %w", releaseName, err)
+	}
+
+	// Ensure the name is consistent for deployments
+	if err := os.Rename(saved, StandardName(h.chartPath, h.chart)+".tgz"); err != nil {
+		return fmt.Errorf("unable to save the final chart tarball: %w", err)
+	}
+
+	if len(h.chart.ValuesFiles) > 0 {
+		valuesData, err := yaml.Marshal(lastRelease.Config)
+		if err != nil {
+			return fmt.Errorf("unable to marshal the values for release %s: %w", releaseName, err)
+		}
+
+		if err := os.WriteFile(StandardValuesName(h.valuesPath, h.chart, 0), valuesData, helpers.ReadWriteUser); err != nil {
+			return fmt.Errorf("unable to write the values for release %s: %w", releaseName, err)
+		}
+	}
+
+	spinner.Success()
+
+	return nil
+}
+
+// GetReleaseManifest returns the rendered manifest of the latest revision of a deployed release.
+//
+// CRDs and hooks are included the same way they are by TemplateChart.
+func (h *Helm) GetReleaseManifest(releaseName string) (string, error) {
+	return h.getReleaseManifest(releaseName, true)
+}
+
+// GetReleaseResourcesManifest returns the rendered manifest of the resources that the latest revision of a deployed release keeps installed.
+//
+// CRDs are included but hooks are not, as hook resources are commonly deleted once they have run.
+func (h *Helm) GetReleaseResourcesManifest(releaseName string) (string, error) {
+	return h.getReleaseManifest(releaseName, false)
+}
+
+func (h *Helm) getReleaseManifest(releaseName string, includeHooks bool) (string, error) {
+	spinner := message.NewProgressSpinner("Loading helm release %s", releaseName)
+	defer spinner.Stop()
+
+	lastRelease, err := h.getLatestRelease(releaseName, spinner)
+	if err != nil {
+		return "", err
+	}
+
+	manifest := lastRelease.Manifest
+
+	if lastRelease.Chart != nil {
+		for _, crd := range lastRelease.Chart.CRDObjects() {
+			manifest += fmt.Sprintf("\n---\n%s", crd.File.Data)
+		}
+	}
+
+	if includeHooks {
+		for _, hook := range lastRelease.Hooks {
+			manifest += fmt.Sprintf("\n---\n%s", hook.Manifest)
+		}
+	}
+
+	spinner.Success()
+
+	return manifest, nil
+}
+
+func (h *Helm) getLatestRelease(releaseName string, spinner *message.Spinner) (*release.Release, error) {
+	err := h.createActionConfig(h.chart.Namespace, spinner)
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize the K8s client: %w", err)
+	}
+
+	// A version of 0 returns the latest revision of the release.
+	client := action.NewGet(h.actionConfig)
+	lastRelease, err := client.Run(releaseName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find the %s helm release: %w", releaseName, err)
+	}
+
+	return lastRelease, nil
+}
+
 func (h *Helm) installChart(postRender *renderer) (*release.Release, error) {
 	// Bind the helm action.
 	client := action.NewInstall(h.actionConfig)
@@ -84,10 +84,7 @@ func NewFromJackalManifest(manifest types.JackalManifest, manifestPath, packageN
 
 	// Generate a hashed chart name.
 	rawChartName := fmt.Sprintf("raw-%s-%s-%s", packageName, componentName, manifest.Name)
-	hasher := sha1.New()
-	hasher.Write([]byte(rawChartName))
 	tmpChart.Metadata.Name = rawChartName
-	sha1ReleaseName := hex.EncodeToString(hasher.Sum(nil))
 
 	// This is fun, increment forward in a semver-way using epoch so helm doesn't cry.
 	tmpChart.Metadata.Version = fmt.Sprintf("0.1.%d", config.GetStartTime())
@@ -112,9 +109,8 @@ func NewFromJackalManifest(manifest types.JackalManifest, manifestPath, packageN
 	// Generate the struct to pass to InstallOrUpgradeChart().
 	h = &Helm{
 		chart: types.JackalChart{
-			Name: tmpChart.Metadata.Name,
-			// Preserve the jackal prefix for chart names to match v0.22.x and earlier behavior.
-			ReleaseName: fmt.Sprintf("jackal-%s", sha1ReleaseName),
+			Name:        tmpChart.Metadata.Name,
+			ReleaseName: ManifestReleaseName(packageName, componentName, manifest.Name),
 			Version:     tmpChart.Metadata.Version,
 			Namespace:   manifest.Namespace,
 			NoWait:      manifest.NoWait,
@@ -132,6 +128,16 @@ func NewFromJackalManifest(manifest types.JackalManifest, manifestPath, packageN
 	return h, nil
 }
 
+// ManifestReleaseName returns the helm release name Jackal uses when deploying the given manifest.
+func ManifestReleaseName(packageName, componentName, manifestName string) string {
+	rawChartName := fmt.Sprintf("raw-%s-%s-%s", packageName, componentName, manifestName)
+	hasher := sha1.New()
+	hasher.Write([]byte(rawChartName))
+
+	// Preserve the jackal prefix for chart names to match v0.22.x and earlier behavior.
+	return fmt.Sprintf("jackal-%s", hex.EncodeToString(hasher.Sum(nil)))
+}
+
 // WithDeployInfo adds the necessary information to deploy a given chart
 func WithDeployInfo(component types.JackalComponent, cfg *types.PackagerConfig, cluster *cluster.Cluster, valuesOverrides map[string]any, timeout time.Duration, retries int) Modifier {
 	return func(h *Helm) {
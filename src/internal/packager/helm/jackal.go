@@ -36,7 +36,7 @@ func (h *Helm) UpdateJackalRegistryValues() error {
 
 	h.chart = types.JackalChart{
 		Namespace:   "jackal",
-		ReleaseName: "jackal-docker-registry",
+		ReleaseName: types.JackalInClusterContainerRegistryName,
 	}
 
 	err = h.UpdateReleaseValues(registryValues)
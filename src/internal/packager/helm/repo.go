@@ -137,6 +137,7 @@ func (h *Helm) DownloadPublishedChart(cosignKeyPath string) error {
 	// Set up the helm pull config
 	pull := action.NewPull()
 	pull.Settings = cli.New()
+	pull.Settings.RepositoryCache = repositoryCache()
 
 	var (
 		regClient *registry.Client
@@ -194,8 +195,10 @@ func (h *Helm) DownloadPublishedChart(cosignKeyPath string) error {
 		Out:            spinner,
 		RegistryClient: regClient,
 		// TODO: Further research this with regular/OCI charts
-		Verify:  downloader.VerifyNever,
-		Getters: getter.All(pull.Settings),
+		Verify:           downloader.VerifyNever,
+		Getters:          getter.All(pull.Settings),
+		RepositoryConfig: pull.Settings.RepositoryConfig,
+		RepositoryCache:  pull.Settings.RepositoryCache,
 		Options: []getter.Option{
 			getter.WithInsecureSkipVerifyTLS(config.CommonOptions.Insecure),
 			getter.WithBasicAuth(username, password),
@@ -287,6 +290,7 @@ func (h *Helm) buildChartDependencies(spinner *message.Spinner) error {
 	}
 
 	h.settings = cli.New()
+	h.settings.RepositoryCache = repositoryCache()
 	defaultKeyring := filepath.Join(homedir.HomeDir(), ".gnupg", "pubring.gpg")
 	if v, ok := os.LookupEnv("GNUPGHOME"); ok {
 		defaultKeyring = filepath.Join(v, "pubring.gpg")
@@ -322,6 +326,11 @@ func (h *Helm) buildChartDependencies(spinner *message.Spinner) error {
 	return nil
 }
 
+// repositoryCache returns the directory within the Jackal cache that Helm caches repository indexes and charts in.
+func repositoryCache() string {
+	return filepath.Join(config.GetAbsCachePath(), "helm")
+}
+
 func (h *Helm) loadAndValidateChart(location string) (loader.ChartLoader, *chart.Chart, error) {
 	// Validate the chart
 	cl, err := loader.Loader(location)
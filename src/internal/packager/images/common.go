@@ -24,4 +24,6 @@ type ImageConfig struct {
 	Architectures []string
 
 	RegistryOverrides map[string]string
+
+	Concurrency int
 }
@@ -0,0 +1,367 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package images provides functions for building and pushing images.
+package images
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/logs"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/racer159/jackal/src/config"
+	"github.com/racer159/jackal/src/pkg/cluster"
+	"github.com/racer159/jackal/src/pkg/k8s"
+	"github.com/racer159/jackal/src/pkg/message"
+	"github.com/racer159/jackal/src/pkg/transform"
+	"github.com/racer159/jackal/src/types"
+)
+
+// RegistryGC deletes the manifests in the Jackal registry that no deployed package references and then runs the registry's blob garbage collection.
+//
+// The blob garbage collection can delete blobs that are being pushed at the same time, so it can be skipped to only delete the manifests.
+type RegistryGC struct {
+	RegInfo types.RegistryInfo
+
+	Insecure bool
+
+	// Packages are the deployed packages whose images are kept
+	Packages []types.DeployedPackage
+
+	// History is the deploy history of the packages, whose images are kept so that they can still be rolled back to
+	History []types.DeployedPackageGeneration
+
+	// Digests limits the collection to these manifest digests, every manifest in the registry is considered if empty.
+	// Only these manifests are looked up, so the tags and sizes of the collected manifests are not reported.
+	Digests []string
+
+	// SkipBlobGC only deletes the manifests, leaving their blobs on disk until the registry's blob garbage collection is next run
+	SkipBlobGC bool
+
+	// DryRun reports what would be deleted without deleting anything
+	DryRun bool
+
+	// Confirm is called with the report before anything is deleted, nothing is deleted if it returns false
+	Confirm func(report *GCReport) bool
+}
+
+// GCManifest is a manifest in the Jackal registry that no deployed package references.
+type GCManifest struct {
+	Repository string
+	Digest     string
+	Tags       []string
+	// Size is the size of the manifest and of the blobs that no referenced manifest shares
+	Size int64
+}
+
+// GCReport is the result of a registry garbage collection.
+type GCReport struct {
+	Manifests []GCManifest
+
+	// ReclaimedBytes is the size of the manifests and the blobs that are only used by them
+	ReclaimedBytes int64
+
+	// Deleted is whether the manifests were deleted (rather than a dry run or declined)
+	Deleted bool
+
+	// BlobGCOutput is the output of the registry's blob garbage collection, if it was run
+	BlobGCOutput string
+}
+
+// Run finds the unreferenced manifests in the Jackal registry and, unless this is a dry run, deletes them and garbage collects their blobs.
+func (gc *RegistryGC) Run() (*GCReport, error) {
+	logs.Warn.SetOutput(&message.DebugWriter{})
+	logs.Progress.SetOutput(&message.DebugWriter{})
+
+	var (
+		err         error
+		tunnel      *k8s.Tunnel
+		registryURL = gc.RegInfo.Address
+	)
+
+	c, _ := cluster.NewCluster()
+	if c != nil {
+		registryURL, tunnel, err = c.ConnectToJackalRegistryEndpoint(gc.RegInfo)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if tunnel != nil {
+		defer tunnel.Close()
+	}
+
+	wrap := func(function func() error) error {
+		if tunnel != nil {
+			return tunnel.Wrap(function)
+		}
+
+		return function()
+	}
+
+	options := config.GetCraneOptions(gc.Insecure)
+	options = append(options, config.GetCraneAuthOption(gc.RegInfo.PushUsername, gc.RegInfo.PushPassword))
+
+	spinner := message.NewProgressSpinner("Finding the images referenced by deployed packages")
+	defer spinner.Stop()
+
+	var report *GCReport
+	err = wrap(func() error {
+		referenced, err := gc.referencedDigests(registryURL, options)
+		if err != nil {
+			return err
+		}
+
+		spinner.Updatef("Cataloging the images in the registry")
+		report, err = gc.plan(registryURL, referenced, options)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	spinner.Success()
+
+	if gc.DryRun || len(report.Manifests) == 0 || (gc.Confirm != nil && !gc.Confirm(report)) {
+		return report, nil
+	}
+
+	spinner = message.NewProgressSpinner("Deleting %d unreferenced manifests", len(report.Manifests))
+	defer spinner.Stop()
+
+	for _, manifest := range report.Manifests {
+		digestRef := fmt.Sprintf("%s/%s@%s", registryURL, manifest.Repository, manifest.Digest)
+		message.Debugf("crane.Delete() %s", digestRef)
+		if err := wrap(func() error { return crane.Delete(digestRef, options...) }); err != nil {
+			return report, fmt.Errorf("unable to delete %s@%s: %w", manifest.Repository, manifest.Digest, err)
+		}
+	}
+	report.Deleted = true
+
+	// Deleting a manifest only unlinks it, its blobs remain on disk until the registry garbage collects them
+	if gc.SkipBlobGC {
+		message.Debug("Skipping the registry blob garbage collection")
+	} else if gc.RegInfo.InternalRegistry && c != nil {
+		spinner.Updatef("Garbage collecting the registry blobs")
+		if report.BlobGCOutput, err = c.GarbageCollectRegistry(context.TODO()); err != nil {
+			return report, fmt.Errorf("unable to garbage collect the registry blobs: %w", err)
+		}
+	} else {
+		message.Debug("The registry is external, its blobs are reclaimed by its own garbage collection")
+	}
+
+	spinner.Success()
+	return report, nil
+}
+
+// referencedDigests returns the digests of the manifests referenced by the deployed packages and their history.
+//
+// Components deployed before their images were recorded are resolved through the tags their images were pushed with.
+func (gc *RegistryGC) referencedDigests(registryURL string, options []crane.Option) (map[string]bool, error) {
+	referenced := make(map[string]bool)
+	legacy := []string{}
+
+	addComponents := func(pkg types.JackalPackage, deployedComponents []types.DeployedComponent) {
+		for _, deployedComponent := range deployedComponents {
+			for _, image := range deployedComponent.Images {
+				referenced[image.Digest] = true
+			}
+			if len(deployedComponent.Images) > 0 {
+				continue
+			}
+			for _, component := range pkg.Components {
+				if component.Name == deployedComponent.Name {
+					legacy = append(legacy, component.Images...)
+				}
+			}
+		}
+	}
+	for _, pkg := range gc.Packages {
+		addComponents(pkg.Data, pkg.DeployedComponents)
+	}
+	for _, generation := range gc.History {
+		addComponents(generation.Data, generation.DeployedComponents)
+	}
+
+	for _, image := range legacy {
+		refInfo, err := transform.ParseImageRef(image)
+		if err != nil {
+			return nil, err
+		}
+		names, err := (&ImageConfig{}).jackalRegistryNames(registryURL, refInfo)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			digest, err := crane.Digest(name, options...)
+			if isNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("unable to resolve the deployed image %s: %w", image, err)
+			}
+			referenced[digest] = true
+		}
+	}
+
+	return referenced, nil
+}
+
+// plan finds the manifests in the registry that are not referenced and how much space deleting them reclaims.
+func (gc *RegistryGC) plan(registryURL string, referenced map[string]bool, options []crane.Option) (*GCReport, error) {
+	if len(gc.Digests) > 0 {
+		return gc.planDigests(registryURL, referenced, options)
+	}
+
+	repositories, err := crane.Catalog(registryURL, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	type manifestInfo struct {
+		size  int64
+		blobs map[string]int64
+	}
+	manifests := make(map[string]manifestInfo)
+	// Blobs that a kept manifest uses are not reclaimed
+	keptBlobs := make(map[string]bool)
+	report := &GCReport{}
+
+	for _, repository := range repositories {
+		repositoryRef := fmt.Sprintf("%s/%s", registryURL, repository)
+		tags, err := crane.ListTags(repositoryRef, options...)
+		if err != nil {
+			return nil, err
+		}
+
+		tagsByDigest := make(map[string][]string)
+		for _, tag := range tags {
+			digest, err := crane.Digest(fmt.Sprintf("%s:%s", repositoryRef, tag), options...)
+			if err != nil {
+				return nil, err
+			}
+			tagsByDigest[digest] = append(tagsByDigest[digest], tag)
+		}
+
+		for digest, tags := range tagsByDigest {
+			info, ok := manifests[digest]
+			if !ok {
+				raw, err := crane.Manifest(fmt.Sprintf("%s@%s", repositoryRef, digest), options...)
+				if err != nil {
+					return nil, err
+				}
+				info = manifestInfo{size: int64(len(raw)), blobs: manifestBlobs(raw)}
+				manifests[digest] = info
+			}
+
+			if referenced[digest] {
+				for blob := range info.blobs {
+					keptBlobs[blob] = true
+				}
+				continue
+			}
+
+			slices.Sort(tags)
+			report.Manifests = append(report.Manifests, GCManifest{Repository: repository, Digest: digest, Tags: tags})
+		}
+	}
+
+	reclaimedBlobs := make(map[string]bool)
+	for idx, manifest := range report.Manifests {
+		info := manifests[manifest.Digest]
+		report.Manifests[idx].Size = info.size
+		report.ReclaimedBytes += info.size
+		for blob, size := range info.blobs {
+			if keptBlobs[blob] {
+				continue
+			}
+			report.Manifests[idx].Size += size
+			if !reclaimedBlobs[blob] {
+				reclaimedBlobs[blob] = true
+				report.ReclaimedBytes += size
+			}
+		}
+	}
+
+	sort.Slice(report.Manifests, func(i, j int) bool {
+		if report.Manifests[i].Repository != report.Manifests[j].Repository {
+			return report.Manifests[i].Repository < report.Manifests[j].Repository
+		}
+		return report.Manifests[i].Digest < report.Manifests[j].Digest
+	})
+
+	return report, nil
+}
+
+// planDigests finds the repositories that hold the manifests of the digests the collection is limited to that are not referenced.
+//
+// The manifests are looked up by digest so that the tags of every repository in the registry do not have to be resolved.
+func (gc *RegistryGC) planDigests(registryURL string, referenced map[string]bool, options []crane.Option) (*GCReport, error) {
+	report := &GCReport{}
+
+	var digests []string
+	for _, digest := range gc.Digests {
+		if !referenced[digest] && !slices.Contains(digests, digest) {
+			digests = append(digests, digest)
+		}
+	}
+	if len(digests) == 0 {
+		return report, nil
+	}
+
+	repositories, err := crane.Catalog(registryURL, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, repository := range repositories {
+		for _, digest := range digests {
+			_, err := crane.Manifest(fmt.Sprintf("%s/%s@%s", registryURL, repository, digest), options...)
+			if isNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			report.Manifests = append(report.Manifests, GCManifest{Repository: repository, Digest: digest})
+		}
+	}
+
+	sort.Slice(report.Manifests, func(i, j int) bool {
+		if report.Manifests[i].Repository != report.Manifests[j].Repository {
+			return report.Manifests[i].Repository < report.Manifests[j].Repository
+		}
+		return report.Manifests[i].Digest < report.Manifests[j].Digest
+	})
+
+	return report, nil
+}
+
+// manifestBlobs returns the config and layer blobs of an image manifest by digest, an index has no blobs of its own.
+func manifestBlobs(raw []byte) map[string]int64 {
+	blobs := make(map[string]int64)
+	manifest, err := v1.ParseManifest(strings.NewReader(string(raw)))
+	if err != nil || manifest.MediaType.IsIndex() {
+		return blobs
+	}
+	if manifest.Config.Digest.Hex != "" {
+		blobs[manifest.Config.Digest.String()] = manifest.Config.Size
+	}
+	for _, layer := range manifest.Layers {
+		blobs[layer.Digest.String()] = layer.Size
+	}
+	return blobs
+}
+
+// isNotFound returns whether a registry error means that the reference does not exist.
+func isNotFound(err error) bool {
+	var transportErr *transport.Error
+	return errors.As(err, &transportErr) && transportErr.StatusCode == http.StatusNotFound
+}
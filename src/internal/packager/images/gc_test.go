@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package images provides functions for building and pushing images.
+package images
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/racer159/jackal/src/pkg/transform"
+	"github.com/racer159/jackal/src/types"
+	"github.com/stretchr/testify/require"
+)
+
+// imageSize returns the size of an image's manifest, config and layers.
+func imageSize(t *testing.T, img v1.Image) int64 {
+	t.Helper()
+	manifest, err := img.RawManifest()
+	require.NoError(t, err)
+	config, err := img.RawConfigFile()
+	require.NoError(t, err)
+	size := int64(len(manifest) + len(config))
+	layers, err := img.Layers()
+	require.NoError(t, err)
+	for _, layer := range layers {
+		layerSize, err := layer.Size()
+		require.NoError(t, err)
+		size += layerSize
+	}
+	return size
+}
+
+func TestRegistryGC(t *testing.T) {
+	// Make sure no cluster is found so that the registry is reached directly
+	t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing"))
+
+	recorder := &requestRecorder{}
+	srv := httptest.NewServer(recorder.wrap(registry.New()))
+	t.Cleanup(srv.Close)
+	registryURL := strings.TrimPrefix(srv.URL, "http://")
+
+	push := func(img v1.Image, names ...string) string {
+		for _, name := range names {
+			require.NoError(t, crane.Push(img, registryURL+"/"+name))
+		}
+		digest, err := img.Digest()
+		require.NoError(t, err)
+		return digest.String()
+	}
+
+	// The deployed image is pushed with both its checksum and non-checksum tags
+	deployedImg, err := random.Image(1024, 2)
+	require.NoError(t, err)
+	deployedDigest := push(deployedImg, "stefanprodan/podinfo:6.4.0-jackal-2985051089", "stefanprodan/podinfo:6.4.0")
+
+	// An old version of the image shares the deployed image's layers
+	extraLayer, err := random.Layer(512, "application/vnd.docker.image.rootfs.diff.tar.gzip")
+	require.NoError(t, err)
+	oldImg, err := mutate.AppendLayers(deployedImg, extraLayer)
+	require.NoError(t, err)
+	oldDigest := push(oldImg, "stefanprodan/podinfo:6.3.0")
+
+	orphanImg, err := random.Image(2048, 1)
+	require.NoError(t, err)
+	orphanDigest := push(orphanImg, "library/orphan:1.0.0")
+
+	// A component deployed before images were recorded is resolved through its tags
+	legacyImg, err := random.Image(256, 1)
+	require.NoError(t, err)
+	legacyRef, err := transform.ParseImageRef("docker.io/library/legacy:1.0.0")
+	require.NoError(t, err)
+	legacyNames, err := (&ImageConfig{}).jackalRegistryNames(registryURL, legacyRef)
+	require.NoError(t, err)
+	for _, name := range legacyNames {
+		require.NoError(t, crane.Push(legacyImg, name))
+	}
+
+	packages := []types.DeployedPackage{
+		{
+			Name: "podinfo",
+			Data: types.JackalPackage{Components: []types.JackalComponent{
+				{Name: "podinfo", Images: []string{"ghcr.io/stefanprodan/podinfo:6.4.0"}},
+				{Name: "legacy", Images: []string{"docker.io/library/legacy:1.0.0"}},
+			}},
+			DeployedComponents: []types.DeployedComponent{
+				{Name: "podinfo", Images: []types.DeployedImage{{Source: "ghcr.io/stefanprodan/podinfo:6.4.0", Digest: deployedDigest}}},
+				{Name: "legacy"},
+			},
+		},
+	}
+
+	gc := RegistryGC{
+		RegInfo:  types.RegistryInfo{Address: registryURL},
+		Packages: packages,
+		DryRun:   true,
+	}
+
+	report, err := gc.Run()
+	require.NoError(t, err)
+	require.False(t, report.Deleted)
+	require.Equal(t, []GCManifest{
+		{Repository: "library/orphan", Digest: orphanDigest, Tags: []string{"1.0.0"}, Size: imageSize(t, orphanImg)},
+		{Repository: "stefanprodan/podinfo", Digest: oldDigest, Tags: []string{"6.3.0"}, Size: report.Manifests[1].Size},
+	}, report.Manifests)
+
+	// The old image's layers shared with the deployed image are not reclaimed
+	oldManifest, err := oldImg.RawManifest()
+	require.NoError(t, err)
+	oldConfig, err := oldImg.RawConfigFile()
+	require.NoError(t, err)
+	extraSize, err := extraLayer.Size()
+	require.NoError(t, err)
+	require.Equal(t, int64(len(oldManifest)+len(oldConfig))+extraSize, report.Manifests[1].Size)
+	require.Equal(t, report.Manifests[0].Size+report.Manifests[1].Size, report.ReclaimedBytes)
+
+	// Nothing was deleted by the dry run
+	_, err = crane.Digest(registryURL + "/library/orphan:1.0.0")
+	require.NoError(t, err)
+
+	// Scoping to a digest only collects that manifest without resolving the tags of the registry
+	recorder.reset()
+	gc.Digests = []string{oldDigest, deployedDigest}
+	report, err = gc.Run()
+	require.NoError(t, err)
+	require.Equal(t, []GCManifest{{Repository: "stefanprodan/podinfo", Digest: oldDigest}}, report.Manifests)
+	require.Zero(t, recorder.count("HEAD manifests"))
+
+	// Images kept for a rollback are not collected
+	history := RegistryGC{
+		RegInfo: gc.RegInfo,
+		History: []types.DeployedPackageGeneration{{Name: "podinfo", Data: packages[0].Data, DeployedComponents: packages[0].DeployedComponents}},
+		Digests: []string{deployedDigest},
+		DryRun:  true,
+	}
+	report, err = history.Run()
+	require.NoError(t, err)
+	require.Empty(t, report.Manifests)
+
+	// Declining the confirmation deletes nothing
+	gc.Digests = nil
+	gc.DryRun = false
+	gc.Confirm = func(*GCReport) bool { return false }
+	report, err = gc.Run()
+	require.NoError(t, err)
+	require.False(t, report.Deleted)
+	require.Len(t, report.Manifests, 2)
+
+	require.Zero(t, recorder.count("DELETE manifests"))
+
+	gc.Confirm = func(*GCReport) bool { return true }
+	report, err = gc.Run()
+	require.NoError(t, err)
+	require.True(t, report.Deleted)
+	require.Equal(t, 2, recorder.count("DELETE manifests"))
+
+	_, err = crane.Digest(registryURL + "/library/orphan@" + orphanDigest)
+	require.Error(t, err)
+	_, err = crane.Digest(registryURL + "/stefanprodan/podinfo@" + oldDigest)
+	require.Error(t, err)
+	for _, name := range []string{"stefanprodan/podinfo:6.4.0", "stefanprodan/podinfo:6.4.0-jackal-2985051089"} {
+		digest, err := crane.Digest(registryURL + "/" + name)
+		require.NoError(t, err)
+		require.Equal(t, deployedDigest, digest)
+	}
+	for _, name := range legacyNames {
+		_, err := crane.Digest(name)
+		require.NoError(t, err)
+	}
+}
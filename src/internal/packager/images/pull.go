@@ -12,6 +12,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/defenseunicorns/pkg/helpers"
 	"github.com/google/go-containerregistry/pkg/crane"
@@ -26,6 +27,8 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/stream"
 	"github.com/moby/moby/client"
 	"github.com/racer159/jackal/src/config"
+	"github.com/racer159/jackal/src/pkg/cluster"
+	"github.com/racer159/jackal/src/pkg/k8s"
 	"github.com/racer159/jackal/src/pkg/layout"
 	"github.com/racer159/jackal/src/pkg/message"
 	"github.com/racer159/jackal/src/pkg/transform"
@@ -225,15 +228,22 @@ func (i *ImageConfig) PullAll() ([]ImgInfo, error) {
 				return
 			}
 
-			readCloser, err := layer.Compressed()
-			if err != nil {
+			// Create the directory for the blob if it doesn't exist
+			dir := filepath.Join(string(cranePath), "blobs", digest.Algorithm)
+			if err := helpers.CreateDirectory(dir, os.ModePerm); err != nil {
 				layerWritingConcurrency.ErrorChan <- err
 				return
 			}
 
-			// Create the directory for the blob if it doesn't exist
-			dir := filepath.Join(string(cranePath), "blobs", digest.Algorithm)
-			if err := helpers.CreateDirectory(dir, os.ModePerm); err != nil {
+			// Share layers that are already in the image cache across builds by linking them instead of copying them
+			file := filepath.Join(dir, digest.Hex)
+			if linkCachedLayer(digest, size, file) {
+				layerWritingConcurrency.ProgressChan <- true
+				return
+			}
+
+			readCloser, err := layer.Compressed()
+			if err != nil {
 				layerWritingConcurrency.ErrorChan <- err
 				return
 			}
@@ -243,7 +253,6 @@ func (i *ImageConfig) PullAll() ([]ImgInfo, error) {
 			}
 
 			// Check if blob already exists and is the correct size
-			file := filepath.Join(dir, digest.Hex)
 			if s, err := os.Stat(file); err == nil && !s.IsDir() && (s.Size() == size || size == -1) {
 				layerWritingConcurrency.ProgressChan <- true
 				return
@@ -489,3 +498,203 @@ func (i *ImageConfig) PullImage(src string, spinner *message.Spinner) (img v1.Im
 	return img, hasImageLayers, nil
 
 }
+
+// linkCachedLayer hard links a layer from the image cache to the given path, returning whether it was linked.
+func linkCachedLayer(digest v1.Hash, size int64, dst string) bool {
+	if digest.Hex == "" || size == -1 {
+		return false
+	}
+
+	cachedLayer := filepath.Join(config.GetAbsCachePath(), layout.ImagesDir, digest.String())
+	s, err := os.Stat(cachedLayer)
+	if err != nil || s.Size() != size {
+		return false
+	}
+
+	// Mark the layer as recently used so that it is kept when the cache is pruned
+	now := time.Now()
+	_ = os.Chtimes(cachedLayer, now, now)
+
+	return os.Link(cachedLayer, dst) == nil
+}
+
+// PullFromJackalRegistry pulls the images in the ImageList back out of the configured Jackal registry
+// and saves them to the ImagesPath under their original (untransformed) references.
+func (i *ImageConfig) PullFromJackalRegistry() ([]ImgInfo, error) {
+	var (
+		err               error
+		tunnel            *k8s.Tunnel
+		registryURL       = i.RegInfo.Address
+		referenceToDigest = make(map[string]string)
+		imgInfoList       []ImgInfo
+	)
+
+	spinner := message.NewProgressSpinner("Pulling %d images from the jackal registry", len(i.ImageList))
+	defer spinner.Stop()
+
+	logs.Warn.SetOutput(&message.DebugWriter{})
+	logs.Progress.SetOutput(&message.DebugWriter{})
+
+	pullOptions := config.GetCraneOptions(i.Insecure, i.Architectures...)
+	pullOptions = append(pullOptions, config.GetCraneAuthOption(i.RegInfo.PullUsername, i.RegInfo.PullPassword))
+
+	registryURL, tunnel, err = i.connectToJackalRegistry()
+	if err != nil {
+		return nil, err
+	}
+
+	if tunnel != nil {
+		defer tunnel.Close()
+	}
+
+	// Create the ImagePath directory
+	if err := helpers.CreateDirectory(i.ImagesPath, helpers.ReadExecuteAllWriteUser); err != nil {
+		return nil, fmt.Errorf("failed to create image path %s: %w", i.ImagesPath, err)
+	}
+
+	cranePath, err := clayout.FromPath(i.ImagesPath)
+	if err != nil {
+		cranePath, err = clayout.Write(i.ImagesPath, empty.Index)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, refInfo := range i.ImageList {
+		spinner.Updatef("Pulling %s", refInfo.Reference)
+
+		names, err := i.jackalRegistryNames(registryURL, refInfo)
+		if err != nil {
+			return nil, err
+		}
+
+		var img v1.Image
+		pullImage := func() error {
+			var pullErr error
+			for _, name := range names {
+				message.Debugf("crane.Pull() %s -> %s:%s)", name, i.ImagesPath, refInfo.Reference)
+				if img, pullErr = crane.Pull(name, pullOptions...); pullErr == nil {
+					// Layers are fetched lazily so they must be written while the tunnel is open
+					return cranePath.AppendImage(img)
+				}
+			}
+			return pullErr
+		}
+
+		if tunnel != nil {
+			err = tunnel.Wrap(pullImage)
+		} else {
+			err = pullImage()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to pull %s from the jackal registry: %w", refInfo.Reference, err)
+		}
+
+		imgDigest, err := img.Digest()
+		if err != nil {
+			return nil, err
+		}
+		referenceToDigest[refInfo.Reference] = imgDigest.String()
+
+		hasImageLayers, err := utils.HasImageLayers(img)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check image layer mediatype: %w", err)
+		}
+
+		imgInfoList = append(imgInfoList, ImgInfo{RefInfo: refInfo, Img: img, HasImageLayers: hasImageLayers})
+	}
+
+	if err := utils.AddImageNameAnnotation(i.ImagesPath, referenceToDigest); err != nil {
+		return nil, fmt.Errorf("unable to format OCI layout: %w", err)
+	}
+
+	spinner.Successf("Pulled %d images from the jackal registry", len(i.ImageList))
+
+	return imgInfoList, nil
+}
+
+// DigestsFromJackalRegistry resolves the digest of each image in the ImageList as it is stored in the configured Jackal registry,
+// keyed by its original (untransformed) reference.
+func (i *ImageConfig) DigestsFromJackalRegistry() (map[string]string, error) {
+	referenceToDigest := make(map[string]string)
+
+	spinner := message.NewProgressSpinner("Resolving %d image digests from the jackal registry", len(i.ImageList))
+	defer spinner.Stop()
+
+	logs.Warn.SetOutput(&message.DebugWriter{})
+	logs.Progress.SetOutput(&message.DebugWriter{})
+
+	digestOptions := config.GetCraneOptions(i.Insecure, i.Architectures...)
+	digestOptions = append(digestOptions, config.GetCraneAuthOption(i.RegInfo.PullUsername, i.RegInfo.PullPassword))
+
+	registryURL, tunnel, err := i.connectToJackalRegistry()
+	if err != nil {
+		return nil, err
+	}
+
+	if tunnel != nil {
+		defer tunnel.Close()
+	}
+
+	for _, refInfo := range i.ImageList {
+		spinner.Updatef("Resolving %s", refInfo.Reference)
+
+		names, err := i.jackalRegistryNames(registryURL, refInfo)
+		if err != nil {
+			return nil, err
+		}
+
+		var digest string
+		resolveDigest := func() error {
+			var digestErr error
+			for _, name := range names {
+				if digest, digestErr = crane.Digest(name, digestOptions...); digestErr == nil {
+					return nil
+				}
+			}
+			return digestErr
+		}
+
+		if tunnel != nil {
+			err = tunnel.Wrap(resolveDigest)
+		} else {
+			err = resolveDigest()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve the digest of %s from the jackal registry: %w", refInfo.Reference, err)
+		}
+
+		referenceToDigest[refInfo.Reference] = digest
+	}
+
+	spinner.Successf("Resolved %d image digests from the jackal registry", len(i.ImageList))
+
+	return referenceToDigest, nil
+}
+
+// connectToJackalRegistry returns the address of the configured Jackal registry, opening a tunnel to it when a cluster is available.
+func (i *ImageConfig) connectToJackalRegistry() (string, *k8s.Tunnel, error) {
+	c, _ := cluster.NewCluster()
+	if c == nil {
+		return i.RegInfo.Address, nil, nil
+	}
+	return c.ConnectToJackalRegistryEndpoint(i.RegInfo)
+}
+
+// jackalRegistryNames returns the names an image may be stored under in the Jackal registry,
+// preferring the checksum tag the Jackal agent uses and falling back to the non-checksum tag.
+func (i *ImageConfig) jackalRegistryNames(registryURL string, refInfo transform.Image) ([]string, error) {
+	var names []string
+	if !i.NoChecksum {
+		offlineNameCRC, err := transform.ImageTransformHost(registryURL, refInfo.Reference)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, offlineNameCRC)
+	}
+	offlineName, err := transform.ImageTransformHostWithoutChecksum(registryURL, refInfo.Reference)
+	if err != nil {
+		return nil, err
+	}
+	return append(names, offlineName), nil
+}
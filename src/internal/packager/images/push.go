@@ -5,62 +5,79 @@
 package images
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/defenseunicorns/pkg/helpers"
 	"github.com/google/go-containerregistry/pkg/crane"
 	"github.com/google/go-containerregistry/pkg/logs"
+	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
 	"github.com/racer159/jackal/src/config"
 	"github.com/racer159/jackal/src/pkg/cluster"
 	"github.com/racer159/jackal/src/pkg/k8s"
 	"github.com/racer159/jackal/src/pkg/message"
 	"github.com/racer159/jackal/src/pkg/transform"
 	"github.com/racer159/jackal/src/pkg/utils"
+	"github.com/racer159/jackal/src/types"
 )
 
 // PushToJackalRegistry pushes a provided image into the configured Jackal registry
 // This function will optionally shorten the image name while appending a checksum of the original image name.
-func (i *ImageConfig) PushToJackalRegistry() error {
+//
+// Up to Concurrency images are pushed at the same time. Images whose manifest is already in the registry are skipped and the second
+// reference of an image is tagged onto the manifest that is already there instead of being pushed again, so a retried push resumes where it stopped.
+//
+// The digest and registry references of each image are returned so that they can be recorded against the deployed component.
+func (i *ImageConfig) PushToJackalRegistry() ([]types.DeployedImage, error) {
 	message.Debug("images.PushToJackalRegistry()")
 
 	logs.Warn.SetOutput(&message.DebugWriter{})
 	logs.Progress.SetOutput(&message.DebugWriter{})
 
 	refInfoToImage := map[transform.Image]v1.Image{}
+	refInfoToSize := map[transform.Image]int64{}
 	var totalSize int64
 	// Build an image list from the references
 	for _, refInfo := range i.ImageList {
 		img, err := utils.LoadOCIImage(i.ImagesPath, refInfo)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		refInfoToImage[refInfo] = img
 		imgSize, err := calcImgSize(img)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		refInfoToSize[refInfo] = imgSize
 		totalSize += imgSize
 	}
 
-	// If this is not a no checksum image push we will be pushing two images (the second will go faster as it checks the same layers)
-	if !i.NoChecksum {
-		totalSize = totalSize * 2
-	}
-
 	httpTransport := http.DefaultTransport.(*http.Transport).Clone()
 	httpTransport.TLSClientConfig.InsecureSkipVerify = i.Insecure
 	// TODO (@WSTARR) This is set to match the TLSHandshakeTimeout to potentially mitigate effects of https://github.com/racer159/jackal/issues/1444
 	httpTransport.ResponseHeaderTimeout = 10 * time.Second
 	progressBar := message.NewProgressBar(totalSize, fmt.Sprintf("Pushing %d images to the jackal registry", len(i.ImageList)))
 	defer progressBar.Stop()
-	craneTransport := helpers.NewTransport(httpTransport, progressBar)
+	// statusLock guards the progress bar (which the transport writes to from every concurrent push) and the status of the pushes
+	var statusLock sync.Mutex
+	craneTransport := helpers.NewTransport(httpTransport, &lockedProgressWriter{mu: &statusLock, bar: progressBar})
+
+	// Cancelling the context stops every push that is in flight, not just the one that ran into a failure
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
 	pushOptions := config.GetCraneOptions(i.Insecure, i.Architectures...)
 	pushOptions = append(pushOptions, config.GetCraneAuthOption(i.RegInfo.PushUsername, i.RegInfo.PushPassword))
 	pushOptions = append(pushOptions, crane.WithTransport(craneTransport))
+	pushOptions = append(pushOptions, crane.WithContext(ctx))
 
 	var (
 		err         error
@@ -74,25 +91,69 @@ func (i *ImageConfig) PushToJackalRegistry() error {
 	if c != nil {
 		registryURL, tunnel, err = c.ConnectToJackalRegistryEndpoint(i.RegInfo)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
+	var tunnelErr error
 	if tunnel != nil {
 		defer tunnel.Close()
+
+		// The pushes share one tunnel, whose error is only received once, so a failure of the tunnel is broadcast by cancelling them all
+		go func() {
+			select {
+			case err := <-tunnel.ErrChan():
+				if err == nil {
+					err = errors.New("the connection was closed")
+				}
+				statusLock.Lock()
+				tunnelErr = err
+				statusLock.Unlock()
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
 	}
 
-	pushImage := func(img v1.Image, name string) error {
-		if tunnel != nil {
-			return tunnel.Wrap(func() error { return crane.Push(img, name, pushOptions...) })
+	wrap := func(function func() error) error {
+		err := function()
+		if ctx.Err() != nil {
+			statusLock.Lock()
+			defer statusLock.Unlock()
+			if tunnelErr != nil {
+				return fmt.Errorf("lost the tunnel to the jackal registry: %w", tunnelErr)
+			}
 		}
 
-		return crane.Push(img, name, pushOptions...)
+		return err
 	}
 
-	for refInfo, img := range refInfoToImage {
+	var (
+		completed      int
+		skipped        int
+		deployedImages []types.DeployedImage
+	)
+
+	// updateStatus shows what is happening to an image along with how many images have finished
+	updateStatus := func(refInfo transform.Image, status string, done bool) {
+		statusLock.Lock()
+		defer statusLock.Unlock()
+
+		if done {
+			completed++
+		}
 		refTruncated := message.Truncate(refInfo.Reference, 55, true)
-		progressBar.UpdateTitle(fmt.Sprintf("Pushing %s", refTruncated))
+		progressBar.UpdateTitle(fmt.Sprintf("%s %s (%d of %d images complete)", status, refTruncated, completed, len(i.ImageList)))
+	}
+
+	pushImage := func(refInfo transform.Image) error {
+		img := refInfoToImage[refInfo]
+		digest, err := img.Digest()
+		if err != nil {
+			return fmt.Errorf("unable to get the digest of %s: %w", refInfo.Reference, err)
+		}
+
+		var offlineNames []string
 
 		// If this is not a no checksum image push it for use with the Jackal agent
 		if !i.NoChecksum {
@@ -100,13 +161,7 @@ func (i *ImageConfig) PushToJackalRegistry() error {
 			if err != nil {
 				return err
 			}
-
-			message.Debugf("crane.Push() %s:%s -> %s)", i.ImagesPath, refInfo.Reference, offlineNameCRC)
-
-			err = pushImage(img, offlineNameCRC)
-			if err != nil {
-				return err
-			}
+			offlineNames = append(offlineNames, offlineNameCRC)
 		}
 
 		// To allow for other non-jackal workloads to easily see the images upload a non-checksum version
@@ -115,18 +170,142 @@ func (i *ImageConfig) PushToJackalRegistry() error {
 		if err != nil {
 			return err
 		}
+		offlineNames = append(offlineNames, offlineName)
 
-		message.Debugf("crane.Push() %s:%s -> %s)", i.ImagesPath, refInfo.Reference, offlineName)
+		// Both names share a repository so once the manifest is there the remaining names only need a tag
+		inRepository := false
+		pushed := false
+		for _, offlineName := range offlineNames {
+			updateStatus(refInfo, "Checking", false)
+			if i.hasManifest(offlineName, digest, wrap, pushOptions) {
+				message.Debugf("%s already exists in the registry as %s, skipping", offlineName, digest)
+				inRepository = true
+				continue
+			}
 
-		err = pushImage(img, offlineName)
-		if err != nil {
-			return err
+			if inRepository {
+				updateStatus(refInfo, "Tagging", false)
+				message.Debugf("crane.Tag() %s -> %s", digest, offlineName)
+				if err := i.tagManifest(offlineName, digest, wrap, pushOptions); err != nil {
+					return err
+				}
+				continue
+			}
+
+			updateStatus(refInfo, "Pushing", false)
+			message.Debugf("crane.Push() %s:%s -> %s)", i.ImagesPath, refInfo.Reference, offlineName)
+			if err := wrap(func() error { return crane.Push(img, offlineName, pushOptions...) }); err != nil {
+				return err
+			}
+			inRepository = true
+			pushed = true
+		}
+
+		deployedImage := types.DeployedImage{Source: refInfo.Reference, Digest: digest.String()}
+		for _, offlineName := range offlineNames {
+			deployedImage.References = append(deployedImage.References, strings.TrimPrefix(offlineName, registryURL+"/"))
+		}
+		statusLock.Lock()
+		deployedImages = append(deployedImages, deployedImage)
+		statusLock.Unlock()
+
+		if !pushed {
+			// Nothing was uploaded for this image so account for it on the progress bar all at once
+			statusLock.Lock()
+			progressBar.Add(int(refInfoToSize[refInfo]))
+			skipped++
+			statusLock.Unlock()
+			updateStatus(refInfo, "Skipped", true)
+			return nil
 		}
+
+		updateStatus(refInfo, "Pushed", true)
+		return nil
+	}
+
+	concurrency := i.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var (
+		results  = make(chan error)
+		next     = 0
+		inFlight = 0
+		errs     []error
+	)
+
+	for {
+		// Stop starting new pushes as soon as one has failed
+		for len(errs) == 0 && inFlight < concurrency && next < len(i.ImageList) {
+			refInfo := i.ImageList[next]
+			next++
+			inFlight++
+			go func() {
+				results <- pushImage(refInfo)
+			}()
+		}
+
+		if inFlight == 0 {
+			break
+		}
+
+		if err := <-results; err != nil {
+			errs = append(errs, err)
+		}
+		inFlight--
 	}
 
-	progressBar.Successf("Pushed %d images to the jackal registry", len(i.ImageList))
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
 
-	return nil
+	if skipped > 0 {
+		progressBar.Successf("Pushed %d images to the jackal registry (%d were already present)", len(i.ImageList), skipped)
+	} else {
+		progressBar.Successf("Pushed %d images to the jackal registry", len(i.ImageList))
+	}
+
+	// Images finish in any order so sort them to keep the recorded images stable between deploys
+	sort.Slice(deployedImages, func(a, b int) bool {
+		return deployedImages[a].Source < deployedImages[b].Source
+	})
+
+	return deployedImages, nil
+}
+
+// hasManifest returns whether the registry already has the given manifest digest at a reference.
+func (i *ImageConfig) hasManifest(offlineName string, digest v1.Hash, wrap func(func() error) error, pushOptions []crane.Option) bool {
+	var desc *v1.Descriptor
+	err := wrap(func() (err error) {
+		desc, err = crane.Head(offlineName, pushOptions...)
+		return err
+	})
+	if err != nil {
+		// Not every registry answers HEAD requests for manifests, so anything other than a not found falls back to pushing the image
+		var transportErr *transport.Error
+		if !errors.As(err, &transportErr) || transportErr.StatusCode != http.StatusNotFound {
+			message.Debugf("Unable to check if %s exists in the registry: %s", offlineName, err.Error())
+		}
+		return false
+	}
+
+	return desc.Digest == digest
+}
+
+// tagManifest tags a manifest that is already in the repository of a reference with the tag of that reference.
+func (i *ImageConfig) tagManifest(offlineName string, digest v1.Hash, wrap func(func() error) error, pushOptions []crane.Option) error {
+	ref, err := name.ParseReference(offlineName)
+	if err != nil {
+		return err
+	}
+	tag, ok := ref.(name.Tag)
+	if !ok {
+		return fmt.Errorf("unable to tag %s as it is not referenced by a tag", offlineName)
+	}
+
+	src := ref.Context().Digest(digest.String()).String()
+	return wrap(func() error { return crane.Tag(src, tag.TagStr(), pushOptions...) })
 }
 
 func calcImgSize(img v1.Image) (int64, error) {
@@ -150,3 +329,21 @@ func calcImgSize(img v1.Image) (int64, error) {
 
 	return size, nil
 }
+
+// lockedProgressWriter serializes the writes to a progress bar with a lock shared with the other updates to it.
+type lockedProgressWriter struct {
+	mu  *sync.Mutex
+	bar helpers.ProgressWriter
+}
+
+func (w *lockedProgressWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.bar.Write(p)
+}
+
+func (w *lockedProgressWriter) UpdateTitle(title string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.bar.UpdateTitle(title)
+}
@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package images provides functions for building and pushing images.
+package images
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/racer159/jackal/src/pkg/transform"
+	"github.com/racer159/jackal/src/types"
+	"github.com/stretchr/testify/require"
+)
+
+// requestRecorder records the requests made to a registry by method and kind of path.
+type requestRecorder struct {
+	mu       sync.Mutex
+	requests []string
+}
+
+func (r *requestRecorder) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		kind := "other"
+		switch {
+		case strings.Contains(req.URL.Path, "/blobs/"):
+			kind = "blobs"
+		case strings.Contains(req.URL.Path, "/manifests/"):
+			kind = "manifests"
+		}
+		r.mu.Lock()
+		r.requests = append(r.requests, req.Method+" "+kind)
+		r.mu.Unlock()
+		next.ServeHTTP(w, req)
+	})
+}
+
+func (r *requestRecorder) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = nil
+}
+
+func (r *requestRecorder) count(request string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for _, recorded := range r.requests {
+		if recorded == request {
+			count++
+		}
+	}
+	return count
+}
+
+func TestPushToJackalRegistry(t *testing.T) {
+	// Make sure no cluster is found so that images are pushed straight to the registry
+	t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing"))
+
+	recorder := &requestRecorder{}
+	srv := httptest.NewServer(recorder.wrap(registry.New()))
+	t.Cleanup(srv.Close)
+	registryURL := strings.TrimPrefix(srv.URL, "http://")
+
+	imagesPath := t.TempDir()
+	layoutPath, err := layout.Write(imagesPath, empty.Index)
+	require.NoError(t, err)
+
+	references := []string{"ghcr.io/racer159/jackal/agent:v1.0.0", "docker.io/library/registry:2.8.0", "quay.io/jackal/podinfo:6.4.0"}
+	imageList := []transform.Image{}
+	images := map[string]v1.Image{}
+	for _, reference := range references {
+		img, err := random.Image(1024, 2)
+		require.NoError(t, err)
+		require.NoError(t, layoutPath.AppendImage(img, layout.WithAnnotations(map[string]string{ocispec.AnnotationBaseImageName: reference})))
+		refInfo, err := transform.ParseImageRef(reference)
+		require.NoError(t, err)
+		imageList = append(imageList, refInfo)
+		images[reference] = img
+	}
+
+	// Push the checksum reference of one image as if an earlier push was interrupted
+	offlineNameCRC, err := transform.ImageTransformHost(registryURL, references[0])
+	require.NoError(t, err)
+	require.NoError(t, crane.Push(images[references[0]], offlineNameCRC))
+	recorder.reset()
+
+	imgConfig := ImageConfig{
+		ImagesPath:  imagesPath,
+		ImageList:   imageList,
+		RegInfo:     types.RegistryInfo{Address: registryURL},
+		Insecure:    true,
+		Concurrency: 2,
+	}
+	deployedImages, err := imgConfig.PushToJackalRegistry()
+	require.NoError(t, err)
+	require.Len(t, deployedImages, len(references))
+	deployedBySource := map[string]types.DeployedImage{}
+	for _, deployedImage := range deployedImages {
+		deployedBySource[deployedImage.Source] = deployedImage
+	}
+
+	for _, reference := range references {
+		digest, err := images[reference].Digest()
+		require.NoError(t, err)
+
+		offlineNameCRC, err := transform.ImageTransformHost(registryURL, reference)
+		require.NoError(t, err)
+		offlineName, err := transform.ImageTransformHostWithoutChecksum(registryURL, reference)
+		require.NoError(t, err)
+		for _, name := range []string{offlineNameCRC, offlineName} {
+			pushed, err := crane.Digest(name)
+			require.NoError(t, err)
+			require.Equal(t, digest.String(), pushed)
+		}
+
+		// Both references are recorded relative to the registry
+		require.Equal(t, types.DeployedImage{
+			Source:     reference,
+			Digest:     digest.String(),
+			References: []string{strings.TrimPrefix(offlineNameCRC, registryURL+"/"), strings.TrimPrefix(offlineName, registryURL+"/")},
+		}, deployedBySource[reference])
+	}
+
+	// Two images are pushed with their blobs and three manifests are tagged without touching blobs
+	require.Equal(t, 6, recorder.count("POST blobs"))
+	require.Equal(t, 5, recorder.count("PUT manifests"))
+
+	// Pushing again only checks that the manifests are there
+	recorder.reset()
+	_, err = imgConfig.PushToJackalRegistry()
+	require.NoError(t, err)
+	require.Zero(t, recorder.count("POST blobs"))
+	require.Zero(t, recorder.count("PUT manifests"))
+	require.Equal(t, 6, recorder.count("HEAD manifests"))
+
+	// Without checksums each image only has one reference to check
+	recorder.reset()
+	imgConfig.NoChecksum = true
+	deployedImages, err = imgConfig.PushToJackalRegistry()
+	require.NoError(t, err)
+	require.Len(t, deployedImages[0].References, 1)
+	require.Zero(t, recorder.count("PUT manifests"))
+	require.Equal(t, 3, recorder.count("HEAD manifests"))
+}
@@ -0,0 +1,289 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package rotate contains functions for rotating the credentials of the services Jackal manages.
+package rotate
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/racer159/jackal/src/config"
+	"github.com/racer159/jackal/src/config/lang"
+	"github.com/racer159/jackal/src/internal/packager/git"
+	"github.com/racer159/jackal/src/internal/packager/helm"
+	"github.com/racer159/jackal/src/pkg/cluster"
+	"github.com/racer159/jackal/src/pkg/message"
+	"github.com/racer159/jackal/src/types"
+)
+
+// Services are the services whose credentials can be rotated.
+var Services = []string{message.RegistryKey, message.GitKey, message.ArtifactKey}
+
+// Credentials rotates the generated credentials of the internal registry, git server and artifact server.
+//
+// Only services with credentials older than olderThan are rotated. The new credentials are saved to the Jackal state as pending before the services
+// are switched to them (the services only accept one password per user, so the old and new credentials cannot overlap) and are then verified
+// against the services; if that fails the services are switched back and only a newly created artifact server token is kept (as creating it
+// deletes the previous one). Once verified, the Jackal state and the Jackal-managed secrets are updated and the workloads that use those secrets
+// are restarted. If a rotation is interrupted, the next one keeps whichever of the pending or previous credentials each service accepts.
+func Credentials(c *cluster.Cluster, services []string, olderThan time.Duration) error {
+	oldState, err := c.LoadJackalState()
+	if err != nil || oldState.Distro == "" {
+		// If no distro the jackal secret did not load properly
+		return errors.New(lang.ErrLoadState)
+	}
+
+	if oldState.CredentialRotation.Pending != nil {
+		if err := recoverPending(c, oldState); err != nil {
+			return fmt.Errorf("unable to recover the interrupted credential rotation: %w", err)
+		}
+	}
+
+	now := time.Now()
+	due := []string{}
+	for _, service := range services {
+		if !isInternal(oldState, service) {
+			message.Notef(lang.CmdToolsRotateCredsExternal, service)
+			continue
+		}
+		if !oldState.CredentialRotation.IsDue(service, olderThan, now) {
+			message.Notef(lang.CmdToolsRotateCredsNotDue, service, oldState.CredentialRotation.LastRotated[service].Format(time.RFC3339))
+			continue
+		}
+		due = append(due, service)
+	}
+	if len(due) == 0 {
+		message.Note(lang.CmdToolsRotateCredsNothingDue)
+		return nil
+	}
+
+	newState, err := c.MergeJackalState(oldState, types.JackalInitOptions{}, due)
+	if err != nil {
+		return err
+	}
+
+	// Keep the new credentials before any service is switched to them so that an interrupted rotation can be recovered
+	oldState.CredentialRotation.Pending = &types.PendingCredentials{
+		Services:       due,
+		RegistryInfo:   newState.RegistryInfo,
+		GitServer:      newState.GitServer,
+		ArtifactServer: newState.ArtifactServer,
+	}
+	if err := c.SaveJackalState(oldState); err != nil {
+		return fmt.Errorf("%s: %w", lang.ErrSaveState, err)
+	}
+
+	applied, err := apply(c, oldState, newState, due)
+	if err == nil {
+		err = verify(c, newState, due)
+	}
+	if err != nil {
+		if rollbackErr := rollback(c, oldState, newState, applied); rollbackErr != nil {
+			// The pending credentials stay in the state so that the next rotation can work out which ones the services accept
+			return fmt.Errorf("%w, and the services could not be switched back to the previous credentials (the next rotation will recover them): %w", err, rollbackErr)
+		}
+		oldState.CredentialRotation.Pending = nil
+		// The previous artifact token is deleted when a new one is created so keep the new one rather than a token that no longer exists
+		if slices.Contains(applied, message.ArtifactKey) {
+			oldState.ArtifactServer.PushToken = newState.ArtifactServer.PushToken
+		}
+		if saveErr := c.SaveJackalState(oldState); saveErr != nil {
+			return fmt.Errorf("%w, and the previous credentials could not be saved: %w", err, saveErr)
+		}
+		return err
+	}
+
+	newState.CredentialRotation.Pending = nil
+	newState.CredentialRotation.Record(now, due...)
+	if err := c.SaveJackalState(newState); err != nil {
+		return fmt.Errorf("%s: %w", lang.ErrSaveState, err)
+	}
+
+	if err := updateSecrets(c, newState, due); err != nil {
+		return err
+	}
+
+	message.Successf(lang.CmdToolsRotateCredsSuccess, strings.Join(due, ", "))
+	return nil
+}
+
+// updateSecrets updates the Jackal-managed secrets of the rotated services and restarts the workloads that use them.
+func updateSecrets(c *cluster.Cluster, state *types.JackalState, services []string) error {
+	secretNames := []string{}
+	if slices.Contains(services, message.RegistryKey) {
+		c.UpdateJackalManagedImageSecrets(state)
+		secretNames = append(secretNames, config.JackalImagePullSecretName)
+	}
+	if slices.Contains(services, message.GitKey) {
+		c.UpdateJackalManagedGitSecrets(state)
+		secretNames = append(secretNames, config.JackalGitServerSecretName)
+	}
+	if len(secretNames) > 0 {
+		restarted, err := c.RestartWorkloadsUsingSecrets(secretNames...)
+		if err != nil {
+			return fmt.Errorf("unable to restart the workloads using the rotated credentials: %w", err)
+		}
+		message.Infof(lang.CmdToolsRotateCredsRestarted, restarted)
+	}
+	return nil
+}
+
+// recoverPending settles a rotation that was interrupted after its credentials were saved as pending, keeping whichever of the pending or
+// previous credentials each service accepts.
+func recoverPending(c *cluster.Cluster, state *types.JackalState) error {
+	pending := state.CredentialRotation.Pending
+	message.Notef(lang.CmdToolsRotateCredsRecovering, strings.Join(pending.Services, ", "))
+
+	// The services are settled in the order they are switched in as the artifact token depends on the git server credentials
+	switched := []string{}
+	for _, service := range Services {
+		if !slices.Contains(pending.Services, service) {
+			continue
+		}
+
+		candidate := *state
+		switch service {
+		case message.RegistryKey:
+			candidate.RegistryInfo = pending.RegistryInfo
+		case message.GitKey:
+			candidate.GitServer = pending.GitServer
+		case message.ArtifactKey:
+			candidate.ArtifactServer = pending.ArtifactServer
+		}
+
+		pendingErr := verify(c, &candidate, []string{service})
+		if pendingErr == nil {
+			*state = candidate
+			switched = append(switched, service)
+			continue
+		}
+		previousErr := verify(c, state, []string{service})
+		if previousErr == nil {
+			continue
+		}
+
+		// Creating the pending artifact token deletes the previous one, so if neither is known a new one is created
+		if service == message.ArtifactKey {
+			tokenResponse, err := git.New(state.GitServer).CreatePackageRegistryToken()
+			if err != nil {
+				return fmt.Errorf("unable to create a new artifact server token: %w", err)
+			}
+			state.ArtifactServer.PushToken = tokenResponse.Sha1
+			switched = append(switched, service)
+			continue
+		}
+
+		return fmt.Errorf("neither the pending nor the previous %s credentials are accepted: %w", service, errors.Join(pendingErr, previousErr))
+	}
+
+	state.CredentialRotation.Pending = nil
+	state.CredentialRotation.Record(time.Now(), switched...)
+	if err := c.SaveJackalState(state); err != nil {
+		return fmt.Errorf("%s: %w", lang.ErrSaveState, err)
+	}
+
+	return updateSecrets(c, state, switched)
+}
+
+// isInternal returns whether the credentials of a service are generated and managed by Jackal.
+func isInternal(state *types.JackalState, service string) bool {
+	switch service {
+	case message.RegistryKey:
+		return state.RegistryInfo.InternalRegistry
+	case message.GitKey:
+		return state.GitServer.InternalServer
+	case message.ArtifactKey:
+		return state.ArtifactServer.InternalServer
+	}
+	return false
+}
+
+// apply switches the services to the credentials in newState, returning the services that were switched.
+func apply(c *cluster.Cluster, oldState, newState *types.JackalState, services []string) (applied []string, err error) {
+	if slices.Contains(services, message.RegistryKey) {
+		h := helm.NewClusterOnly(&types.PackagerConfig{State: newState}, c)
+		if err := h.UpdateJackalRegistryValues(); err != nil {
+			return applied, fmt.Errorf("unable to update the registry credentials: %w", err)
+		}
+		applied = append(applied, message.RegistryKey)
+	}
+
+	if slices.Contains(services, message.GitKey) {
+		g := git.New(newState.GitServer)
+		if err := g.UpdateJackalGiteaUsers(oldState); err != nil {
+			return applied, fmt.Errorf("unable to update the git server credentials: %w", err)
+		}
+		applied = append(applied, message.GitKey)
+	}
+
+	if slices.Contains(services, message.ArtifactKey) {
+		// The git server credentials have already been switched (if they were due) so the token is created with the new ones
+		g := git.New(newState.GitServer)
+		tokenResponse, err := g.CreatePackageRegistryToken()
+		if err != nil {
+			return applied, fmt.Errorf("unable to create a new artifact server token: %w", err)
+		}
+		newState.ArtifactServer.PushToken = tokenResponse.Sha1
+		applied = append(applied, message.ArtifactKey)
+	}
+
+	return applied, nil
+}
+
+// verify checks that the services accept the credentials in newState.
+func verify(c *cluster.Cluster, newState *types.JackalState, services []string) error {
+	spinner := message.NewProgressSpinner(lang.CmdToolsRotateCredsVerifying)
+	defer spinner.Stop()
+
+	if slices.Contains(services, message.RegistryKey) {
+		if err := c.VerifyRegistryCredentials(newState.RegistryInfo); err != nil {
+			return fmt.Errorf("unable to verify the new registry credentials: %w", err)
+		}
+	}
+
+	g := git.New(newState.GitServer)
+	if slices.Contains(services, message.GitKey) {
+		for _, credential := range [][2]string{
+			{newState.GitServer.PushUsername, newState.GitServer.PushPassword},
+			{newState.GitServer.PullUsername, newState.GitServer.PullPassword},
+		} {
+			if err := g.VerifyGiteaLogin(credential[0], credential[1]); err != nil {
+				return fmt.Errorf("unable to verify the new git server credentials: %w", err)
+			}
+		}
+	}
+
+	if slices.Contains(services, message.ArtifactKey) {
+		if err := g.VerifyGiteaLogin(newState.ArtifactServer.PushUsername, newState.ArtifactServer.PushToken); err != nil {
+			return fmt.Errorf("unable to verify the new artifact server token: %w", err)
+		}
+	}
+
+	spinner.Success()
+	return nil
+}
+
+// rollback switches the registry and git server back to the credentials in oldState if they were switched to the ones in newState.
+func rollback(c *cluster.Cluster, oldState, newState *types.JackalState, applied []string) error {
+	var errs []error
+
+	if slices.Contains(applied, message.RegistryKey) {
+		h := helm.NewClusterOnly(&types.PackagerConfig{State: oldState}, c)
+		if err := h.UpdateJackalRegistryValues(); err != nil {
+			errs = append(errs, fmt.Errorf("registry: %w", err))
+		}
+	}
+
+	if slices.Contains(applied, message.GitKey) {
+		g := git.New(oldState.GitServer)
+		if err := g.UpdateJackalGiteaUsers(newState); err != nil {
+			errs = append(errs, fmt.Errorf("git: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -34,11 +34,13 @@ import (
 
 // Builder is the main struct used to build SBOM artifacts.
 type Builder struct {
-	spinner    *message.Spinner
-	cachePath  string
-	imagesPath string
-	outputDir  string
-	jsonList   []byte
+	spinner      *message.Spinner
+	basePath     string
+	cachePath    string
+	imagesPath   string
+	outputDir    string
+	jsonList     []byte
+	reproducible bool
 }
 
 //go:embed viewer/*
@@ -52,10 +54,12 @@ func Catalog(componentSBOMs map[string]*layout.ComponentSBOM, imageList []transf
 	imageCount := len(imageList)
 	componentCount := len(componentSBOMs)
 	builder := Builder{
-		spinner:    message.NewProgressSpinner("Creating SBOMs for %d images and %d components with files.", imageCount, componentCount),
-		cachePath:  config.GetAbsCachePath(),
-		imagesPath: paths.Images.Base,
-		outputDir:  paths.SBOMs.Path,
+		spinner:      message.NewProgressSpinner("Creating SBOMs for %d images and %d components with files.", imageCount, componentCount),
+		basePath:     paths.Base,
+		cachePath:    config.GetAbsCachePath(),
+		imagesPath:   paths.Images.Base,
+		outputDir:    paths.SBOMs.Path,
+		reproducible: paths.SBOMs.IsReproducible(),
 	}
 	defer builder.spinner.Stop()
 
@@ -205,17 +209,43 @@ func (b *Builder) createImageSBOM(img v1.Image, src string) ([]byte, error) {
 }
 
 // createPathSBOM uses syft to generate SBOM for a filepath.
-func (b *Builder) createFileSBOM(componentSBOM layout.ComponentSBOM, component string) ([]byte, error) {
+func (b *Builder) createFileSBOM(componentSBOM layout.ComponentSBOM, component string) (_ []byte, err error) {
+	relPath := func(path string) string {
+		return path
+	}
+
+	// Catalog the files of a reproducible package relative to the package so that the SBOM does not depend on the temporary directory it was created in
+	if b.reproducible {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		if err := os.Chdir(b.basePath); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if chdirErr := os.Chdir(cwd); err == nil && chdirErr != nil {
+				err = fmt.Errorf("unable to return to the directory %s: %w", cwd, chdirErr)
+			}
+		}()
+		relPath = func(path string) string {
+			if rel, err := filepath.Rel(b.basePath, path); err == nil {
+				return rel
+			}
+			return path
+		}
+	}
+
 	catalog := pkg.NewCollection()
 	relationships := []artifact.Relationship{}
-	parentSource, err := source.NewFromDirectoryPath(componentSBOM.Component.Base)
+	parentSource, err := source.NewFromDirectoryPath(relPath(componentSBOM.Component.Base))
 	if err != nil {
 		return nil, err
 	}
 
 	for _, sbomFile := range componentSBOM.Files {
 		// Create the sbom source
-		fileSource, err := source.NewFromFile(source.FileConfig{Path: sbomFile})
+		fileSource, err := source.NewFromFile(source.FileConfig{Path: relPath(sbomFile)})
 		if err != nil {
 			return nil, err
 		}
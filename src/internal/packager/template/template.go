@@ -118,7 +118,7 @@ func (values *Values) GetVariables(component types.JackalComponent) (templateMap
 
 		// Don't template component-specific variables for every component
 		switch component.Name {
-		case "jackal-agent":
+		case "jackal-agent", "jackal-agent-policy":
 			agentTLS := values.config.State.AgentTLS
 			builtinMap["AGENT_CRT"] = base64.StdEncoding.EncodeToString(agentTLS.Cert)
 			builtinMap["AGENT_KEY"] = base64.StdEncoding.EncodeToString(agentTLS.Key)
@@ -149,7 +149,7 @@ func (values *Values) GetVariables(component types.JackalComponent) (templateMap
 		}
 	}
 
-	for key, variable := range values.config.SetVariableMap {
+	for key, variable := range values.config.GetVariables() {
 		// Variable keys are always uppercase in the format ###JACKAL_VAR_KEY###
 		templateMap[strings.ToUpper(fmt.Sprintf("###JACKAL_VAR_%s###", key))] = &TextTemplate{
 			Value:      variable.Value,
@@ -13,6 +13,7 @@ import (
 	"github.com/defenseunicorns/pkg/helpers"
 	"github.com/racer159/jackal/src/config"
 	"github.com/racer159/jackal/src/config/lang"
+	"github.com/racer159/jackal/src/pkg/utils"
 	"github.com/racer159/jackal/src/types"
 )
 
@@ -92,6 +93,28 @@ func Run(pkg types.JackalPackage) error {
 		}
 	}
 
+	return validateComponentDependencies(pkg.Components, uniqueComponentNames)
+}
+
+// validateComponentDependencies ensures that dependsOn only references components in the package and does not form a cycle.
+func validateComponentDependencies(components []types.JackalComponent, componentNames map[string]bool) error {
+	dependencies := []utils.Dependency{}
+	for _, component := range components {
+		for _, dependency := range component.DependsOn {
+			if dependency == component.Name {
+				return fmt.Errorf(lang.PkgValidateErrComponentDependsOnSelf, component.Name)
+			}
+			if !componentNames[dependency] {
+				return fmt.Errorf(lang.PkgValidateErrComponentDependsOn, component.Name, dependency)
+			}
+		}
+		dependencies = append(dependencies, utils.ComponentDependency{Component: component.Name, DependsOn: component.DependsOn})
+	}
+
+	if _, err := utils.SortDependencies(dependencies); err != nil {
+		return fmt.Errorf(lang.PkgValidateErrComponentDependsOnCycle, err)
+	}
+
 	return nil
 }
 
@@ -181,6 +204,12 @@ func validateComponent(pkg types.JackalPackage, component types.JackalComponent)
 		}
 	}
 
+	for _, data := range component.DataInjections {
+		if err := DataInjection(component.Images, data); err != nil {
+			return fmt.Errorf(lang.PkgValidateErrDataInjection, err)
+		}
+	}
+
 	if pkg.Metadata.YOLO {
 		if err := validateYOLO(component); err != nil {
 			return fmt.Errorf(lang.PkgValidateErrComponentYOLO, component.Name, err)
@@ -348,6 +377,32 @@ func validateChart(chart types.JackalChart) error {
 	return nil
 }
 
+// DataInjection validates that a data injection targets either a pod and container or a persistent volume claim, and that the loader
+// image of a persistent volume claim target is one of the given images of its component.
+func DataInjection(images []string, data types.JackalDataInjection) error {
+	target := data.Target
+
+	if target.PersistentVolumeClaim == "" {
+		// Must have a pod and container to inject into
+		if target.Selector == "" || target.Container == "" || target.LoaderImage != "" {
+			return fmt.Errorf(lang.PkgValidateErrDataInjectionTarget, target.Path)
+		}
+		return nil
+	}
+
+	// The data is injected through the loader pod rather than a running container
+	if target.Selector != "" || target.Container != "" {
+		return fmt.Errorf(lang.PkgValidateErrDataInjectionTarget, target.Path)
+	}
+
+	// The loader pod must run an image that is pushed to the registry with this component
+	if !slices.Contains(images, target.LoaderImage) {
+		return fmt.Errorf(lang.PkgValidateErrDataInjectionImage, target.PersistentVolumeClaim, target.LoaderImage)
+	}
+
+	return nil
+}
+
 func validateManifest(manifest types.JackalManifest) error {
 	// Don't allow empty names
 	if manifest.Name == "" {
@@ -5,12 +5,24 @@
 package cluster
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/defenseunicorns/pkg/helpers"
 	"github.com/racer159/jackal/src/config"
@@ -18,133 +30,554 @@ import (
 	"github.com/racer159/jackal/src/pkg/layout"
 	"github.com/racer159/jackal/src/pkg/message"
 	"github.com/racer159/jackal/src/pkg/utils"
-	"github.com/racer159/jackal/src/pkg/utils/exec"
 	"github.com/racer159/jackal/src/types"
 	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
 )
 
-// HandleDataInjection waits for the target pod(s) to come up and inject the data into them
-// todo:  this currently requires kubectl but we should have enough k8s work to make this native now.
-func (c *Cluster) HandleDataInjection(wg *sync.WaitGroup, data types.JackalDataInjection, componentPath *layout.ComponentPaths, dataIdx int) {
-	defer wg.Done()
+const (
+	// DataInjectionsAnnotation is the annotation on a persistent volume claim that records the digest of the data injected into each path.
+	DataInjectionsAnnotation = "jackal.dev/data-injections"
+	// DataInjectionMaxRetries is the number of times a failed data injection is retried when the package does not set maxRetries.
+	DataInjectionMaxRetries = 3
+	// DataInjectionTimeout is how long a data injection may take when the package does not set maxTotalSeconds.
+	DataInjectionTimeout = 15 * time.Minute
 
-	injectionCompletionMarker := filepath.Join(componentPath.DataInjections, config.GetDataInjectionMarker())
-	if err := os.WriteFile(injectionCompletionMarker, []byte("🦄"), helpers.ReadWriteUser); err != nil {
-		message.WarnErrf(err, "Unable to create the data injection completion marker")
-		return
+	dataLoaderContainer = "data-loader"
+	// injectedManifestName is the file left in the target path that records the data injection manifest of the data injected there.
+	injectedManifestName = "." + layout.DataInjectionManifestFile
+)
+
+// HandleDataInjection waits for the target pod(s) to come up and injects the data into them.
+//
+// The data is streamed into the target container over an exec session and verified against its checksums before the
+// completion marker that pods wait on is written.
+func (c *Cluster) HandleDataInjection(ctx context.Context, data types.JackalDataInjection, componentPath *layout.ComponentPaths, dataIdx int) error {
+	source := filepath.Join(componentPath.DataInjections, filepath.Base(data.Target.Path))
+	if helpers.InvalidPath(source) {
+		// The path is likely invalid because of how we compose OCI components, add an index suffix to the filename
+		source = filepath.Join(componentPath.DataInjections, strconv.Itoa(dataIdx), filepath.Base(data.Target.Path))
+		if helpers.InvalidPath(source) {
+			return fmt.Errorf("unable to find the data injection source path %s", source)
+		}
+	}
+
+	// Packages created before data injection manifests were recorded are hashed at deploy time instead
+	manifest, err := layout.ReadDataInjectionManifest(filepath.Join(filepath.Dir(source), layout.DataInjectionManifestFile))
+	if err != nil {
+		message.Debugf("Unable to read the data injection manifest for %s, hashing the data: %s", source, err.Error())
+		if manifest, err = layout.NewDataInjectionManifest(source); err != nil {
+			return fmt.Errorf("unable to hash the data injection source %s: %w", source, err)
+		}
+	}
+
+	timeout := DataInjectionTimeout
+	if data.MaxTotalSeconds != nil {
+		timeout = time.Duration(*data.MaxTotalSeconds) * time.Second
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
 	}
 
-	tarCompressFlag := ""
-	if data.Compress {
-		tarCompressFlag = "-z"
+	maxRetries := DataInjectionMaxRetries
+	if data.MaxRetries != nil {
+		maxRetries = *data.MaxRetries
 	}
 
+	inject := c.injectData
+	target := fmt.Sprintf("the pods matching %q", data.Target.Selector)
+	if data.Target.PersistentVolumeClaim != "" {
+		inject = c.injectDataIntoPVC
+		target = fmt.Sprintf("the persistent volume claim %s", data.Target.PersistentVolumeClaim)
+	}
+
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			message.Warnf("Retrying the data injection into %s (attempt %d of %d): %s", data.Target.Path, attempt, maxRetries, err.Error())
+		}
+
+		if err = inject(ctx, data, source, manifest); err == nil || ctx.Err() != nil {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("unable to inject data into %s in %s in the %s namespace: %w", data.Target.Path, target, data.Target.Namespace, err)
+	}
+
+	// Cleanup now to reduce disk pressure
+	_ = os.RemoveAll(source)
+
+	return nil
+}
+
+// injectData makes a single attempt at injecting the data into every target pod.
+func (c *Cluster) injectData(ctx context.Context, data types.JackalDataInjection, source string, manifest layout.DataInjectionManifest) error {
 	// Pod filter to ensure we only use the current deployment's pods
 	podFilterByInitContainer := func(pod corev1.Pod) bool {
 		// Look everywhere in the pod for a matching data injection marker
 		return strings.Contains(message.JSONValue(pod), config.GetDataInjectionMarker())
 	}
 
-	// Get the OS shell to execute commands in
-	shell, shellArgs := exec.GetOSShell(exec.Shell{Windows: "cmd"})
+	target := k8s.PodLookup{
+		Namespace: data.Target.Namespace,
+		Selector:  data.Target.Selector,
+		Container: data.Target.Container,
+	}
 
-	if _, _, err := exec.Cmd(shell, append(shellArgs, "tar --version")...); err != nil {
-		message.WarnErr(err, "Unable to execute tar on this system.  Please ensure it is installed and on your $PATH.")
-		return
+	// Wait until the pod we are injecting data into becomes available, some data injections wait on very long deployments
+	var pods []corev1.Pod
+	for len(pods) < 1 {
+		message.Debugf("Waiting for the data injection target %#v", target)
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("timed out waiting for the target container %q: %w", data.Target.Container, err)
+		}
+		pods = c.WaitForPodsAndContainers(target, podFilterByInitContainer)
 	}
 
-iterator:
-	// The eternal loop because some data injections can take a very long time
-	for {
-		message.Debugf("Attempting to inject data into %s", data.Target)
-		source := filepath.Join(componentPath.DataInjections, filepath.Base(data.Target.Path))
-		if helpers.InvalidPath(source) {
-			// The path is likely invalid because of how we compose OCI components, add an index suffix to the filename
-			source = filepath.Join(componentPath.DataInjections, strconv.Itoa(dataIdx), filepath.Base(data.Target.Path))
-			if helpers.InvalidPath(source) {
-				message.Warnf("Unable to find the data injection source path %s", source)
-				return
-			}
+	for _, pod := range pods {
+		if err := c.injectDataIntoPod(ctx, data, source, manifest, pod.Name); err != nil {
+			return fmt.Errorf("pod %s: %w", pod.Name, err)
 		}
 
-		target := k8s.PodLookup{
-			Namespace: data.Target.Namespace,
-			Selector:  data.Target.Selector,
-			Container: data.Target.Container,
+		// Leave a marker in the target container for pods to track the sync action
+		var marker bytes.Buffer
+		if err := writeFileArchive(&marker, config.GetDataInjectionMarker(), []byte("🦄")); err != nil {
+			return err
+		}
+		if err := c.execInContainer(ctx, data.Target.Namespace, pod.Name, data.Target.Container, []string{"tar", "-x", "-f", "-", "-C", data.Target.Path}, &marker); err != nil {
+			return fmt.Errorf("pod %s: unable to save the data injection completion marker: %w", pod.Name, err)
 		}
+	}
 
-		// Wait until the pod we are injecting data into becomes available
-		pods := c.WaitForPodsAndContainers(target, podFilterByInitContainer)
-		if len(pods) < 1 {
+	// Do not look for a specific container after injection in case they are running an init container
+	podOnlyTarget := k8s.PodLookup{
+		Namespace: data.Target.Namespace,
+		Selector:  data.Target.Selector,
+	}
+
+	// Block one final time to make sure at least one pod has come up and injected the data
+	// Using only the pod as the final selector because we don't know what the container name will be
+	// Still using the init container filter to make sure we have the right running pod
+	_ = c.WaitForPodsAndContainers(podOnlyTarget, podFilterByInitContainer)
+
+	return nil
+}
+
+// injectDataIntoPod streams the files that have changed since the last injection into the target container of a pod and verifies them
+// against the checksums they were archived with.
+//
+// The files are verified in the container with sha256sum when it is available, otherwise only the checksums of the streamed data are checked.
+func (c *Cluster) injectDataIntoPod(ctx context.Context, data types.JackalDataInjection, source string, manifest layout.DataInjectionManifest, podName string) error {
+	exec := func(command []string, stdin io.Reader) error {
+		return c.execInContainer(ctx, data.Target.Namespace, podName, data.Target.Container, command, stdin)
+	}
+
+	// Must create the target directory before extracting into it
+	if err := exec([]string{"mkdir", "-p", data.Target.Path}, nil); err != nil {
+		return fmt.Errorf("unable to create the target directory: %w", err)
+	}
+
+	// Only transfer the files that differ from the manifest left behind by the last injection into this target
+	injected := c.readInjectedManifest(ctx, data, podName)
+	changed := make(map[string]bool)
+	var transferSize, unchangedSize int64
+	for name, file := range manifest {
+		if injected[name] == file {
+			unchangedSize += file.Size
 			continue
 		}
+		changed[name] = true
+		transferSize += file.Size
+	}
 
-		// Inject into all the pods
-		for _, pod := range pods {
-			// Try to use the embedded kubectl if we can
-			jackalCommand, err := utils.GetFinalExecutableCommand()
-			kubectlBinPath := "kubectl"
-			if err != nil {
-				message.Warnf("Unable to get the jackal executable path, falling back to host kubectl: %s", err)
-			} else {
-				kubectlBinPath = fmt.Sprintf("%s tools kubectl", jackalCommand)
-			}
-			kubectlCmd := fmt.Sprintf("%s exec -i -n %s %s -c %s ", kubectlBinPath, data.Target.Namespace, pod.Name, data.Target.Container)
+	progressBar := message.NewProgressBar(transferSize, fmt.Sprintf("Injecting data into %s in pod %s", data.Target.Path, podName))
+	defer progressBar.Stop()
+
+	if len(changed) > 0 {
+		untarCmd := []string{"tar", "-x", "-f", "-", "-C", data.Target.Path}
+		if data.Compress {
+			untarCmd = []string{"tar", "-x", "-z", "-f", "-", "-C", data.Target.Path}
+		}
 
-			// Note that each command flag is separated to provide the widest cross-platform tar support
-			tarCmd := fmt.Sprintf("tar -c %s -f -", tarCompressFlag)
-			untarCmd := fmt.Sprintf("tar -x %s -v -f - -C %s", tarCompressFlag, data.Target.Path)
+		reader, writer := io.Pipe()
+		checksums := make(chan map[string]string, 1)
+		go func() {
+			sums, err := writeInjectionArchive(writer, source, data.Compress, progressBar, func(name string) bool { return changed[name] })
+			checksums <- sums
+			writer.CloseWithError(err)
+		}()
+
+		err := exec(untarCmd, reader)
+		// Unblock the archive writer if the exec ended before reading all of the data
+		reader.CloseWithError(errors.New("the data injection stream was closed"))
+		sums := <-checksums
+		if err != nil {
+			return fmt.Errorf("unable to copy the data: %w", err)
+		}
 
-			// Must create the target directory before trying to change to it for untar
-			mkdirCmd := fmt.Sprintf("%s -- mkdir -p %s", kubectlCmd, data.Target.Path)
-			if err := exec.CmdWithPrint(shell, append(shellArgs, mkdirCmd)...); err != nil {
-				message.Warnf("Unable to create the data injection target directory %s in pod %s", data.Target.Path, pod.Name)
-				continue iterator
+		// The data must match what was recorded when the package was created
+		for name, sum := range sums {
+			if sum != manifest[name].SHA256 {
+				return fmt.Errorf("the data injection file %s does not match the package's data injection manifest", name)
 			}
+		}
 
-			cpPodCmd := fmt.Sprintf("%s -C %s . | %s -- %s",
-				tarCmd,
-				source,
-				kubectlCmd,
-				untarCmd,
-			)
-
-			// Do the actual data injection
-			if err := exec.CmdWithPrint(shell, append(shellArgs, cpPodCmd)...); err != nil {
-				message.Warnf("Error copying data into the pod %#v: %#v\n", pod.Name, err)
-				continue iterator
+		// Verify every file that was written against the checksum it was archived with, if the container has sha256sum to do so
+		if err := exec([]string{"sha256sum"}, strings.NewReader("")); err != nil {
+			message.Warnf("Unable to verify the data written to %s in pod %s as sha256sum is not available in the container, relying on the checksums of the streamed data", data.Target.Path, podName)
+			message.Debugf("sha256sum is not available in pod %s: %s", podName, err.Error())
+		} else if err := exec([]string{"sha256sum", "-c", "-"}, strings.NewReader(checksumList(data.Target.Path, sums))); err != nil {
+			return fmt.Errorf("unable to verify the injected data: %w", err)
+		}
+	}
+
+	// Leave the manifest of the verified data for the next injection into this target to compare against
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	var manifestArchive bytes.Buffer
+	if err := writeFileArchive(&manifestArchive, injectedManifestName, manifestJSON); err != nil {
+		return err
+	}
+	if err := exec([]string{"tar", "-x", "-f", "-", "-C", data.Target.Path}, &manifestArchive); err != nil {
+		return fmt.Errorf("unable to save the data injection manifest: %w", err)
+	}
+
+	progressBar.Successf("Injected %s of data into %s in pod %s (%s unchanged)",
+		utils.ByteFormat(float64(transferSize), 2), data.Target.Path, podName, utils.ByteFormat(float64(unchangedSize), 2))
+
+	return nil
+}
+
+// readInjectedManifest returns the manifest left behind by the last injection into the target container of a pod, or an empty manifest if there is none.
+func (c *Cluster) readInjectedManifest(ctx context.Context, data types.JackalDataInjection, podName string) layout.DataInjectionManifest {
+	var stdout, stderr bytes.Buffer
+	command := []string{"cat", path.Join(data.Target.Path, injectedManifestName)}
+	if err := c.ExecInPod(ctx, data.Target.Namespace, podName, data.Target.Container, command, nil, &stdout, &stderr); err != nil {
+		message.Debugf("No data injection manifest found in %s in pod %s, transferring all files: %s", data.Target.Path, podName, strings.TrimSpace(stderr.String()))
+		return layout.DataInjectionManifest{}
+	}
+
+	var injected layout.DataInjectionManifest
+	if err := json.Unmarshal(stdout.Bytes(), &injected); err != nil {
+		message.Debugf("Invalid data injection manifest found in %s in pod %s, transferring all files: %s", data.Target.Path, podName, err.Error())
+		return layout.DataInjectionManifest{}
+	}
+	return injected
+}
+
+// injectDataIntoPVC makes a single attempt at injecting the data into a persistent volume claim through a short-lived loader pod.
+//
+// The digest of the injected data is recorded on the claim so that unchanged data is not injected again.
+func (c *Cluster) injectDataIntoPVC(ctx context.Context, data types.JackalDataInjection, source string, manifest layout.DataInjectionManifest) error {
+	namespace := data.Target.Namespace
+	claimName := data.Target.PersistentVolumeClaim
+
+	digest := dataInjectionDigest(manifest)
+
+	// Wait until the claim exists, it may be created by a chart in this component
+	var claim *corev1.PersistentVolumeClaim
+	var err error
+	for claim == nil {
+		message.Debugf("Waiting for the data injection target persistent volume claim %s/%s", namespace, claimName)
+		claim, err = c.Clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, claimName, metav1.GetOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return err
+		}
+		if err != nil {
+			claim = nil
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("timed out waiting for the persistent volume claim: %w", ctx.Err())
+			case <-time.After(3 * time.Second):
 			}
+		}
+	}
+
+	if recordedDataInjections(claim)[data.Target.Path] == digest {
+		message.Successf("Data in %s in the persistent volume claim %s/%s is unchanged, skipping", data.Target.Path, namespace, claimName)
+		return nil
+	}
+
+	loader := c.buildDataLoaderPod(data)
+
+	// Remove a loader left behind by a previous deployment that was interrupted
+	if err := c.DeletePod(namespace, loader.Name); err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
+	if _, err := c.CreatePod(loader); err != nil {
+		return fmt.Errorf("unable to create the data loader pod: %w", err)
+	}
+	defer func() {
+		if err := c.DeletePod(namespace, loader.Name); err != nil {
+			message.WarnErrf(err, "Unable to remove the data loader pod %s/%s", namespace, loader.Name)
+		}
+	}()
+
+	if err := c.waitForPodRunning(ctx, namespace, loader.Name); err != nil {
+		return err
+	}
+
+	// Files left in the volume by a previous injection are compared against the manifest in the volume, not re-sent
+	loaderData := data
+	loaderData.Target.Container = dataLoaderContainer
+	if err := c.injectDataIntoPod(ctx, loaderData, source, manifest, loader.Name); err != nil {
+		return fmt.Errorf("persistent volume claim %s: %w", claimName, err)
+	}
 
-			// Leave a marker in the target container for pods to track the sync action
-			cpPodCmd = fmt.Sprintf("%s -C %s %s | %s -- %s",
-				tarCmd,
-				componentPath.DataInjections,
-				config.GetDataInjectionMarker(),
-				kubectlCmd,
-				untarCmd,
-			)
-
-			if err := exec.CmdWithPrint(shell, append(shellArgs, cpPodCmd)...); err != nil {
-				message.Warnf("Error saving the jackal sync completion file after injection into pod %#v\n", pod.Name)
-				continue iterator
+	if err := c.recordDataInjection(ctx, namespace, claimName, data.Target.Path, digest); err != nil {
+		return fmt.Errorf("unable to record the data injection: %w", err)
+	}
+
+	return nil
+}
+
+// buildDataLoaderPod returns a pod that mounts the target persistent volume claim of a data injection at the target path.
+func (c *Cluster) buildDataLoaderPod(data types.JackalDataInjection) *corev1.Pod {
+	name := fmt.Sprintf("jackal-data-loader-%d", helpers.GetCRCHash(data.Target.PersistentVolumeClaim+data.Target.Path))
+	pod := c.GeneratePod(name, data.Target.Namespace)
+
+	pod.Labels["app"] = "jackal-data-loader"
+
+	// Do not try to restart the pod as it is deleted once the data is injected
+	pod.Spec.RestartPolicy = corev1.RestartPolicyNever
+
+	pod.Spec.Containers = []corev1.Container{
+		{
+			Name: dataLoaderContainer,
+
+			// An image from this component, mutated by the Jackal agent to be pulled from the Jackal registry
+			Image:           data.Target.LoaderImage,
+			ImagePullPolicy: corev1.PullIfNotPresent,
+
+			// Stay up until the data has been streamed in
+			Command: []string{"sleep", strconv.Itoa(int((24 * time.Hour).Seconds()))},
+
+			VolumeMounts: []corev1.VolumeMount{
+				{
+					Name:      "data",
+					MountPath: data.Target.Path,
+				},
+			},
+		},
+	}
+
+	pod.Spec.Volumes = []corev1.Volume{
+		{
+			Name: "data",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: data.Target.PersistentVolumeClaim,
+				},
+			},
+		},
+	}
+
+	return pod
+}
+
+// waitForPodRunning waits for a pod to start running.
+func (c *Cluster) waitForPodRunning(ctx context.Context, namespace, name string) error {
+	for {
+		pod, err := c.Clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		switch pod.Status.Phase {
+		case corev1.PodRunning:
+			return nil
+		case corev1.PodFailed, corev1.PodSucceeded:
+			return fmt.Errorf("the pod %s/%s stopped before the data was injected", namespace, name)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for the pod %s/%s to start: %w", namespace, name, ctx.Err())
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+// recordedDataInjections returns the digest of the data injected into each path of a persistent volume claim.
+func recordedDataInjections(claim *corev1.PersistentVolumeClaim) map[string]string {
+	recorded := make(map[string]string)
+	if value, ok := claim.Annotations[DataInjectionsAnnotation]; ok {
+		if err := json.Unmarshal([]byte(value), &recorded); err != nil {
+			message.Debugf("Ignoring the invalid %s annotation on %s/%s: %s", DataInjectionsAnnotation, claim.Namespace, claim.Name, err.Error())
+		}
+	}
+	return recorded
+}
+
+// recordDataInjection records the digest of the data injected into a path of a persistent volume claim.
+func (c *Cluster) recordDataInjection(ctx context.Context, namespace, claimName, path, digest string) error {
+	// Injections into other paths of the same claim may be recorded at the same time
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		claim, err := c.Clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, claimName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		recorded := recordedDataInjections(claim)
+		recorded[path] = digest
+		value, err := json.Marshal(recorded)
+		if err != nil {
+			return err
+		}
+
+		if claim.Annotations == nil {
+			claim.Annotations = make(map[string]string)
+		}
+		claim.Annotations[DataInjectionsAnnotation] = string(value)
+
+		_, err = c.Clientset.CoreV1().PersistentVolumeClaims(namespace).Update(ctx, claim, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// dataInjectionDigest returns a digest of the files in a data injection manifest and their contents.
+func dataInjectionDigest(manifest layout.DataInjectionManifest) string {
+	checksums := make(map[string]string, len(manifest))
+	for name, file := range manifest {
+		checksums[name] = file.SHA256
+	}
+	digest := sha256.Sum256([]byte(checksumList("", checksums)))
+	return hex.EncodeToString(digest[:])
+}
+
+// execInContainer runs a command in a container of a pod, returning its output as part of the error if it fails.
+func (c *Cluster) execInContainer(ctx context.Context, namespace, podName, container string, command []string, stdin io.Reader) error {
+	var stdout, stderr bytes.Buffer
+	if err := c.ExecInPod(ctx, namespace, podName, container, command, stdin, &stdout, &stderr); err != nil {
+		return fmt.Errorf("%q failed: %w: %s", strings.Join(command, " "), err, strings.TrimSpace(stderr.String()+"\n"+stdout.String()))
+	}
+	return nil
+}
+
+// writeInjectionArchive writes the contents of a data injection source as a tar stream, returning the sha256 checksum of each regular file by its path in the archive.
+//
+// A directory source is archived relative to itself, so its contents are extracted directly into the target path.
+// Only the regular files accepted by include are archived, a nil include archives every file.
+func writeInjectionArchive(w io.Writer, source string, compress bool, progress io.Writer, include func(name string) bool) (map[string]string, error) {
+	out := w
+	var gzipWriter *gzip.Writer
+	if compress {
+		gzipWriter = gzip.NewWriter(w)
+		out = gzipWriter
+	}
+	tarWriter := tar.NewWriter(out)
+
+	checksums := make(map[string]string)
+	err := filepath.WalkDir(source, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		name, err := layout.DataInjectionName(source, file)
+		if err != nil {
+			return err
+		}
+		if name == "." {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		if info.Mode().IsRegular() && include != nil && !include(name) {
+			return nil
+		}
+
+		var link string
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if link, err = os.Readlink(file); err != nil {
+				return err
 			}
 		}
 
-		// Do not look for a specific container after injection in case they are running an init container
-		podOnlyTarget := k8s.PodLookup{
-			Namespace: data.Target.Namespace,
-			Selector:  data.Target.Selector,
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = name
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
 		}
 
-		// Block one final time to make sure at least one pod has come up and injected the data
-		// Using only the pod as the final selector because we don't know what the container name will be
-		// Still using the init container filter to make sure we have the right running pod
-		_ = c.WaitForPodsAndContainers(podOnlyTarget, podFilterByInitContainer)
+		if !info.Mode().IsRegular() {
+			return nil
+		}
 
-		// Cleanup now to reduce disk pressure
-		_ = os.RemoveAll(source)
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		hash := sha256.New()
+		if _, err := io.Copy(io.MultiWriter(tarWriter, hash), io.TeeReader(f, progress)); err != nil {
+			return err
+		}
+		checksums[name] = hex.EncodeToString(hash.Sum(nil))
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return nil, err
+	}
+	if gzipWriter != nil {
+		if err := gzipWriter.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	return checksums, nil
+}
+
+// writeFileArchive writes a tar stream containing a single file.
+func writeFileArchive(w io.Writer, name string, content []byte) error {
+	tarWriter := tar.NewWriter(w)
+	if err := tarWriter.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(content)),
+		ModTime: time.Now(),
+	}); err != nil {
+		return err
+	}
+	if _, err := tarWriter.Write(content); err != nil {
+		return err
+	}
+
+	return tarWriter.Close()
+}
+
+// checksumList formats checksums in the format read by 'sha256sum -c', with each file under the target path.
+func checksumList(targetPath string, checksums map[string]string) string {
+	names := make([]string, 0, len(checksums))
+	for name := range checksums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
 
-		// Return to stop the loop
-		return
+	var list strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&list, "%s  %s\n", checksums[name], path.Join(targetPath, name))
 	}
+	return list.String()
 }
@@ -0,0 +1,159 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package cluster contains Jackal-specific cluster management functions.
+package cluster
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/racer159/jackal/src/pkg/k8s"
+	"github.com/racer159/jackal/src/pkg/layout"
+	"github.com/racer159/jackal/src/types"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWriteInjectionArchive(t *testing.T) {
+	t.Parallel()
+
+	source := filepath.Join(t.TempDir(), "data")
+	require.NoError(t, os.MkdirAll(filepath.Join(source, "nested", "empty"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(source, "top.txt"), []byte("top"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(source, "nested", "inner.txt"), []byte("inner"), 0600))
+
+	sha := func(content string) string {
+		sum := sha256.Sum256([]byte(content))
+		return hex.EncodeToString(sum[:])
+	}
+
+	readArchive := func(r io.Reader) map[string]string {
+		t.Helper()
+		entries := make(map[string]string)
+		tarReader := tar.NewReader(r)
+		for {
+			header, err := tarReader.Next()
+			if errors.Is(err, io.EOF) {
+				return entries
+			}
+			require.NoError(t, err)
+			content, err := io.ReadAll(tarReader)
+			require.NoError(t, err)
+			entries[header.Name] = string(content)
+		}
+	}
+
+	for _, compress := range []bool{false, true} {
+		var archive, progress bytes.Buffer
+		checksums, err := writeInjectionArchive(&archive, source, compress, &progress, nil)
+		require.NoError(t, err)
+
+		// Only regular files are checksummed, and their content is reported as progress
+		require.Equal(t, map[string]string{"top.txt": sha("top"), "nested/inner.txt": sha("inner")}, checksums)
+		require.Equal(t, len("top")+len("inner"), progress.Len())
+
+		var r io.Reader = &archive
+		if compress {
+			r, err = gzip.NewReader(&archive)
+			require.NoError(t, err)
+		}
+
+		// Directory sources are archived relative to themselves
+		require.Equal(t, map[string]string{
+			"nested/":          "",
+			"nested/empty/":    "",
+			"nested/inner.txt": "inner",
+			"top.txt":          "top",
+		}, readArchive(r))
+	}
+
+	// A file source is archived by its name
+	var archive bytes.Buffer
+	checksums, err := writeInjectionArchive(&archive, filepath.Join(source, "top.txt"), false, io.Discard, nil)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"top.txt": sha("top")}, checksums)
+	require.Equal(t, map[string]string{"top.txt": "top"}, readArchive(&archive))
+
+	// Files that are not included are left out while the directories are kept
+	archive.Reset()
+	checksums, err = writeInjectionArchive(&archive, source, false, io.Discard, func(name string) bool { return name == "nested/inner.txt" })
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"nested/inner.txt": sha("inner")}, checksums)
+	require.Equal(t, map[string]string{
+		"nested/":          "",
+		"nested/empty/":    "",
+		"nested/inner.txt": "inner",
+	}, readArchive(&archive))
+
+	// Checksums are listed in order with each file under the target path
+	list := checksumList("/data", map[string]string{"top.txt": sha("top"), "nested/inner.txt": sha("inner")})
+	require.Equal(t, sha("inner")+"  /data/nested/inner.txt\n"+sha("top")+"  /data/top.txt\n", list)
+}
+
+func TestDataInjectionPVC(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c := &Cluster{
+		K8s: &k8s.K8s{
+			Clientset: fake.NewSimpleClientset(&corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "test"},
+			}),
+			Log:    func(string, ...interface{}) {},
+			Labels: k8s.Labels{},
+		},
+	}
+
+	source := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(source, "data.txt"), []byte("data"), 0600))
+	manifest, err := layout.NewDataInjectionManifest(source)
+	require.NoError(t, err)
+	digest := dataInjectionDigest(manifest)
+
+	// The digest only changes with the data
+	same, err := layout.NewDataInjectionManifest(source)
+	require.NoError(t, err)
+	require.Equal(t, digest, dataInjectionDigest(same))
+	require.NoError(t, os.WriteFile(filepath.Join(source, "data.txt"), []byte("changed"), 0600))
+	manifest, err = layout.NewDataInjectionManifest(source)
+	require.NoError(t, err)
+	changed := dataInjectionDigest(manifest)
+	require.NotEqual(t, digest, changed)
+
+	require.NoError(t, c.recordDataInjection(ctx, "test", "data", "/data", digest))
+	require.NoError(t, c.recordDataInjection(ctx, "test", "data", "/models", changed))
+
+	claim, err := c.Clientset.CoreV1().PersistentVolumeClaims("test").Get(ctx, "data", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"/data": digest, "/models": changed}, recordedDataInjections(claim))
+
+	data := types.JackalDataInjection{
+		Target: types.JackalContainerTarget{
+			Namespace:             "test",
+			Path:                  "/data",
+			PersistentVolumeClaim: "data",
+			LoaderImage:           "busybox:1.36",
+		},
+	}
+	pod := c.buildDataLoaderPod(data)
+	require.Equal(t, "test", pod.Namespace)
+	require.Equal(t, "busybox:1.36", pod.Spec.Containers[0].Image)
+	require.Equal(t, "/data", pod.Spec.Containers[0].VolumeMounts[0].MountPath)
+	require.Equal(t, "data", pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName)
+
+	// Loaders for different targets do not collide
+	data.Target.Path = "/models"
+	require.NotEqual(t, pod.Name, c.buildDataLoaderPod(data).Name)
+}
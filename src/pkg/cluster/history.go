@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package cluster contains Jackal-specific cluster management functions.
+package cluster
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/racer159/jackal/src/config"
+	"github.com/racer159/jackal/src/types"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// JackalPackageHistoryLabel is the label (set to the package name) on the secrets that record the deploy history of a package.
+	JackalPackageHistoryLabel = "package-deploy-history"
+	// MaxPackageHistory is the number of generations of a package that are kept in its deploy history.
+	MaxPackageHistory = 10
+)
+
+// packageHistorySecretName returns the name of the secret that records the given generation of a package.
+func packageHistorySecretName(packageName string, generation int) string {
+	return fmt.Sprintf("%s%s.v%d", config.JackalPackageHistoryPrefix, packageName, generation)
+}
+
+// RecordPackageGeneration saves a generation of a deployed package to its deploy history and prunes all but the newest MaxPackageHistory generations.
+func (c *Cluster) RecordPackageGeneration(generation types.DeployedPackageGeneration) error {
+	secretName := packageHistorySecretName(generation.Name, generation.Generation)
+	generationSecret := c.GenerateSecret(JackalNamespaceName, secretName, corev1.SecretTypeOpaque)
+	generationSecret.Labels[JackalPackageHistoryLabel] = generation.Name
+
+	generationData, err := json.Marshal(generation)
+	if err != nil {
+		return err
+	}
+	generationSecret.Data = map[string][]byte{"data": generationData}
+
+	if _, err := c.CreateOrUpdateSecret(generationSecret); err != nil {
+		return fmt.Errorf("failed to record package generation in secret '%s': %w", secretName, err)
+	}
+
+	history, err := c.GetPackageHistory(generation.Name)
+	if err != nil {
+		return err
+	}
+
+	for len(history) > MaxPackageHistory {
+		if err := c.deletePackageGeneration(history[0].Name, history[0].Generation); err != nil {
+			return err
+		}
+		history = history[1:]
+	}
+
+	return nil
+}
+
+// GetPackageHistory returns the recorded generations of a deployed package, oldest first.
+func (c *Cluster) GetPackageHistory(packageName string) ([]types.DeployedPackageGeneration, error) {
+	secrets, err := c.GetSecretsWithLabel(JackalNamespaceName, fmt.Sprintf("%s=%s", JackalPackageHistoryLabel, packageName))
+	if err != nil {
+		return nil, err
+	}
+
+	history := []types.DeployedPackageGeneration{}
+	for _, secret := range secrets.Items {
+		var generation types.DeployedPackageGeneration
+		if err := json.Unmarshal(secret.Data["data"], &generation); err != nil {
+			return nil, fmt.Errorf("unable to unmarshal the secret %s/%s: %w", secret.Namespace, secret.Name, err)
+		}
+		history = append(history, generation)
+	}
+
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].Generation < history[j].Generation
+	})
+
+	return history, nil
+}
+
+// GetPackageGeneration returns a single recorded generation of a deployed package.
+func (c *Cluster) GetPackageGeneration(packageName string, generation int) (*types.DeployedPackageGeneration, error) {
+	secret, err := c.GetSecret(JackalNamespaceName, packageHistorySecretName(packageName, generation))
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, fmt.Errorf("generation %d of package %q is not in its deploy history", generation, packageName)
+		}
+		return nil, err
+	}
+
+	var deployedGeneration types.DeployedPackageGeneration
+	if err := json.Unmarshal(secret.Data["data"], &deployedGeneration); err != nil {
+		return nil, err
+	}
+
+	return &deployedGeneration, nil
+}
+
+// DeletePackageHistory removes every recorded generation of a deployed package.
+func (c *Cluster) DeletePackageHistory(packageName string) error {
+	history, err := c.GetPackageHistory(packageName)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, generation := range history {
+		if err := c.deletePackageGeneration(packageName, generation.Generation); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Cluster) deletePackageGeneration(packageName string, generation int) error {
+	secretName := packageHistorySecretName(packageName, generation)
+	secret := c.GenerateSecret(JackalNamespaceName, secretName, corev1.SecretTypeOpaque)
+	if err := c.DeleteSecret(secret); err != nil {
+		return fmt.Errorf("unable to delete the %s package history secret: %w", secretName, err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package cluster contains Jackal-specific cluster management functions.
+package cluster
+
+import (
+	"testing"
+
+	"github.com/racer159/jackal/src/pkg/k8s"
+	"github.com/racer159/jackal/src/types"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPackageHistory(t *testing.T) {
+	t.Parallel()
+
+	c := &Cluster{
+		K8s: &k8s.K8s{
+			Clientset: fake.NewSimpleClientset(),
+			Log:       func(string, ...interface{}) {},
+		},
+	}
+
+	for generation := 1; generation <= MaxPackageHistory+2; generation++ {
+		require.NoError(t, c.RecordPackageGeneration(types.DeployedPackageGeneration{
+			Name:       "test",
+			Generation: generation,
+			Releases:   []types.DeployedRelease{{Component: "baseline", Namespace: "test", ChartName: "podinfo", Revision: generation}},
+		}))
+	}
+	require.NoError(t, c.RecordPackageGeneration(types.DeployedPackageGeneration{Name: "other", Generation: 1}))
+
+	// Only the newest generations are kept, oldest first
+	history, err := c.GetPackageHistory("test")
+	require.NoError(t, err)
+	require.Len(t, history, MaxPackageHistory)
+	require.Equal(t, 3, history[0].Generation)
+	require.Equal(t, MaxPackageHistory+2, history[len(history)-1].Generation)
+
+	generation, err := c.GetPackageGeneration("test", 5)
+	require.NoError(t, err)
+	require.Equal(t, 5, generation.Releases[0].Revision)
+
+	_, err = c.GetPackageGeneration("test", 1)
+	require.ErrorContains(t, err, "generation 1 of package \"test\" is not in its deploy history")
+
+	require.NoError(t, c.DeletePackageHistory("test"))
+	history, err = c.GetPackageHistory("test")
+	require.NoError(t, err)
+	require.Empty(t, history)
+
+	history, err = c.GetPackageHistory("other")
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+}
@@ -5,11 +5,12 @@
 package cluster
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
-	"regexp"
+	"strings"
 	"time"
 
 	"github.com/defenseunicorns/pkg/helpers"
@@ -22,7 +23,9 @@ import (
 	"github.com/racer159/jackal/src/pkg/transform"
 	"github.com/racer159/jackal/src/pkg/utils"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
@@ -36,11 +39,19 @@ var (
 	injectorLimitMemory     = resource.MustParse("256Mi")
 )
 
-// imageNodeMap is a map of image/node pairs.
-type imageNodeMap map[string][]string
+// injectorConcurrency is the number of injector pods that are attempted at once.
+const injectorConcurrency = 3
+
+// injectorAttemptTimeout is how long an injector pod has to serve the seed images before it is abandoned.
+var injectorAttemptTimeout = 90 * time.Second
+
+// injectorPodSelector selects every injector pod.
+var injectorPodSelector = fmt.Sprintf("app=%s", JackalInjectorName)
 
 // StartInjectionMadness initializes a Jackal injection into the cluster.
-func (c *Cluster) StartInjectionMadness(tmpDir string, imagesDir string, injectorSeedSrcs []string) {
+//
+// The injector is attempted with the best candidate images on each node that can host it, and if no candidate succeeds the returned error reports why each failed.
+func (c *Cluster) StartInjectionMadness(tmpDir string, imagesDir string, injectorSeedSrcs []string, arch string) error {
 	spinner := message.NewProgressSpinner("Attempting to bootstrap the seed image into the cluster")
 	defer spinner.Stop()
 
@@ -53,11 +64,12 @@ func (c *Cluster) StartInjectionMadness(tmpDir string, imagesDir string, injecto
 	}
 
 	if err := helpers.CreateDirectory(tmp.SeedImagesDir, helpers.ReadWriteExecuteUser); err != nil {
-		spinner.Fatalf(err, "Unable to create the seed images directory")
+		return fmt.Errorf("unable to create the seed images directory: %w", err)
 	}
 
 	var err error
-	var images imageNodeMap
+	var candidates []injectionCandidate
+	var report injectionReport
 	var payloadConfigmaps []string
 	var sha256sum string
 	var seedImages []transform.Image
@@ -65,80 +77,265 @@ func (c *Cluster) StartInjectionMadness(tmpDir string, imagesDir string, injecto
 	// Get all the images from the cluster
 	timeout := 5 * time.Minute
 	spinner.Updatef("Getting the list of existing cluster images (%s timeout)", timeout.String())
-	if images, err = c.getImagesAndNodesForInjection(timeout); err != nil {
-		spinner.Fatalf(err, "Unable to generate a list of candidate images to perform the registry injection")
+	if candidates, report, err = c.getInjectionCandidates(timeout, arch); err != nil {
+		return fmt.Errorf("unable to generate a list of candidate images to perform the registry injection: %w", err)
 	}
 
 	spinner.Updatef("Creating the injector configmap")
 	if err = c.createInjectorConfigmap(tmp.InjectionBinary); err != nil {
-		spinner.Fatalf(err, "Unable to create the injector configmap")
+		return fmt.Errorf("unable to create the injector configmap: %w", err)
 	}
 
 	spinner.Updatef("Creating the injector service")
-	if service, err := c.createService(); err != nil {
-		spinner.Fatalf(err, "Unable to create the injector service")
-	} else {
-		config.JackalSeedPort = fmt.Sprintf("%d", service.Spec.Ports[0].NodePort)
+	service, err := c.createService()
+	if err != nil {
+		return fmt.Errorf("unable to create the injector service: %w", err)
 	}
+	config.JackalSeedPort = fmt.Sprintf("%d", service.Spec.Ports[0].NodePort)
 
 	spinner.Updatef("Loading the seed image from the package")
 	if seedImages, err = c.loadSeedImages(imagesDir, tmp.SeedImagesDir, injectorSeedSrcs, spinner); err != nil {
-		spinner.Fatalf(err, "Unable to load the injector seed image from the package")
+		return fmt.Errorf("unable to load the injector seed image from the package: %w", err)
 	}
 
 	spinner.Updatef("Loading the seed registry configmaps")
 	if payloadConfigmaps, sha256sum, err = c.createPayloadConfigmaps(tmp.SeedImagesDir, tmp.InjectorPayloadTarGz, spinner); err != nil {
-		spinner.Fatalf(err, "Unable to generate the injector payload configmaps")
+		return fmt.Errorf("unable to generate the injector payload configmaps: %w", err)
+	}
+
+	// Make sure no injector pods are left from a previous run
+	if err := c.deleteInjectorPods(); err != nil {
+		return fmt.Errorf("unable to remove the previous injector pods: %w", err)
+	}
+
+	winner, failures := c.runInjectionAttempts(candidates, payloadConfigmaps, sha256sum, seedImages, spinner)
+	if winner == nil {
+		// All candidates were exhausted and still no happiness
+		report = append(report, failures...)
+		return fmt.Errorf("unable to perform the injection, no candidate image could host the injector:\n%s", report)
 	}
 
-	// https://regex101.com/r/eLS3at/1
-	jackalImageRegex := regexp.MustCompile(`(?m)^127\.0\.0\.1:`)
+	message.Debugf("Bootstrapped the seed image with %s on node %s", winner.image, winner.node)
+	spinner.Success()
+	return nil
+}
+
+// runInjectionAttempts tries candidates in order with injectorConcurrency attempts running at once, returning the first to succeed.
+//
+// Once a candidate succeeds no new attempts are started and the attempts in flight are cancelled.
+func (c *Cluster) runInjectionAttempts(candidates []injectionCandidate, payloadConfigmaps []string, payloadShasum string, seedImages []transform.Image, spinner *message.Spinner) (*injectionCandidate, injectionReport) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	type result struct {
+		candidate injectionCandidate
+		podName   string
+		failure   *injectionFailure
+	}
+
+	var (
+		results  = make(chan result)
+		next     = 0
+		inFlight = 0
+		winner   *injectionCandidate
+		report   injectionReport
+	)
+
+	for {
+		for winner == nil && inFlight < injectorConcurrency && next < len(candidates) {
+			candidate := candidates[next]
+			podName := fmt.Sprintf("injector-%d", next)
+			next++
+			inFlight++
+			go func() {
+				results <- result{candidate: candidate, podName: podName, failure: c.attemptInjection(ctx, podName, candidate, payloadConfigmaps, payloadShasum, seedImages)}
+			}()
+		}
 
-	// Try to create an injector pod using an existing image in the cluster
-	for image, node := range images {
-		// Don't try to run against the seed image if this is a secondary jackal init run
-		if jackalImageRegex.MatchString(image) {
-			continue
+		if inFlight == 0 {
+			break
 		}
 
-		spinner.Updatef("Attempting to bootstrap with the %s/%s", node, image)
+		if winner == nil {
+			spinner.Updatef("Attempting to bootstrap with %d candidate images (%d of %d attempted)", len(candidates), next-inFlight, len(candidates))
+		}
 
-		// Make sure the pod is not there first
-		_ = c.DeletePod(JackalNamespaceName, "injector")
+		r := <-results
+		inFlight--
 
-		// Update the podspec image path and use the first node found
-		pod, err := c.buildInjectionPod(node[0], image, payloadConfigmaps, sha256sum)
-		if err != nil {
-			// Just debug log the output because failures just result in trying the next image
-			message.Debug(err)
-			continue
+		switch {
+		case winner != nil:
+			// Only one injector is needed, remove any that also succeeded while they were being cancelled
+			if r.failure == nil {
+				c.deleteInjectorPod(r.podName)
+			}
+		case r.failure == nil:
+			winner = &r.candidate
+			cancel()
+		default:
+			message.Debugf("Unable to bootstrap with %s on node %s: %s (%s)", r.candidate.image, r.candidate.node, r.failure.reason, r.failure.detail)
+			report = append(report, *r.failure)
 		}
+	}
 
-		// Create the pod in the cluster
-		pod, err = c.CreatePod(pod)
-		if err != nil {
-			// Just debug log the output because failures just result in trying the next image
-			message.Debug(pod, err)
-			continue
+	return winner, report
+}
+
+// attemptInjection runs the injector with a candidate image, returning why it failed or nil once it is serving the seed images.
+//
+// The injector pod is removed if the attempt fails or is cancelled.
+func (c *Cluster) attemptInjection(ctx context.Context, podName string, candidate injectionCandidate, payloadConfigmaps []string, payloadShasum string, seedImages []transform.Image) *injectionFailure {
+	fail := func(reason, format string, a ...any) *injectionFailure {
+		return &injectionFailure{node: candidate.node, image: candidate.image, reason: reason, detail: fmt.Sprintf(format, a...)}
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, injectorAttemptTimeout)
+	defer cancel()
+
+	pod, err := c.buildInjectionPod(podName, candidate.node, candidate.image, payloadConfigmaps, payloadShasum)
+	if err != nil {
+		return fail(injectionReasonContainer, "unable to build the injector pod: %s", err)
+	}
+
+	// Create the pod in the cluster
+	if _, err = c.CreatePod(pod); err != nil {
+		// Admission controllers (e.g. Pod Security Admission or a policy engine) reject the pod on creation
+		if errors.IsForbidden(err) || errors.IsInvalid(err) {
+			return fail(injectionReasonSecurityPolicy, "the injector pod was rejected: %s", err)
+		}
+		return fail(injectionReasonContainer, "unable to create the injector pod: %s", err)
+	}
+
+	var (
+		state   = "the injector pod was not observed"
+		seedErr error
+	)
+	for {
+		pod, err := c.Clientset.CoreV1().Pods(JackalNamespaceName).Get(attemptCtx, podName, metav1.GetOptions{})
+		if err == nil {
+			if reason, detail, failed := diagnoseInjectorPod(pod); failed {
+				c.deleteInjectorPod(podName)
+				return fail(reason, "%s", detail)
+			}
+
+			// If the pod is ready, check that the seed images are being served
+			state = describeInjectorPod(pod)
+			if isPodReady(pod) {
+				if seedErr = c.injectorIsReady(podName, seedImages); seedErr == nil {
+					return nil
+				}
+			}
+		}
+
+		select {
+		case <-attemptCtx.Done():
+			c.deleteInjectorPod(podName)
+			if ctx.Err() != nil {
+				return fail(injectionReasonTimeout, "cancelled as another candidate succeeded")
+			}
+			if seedErr != nil {
+				return fail(injectionReasonSeedImage, "%s after %s", seedErr, injectorAttemptTimeout)
+			}
+			return fail(injectionReasonTimeout, "%s after %s", state, injectorAttemptTimeout)
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+// diagnoseInjectorPod returns why an injector pod has failed, if it has.
+func diagnoseInjectorPod(pod *corev1.Pod) (reason string, detail string, failed bool) {
+	for _, status := range pod.Status.ContainerStatuses {
+		if waiting := status.State.Waiting; waiting != nil {
+			detail := fmt.Sprintf("%s: %s", waiting.Reason, waiting.Message)
+			switch waiting.Reason {
+			case "ErrImagePull", "ImagePullBackOff", "ErrImageNeverPull", "InvalidImageName":
+				if isArchMismatch(waiting.Message) {
+					return injectionReasonImageArch, detail, true
+				}
+				return injectionReasonPullPolicy, fmt.Sprintf("the image is not on the node and could not be pulled, %s", detail), true
+			case "CreateContainerConfigError", "CreateContainerError", "RunContainerError", "CrashLoopBackOff":
+				if isArchMismatch(waiting.Message) {
+					return injectionReasonImageArch, detail, true
+				}
+				return injectionReasonContainer, detail, true
+			}
+		}
+
+		if terminated := status.State.Terminated; terminated != nil {
+			detail := fmt.Sprintf("exited with code %d, %s: %s", terminated.ExitCode, terminated.Reason, terminated.Message)
+			if isArchMismatch(terminated.Message) {
+				return injectionReasonImageArch, detail, true
+			}
+			return injectionReasonContainer, detail, true
+		}
+	}
+
+	// The kubelet fails pods that it will not admit (e.g. OutOfcpu, NodeAffinity or AppArmor)
+	if pod.Status.Phase == corev1.PodFailed {
+		return injectionReasonNodeAdmission, fmt.Sprintf("%s: %s", pod.Status.Reason, pod.Status.Message), true
+	}
+
+	return "", "", false
+}
+
+// isArchMismatch returns whether a container message means that the image is not for the architecture of the node.
+func isArchMismatch(message string) bool {
+	message = strings.ToLower(message)
+	return strings.Contains(message, "exec format error") ||
+		strings.Contains(message, "no matching manifest") ||
+		strings.Contains(message, "no match for platform")
+}
+
+// describeInjectorPod returns the current state of an injector pod.
+func describeInjectorPod(pod *corev1.Pod) string {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Waiting != nil {
+			return fmt.Sprintf("the injector pod was %s (%s)", pod.Status.Phase, status.State.Waiting.Reason)
 		}
+	}
+	if isPodReady(pod) {
+		return fmt.Sprintf("the injector pod was %s and ready", pod.Status.Phase)
+	}
+	return fmt.Sprintf("the injector pod was %s", pod.Status.Phase)
+}
 
-		// if no error, try and wait for a seed image to be present, return if successful
-		if c.injectorIsReady(seedImages, spinner) {
-			spinner.Success()
-			return
+// isPodReady returns whether a pod has the Ready condition.
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
 		}
+	}
+	return false
+}
 
-		// Otherwise just continue to try next image
+// deleteInjectorPod removes an injector pod without waiting for it to terminate.
+func (c *Cluster) deleteInjectorPod(podName string) {
+	deleteGracePeriod := int64(0)
+	err := c.Clientset.CoreV1().Pods(JackalNamespaceName).Delete(context.TODO(), podName, metav1.DeleteOptions{GracePeriodSeconds: &deleteGracePeriod})
+	if err != nil && !errors.IsNotFound(err) {
+		message.Debugf("Unable to remove the injector pod %s: %s", podName, err)
 	}
+}
 
-	// All images were exhausted and still no happiness
-	spinner.Fatalf(nil, "Unable to perform the injection")
+// deleteInjectorPods removes every injector pod and waits for them to terminate.
+func (c *Cluster) deleteInjectorPods() error {
+	pods, err := c.Clientset.CoreV1().Pods(JackalNamespaceName).List(context.TODO(), metav1.ListOptions{LabelSelector: injectorPodSelector})
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods.Items {
+		if err := c.DeletePod(JackalNamespaceName, pod.Name); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
 }
 
 // StopInjectionMadness handles cleanup once the seed registry is up.
 func (c *Cluster) StopInjectionMadness() error {
-	// Try to kill the injector pod now
-	if err := c.DeletePod(JackalNamespaceName, "injector"); err != nil {
+	// Try to kill the injector pods now
+	if err := c.deleteInjectorPods(); err != nil {
 		return err
 	}
 
@@ -239,21 +436,19 @@ func (c *Cluster) createPayloadConfigmaps(seedImagesDir, tarPath string, spinner
 	return configMaps, sha256sum, nil
 }
 
-// Test for pod readiness and seed image presence.
-func (c *Cluster) injectorIsReady(seedImages []transform.Image, spinner *message.Spinner) bool {
-	tunnel, err := c.NewTunnel(JackalNamespaceName, k8s.SvcResource, JackalInjectorName, "", 0, JackalInjectorPort)
+// injectorIsReady tests an injector pod for seed image presence.
+func (c *Cluster) injectorIsReady(podName string, seedImages []transform.Image) error {
+	tunnel, err := c.NewTunnel(JackalNamespaceName, k8s.PodResource, podName, "", 0, JackalInjectorPort)
 	if err != nil {
-		return false
+		return err
 	}
 
 	_, err = tunnel.Connect()
 	if err != nil {
-		return false
+		return fmt.Errorf("unable to connect to the injector: %w", err)
 	}
 	defer tunnel.Close()
 
-	spinner.Updatef("Testing the injector for seed image availability")
-
 	for _, seedImage := range seedImages {
 		seedRegistry := fmt.Sprintf("%s/v2/%s/manifests/%s", tunnel.HTTPEndpoint(), seedImage.Path, seedImage.Tag)
 
@@ -263,16 +458,16 @@ func (c *Cluster) injectorIsReady(seedImages []transform.Image, spinner *message
 			resp, err = http.Get(seedRegistry)
 			return err
 		})
-
-		if err != nil || resp.StatusCode != 200 {
-			// Just debug log the output because failures just result in trying the next image
-			message.Debug(resp, err)
-			return false
+		if err != nil {
+			return fmt.Errorf("unable to get the seed image %s from the injector: %w", seedImage.Reference, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("the injector responded to the seed image %s with status code %d", seedImage.Reference, resp.StatusCode)
 		}
 	}
 
-	spinner.Updatef("Seed image found, injector is ready")
-	return true
+	return nil
 }
 
 func (c *Cluster) createInjectorConfigmap(binaryPath string) error {
@@ -313,8 +508,8 @@ func (c *Cluster) createService() (*corev1.Service, error) {
 }
 
 // buildInjectionPod return a pod for injection with the appropriate containers to perform the injection.
-func (c *Cluster) buildInjectionPod(node, image string, payloadConfigmaps []string, payloadShasum string) (*corev1.Pod, error) {
-	pod := c.GeneratePod("injector", JackalNamespaceName)
+func (c *Cluster) buildInjectionPod(podName, node, image string, payloadConfigmaps []string, payloadShasum string) (*corev1.Pod, error) {
+	pod := c.GeneratePod(podName, JackalNamespaceName)
 	executeMode := int32(0777)
 
 	pod.Labels["app"] = "zarf-injector"
@@ -430,71 +625,3 @@ func (c *Cluster) buildInjectionPod(node, image string, payloadConfigmaps []stri
 
 	return pod, nil
 }
-
-// GetImagesFromAvailableNodes checks for images on schedulable nodes within a cluster and returns
-func (c *Cluster) getImagesAndNodesForInjection(timeoutDuration time.Duration) (imageNodeMap, error) {
-	timeout := time.After(timeoutDuration)
-	result := make(imageNodeMap)
-
-	for {
-		select {
-
-		// On timeout abort
-		case <-timeout:
-			return nil, fmt.Errorf("get image list timed-out")
-
-		// After delay, try running
-		default:
-			pods, err := c.GetPods(corev1.NamespaceAll)
-			if err != nil {
-				return nil, fmt.Errorf("unable to get the list of pods in the cluster")
-			}
-
-		findImages:
-			for _, pod := range pods.Items {
-				nodeName := pod.Spec.NodeName
-
-				// If this pod doesn't have a node (i.e. is Pending), skip it
-				if nodeName == "" {
-					continue
-				}
-
-				nodeDetails, err := c.GetNode(nodeName)
-
-				if err != nil {
-					return nil, fmt.Errorf("unable to get the node %s", pod.Spec.NodeName)
-				}
-
-				if nodeDetails.Status.Allocatable.Cpu().Cmp(injectorRequestedCPU) < 0 ||
-					nodeDetails.Status.Allocatable.Memory().Cmp(injectorRequestedMemory) < 0 {
-					continue findImages
-				}
-
-				for _, taint := range nodeDetails.Spec.Taints {
-					if taint.Effect == corev1.TaintEffectNoSchedule || taint.Effect == corev1.TaintEffectNoExecute {
-						continue findImages
-					}
-				}
-
-				for _, container := range pod.Spec.InitContainers {
-					result[container.Image] = append(result[container.Image], nodeName)
-				}
-
-				for _, container := range pod.Spec.Containers {
-					result[container.Image] = append(result[container.Image], nodeName)
-				}
-
-				for _, container := range pod.Spec.EphemeralContainers {
-					result[container.Image] = append(result[container.Image], nodeName)
-				}
-			}
-		}
-
-		if len(result) < 1 {
-			c.Log("no images found: %w")
-			time.Sleep(2 * time.Second)
-		} else {
-			return result, nil
-		}
-	}
-}
@@ -0,0 +1,257 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package cluster contains Jackal-specific cluster management functions.
+package cluster
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/racer159/jackal/src/pkg/transform"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Reasons that a node or candidate image could not host the injector.
+const (
+	injectionReasonCordoned       = "cordoned"
+	injectionReasonTaint          = "taint"
+	injectionReasonResources      = "insufficient resources"
+	injectionReasonNodeArch       = "node arch"
+	injectionReasonImageArch      = "image arch"
+	injectionReasonPullPolicy     = "pull policy"
+	injectionReasonSecurityPolicy = "security policy"
+	injectionReasonNodeAdmission  = "node admission"
+	injectionReasonContainer      = "container error"
+	injectionReasonTimeout        = "timeout"
+	injectionReasonSeedImage      = "seed image unavailable"
+)
+
+// https://regex101.com/r/eLS3at/1
+var jackalImageRegex = regexp.MustCompile(`(?m)^127\.0\.0\.1:`)
+
+// preferredInjectorImages are small images found on most distros that are known to start the injector binary without needing a shell.
+var preferredInjectorImages = regexp.MustCompile(`/(pause|mirrored-pause|coredns|mirrored-coredns-coredns|local-path-provisioner|klipper-helm|metrics-server)[:@]`)
+
+// injectionCandidate is an existing cluster image on a node that the injector pod can be run with.
+type injectionCandidate struct {
+	image string
+	node  string
+	// cached is whether the node reports the image in its image cache, meaning it will not need to be pulled
+	cached bool
+	// size is the size of the image in the node's image cache
+	size  int64
+	score int
+}
+
+// injectionFailure records why a node or candidate image could not host the injector.
+type injectionFailure struct {
+	node   string
+	image  string
+	reason string
+	detail string
+}
+
+// injectionReport is the collection of failures from an injection.
+type injectionReport []injectionFailure
+
+// String returns the failures in the report, one per line.
+func (r injectionReport) String() string {
+	failures := slices.Clone(r)
+	sort.SliceStable(failures, func(i, j int) bool {
+		if failures[i].node != failures[j].node {
+			return failures[i].node < failures[j].node
+		}
+		return failures[i].image < failures[j].image
+	})
+
+	lines := []string{}
+	for _, failure := range failures {
+		target := fmt.Sprintf("node %s", failure.node)
+		if failure.image != "" {
+			target = fmt.Sprintf("%s on %s", failure.image, target)
+		}
+		lines = append(lines, fmt.Sprintf("  - %s: %s (%s)", target, failure.reason, failure.detail))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// checkInjectorNode returns why the injector cannot be run on a node, or nil if it can.
+func checkInjectorNode(node corev1.Node, arch string) *injectionFailure {
+	failure := func(reason, format string, a ...any) *injectionFailure {
+		return &injectionFailure{node: node.Name, reason: reason, detail: fmt.Sprintf(format, a...)}
+	}
+
+	if node.Spec.Unschedulable {
+		return failure(injectionReasonCordoned, "the node is marked unschedulable")
+	}
+
+	for _, taint := range node.Spec.Taints {
+		if taint.Effect == corev1.TaintEffectNoSchedule || taint.Effect == corev1.TaintEffectNoExecute {
+			return failure(injectionReasonTaint, "the node has the taint %s", taint.ToString())
+		}
+	}
+
+	if node.Status.Allocatable.Cpu().Cmp(injectorRequestedCPU) < 0 ||
+		node.Status.Allocatable.Memory().Cmp(injectorRequestedMemory) < 0 {
+		return failure(injectionReasonResources, "the injector requests %s cpu and %s memory, the node has %s cpu and %s memory allocatable",
+			injectorRequestedCPU.String(), injectorRequestedMemory.String(), node.Status.Allocatable.Cpu().String(), node.Status.Allocatable.Memory().String())
+	}
+
+	if nodeArch := node.Status.NodeInfo.Architecture; arch != "" && nodeArch != "" && nodeArch != arch {
+		return failure(injectionReasonNodeArch, "the node is %s and the injector is %s", nodeArch, arch)
+	}
+
+	return nil
+}
+
+// normalizeImage returns the fully qualified reference of an image so that it can be compared with the node image cache.
+func normalizeImage(image string) string {
+	ref, err := transform.ParseImageRef(image)
+	if err != nil {
+		return image
+	}
+	return ref.Reference
+}
+
+// newInjectionCandidate scores an image on a node as a host for the injector.
+//
+// Images already in the node image cache are preferred as they do not depend on a pull (which may not be possible in the air gap),
+// followed by images that are known to work, followed by smaller images.
+func newInjectionCandidate(image string, node corev1.Node) injectionCandidate {
+	candidate := injectionCandidate{image: image, node: node.Name}
+
+	normalized := normalizeImage(image)
+	for _, cached := range node.Status.Images {
+		for _, name := range cached.Names {
+			if name == image || normalizeImage(name) == normalized {
+				candidate.cached = true
+				candidate.size = cached.SizeBytes
+			}
+		}
+	}
+
+	if candidate.cached {
+		candidate.score += 1000
+		// Lose a point per MiB, up to half of the cached bonus
+		candidate.score -= int(min(candidate.size>>20, 499))
+	}
+	if preferredInjectorImages.MatchString(normalized) {
+		candidate.score += 500
+	}
+
+	return candidate
+}
+
+// orderInjectionCandidates sorts candidates by score, interleaving nodes so that the best candidate on each node is attempted before the second best on any node.
+func orderInjectionCandidates(candidates []injectionCandidate) []injectionCandidate {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		if candidates[i].image != candidates[j].image {
+			return candidates[i].image < candidates[j].image
+		}
+		return candidates[i].node < candidates[j].node
+	})
+
+	// Rank each candidate within its node and then order by rank, keeping the score order within a rank
+	ranks := make([]int, len(candidates))
+	seen := make(map[string]int)
+	for idx, candidate := range candidates {
+		ranks[idx] = seen[candidate.node]
+		seen[candidate.node]++
+	}
+
+	ordered := make([]injectionCandidate, 0, len(candidates))
+	for rank := 0; len(ordered) < len(candidates); rank++ {
+		for idx, candidate := range candidates {
+			if ranks[idx] == rank {
+				ordered = append(ordered, candidate)
+			}
+		}
+	}
+	return ordered
+}
+
+// getInjectionCandidates finds the images running on nodes that can host the injector and returns them in the order they should be attempted.
+//
+// The report contains the nodes that cannot host the injector and why.
+func (c *Cluster) getInjectionCandidates(timeoutDuration time.Duration, arch string) ([]injectionCandidate, injectionReport, error) {
+	timeout := time.After(timeoutDuration)
+
+	for {
+		var (
+			candidates []injectionCandidate
+			report     injectionReport
+		)
+
+		nodes, err := c.GetNodes()
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to get the list of nodes in the cluster: %w", err)
+		}
+		eligible := make(map[string]corev1.Node)
+		for _, node := range nodes.Items {
+			if failure := checkInjectorNode(node, arch); failure != nil {
+				report = append(report, *failure)
+				continue
+			}
+			eligible[node.Name] = node
+		}
+
+		pods, err := c.GetPods(corev1.NamespaceAll)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to get the list of pods in the cluster: %w", err)
+		}
+
+		found := make(map[string]bool)
+		for _, pod := range pods.Items {
+			// If this pod doesn't have a node (i.e. is Pending) or is on a node that can't host the injector, skip it
+			node, ok := eligible[pod.Spec.NodeName]
+			if !ok {
+				continue
+			}
+
+			images := []string{}
+			for _, container := range pod.Spec.InitContainers {
+				images = append(images, container.Image)
+			}
+			for _, container := range pod.Spec.Containers {
+				images = append(images, container.Image)
+			}
+			for _, container := range pod.Spec.EphemeralContainers {
+				images = append(images, container.Image)
+			}
+
+			for _, image := range images {
+				// Don't try to run against the seed image if this is a secondary jackal init run
+				if jackalImageRegex.MatchString(image) || found[image+"@"+node.Name] {
+					continue
+				}
+				found[image+"@"+node.Name] = true
+				candidates = append(candidates, newInjectionCandidate(image, node))
+			}
+		}
+
+		if len(candidates) > 0 {
+			return orderInjectionCandidates(candidates), report, nil
+		}
+
+		select {
+		// On timeout abort
+		case <-timeout:
+			if len(report) > 0 {
+				return nil, report, fmt.Errorf("get image list timed-out, no node can host the injector:\n%s", report)
+			}
+			return nil, report, fmt.Errorf("get image list timed-out")
+
+		// After delay, try running
+		case <-time.After(2 * time.Second):
+			c.Log("no images found on nodes that can host the injector")
+		}
+	}
+}
@@ -0,0 +1,208 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package cluster contains Jackal-specific cluster management functions.
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/racer159/jackal/src/pkg/k8s"
+	"github.com/racer159/jackal/src/pkg/message"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newInjectorTestNode(name, arch string, images ...corev1.ContainerImage) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+			NodeInfo: corev1.NodeSystemInfo{Architecture: arch},
+			Images:   images,
+		},
+	}
+}
+
+func newInjectorTestPod(name, node string, images ...string) *corev1.Pod {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: name}, Spec: corev1.PodSpec{NodeName: node}}
+	for _, image := range images {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: name, Image: image})
+	}
+	return pod
+}
+
+func TestGetInjectionCandidates(t *testing.T) {
+	t.Parallel()
+
+	tainted := newInjectorTestNode("control-plane", "amd64")
+	tainted.Spec.Taints = []corev1.Taint{{Key: "node-role.kubernetes.io/control-plane", Effect: corev1.TaintEffectNoSchedule}}
+	cordoned := newInjectorTestNode("cordoned", "amd64")
+	cordoned.Spec.Unschedulable = true
+	small := newInjectorTestNode("small", "amd64")
+	small.Status.Allocatable[corev1.ResourceMemory] = resource.MustParse("32Mi")
+
+	c := &Cluster{
+		K8s: &k8s.K8s{
+			Clientset: fake.NewSimpleClientset(
+				tainted, cordoned, small,
+				newInjectorTestNode("arm", "arm64"),
+				newInjectorTestNode("worker-a", "amd64",
+					corev1.ContainerImage{Names: []string{"docker.io/library/postgres:16"}, SizeBytes: 400 << 20},
+					corev1.ContainerImage{Names: []string{"registry.k8s.io/pause:3.9"}, SizeBytes: 1 << 20},
+				),
+				newInjectorTestNode("worker-b", "amd64",
+					corev1.ContainerImage{Names: []string{"docker.io/library/postgres:16"}, SizeBytes: 400 << 20},
+				),
+				newInjectorTestPod("etcd", "control-plane", "registry.k8s.io/etcd:3.5.9"),
+				newInjectorTestPod("coredns", "arm", "registry.k8s.io/coredns/coredns:v1.11.1"),
+				newInjectorTestPod("database", "worker-a", "postgres:16", "registry.k8s.io/pause:3.9"),
+				newInjectorTestPod("database-replica", "worker-b", "postgres:16", "127.0.0.1:31999/library/registry:2.8.0"),
+				newInjectorTestPod("app", "worker-b", "ghcr.io/example/app:1.0.0"),
+				newInjectorTestPod("pending", "", "ghcr.io/example/pending:1.0.0"),
+			),
+			Log: func(string, ...interface{}) {},
+		},
+	}
+
+	candidates, report, err := c.getInjectionCandidates(time.Second, "amd64")
+	require.NoError(t, err)
+
+	// The best candidate on each node comes first, with cached and smaller images preferred
+	ordered := []string{}
+	for _, candidate := range candidates {
+		ordered = append(ordered, candidate.image+"@"+candidate.node)
+	}
+	require.Equal(t, []string{
+		"registry.k8s.io/pause:3.9@worker-a",
+		"postgres:16@worker-b",
+		"postgres:16@worker-a",
+		"ghcr.io/example/app:1.0.0@worker-b",
+	}, ordered)
+	require.True(t, candidates[1].cached)
+	require.False(t, candidates[3].cached)
+
+	reasons := map[string]string{}
+	for _, failure := range report {
+		reasons[failure.node] = failure.reason
+	}
+	require.Equal(t, map[string]string{
+		"control-plane": injectionReasonTaint,
+		"cordoned":      injectionReasonCordoned,
+		"small":         injectionReasonResources,
+		"arm":           injectionReasonNodeArch,
+	}, reasons)
+	require.Contains(t, report.String(), "node control-plane: taint (the node has the taint node-role.kubernetes.io/control-plane:NoSchedule)")
+}
+
+func TestDiagnoseInjectorPod(t *testing.T) {
+	t.Parallel()
+
+	withState := func(state corev1.ContainerState) *corev1.Pod {
+		return &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodPending, ContainerStatuses: []corev1.ContainerStatus{{State: state}}}}
+	}
+
+	tests := []struct {
+		name   string
+		pod    *corev1.Pod
+		reason string
+	}{
+		{
+			name: "starting",
+			pod:  withState(corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}}),
+		},
+		{
+			name:   "not cached and not pullable",
+			pod:    withState(corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image"}}),
+			reason: injectionReasonPullPolicy,
+		},
+		{
+			name:   "wrong platform",
+			pod:    withState(corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ErrImagePull", Message: "no match for platform in manifest"}}),
+			reason: injectionReasonImageArch,
+		},
+		{
+			name:   "exec format error",
+			pod:    withState(corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Reason: "Error", Message: "exec /zarf-init/zarf-injector: exec format error"}}),
+			reason: injectionReasonImageArch,
+		},
+		{
+			name:   "run as non root",
+			pod:    withState(corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CreateContainerConfigError", Message: "container has runAsNonRoot and image will run as root"}}),
+			reason: injectionReasonContainer,
+		},
+		{
+			name:   "rejected by the kubelet",
+			pod:    &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodFailed, Reason: "OutOfmemory", Message: "Node didn't have enough resource: memory"}},
+			reason: injectionReasonNodeAdmission,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			reason, _, failed := diagnoseInjectorPod(tt.pod)
+			require.Equal(t, tt.reason != "", failed)
+			require.Equal(t, tt.reason, reason)
+		})
+	}
+}
+
+func TestRunInjectionAttempts(t *testing.T) {
+	t.Parallel()
+
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		pod := action.(k8stesting.CreateAction).GetObject().(*corev1.Pod)
+		switch pod.Spec.Containers[0].Image {
+		case "restricted":
+			return true, nil, errors.NewForbidden(schema.GroupResource{Resource: "pods"}, pod.Name, nil)
+		case "missing":
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ErrImageNeverPull"}}}}
+		case "arm64":
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 255, Message: "exec format error"}}}}
+		}
+		return false, nil, nil
+	})
+
+	c := &Cluster{K8s: &k8s.K8s{Clientset: clientset, Log: func(string, ...interface{}) {}}}
+
+	candidates := []injectionCandidate{
+		{image: "restricted", node: "worker-a"},
+		{image: "missing", node: "worker-b"},
+		{image: "arm64", node: "worker-a"},
+		{image: "missing", node: "worker-a"},
+	}
+	winner, report := c.runInjectionAttempts(candidates, nil, "", nil, message.NewProgressSpinner("Attempting"))
+	require.Nil(t, winner)
+	require.Len(t, report, len(candidates))
+
+	reasons := map[string]string{}
+	for _, failure := range report {
+		reasons[failure.image+"@"+failure.node] = failure.reason
+	}
+	require.Equal(t, map[string]string{
+		"restricted@worker-a": injectionReasonSecurityPolicy,
+		"missing@worker-b":    injectionReasonPullPolicy,
+		"arm64@worker-a":      injectionReasonImageArch,
+		"missing@worker-a":    injectionReasonPullPolicy,
+	}, reasons)
+
+	// Failed attempts are cleaned up
+	pods, err := clientset.CoreV1().Pods(JackalNamespaceName).List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Empty(t, pods.Items)
+}
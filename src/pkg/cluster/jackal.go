@@ -132,6 +132,11 @@ func (c *Cluster) RecordPackageDeploymentAndWait(pkg types.JackalPackage, compon
 		return nil, err
 	}
 
+	return c.WaitForPackageWebhooks(deployedPackage, component, skipWebhooks)
+}
+
+// WaitForPackageWebhooks waits for the webhooks running against a component of a recorded package deployment to complete.
+func (c *Cluster) WaitForPackageWebhooks(deployedPackage *types.DeployedPackage, component types.JackalComponent, skipWebhooks bool) (_ *types.DeployedPackage, err error) {
 	packageNeedsWait, waitSeconds, hookName := c.PackageSecretNeedsWait(deployedPackage, component, skipWebhooks)
 	// If no webhooks need to complete, we can return immediately.
 	if !packageNeedsWait {
@@ -218,7 +223,7 @@ func (c *Cluster) RecordPackageDeployment(pkg types.JackalPackage, components []
 
 // EnableRegHPAScaleDown enables the HPA scale down for the Jackal Registry.
 func (c *Cluster) EnableRegHPAScaleDown() error {
-	hpa, err := c.GetHPA(JackalNamespaceName, "jackal-docker-registry")
+	hpa, err := c.GetHPA(JackalNamespaceName, types.JackalInClusterContainerRegistryName)
 	if err != nil {
 		return err
 	}
@@ -237,7 +242,7 @@ func (c *Cluster) EnableRegHPAScaleDown() error {
 
 // DisableRegHPAScaleDown disables the HPA scale down for the Jackal Registry.
 func (c *Cluster) DisableRegHPAScaleDown() error {
-	hpa, err := c.GetHPA(JackalNamespaceName, "jackal-docker-registry")
+	hpa, err := c.GetHPA(JackalNamespaceName, types.JackalInClusterContainerRegistryName)
 	if err != nil {
 		return err
 	}
@@ -254,6 +259,32 @@ func (c *Cluster) DisableRegHPAScaleDown() error {
 	return nil
 }
 
+// GarbageCollectRegistry runs the garbage collection of the Jackal Registry to delete the blobs that no manifest references, returning its output.
+//
+// Blobs uploaded while the garbage collection runs may be deleted, so it should not be run during a deployment.
+func (c *Cluster) GarbageCollectRegistry(ctx context.Context) (string, error) {
+	pods, err := c.Clientset.CoreV1().Pods(JackalNamespaceName).List(ctx, metav1.ListOptions{LabelSelector: "app=docker-registry,release=jackal-docker-registry"})
+	if err != nil {
+		return "", err
+	}
+
+	// The registry replicas share their storage so running the garbage collection in one of them is enough
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+
+		var stdout, stderr strings.Builder
+		command := []string{"/bin/registry", "garbage-collect", "/etc/docker/registry/config.yml"}
+		if err := c.ExecInPod(ctx, JackalNamespaceName, pod.Name, "docker-registry", command, nil, &stdout, &stderr); err != nil {
+			return stdout.String(), fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return stdout.String(), nil
+	}
+
+	return "", fmt.Errorf("no running Jackal registry pods were found")
+}
+
 // GetInstalledChartsForComponent returns any installed Helm Charts for the provided package component.
 func (c *Cluster) GetInstalledChartsForComponent(packageName string, component types.JackalComponent) (installedCharts []types.InstalledChart, err error) {
 	deployedPackage, err := c.GetDeployedPackage(packageName)
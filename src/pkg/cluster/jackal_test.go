@@ -7,6 +7,7 @@ package cluster
 import (
 	"testing"
 
+	"github.com/racer159/jackal/src/pkg/utils"
 	"github.com/racer159/jackal/src/types"
 	"github.com/stretchr/testify/require"
 )
@@ -191,3 +192,24 @@ func TestPackageSecretNeedsWait(t *testing.T) {
 		})
 	}
 }
+
+// TestInClusterRegistryAddress verifies that the in-cluster registry address matches the service the init package deploys the registry with.
+func TestInClusterRegistryAddress(t *testing.T) {
+	t.Parallel()
+
+	registryValues := struct {
+		FullnameOverride string `json:"fullnameOverride"`
+	}{}
+	require.NoError(t, utils.ReadYaml("../../../packages/jackal-registry/registry-values.yaml", &registryValues))
+	require.Equal(t, types.JackalInClusterContainerRegistryName, registryValues.FullnameOverride)
+
+	chartValues := struct {
+		Service struct {
+			Port int `json:"port"`
+		} `json:"service"`
+	}{}
+	require.NoError(t, utils.ReadYaml("../../../packages/jackal-registry/chart/values.yaml", &chartValues))
+	require.Equal(t, types.JackalInClusterContainerRegistryPort, chartValues.Service.Port)
+
+	require.Equal(t, "jackal-docker-registry."+JackalNamespaceName+".svc.cluster.local:5000", types.JackalInClusterContainerRegistryAddress)
+}
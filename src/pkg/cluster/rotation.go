@@ -0,0 +1,197 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package cluster contains Jackal-specific cluster management functions.
+package cluster
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/racer159/jackal/src/config"
+	"github.com/racer159/jackal/src/pkg/message"
+	"github.com/racer159/jackal/src/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+// RestartedAtAnnotation is the pod template annotation that is changed to roll the pods of a workload (the same one kubectl rollout restart sets).
+const RestartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// VerifyRegistryCredentials checks that the registry accepts both the push and the pull credentials.
+func (c *Cluster) VerifyRegistryCredentials(registryInfo types.RegistryInfo) error {
+	registryEndpoint, tunnel, err := c.ConnectToJackalRegistryEndpoint(registryInfo)
+	if err != nil {
+		return err
+	}
+	if tunnel != nil {
+		defer tunnel.Close()
+	}
+
+	registry, err := name.NewRegistry(registryEndpoint)
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("%s://%s/v2/", registry.Scheme(), registry.RegistryStr())
+
+	httpTransport := http.DefaultTransport.(*http.Transport).Clone()
+	httpTransport.TLSClientConfig = &tls.Config{InsecureSkipVerify: config.CommonOptions.Insecure}
+	client := &http.Client{Transport: httpTransport, Timeout: 20 * time.Second}
+
+	credentials := [][2]string{
+		{registryInfo.PushUsername, registryInfo.PushPassword},
+		{registryInfo.PullUsername, registryInfo.PullPassword},
+	}
+	for _, credential := range credentials {
+		request, err := http.NewRequest(http.MethodGet, endpoint, nil)
+		if err != nil {
+			return err
+		}
+		request.SetBasicAuth(credential[0], credential[1])
+
+		verify := func() error {
+			response, err := client.Do(request)
+			if err != nil {
+				return err
+			}
+			defer response.Body.Close()
+			if response.StatusCode != http.StatusOK {
+				return fmt.Errorf("the registry rejected the credentials of %s with status code %d", credential[0], response.StatusCode)
+			}
+			return nil
+		}
+		if tunnel != nil {
+			err = tunnel.Wrap(verify)
+		} else {
+			err = verify()
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RestartWorkloadsUsingSecrets rolls the pods of every deployment, statefulset and daemonset that uses one of the given Jackal-managed secrets,
+// returning the number of workloads that were restarted.
+//
+// Only namespaces where the secret is kept up to date by Jackal are considered, matching UpdateJackalManagedImageSecrets and UpdateJackalManagedGitSecrets.
+func (c *Cluster) RestartWorkloadsUsingSecrets(secretNames ...string) (int, error) {
+	ctx := context.TODO()
+
+	namespaces, err := c.GetNamespaces()
+	if err != nil {
+		return 0, err
+	}
+
+	patch := []byte(fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{%q:%q}}}}}`, RestartedAtAnnotation, time.Now().UTC().Format(time.RFC3339)))
+	restarted := 0
+	for _, namespace := range namespaces.Items {
+		managedSecrets := []string{}
+		for _, secretName := range secretNames {
+			secret, err := c.GetSecret(namespace.Name, secretName)
+			if err != nil {
+				continue
+			}
+			if secret.Labels[config.JackalManagedByLabel] == "jackal" ||
+				(namespace.Labels[agentLabel] != "skip" && namespace.Labels[agentLabel] != "ignore") {
+				managedSecrets = append(managedSecrets, secretName)
+			}
+		}
+		if len(managedSecrets) == 0 {
+			continue
+		}
+
+		deployments, err := c.Clientset.AppsV1().Deployments(namespace.Name).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return restarted, err
+		}
+		for _, deployment := range deployments.Items {
+			if !podSpecUsesSecrets(deployment.Spec.Template.Spec, managedSecrets) {
+				continue
+			}
+			message.Debugf("Restarting deployment %s/%s", namespace.Name, deployment.Name)
+			if _, err := c.Clientset.AppsV1().Deployments(namespace.Name).Patch(ctx, deployment.Name, k8stypes.StrategicMergePatchType, patch, metav1.PatchOptions{}); err != nil {
+				return restarted, fmt.Errorf("unable to restart deployment %s/%s: %w", namespace.Name, deployment.Name, err)
+			}
+			restarted++
+		}
+
+		statefulSets, err := c.Clientset.AppsV1().StatefulSets(namespace.Name).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return restarted, err
+		}
+		for _, statefulSet := range statefulSets.Items {
+			if !podSpecUsesSecrets(statefulSet.Spec.Template.Spec, managedSecrets) {
+				continue
+			}
+			message.Debugf("Restarting statefulset %s/%s", namespace.Name, statefulSet.Name)
+			if _, err := c.Clientset.AppsV1().StatefulSets(namespace.Name).Patch(ctx, statefulSet.Name, k8stypes.StrategicMergePatchType, patch, metav1.PatchOptions{}); err != nil {
+				return restarted, fmt.Errorf("unable to restart statefulset %s/%s: %w", namespace.Name, statefulSet.Name, err)
+			}
+			restarted++
+		}
+
+		daemonSets, err := c.Clientset.AppsV1().DaemonSets(namespace.Name).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return restarted, err
+		}
+		for _, daemonSet := range daemonSets.Items {
+			if !podSpecUsesSecrets(daemonSet.Spec.Template.Spec, managedSecrets) {
+				continue
+			}
+			message.Debugf("Restarting daemonset %s/%s", namespace.Name, daemonSet.Name)
+			if _, err := c.Clientset.AppsV1().DaemonSets(namespace.Name).Patch(ctx, daemonSet.Name, k8stypes.StrategicMergePatchType, patch, metav1.PatchOptions{}); err != nil {
+				return restarted, fmt.Errorf("unable to restart daemonset %s/%s: %w", namespace.Name, daemonSet.Name, err)
+			}
+			restarted++
+		}
+	}
+
+	return restarted, nil
+}
+
+// podSpecUsesSecrets returns whether a pod pulls images with, mounts or reads environment variables from one of the given secrets.
+func podSpecUsesSecrets(spec corev1.PodSpec, secretNames []string) bool {
+	for _, pullSecret := range spec.ImagePullSecrets {
+		if slices.Contains(secretNames, pullSecret.Name) {
+			return true
+		}
+	}
+
+	for _, volume := range spec.Volumes {
+		if volume.Secret != nil && slices.Contains(secretNames, volume.Secret.SecretName) {
+			return true
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.Secret != nil && slices.Contains(secretNames, source.Secret.Name) {
+					return true
+				}
+			}
+		}
+	}
+
+	containers := append(slices.Clone(spec.InitContainers), spec.Containers...)
+	for _, container := range containers {
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.SecretRef != nil && slices.Contains(secretNames, envFrom.SecretRef.Name) {
+				return true
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil && slices.Contains(secretNames, env.ValueFrom.SecretKeyRef.Name) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package cluster contains Jackal-specific cluster management functions.
+package cluster
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/racer159/jackal/src/config"
+	"github.com/racer159/jackal/src/pkg/k8s"
+	"github.com/racer159/jackal/src/types"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestVerifyRegistryCredentials(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if r.URL.Path != "/v2/" || !ok || password != username+"-password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := &Cluster{
+		K8s: &k8s.K8s{
+			Clientset: fake.NewSimpleClientset(),
+			Log:       func(string, ...interface{}) {},
+		},
+	}
+
+	registryInfo := types.RegistryInfo{
+		Address:      strings.TrimPrefix(srv.URL, "http://"),
+		PushUsername: "jackal-push",
+		PushPassword: "jackal-push-password",
+		PullUsername: "jackal-pull",
+		PullPassword: "jackal-pull-password",
+	}
+	require.NoError(t, c.VerifyRegistryCredentials(registryInfo))
+
+	registryInfo.PullPassword = "stale"
+	require.ErrorContains(t, c.VerifyRegistryCredentials(registryInfo), "the registry rejected the credentials of jackal-pull with status code 401")
+}
+
+func TestRestartWorkloadsUsingSecrets(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	namespace := func(name, agentMode string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{agentLabel: agentMode}}}
+	}
+	secret := func(namespace, name string, labels map[string]string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels}}
+	}
+	deployment := func(namespace, name string, spec corev1.PodSpec) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec:       appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: spec}},
+		}
+	}
+	pullSecretSpec := corev1.PodSpec{ImagePullSecrets: []corev1.LocalObjectReference{{Name: config.JackalImagePullSecretName}}}
+	gitSecretSpec := corev1.PodSpec{Containers: []corev1.Container{{
+		Name: "controller",
+		Env: []corev1.EnvVar{{
+			Name:      "GIT_PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: config.JackalGitServerSecretName}}},
+		}},
+	}}}
+
+	c := &Cluster{
+		K8s: &k8s.K8s{
+			Clientset: fake.NewSimpleClientset(
+				namespace("app", ""),
+				namespace("ignored", "ignore"),
+				namespace("managed", "ignore"),
+				secret("app", config.JackalImagePullSecretName, nil),
+				secret("app", config.JackalGitServerSecretName, nil),
+				secret("ignored", config.JackalImagePullSecretName, nil),
+				secret("managed", config.JackalImagePullSecretName, map[string]string{config.JackalManagedByLabel: "jackal"}),
+				deployment("app", "podinfo", pullSecretSpec),
+				deployment("app", "source-controller", gitSecretSpec),
+				deployment("app", "unrelated", corev1.PodSpec{}),
+				deployment("ignored", "podinfo", pullSecretSpec),
+				deployment("managed", "podinfo", pullSecretSpec),
+				&appsv1.StatefulSet{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "database"},
+					Spec:       appsv1.StatefulSetSpec{Template: corev1.PodTemplateSpec{Spec: pullSecretSpec}},
+				},
+			),
+			Log: func(string, ...interface{}) {},
+		},
+	}
+
+	restarted, err := c.RestartWorkloadsUsingSecrets(config.JackalImagePullSecretName)
+	require.NoError(t, err)
+	require.Equal(t, 3, restarted)
+
+	isRestarted := func(namespace, name string) bool {
+		deployment, err := c.Clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+		_, ok := deployment.Spec.Template.Annotations[RestartedAtAnnotation]
+		return ok
+	}
+	require.True(t, isRestarted("app", "podinfo"))
+	require.True(t, isRestarted("managed", "podinfo"))
+	require.False(t, isRestarted("app", "source-controller"))
+	require.False(t, isRestarted("app", "unrelated"))
+	require.False(t, isRestarted("ignored", "podinfo"))
+
+	statefulSet, err := c.Clientset.AppsV1().StatefulSets("app").Get(ctx, "database", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Contains(t, statefulSet.Spec.Template.Annotations, RestartedAtAnnotation)
+
+	restarted, err = c.RestartWorkloadsUsingSecrets(config.JackalGitServerSecretName)
+	require.NoError(t, err)
+	require.Equal(t, 1, restarted)
+	require.True(t, isRestarted("app", "source-controller"))
+}
+
+func TestCredentialRotation(t *testing.T) {
+	t.Parallel()
+
+	var rotation types.CredentialRotation
+	rotatedAt := time.Now()
+	maxAge := 30 * 24 * time.Hour
+	require.True(t, rotation.IsDue("registry", 0, rotatedAt))
+
+	rotation.Record(rotatedAt, "registry", "git")
+	require.False(t, rotation.IsDue("registry", maxAge, rotatedAt.Add(24*time.Hour)))
+	require.True(t, rotation.IsDue("git", maxAge, rotatedAt.Add(maxAge)))
+	require.True(t, rotation.IsDue("artifact", maxAge, rotatedAt))
+}
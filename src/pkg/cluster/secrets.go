@@ -47,6 +47,13 @@ func (c *Cluster) GenerateRegistryPullCreds(namespace, name string, registryInfo
 		},
 	}
 
+	// In-cluster clients (e.g. Flux OCI sources) reach the internal registry through its service rather than the node port
+	if inClusterRegistry := registryInfo.InClusterAddress(); inClusterRegistry != registry {
+		dockerConfigJSON.Auths[inClusterRegistry] = DockerConfigEntryWithAuth{
+			Auth: authEncodedValue,
+		}
+	}
+
 	// Convert to JSON
 	dockerConfigData, err := json.Marshal(dockerConfigJSON)
 	if err != nil {
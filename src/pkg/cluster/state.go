@@ -5,7 +5,9 @@
 package cluster
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -17,6 +19,7 @@ import (
 	"github.com/racer159/jackal/src/types"
 
 	"github.com/defenseunicorns/pkg/helpers"
+	"github.com/racer159/jackal/src/pkg/credentials"
 	"github.com/racer159/jackal/src/pkg/k8s"
 	"github.com/racer159/jackal/src/pkg/message"
 	"github.com/racer159/jackal/src/pkg/pki"
@@ -33,6 +36,9 @@ const (
 	JackalInitPackageInfoName = "jackal-package-init"
 )
 
+// JackalCredentialStoreSecretName is the secret that holds the environment the Jackal components in the cluster use to reach the credential store.
+const JackalCredentialStoreSecretName = "jackal-credential-store"
+
 // InitJackalState initializes the Jackal state with the given temporary directory and init configs.
 func (c *Cluster) InitJackalState(initOptions types.JackalInitOptions) error {
 	var (
@@ -44,9 +50,13 @@ func (c *Cluster) InitJackalState(initOptions types.JackalInitOptions) error {
 	defer spinner.Stop()
 
 	// Attempt to load an existing state prior to init.
-	// NOTE: We are ignoring the error here because we don't really expect a state to exist yet.
+	// NOTE: We are ignoring most errors here because we don't really expect a state to exist yet.
 	spinner.Updatef("Checking cluster for existing Jackal deployment")
-	state, _ := c.LoadJackalState()
+	state, err := c.LoadJackalState()
+	if errors.Is(err, credentials.ErrUnresolved) {
+		// A state exists but its credentials are unavailable, continuing would overwrite them
+		return err
+	}
 
 	// If state is nil, this is a new cluster.
 	if state == nil {
@@ -125,6 +135,8 @@ func (c *Cluster) InitJackalState(initOptions types.JackalInitOptions) error {
 		state.RegistryInfo = initOptions.RegistryInfo
 		initOptions.ArtifactServer.FillInEmptyValues()
 		state.ArtifactServer = initOptions.ArtifactServer
+
+		state.CredentialRotation.Record(time.Now(), message.RegistryKey, message.GitKey, message.ArtifactKey, message.AgentKey)
 	} else {
 		if helpers.IsNotZeroAndNotEqual(initOptions.GitServer, state.GitServer) {
 			message.Warn("Detected a change in Git Server init options on a re-init. Ignoring... To update run:")
@@ -155,6 +167,33 @@ func (c *Cluster) InitJackalState(initOptions types.JackalInitOptions) error {
 		state.StorageClass = initOptions.StorageClass
 	}
 
+	if len(initOptions.AllowedRegistries) > 0 {
+		state.AgentPolicy.AllowedRegistries = initOptions.AllowedRegistries
+	}
+
+	// Changing the credential store moves the credentials on the next save
+	if initOptions.CredentialStore.Provider != "" {
+		// The agent and the credential rotation job resolve the credentials too, so the store must be reachable from within the cluster
+		env, err := credentials.ClusterEnv(initOptions.CredentialStore, initOptions.CredentialStoreToken)
+		if err != nil {
+			return fmt.Errorf("unable to use the %s credential store: %w", initOptions.CredentialStore.Provider, err)
+		}
+		if _, err := credentials.New(initOptions.CredentialStore); err != nil {
+			return fmt.Errorf("unable to use the %s credential store: %w", initOptions.CredentialStore.Provider, err)
+		}
+
+		spinner.Updatef("Saving the credential store configuration for the Jackal Agent")
+		secret := c.GenerateSecret(JackalNamespaceName, JackalCredentialStoreSecretName, corev1.SecretTypeOpaque)
+		for key, value := range env {
+			secret.Data[key] = []byte(value)
+		}
+		if _, err := c.CreateOrUpdateSecret(secret); err != nil {
+			return fmt.Errorf("unable to save the credential store configuration: %w", err)
+		}
+
+		state.CredentialStore = initOptions.CredentialStore
+	}
+
 	spinner.Success()
 
 	// Save the state back to K8s
@@ -178,6 +217,11 @@ func (c *Cluster) LoadJackalState() (state *types.JackalState, err error) {
 		return nil, err
 	}
 
+	// Replace any references to credentials held outside of the state with their values
+	if err := credentials.Resolve(context.TODO(), state); err != nil {
+		return nil, err
+	}
+
 	c.debugPrintJackalState(state)
 
 	return state, nil
@@ -204,6 +248,11 @@ func (c *Cluster) sanitizeJackalState(state *types.JackalState) *types.JackalSta
 	// Overwrite the Logging secret
 	state.LoggingSecret = "**sanitized**"
 
+	// Overwrite the credentials of a rotation in progress
+	if state.CredentialRotation.Pending != nil {
+		state.CredentialRotation.Pending = &types.PendingCredentials{Services: state.CredentialRotation.Pending.Services}
+	}
+
 	return state
 }
 
@@ -221,8 +270,14 @@ func (c *Cluster) debugPrintJackalState(state *types.JackalState) {
 func (c *Cluster) SaveJackalState(state *types.JackalState) error {
 	c.debugPrintJackalState(state)
 
+	// Store the credentials outside of the state if it has a credential store.
+	stored, err := credentials.Externalize(context.TODO(), state)
+	if err != nil {
+		return fmt.Errorf("unable to store the Jackal state credentials: %w", err)
+	}
+
 	// Convert the data back to JSON.
-	data, err := json.Marshal(&state)
+	data, err := json.Marshal(&stored)
 	if err != nil {
 		return err
 	}
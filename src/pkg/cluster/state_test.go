@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package cluster contains Jackal-specific cluster management functions.
+package cluster
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/racer159/jackal/src/pkg/credentials"
+	"github.com/racer159/jackal/src/pkg/k8s"
+	"github.com/racer159/jackal/src/types"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestJackalStateCredentialStore(t *testing.T) {
+	c := &Cluster{
+		K8s: &k8s.K8s{
+			Clientset: fake.NewSimpleClientset(),
+			Log:       func(string, ...interface{}) {},
+		},
+	}
+
+	t.Setenv(credentials.FileKeyEnv, "correct horse battery staple")
+	state := &types.JackalState{
+		CredentialStore: types.CredentialStore{Provider: "file", Path: filepath.Join(t.TempDir(), "credentials.json")},
+		RegistryInfo:    types.RegistryInfo{PushUsername: "jackal-push", PushPassword: "push-value"},
+		AgentTLS:        k8s.GeneratedPKI{Key: []byte("key-value")},
+	}
+	require.NoError(t, c.SaveJackalState(state))
+
+	// The secret only holds references to the credentials
+	secret, err := c.GetSecret(JackalNamespaceName, JackalStateSecretName)
+	require.NoError(t, err)
+	require.Contains(t, string(secret.Data[JackalStateDataKey]), credentials.ReferencePrefix+"registry-push-password")
+	require.NotContains(t, string(secret.Data[JackalStateDataKey]), "push-value")
+
+	loaded, err := c.LoadJackalState()
+	require.NoError(t, err)
+	require.Equal(t, state, loaded)
+
+	// Init must not replace a state whose credentials cannot be resolved
+	t.Setenv(credentials.FileKeyEnv, "wrong")
+	_, err = c.LoadJackalState()
+	require.ErrorIs(t, err, credentials.ErrUnresolved)
+	require.ErrorIs(t, c.InitJackalState(types.JackalInitOptions{}), credentials.ErrUnresolved)
+}
+
+func TestInitJackalStateCredentialStore(t *testing.T) {
+	c := &Cluster{
+		K8s: &k8s.K8s{
+			Clientset: fake.NewSimpleClientset(),
+			Log:       func(string, ...interface{}) {},
+		},
+	}
+	require.NoError(t, c.SaveJackalState(&types.JackalState{RegistryInfo: types.RegistryInfo{PushPassword: "push-value"}}))
+
+	// Stores that the agent cannot reach from within the cluster are rejected
+	t.Setenv(credentials.FileKeyEnv, "correct horse battery staple")
+	err := c.InitJackalState(types.JackalInitOptions{
+		CredentialStore: types.CredentialStore{Provider: "file", Path: filepath.Join(t.TempDir(), "credentials.json")},
+	})
+	require.ErrorContains(t, err, "cannot be read by the Jackal Agent")
+
+	t.Setenv("VAULT_ADDR", "")
+	err = c.InitJackalState(types.JackalInitOptions{
+		CredentialStore: types.CredentialStore{Provider: "vault", Address: "https://vault.example.com"},
+	})
+	require.ErrorContains(t, err, "requires a token")
+
+	// Nothing was moved out of the state
+	_, err = c.GetSecret(JackalNamespaceName, JackalCredentialStoreSecretName)
+	require.Error(t, err)
+	loaded, err := c.LoadJackalState()
+	require.NoError(t, err)
+	require.Empty(t, loaded.CredentialStore)
+	require.Equal(t, "push-value", loaded.RegistryInfo.PushPassword)
+}
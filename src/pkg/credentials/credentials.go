@@ -0,0 +1,229 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package credentials contains the providers that keep the credentials of the Jackal state outside of the state itself.
+package credentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/racer159/jackal/src/types"
+)
+
+// ReferencePrefix marks a credential in the Jackal state that is a reference to a value held by the credential store.
+const ReferencePrefix = "jackal-credential://"
+
+// ErrUnresolved is returned when the credential references of a Jackal state cannot be resolved.
+var ErrUnresolved = errors.New("unable to resolve the credentials of the Jackal state")
+
+// Provider stores and retrieves credentials by key.
+type Provider interface {
+	// Get returns the value of a credential.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Put stores the value of a credential, replacing any previous value.
+	Put(ctx context.Context, key string, value []byte) error
+}
+
+// Constructor creates a Provider from the credential store of a Jackal state.
+type Constructor func(store types.CredentialStore) (Provider, error)
+
+// builtinProviders are the providers included with Jackal and cannot be registered.
+var builtinProviders = map[string]Constructor{
+	"file":  NewFileProvider,
+	"vault": NewVaultProvider,
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Constructor{}
+)
+
+// Register registers a Provider constructor for the given provider name.
+//
+// Registering a provider that is already registered replaces the previous constructor, built-in providers cannot be overridden.
+func Register(name string, constructor Constructor) error {
+	name = strings.ToLower(name)
+	if name == "" {
+		return fmt.Errorf("a name is required to register a credential provider")
+	}
+	if constructor == nil {
+		return fmt.Errorf("a constructor is required to register a credential provider for %q", name)
+	}
+	if _, ok := builtinProviders[name]; ok {
+		return fmt.Errorf("unable to register credential provider for built-in provider %q", name)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = constructor
+
+	return nil
+}
+
+// Unregister removes a previously registered Provider constructor.
+func Unregister(name string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	delete(registry, strings.ToLower(name))
+}
+
+// New returns the Provider for a credential store.
+func New(store types.CredentialStore) (Provider, error) {
+	name := strings.ToLower(store.Provider)
+	if constructor, ok := builtinProviders[name]; ok {
+		return constructor(store)
+	}
+
+	registryMu.RLock()
+	constructor, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		names := []string{}
+		for name := range builtinProviders {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		return nil, fmt.Errorf("unknown credential provider %q, valid providers include: %s", store.Provider, strings.Join(names, ", "))
+	}
+	return constructor(store)
+}
+
+// ClusterEnv returns the environment that the Jackal components running in the cluster (the agent and the credential rotation job) need to reach a credential store, authenticating with the given token.
+//
+// Only stores that can be reached over the network from within the cluster are supported, a file store is local to the machine that ran init
+// and a registered provider is not built into the agent.
+func ClusterEnv(store types.CredentialStore, token string) (map[string]string, error) {
+	switch strings.ToLower(store.Provider) {
+	case "vault":
+		return vaultClusterEnv(store, token)
+	case "file":
+		return nil, fmt.Errorf("the file credential store cannot be read by the Jackal Agent or the credential rotation job in the cluster")
+	default:
+		return nil, fmt.Errorf("the %q credential provider is not available to the Jackal Agent or the credential rotation job in the cluster", store.Provider)
+	}
+}
+
+// stringCredentials returns the string credentials of a state by their key.
+func stringCredentials(state *types.JackalState) map[string]*string {
+	stringCredentials := map[string]*string{
+		"registry-push-password": &state.RegistryInfo.PushPassword,
+		"registry-pull-password": &state.RegistryInfo.PullPassword,
+		"registry-secret":        &state.RegistryInfo.Secret,
+		"git-push-password":      &state.GitServer.PushPassword,
+		"git-pull-password":      &state.GitServer.PullPassword,
+		"artifact-push-token":    &state.ArtifactServer.PushToken,
+		"logging-secret":         &state.LoggingSecret,
+	}
+
+	// The credentials of a rotation in progress are kept in the store alongside the current ones
+	if pending := state.CredentialRotation.Pending; pending != nil {
+		stringCredentials["pending-registry-push-password"] = &pending.RegistryInfo.PushPassword
+		stringCredentials["pending-registry-pull-password"] = &pending.RegistryInfo.PullPassword
+		stringCredentials["pending-registry-secret"] = &pending.RegistryInfo.Secret
+		stringCredentials["pending-git-push-password"] = &pending.GitServer.PushPassword
+		stringCredentials["pending-git-pull-password"] = &pending.GitServer.PullPassword
+		stringCredentials["pending-artifact-push-token"] = &pending.ArtifactServer.PushToken
+	}
+
+	return stringCredentials
+}
+
+// bytesCredentials returns the byte credentials of a state by their key.
+func bytesCredentials(state *types.JackalState) map[string]*[]byte {
+	return map[string]*[]byte{
+		"agent-tls-ca":   &state.AgentTLS.CA,
+		"agent-tls-cert": &state.AgentTLS.Cert,
+		"agent-tls-key":  &state.AgentTLS.Key,
+	}
+}
+
+// Externalize stores the credentials of a state in its credential store and returns a copy of the state that references them instead.
+//
+// The state is returned as it is if it has no credential store, and empty credentials are left empty.
+func Externalize(ctx context.Context, state *types.JackalState) (*types.JackalState, error) {
+	if state.CredentialStore.Provider == "" {
+		return state, nil
+	}
+
+	provider, err := New(state.CredentialStore)
+	if err != nil {
+		return nil, err
+	}
+
+	// This is a shallow copy, so every credential that is replaced must be reassigned rather than modified in place
+	stored := *state
+	if pending := state.CredentialRotation.Pending; pending != nil {
+		storedPending := *pending
+		stored.CredentialRotation.Pending = &storedPending
+	}
+	for key, value := range stringCredentials(&stored) {
+		if *value == "" || strings.HasPrefix(*value, ReferencePrefix) {
+			continue
+		}
+		if err := provider.Put(ctx, key, []byte(*value)); err != nil {
+			return nil, fmt.Errorf("unable to store the %s credential: %w", key, err)
+		}
+		*value = ReferencePrefix + key
+	}
+	for key, value := range bytesCredentials(&stored) {
+		if len(*value) == 0 || strings.HasPrefix(string(*value), ReferencePrefix) {
+			continue
+		}
+		if err := provider.Put(ctx, key, *value); err != nil {
+			return nil, fmt.Errorf("unable to store the %s credential: %w", key, err)
+		}
+		*value = []byte(ReferencePrefix + key)
+	}
+
+	return &stored, nil
+}
+
+// Resolve replaces the credential references of a state with their values from its credential store.
+func Resolve(ctx context.Context, state *types.JackalState) error {
+	if state.CredentialStore.Provider == "" {
+		return nil
+	}
+
+	provider, err := New(state.CredentialStore)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUnresolved, err)
+	}
+
+	get := func(reference string) ([]byte, error) {
+		key := strings.TrimPrefix(reference, ReferencePrefix)
+		value, err := provider.Get(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to get the %s credential: %w", ErrUnresolved, key, err)
+		}
+		return value, nil
+	}
+
+	for _, value := range stringCredentials(state) {
+		if !strings.HasPrefix(*value, ReferencePrefix) {
+			continue
+		}
+		resolved, err := get(*value)
+		if err != nil {
+			return err
+		}
+		*value = string(resolved)
+	}
+	for _, value := range bytesCredentials(state) {
+		if !strings.HasPrefix(string(*value), ReferencePrefix) {
+			continue
+		}
+		resolved, err := get(string(*value))
+		if err != nil {
+			return err
+		}
+		*value = resolved
+	}
+
+	return nil
+}
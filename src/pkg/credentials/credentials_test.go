@@ -0,0 +1,154 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package credentials contains the providers that keep the credentials of the Jackal state outside of the state itself.
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/racer159/jackal/src/pkg/k8s"
+	"github.com/racer159/jackal/src/types"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestState(store types.CredentialStore) *types.JackalState {
+	return &types.JackalState{
+		CredentialStore: store,
+		GitServer:       types.GitServerInfo{PushUsername: "jackal-git-user", PushPassword: "git-push-value", PullPassword: "git-pull-value"},
+		RegistryInfo:    types.RegistryInfo{PushPassword: "registry-push-value", PullPassword: "registry-pull-value", Secret: "registry-secret-value"},
+		ArtifactServer:  types.ArtifactServerInfo{PushToken: ""},
+		LoggingSecret:   "logging-value",
+		AgentTLS:        k8s.GeneratedPKI{CA: []byte("ca"), Cert: []byte("cert"), Key: []byte("key")},
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	store := types.CredentialStore{Provider: "file", Path: path}
+
+	t.Setenv(FileKeyEnv, "")
+	_, err := New(store)
+	require.ErrorContains(t, err, FileKeyEnv)
+
+	t.Setenv(FileKeyEnv, "correct horse battery staple")
+	state := newTestState(store)
+	stored, err := Externalize(ctx, state)
+	require.NoError(t, err)
+
+	// The original state keeps its values while the stored copy only holds references
+	require.Equal(t, "registry-push-value", state.RegistryInfo.PushPassword)
+	require.Equal(t, ReferencePrefix+"registry-push-password", stored.RegistryInfo.PushPassword)
+	require.Equal(t, []byte(ReferencePrefix+"agent-tls-key"), stored.AgentTLS.Key)
+	require.Equal(t, "jackal-git-user", stored.GitServer.PushUsername)
+	require.Empty(t, stored.ArtifactServer.PushToken)
+
+	// The values are encrypted at rest
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	for _, value := range []string{"registry-push-value", "git-pull-value", "logging-value"} {
+		require.NotContains(t, string(b), value)
+	}
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// Round trip the state as it would be through the state secret
+	b, err = json.Marshal(stored)
+	require.NoError(t, err)
+	var loaded types.JackalState
+	require.NoError(t, json.Unmarshal(b, &loaded))
+	require.NoError(t, Resolve(ctx, &loaded))
+	require.Equal(t, *state, loaded)
+
+	// Externalizing an already resolved state again replaces the stored values
+	loaded.RegistryInfo.PushPassword = "rotated"
+	_, err = Externalize(ctx, &loaded)
+	require.NoError(t, err)
+	reloaded := *stored
+	require.NoError(t, Resolve(ctx, &reloaded))
+	require.Equal(t, "rotated", reloaded.RegistryInfo.PushPassword)
+
+	t.Setenv(FileKeyEnv, "wrong")
+	unresolved := *stored
+	require.ErrorIs(t, Resolve(ctx, &unresolved), ErrUnresolved)
+}
+
+func TestExternalizeWithoutStore(t *testing.T) {
+	t.Parallel()
+
+	state := newTestState(types.CredentialStore{})
+	stored, err := Externalize(context.Background(), state)
+	require.NoError(t, err)
+	require.Same(t, state, stored)
+	require.NoError(t, Resolve(context.Background(), state))
+}
+
+type memoryProvider map[string][]byte
+
+func (mp memoryProvider) Get(_ context.Context, key string) ([]byte, error) {
+	return mp[key], nil
+}
+
+func (mp memoryProvider) Put(_ context.Context, key string, value []byte) error {
+	mp[key] = value
+	return nil
+}
+
+func TestRegister(t *testing.T) {
+	t.Parallel()
+
+	require.Error(t, Register("vault", NewVaultProvider))
+	require.Error(t, Register("", NewFileProvider))
+	require.Error(t, Register("memory", nil))
+
+	_, err := New(types.CredentialStore{Provider: "memory"})
+	require.ErrorContains(t, err, "valid providers include: file, vault")
+
+	memory := memoryProvider{}
+	require.NoError(t, Register("Memory", func(types.CredentialStore) (Provider, error) { return memory, nil }))
+	t.Cleanup(func() { Unregister("memory") })
+
+	state := newTestState(types.CredentialStore{Provider: "memory"})
+	stored, err := Externalize(context.Background(), state)
+	require.NoError(t, err)
+	require.Equal(t, []byte("git-push-value"), memory["git-push-password"])
+	require.True(t, strings.HasPrefix(stored.GitServer.PushPassword, ReferencePrefix))
+	require.NoError(t, Resolve(context.Background(), stored))
+	require.Equal(t, *state, *stored)
+}
+
+func TestExternalizePendingCredentials(t *testing.T) {
+	t.Parallel()
+
+	memory := memoryProvider{}
+	require.NoError(t, Register("pending-memory", func(types.CredentialStore) (Provider, error) { return memory, nil }))
+	t.Cleanup(func() { Unregister("pending-memory") })
+
+	state := newTestState(types.CredentialStore{Provider: "pending-memory"})
+	state.CredentialRotation.Pending = &types.PendingCredentials{
+		Services:     []string{"registry"},
+		RegistryInfo: types.RegistryInfo{PushPassword: "new-push-value", PullPassword: "new-pull-value"},
+	}
+	stored, err := Externalize(context.Background(), state)
+	require.NoError(t, err)
+
+	// The pending credentials are stored like the current ones without touching the original state
+	require.Equal(t, []byte("new-push-value"), memory["pending-registry-push-password"])
+	require.Equal(t, ReferencePrefix+"pending-registry-push-password", stored.CredentialRotation.Pending.RegistryInfo.PushPassword)
+	require.Equal(t, "new-push-value", state.CredentialRotation.Pending.RegistryInfo.PushPassword)
+
+	b, err := json.Marshal(stored)
+	require.NoError(t, err)
+	require.NotContains(t, string(b), "new-push-value")
+	var loaded types.JackalState
+	require.NoError(t, json.Unmarshal(b, &loaded))
+	require.NoError(t, Resolve(context.Background(), &loaded))
+	require.Equal(t, *state, loaded)
+}
@@ -0,0 +1,184 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package credentials contains the providers that keep the credentials of the Jackal state outside of the state itself.
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/defenseunicorns/pkg/helpers"
+	"github.com/racer159/jackal/src/types"
+	"golang.org/x/crypto/scrypt"
+)
+
+// FileKeyEnv is the environment variable that holds the passphrase the file provider encrypts credentials with.
+const FileKeyEnv = "JACKAL_CREDENTIAL_STORE_KEY"
+
+// fileStore is the content of the file that the file provider keeps credentials in.
+type fileStore struct {
+	Salt        []byte            `json:"salt"`
+	Credentials map[string][]byte `json:"credentials"`
+}
+
+// FileProvider keeps credentials in a local file, encrypted with AES-256-GCM using a key derived from a passphrase.
+type FileProvider struct {
+	path       string
+	passphrase []byte
+	mu         sync.Mutex
+
+	// The derived key is cached by salt as deriving it is deliberately slow
+	salt   []byte
+	cipher cipher.AEAD
+}
+
+// NewFileProvider creates a FileProvider for the file at the path of the store, with the passphrase in JACKAL_CREDENTIAL_STORE_KEY.
+func NewFileProvider(store types.CredentialStore) (Provider, error) {
+	if store.Path == "" {
+		return nil, fmt.Errorf("the file credential provider requires a path")
+	}
+	passphrase := os.Getenv(FileKeyEnv)
+	if passphrase == "" {
+		return nil, fmt.Errorf("the file credential provider requires a passphrase in %s", FileKeyEnv)
+	}
+	return &FileProvider{path: store.Path, passphrase: []byte(passphrase)}, nil
+}
+
+// Get returns the decrypted value of a credential.
+func (fp *FileProvider) Get(_ context.Context, key string) ([]byte, error) {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+
+	store, err := fp.read()
+	if err != nil {
+		return nil, err
+	}
+	sealed, ok := store.Credentials[key]
+	if !ok {
+		return nil, fmt.Errorf("credential %s not found in %s", key, fp.path)
+	}
+
+	aead, err := fp.aead(store.Salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("credential %s in %s is malformed", key, fp.path)
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	// The key is authenticated alongside the value so that values cannot be swapped between keys
+	value, err := aead.Open(nil, nonce, ciphertext, []byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt credential %s, check %s: %w", key, FileKeyEnv, err)
+	}
+	return value, nil
+}
+
+// Put encrypts and stores the value of a credential.
+func (fp *FileProvider) Put(_ context.Context, key string, value []byte) error {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+
+	store, err := fp.read()
+	if err != nil {
+		return err
+	}
+
+	aead, err := fp.aead(store.Salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	store.Credentials[key] = aead.Seal(nonce, nonce, value, []byte(key))
+
+	return fp.write(store)
+}
+
+// read returns the content of the credential file, or a new store with a random salt if the file does not exist yet.
+func (fp *FileProvider) read() (*fileStore, error) {
+	b, err := os.ReadFile(fp.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		store := &fileStore{Salt: make([]byte, 16), Credentials: map[string][]byte{}}
+		if _, err := rand.Read(store.Salt); err != nil {
+			return nil, err
+		}
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var store fileStore
+	if err := json.Unmarshal(b, &store); err != nil {
+		return nil, fmt.Errorf("unable to read the credential file %s: %w", fp.path, err)
+	}
+	if store.Credentials == nil {
+		store.Credentials = map[string][]byte{}
+	}
+	return &store, nil
+}
+
+// write atomically replaces the credential file, readable only by the current user.
+func (fp *FileProvider) write(store *fileStore) error {
+	b, err := json.Marshal(store)
+	if err != nil {
+		return err
+	}
+	if err := helpers.CreateDirectory(filepath.Dir(fp.path), helpers.ReadWriteExecuteUser); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(fp.path), filepath.Base(fp.path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), helpers.ReadWriteUser); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), fp.path)
+}
+
+// aead returns the cipher for the passphrase and salt of the credential file.
+func (fp *FileProvider) aead(salt []byte) (cipher.AEAD, error) {
+	if fp.cipher != nil && bytes.Equal(fp.salt, salt) {
+		return fp.cipher, nil
+	}
+
+	key, err := scrypt.Key(fp.passphrase, salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	fp.salt = salt
+	fp.cipher = aead
+	return aead, nil
+}
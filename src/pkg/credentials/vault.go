@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package credentials contains the providers that keep the credentials of the Jackal state outside of the state itself.
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	vault "github.com/hashicorp/vault/api"
+	"github.com/racer159/jackal/src/types"
+)
+
+// DefaultVaultPath is the KV v2 mount and path the vault provider uses if the store has no path.
+const DefaultVaultPath = "secret/jackal"
+
+// vaultValueField is the field of each Vault secret that holds the credential.
+const vaultValueField = "value"
+
+// VaultProvider keeps each credential as a secret in a Vault KV v2 secrets engine.
+//
+// The client is configured from the standard Vault environment variables (e.g. VAULT_TOKEN, VAULT_NAMESPACE and VAULT_CACERT).
+type VaultProvider struct {
+	kv     *vault.KVv2
+	prefix string
+}
+
+// NewVaultProvider creates a VaultProvider for the server at the address of the store (or VAULT_ADDR), keeping credentials under the KV v2 mount and path of the store.
+func NewVaultProvider(store types.CredentialStore) (Provider, error) {
+	config := vault.DefaultConfig()
+	if config.Error != nil {
+		return nil, config.Error
+	}
+	if store.Address != "" {
+		config.Address = store.Address
+	}
+
+	client, err := vault.NewClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the Vault client: %w", err)
+	}
+	if client.Token() == "" {
+		return nil, fmt.Errorf("the vault credential provider requires a token in VAULT_TOKEN")
+	}
+
+	storePath := strings.Trim(store.Path, "/")
+	if storePath == "" {
+		storePath = DefaultVaultPath
+	}
+	mount, prefix, _ := strings.Cut(storePath, "/")
+
+	return &VaultProvider{kv: client.KVv2(mount), prefix: prefix}, nil
+}
+
+// vaultClusterEnv returns the Vault environment for the Jackal components in the cluster.
+//
+// The address must be reachable from within the cluster and the token needs to be able to read (and, for credential rotation, write) the store path.
+func vaultClusterEnv(store types.CredentialStore, token string) (map[string]string, error) {
+	address := store.Address
+	if address == "" {
+		address = os.Getenv(vault.EnvVaultAddress)
+	}
+	if address == "" {
+		return nil, fmt.Errorf("the vault credential store requires an address that the cluster can reach")
+	}
+	if token == "" {
+		return nil, fmt.Errorf("the vault credential store requires a token for the Jackal components in the cluster")
+	}
+
+	env := map[string]string{
+		vault.EnvVaultAddress: address,
+		vault.EnvVaultToken:   token,
+	}
+	if namespace := os.Getenv(vault.EnvVaultNamespace); namespace != "" {
+		env[vault.EnvVaultNamespace] = namespace
+	}
+	return env, nil
+}
+
+// Get returns the value of a credential from its Vault secret.
+func (vp *VaultProvider) Get(ctx context.Context, key string) ([]byte, error) {
+	secret, err := vp.kv.Get(ctx, path.Join(vp.prefix, key))
+	if err != nil {
+		return nil, err
+	}
+	value, ok := secret.Data[vaultValueField].(string)
+	if !ok {
+		return nil, fmt.Errorf("the Vault secret for credential %s has no %q field", key, vaultValueField)
+	}
+	return []byte(value), nil
+}
+
+// Put writes the value of a credential as a new version of its Vault secret.
+func (vp *VaultProvider) Put(ctx context.Context, key string, value []byte) error {
+	_, err := vp.kv.Put(ctx, path.Join(vp.prefix, key), map[string]interface{}{vaultValueField: string(value)})
+	return err
+}
@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package credentials contains the providers that keep the credentials of the Jackal state outside of the state itself.
+package credentials
+
+import (
+	"context"
+	"testing"
+
+	"github.com/racer159/jackal/src/pkg/credentials/credentialstest"
+	"github.com/racer159/jackal/src/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultProvider(t *testing.T) {
+	ctx := context.Background()
+	srv, secrets := credentialstest.NewVaultKV(t, "root")
+	t.Setenv("VAULT_ADDR", "")
+
+	t.Setenv("VAULT_TOKEN", "")
+	_, err := New(types.CredentialStore{Provider: "vault", Address: srv.URL})
+	require.ErrorContains(t, err, "VAULT_TOKEN")
+
+	t.Setenv("VAULT_TOKEN", "root")
+	state := newTestState(types.CredentialStore{Provider: "vault", Address: srv.URL, Path: "/secret/clusters/edge/"})
+	stored, err := Externalize(ctx, state)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"value": "registry-pull-value"}, secrets["clusters/edge/registry-pull-password"])
+	require.Equal(t, ReferencePrefix+"registry-pull-password", stored.RegistryInfo.PullPassword)
+
+	resolved := *stored
+	require.NoError(t, Resolve(ctx, &resolved))
+	require.Equal(t, *state, resolved)
+
+	// The default path is used if the store has none
+	provider, err := New(types.CredentialStore{Provider: "vault", Address: srv.URL})
+	require.NoError(t, err)
+	require.NoError(t, provider.Put(ctx, "logging-secret", []byte("logging-value")))
+	require.Contains(t, secrets, "jackal/logging-secret")
+	_, err = provider.Get(ctx, "git-push-password")
+	require.Error(t, err)
+
+	t.Setenv("VAULT_TOKEN", "expired")
+	unresolved := *stored
+	require.ErrorIs(t, Resolve(ctx, &unresolved), ErrUnresolved)
+}
+
+func TestClusterEnv(t *testing.T) {
+	t.Setenv("VAULT_ADDR", "")
+	t.Setenv("VAULT_NAMESPACE", "")
+
+	_, err := ClusterEnv(types.CredentialStore{Provider: "file", Path: "credentials.json"}, "token")
+	require.ErrorContains(t, err, "cannot be read by the Jackal Agent")
+
+	_, err = ClusterEnv(types.CredentialStore{Provider: "custom"}, "token")
+	require.ErrorContains(t, err, "not available to the Jackal Agent")
+
+	_, err = ClusterEnv(types.CredentialStore{Provider: "vault"}, "token")
+	require.ErrorContains(t, err, "address")
+
+	_, err = ClusterEnv(types.CredentialStore{Provider: "vault", Address: "https://vault.example.com"}, "")
+	require.ErrorContains(t, err, "token")
+
+	env, err := ClusterEnv(types.CredentialStore{Provider: "vault", Address: "https://vault.example.com"}, "token")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"VAULT_ADDR": "https://vault.example.com", "VAULT_TOKEN": "token"}, env)
+
+	t.Setenv("VAULT_ADDR", "https://vault.internal")
+	t.Setenv("VAULT_NAMESPACE", "ops")
+	env, err = ClusterEnv(types.CredentialStore{Provider: "Vault"}, "token")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"VAULT_ADDR": "https://vault.internal", "VAULT_TOKEN": "token", "VAULT_NAMESPACE": "ops"}, env)
+}
@@ -7,7 +7,9 @@ package k8s
 import (
 	"context"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
@@ -81,3 +83,49 @@ func (k *K8s) updateLabelsAndAnnotations(resourceNamespace string, resourceName
 	_, err = dynamicClient.Resource(mapping.Resource).Namespace(resourceNamespace).Update(context.TODO(), deployedResource, metav1.UpdateOptions{})
 	return err
 }
+
+// ResourceClient gets resources of any kind from the cluster.
+type ResourceClient struct {
+	dynamicClient dynamic.Interface
+	mapper        meta.RESTMapper
+}
+
+// NewResourceClient discovers the resources served by the cluster and returns a client that can get any of them.
+func (k *K8s) NewResourceClient() (*ResourceClient, error) {
+	dynamicClient, err := dynamic.NewForConfig(k.RestConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(k.RestConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	groupResources, err := restmapper.GetAPIGroupResources(discoveryClient)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResourceClient{
+		dynamicClient: dynamicClient,
+		mapper:        restmapper.NewDiscoveryRESTMapper(groupResources),
+	}, nil
+}
+
+// Get returns the resource of the given kind and name, looking up namespaced resources without a namespace in the default namespace provided.
+func (r *ResourceClient) Get(gvk schema.GroupVersionKind, namespace, defaultNamespace, name string) (*unstructured.Unstructured, error) {
+	mapping, err := r.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return r.dynamicClient.Resource(mapping.Resource).Get(context.TODO(), name, metav1.GetOptions{})
+	}
+
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+	return r.dynamicClient.Resource(mapping.Resource).Namespace(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
@@ -9,6 +9,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/defenseunicorns/pkg/helpers"
 	"github.com/mholt/archiver/v3"
@@ -33,6 +34,9 @@ type Components struct {
 	Base     string
 	Dirs     map[string]*ComponentPaths
 	Tarballs map[string]string
+
+	// reproducible normalizes the permissions of archived components so that they do not depend on the umask they were created with.
+	reproducible bool
 }
 
 // ErrNotLoaded is returned when a path is not loaded.
@@ -65,7 +69,13 @@ func (c *Components) Archive(component types.JackalComponent, cleanupTemp bool)
 	if size > 0 {
 		tb := fmt.Sprintf("%s.tar", base)
 		message.Debugf("Archiving %q", name)
-		if err := helpers.CreateReproducibleTarballFromDir(base, name, tb); err != nil {
+		archive := helpers.CreateReproducibleTarballFromDir
+		if c.reproducible {
+			archive = func(dirPath, dirPrefix, tarballPath string) error {
+				return createReproducibleTarball(dirPath, dirPrefix, tarballPath, time.Time{})
+			}
+		}
+		if err := archive(base, name, tb); err != nil {
 			return err
 		}
 		if c.Tarballs == nil {
@@ -80,6 +90,35 @@ func (c *Components) Archive(component types.JackalComponent, cleanupTemp bool)
 	return os.RemoveAll(base)
 }
 
+// Reuse replaces a component directory with an existing tarball of the component, such as one from the build cache.
+func (c *Components) Reuse(component types.JackalComponent, tarball string) (err error) {
+	name := component.Name
+	if _, ok := c.Dirs[name]; !ok {
+		return &fs.PathError{
+			Op:   "check dir map for",
+			Path: name,
+			Err:  ErrNotLoaded,
+		}
+	}
+	base := c.Dirs[name].Base
+	tb := fmt.Sprintf("%s.tar", base)
+	message.Debugf("Reusing %q for %q", tarball, name)
+	// Hard link the tarball where possible, it is never written to once it has been created.
+	_ = os.Remove(tb)
+	if err := os.Link(tarball, tb); err != nil {
+		if err := helpers.CreatePathAndCopy(tarball, tb); err != nil {
+			return err
+		}
+	}
+	if c.Tarballs == nil {
+		c.Tarballs = make(map[string]string)
+	}
+	c.Tarballs[name] = tb
+
+	delete(c.Dirs, name)
+	return os.RemoveAll(base)
+}
+
 // Unarchive unarchives a component.
 func (c *Components) Unarchive(component types.JackalComponent) (err error) {
 	name := component.Name
@@ -16,9 +16,16 @@ const (
 	DataInjectionsDir = "data"
 	ValuesDir         = "values"
 
-	JackalYAML = "jackal.yaml"
-	Signature  = "jackal.yaml.sig"
-	Checksums  = "checksums.txt"
+	DataInjectionManifestFile = "jackal-data-manifest.json"
+
+	JackalYAML      = "jackal.yaml"
+	Signature       = "jackal.yaml.sig"
+	SignatureBundle = "jackal.yaml.bundle"
+	Checksums       = "checksums.txt"
+
+	Provenance                = "jackal.provenance.json"
+	ProvenanceSignature       = "jackal.provenance.json.sig"
+	ProvenanceSignatureBundle = "jackal.provenance.json.bundle"
 
 	ImagesDir     = "images"
 	ComponentsDir = "components"
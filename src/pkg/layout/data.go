@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package layout contains functions for interacting with Jackal's package layout on disk.
+package layout
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/defenseunicorns/pkg/helpers"
+)
+
+// DataInjectionFile is the content of a single file in a data injection.
+type DataInjectionFile struct {
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// DataInjectionManifest is the content of a data injection source, by the path of each regular file relative to the injection's target path.
+type DataInjectionManifest map[string]DataInjectionFile
+
+// DataInjectionName returns the path of a file within a data injection source relative to the injection's target path.
+//
+// A directory source is injected relative to itself, so its contents are placed directly in the target path, while a file source keeps its name.
+func DataInjectionName(source string, file string) (string, error) {
+	root := source
+	if !helpers.IsDir(source) {
+		root = filepath.Dir(source)
+	}
+	rel, err := filepath.Rel(root, file)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// NewDataInjectionManifest hashes every regular file in a data injection source.
+func NewDataInjectionManifest(source string) (DataInjectionManifest, error) {
+	manifest := make(DataInjectionManifest)
+	err := filepath.WalkDir(source, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		name, err := DataInjectionName(source, file)
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		hash := sha256.New()
+		size, err := io.Copy(hash, f)
+		if err != nil {
+			return err
+		}
+		manifest[name] = DataInjectionFile{SHA256: hex.EncodeToString(hash.Sum(nil)), Size: size}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// ReadDataInjectionManifest reads a data injection manifest written by WriteDataInjectionManifest.
+func ReadDataInjectionManifest(path string) (DataInjectionManifest, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var manifest DataInjectionManifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// WriteDataInjectionManifest writes a data injection manifest to the given path.
+func WriteDataInjectionManifest(path string, manifest DataInjectionManifest) error {
+	b, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, helpers.ReadWriteUser)
+}
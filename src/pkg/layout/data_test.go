@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package layout contains functions for interacting with Jackal's package layout on disk.
+package layout
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataInjectionManifest(t *testing.T) {
+	t.Parallel()
+
+	sha := func(s string) string {
+		sum := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+
+	source := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(source, "nested", "empty"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(source, "top.txt"), []byte("top"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(source, "nested", "inner.txt"), []byte("inner"), 0600))
+
+	// Directory sources are recorded relative to themselves and only regular files are recorded
+	manifest, err := NewDataInjectionManifest(source)
+	require.NoError(t, err)
+	expected := DataInjectionManifest{
+		"top.txt":          {SHA256: sha("top"), Size: 3},
+		"nested/inner.txt": {SHA256: sha("inner"), Size: 5},
+	}
+	require.Equal(t, expected, manifest)
+
+	// A file source is recorded by its name
+	manifest, err = NewDataInjectionManifest(filepath.Join(source, "nested", "inner.txt"))
+	require.NoError(t, err)
+	require.Equal(t, DataInjectionManifest{"inner.txt": {SHA256: sha("inner"), Size: 5}}, manifest)
+
+	path := filepath.Join(t.TempDir(), DataInjectionManifestFile)
+	require.NoError(t, WriteDataInjectionManifest(path, expected))
+	manifest, err = ReadDataInjectionManifest(path)
+	require.NoError(t, err)
+	require.Equal(t, expected, manifest)
+}
@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/defenseunicorns/pkg/helpers"
@@ -29,13 +30,21 @@ type PackagePaths struct {
 	JackalYAML string
 	Checksums  string
 
-	Signature string
+	Signature       string
+	SignatureBundle string
+
+	Provenance                string
+	ProvenanceSignature       string
+	ProvenanceSignatureBundle string
 
 	Components Components
 	SBOMs      SBOMs
 	Images     Images
 
 	isLegacyLayout bool
+
+	// reproducibleTime is the time the entries of a reproducible package archive are stamped with.
+	reproducibleTime *time.Time
 }
 
 // InjectionMadnessPaths contains paths for injection madness.
@@ -168,14 +177,14 @@ func (pp *PackagePaths) IsLegacyLayout() bool {
 	return pp.isLegacyLayout
 }
 
-// SignPackage signs the jackal.yaml in a Jackal package.
-func (pp *PackagePaths) SignPackage(signingKeyPath, signingKeyPassword string, isInteractive bool) error {
-	if signingKeyPath == "" {
+// SignPackage signs the jackal.yaml (and provenance if it exists) in a Jackal package.
+//
+// The signing key can be a path or a KMS URI, and keyless signatures are written with a bundle that holds the signing certificate.
+func (pp *PackagePaths) SignPackage(signingKeyPath, signingKeyPassword string, keyless, isInteractive bool) error {
+	if signingKeyPath == "" && !keyless {
 		return nil
 	}
 
-	pp.Signature = filepath.Join(pp.Base, Signature)
-
 	passwordFunc := func(_ bool) ([]byte, error) {
 		if signingKeyPassword != "" {
 			return []byte(signingKeyPassword), nil
@@ -185,14 +194,41 @@ func (pp *PackagePaths) SignPackage(signingKeyPath, signingKeyPassword string, i
 		}
 		return interactive.PromptSigPassword()
 	}
-	_, err := utils.CosignSignBlob(pp.JackalYAML, pp.Signature, signingKeyPath, passwordFunc)
-	if err != nil {
+	signOpts := utils.CosignSignOptions{
+		KeyRef:       signingKeyPath,
+		Keyless:      keyless,
+		PasswordFunc: passwordFunc,
+	}
+
+	pp.Signature = filepath.Join(pp.Base, Signature)
+	pp.SignatureBundle = pp.signatureBundle(pp.SignatureBundle, SignatureBundle, keyless)
+	if _, err := utils.CosignSignBlob(pp.JackalYAML, pp.Signature, pp.SignatureBundle, signOpts); err != nil {
 		return fmt.Errorf("unable to sign the package: %w", err)
 	}
 
+	// Sign the provenance with the same key so that it can be trusted as much as the package itself
+	if pp.Provenance != "" {
+		pp.ProvenanceSignature = filepath.Join(pp.Base, ProvenanceSignature)
+		pp.ProvenanceSignatureBundle = pp.signatureBundle(pp.ProvenanceSignatureBundle, ProvenanceSignatureBundle, keyless)
+		if _, err := utils.CosignSignBlob(pp.Provenance, pp.ProvenanceSignature, pp.ProvenanceSignatureBundle, signOpts); err != nil {
+			return fmt.Errorf("unable to sign the package provenance: %w", err)
+		}
+	}
+
 	return nil
 }
 
+// signatureBundle returns the path of the bundle for a signature, removing the bundle of a previous keyless signature when the package is re-signed with a key.
+func (pp *PackagePaths) signatureBundle(current, name string, keyless bool) string {
+	if keyless {
+		return filepath.Join(pp.Base, name)
+	}
+	if current != "" {
+		_ = os.Remove(current)
+	}
+	return ""
+}
+
 // GenerateChecksums walks through all of the files starting at the base path and generates a checksum file.
 //
 // Each file within the basePath represents a layer within the Jackal package.
@@ -229,9 +265,15 @@ func (pp *PackagePaths) ArchivePackage(destinationTarball string, maxPackageSize
 	defer spinner.Stop()
 
 	// Make the archive
-	archiveSrc := []string{pp.Base + string(os.PathSeparator)}
-	if err := archiver.Archive(archiveSrc, destinationTarball); err != nil {
-		return fmt.Errorf("unable to create package: %w", err)
+	if pp.reproducibleTime != nil {
+		if err := createReproducibleTarball(pp.Base, "", destinationTarball, *pp.reproducibleTime); err != nil {
+			return fmt.Errorf("unable to create package: %w", err)
+		}
+	} else {
+		archiveSrc := []string{pp.Base + string(os.PathSeparator)}
+		if err := archiver.Archive(archiveSrc, destinationTarball); err != nil {
+			return fmt.Errorf("unable to create package: %w", err)
+		}
 	}
 	spinner.Updatef("Wrote %s to %s", pp.Base, destinationTarball)
 
@@ -269,7 +311,8 @@ func (pp *PackagePaths) AddImages() *PackagePaths {
 // AddSBOMs sets the default sbom paths.
 func (pp *PackagePaths) AddSBOMs() *PackagePaths {
 	pp.SBOMs = SBOMs{
-		Path: filepath.Join(pp.Base, SBOMDir),
+		Path:         filepath.Join(pp.Base, SBOMDir),
+		reproducible: pp.reproducibleTime != nil,
 	}
 	return pp
 }
@@ -294,8 +337,16 @@ func (pp *PackagePaths) SetFromPaths(paths []string) {
 			pp.JackalYAML = filepath.Join(pp.Base, path)
 		case path == Signature:
 			pp.Signature = filepath.Join(pp.Base, path)
+		case path == SignatureBundle:
+			pp.SignatureBundle = filepath.Join(pp.Base, path)
 		case path == Checksums:
 			pp.Checksums = filepath.Join(pp.Base, path)
+		case path == Provenance:
+			pp.Provenance = filepath.Join(pp.Base, path)
+		case path == ProvenanceSignature:
+			pp.ProvenanceSignature = filepath.Join(pp.Base, path)
+		case path == ProvenanceSignatureBundle:
+			pp.ProvenanceSignatureBundle = filepath.Join(pp.Base, path)
 		case path == SBOMTar:
 			pp.SBOMs.Path = filepath.Join(pp.Base, path)
 		case path == OCILayoutPath:
@@ -341,7 +392,11 @@ func (pp *PackagePaths) Files() map[string]string {
 
 	add(pp.JackalYAML)
 	add(pp.Signature)
+	add(pp.SignatureBundle)
 	add(pp.Checksums)
+	add(pp.Provenance)
+	add(pp.ProvenanceSignature)
+	add(pp.ProvenanceSignatureBundle)
 
 	add(pp.Images.OCILayout)
 	add(pp.Images.Index)
@@ -61,6 +61,22 @@ func TestPackageFiles(t *testing.T) {
 		require.Equal(t, expected, files)
 	})
 
+	t.Run("Verify Files() with provenance", func(t *testing.T) {
+		t.Parallel()
+
+		pp := New("test")
+		pp.SetFromPaths([]string{Provenance, ProvenanceSignature})
+
+		files := pp.Files()
+		expected := map[string]string{
+			"jackal.yaml":                normalizePath("test/jackal.yaml"),
+			"checksums.txt":              normalizePath("test/checksums.txt"),
+			"jackal.provenance.json":     normalizePath("test/jackal.provenance.json"),
+			"jackal.provenance.json.sig": normalizePath("test/jackal.provenance.json.sig"),
+		}
+		require.Equal(t, expected, files)
+	})
+
 	t.Run("Verify Files() with images", func(t *testing.T) {
 		t.Parallel()
 
@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package layout contains functions for interacting with Jackal's package layout on disk.
+package layout
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/defenseunicorns/pkg/helpers"
+	"github.com/klauspost/compress/zstd"
+)
+
+// SourceDateEpochEnv is the environment variable that sets the timestamp of reproducible builds (https://reproducible-builds.org/specs/source-date-epoch/).
+const SourceDateEpochEnv = "SOURCE_DATE_EPOCH"
+
+// SourceDateEpoch returns the timestamp to use for reproducible builds, which is SOURCE_DATE_EPOCH when it is set and the Unix epoch otherwise.
+func SourceDateEpoch() (time.Time, error) {
+	epoch := os.Getenv(SourceDateEpochEnv)
+	if epoch == "" {
+		return time.Unix(0, 0).UTC(), nil
+	}
+	seconds, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q: %w", SourceDateEpochEnv, epoch, err)
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
+
+// SetReproducible makes every archive created for the package byte-for-byte reproducible, stamping the entries of the package archive with the given time.
+func (pp *PackagePaths) SetReproducible(modTime time.Time) {
+	pp.reproducibleTime = &modTime
+	pp.Components.reproducible = true
+	pp.SBOMs.reproducible = true
+}
+
+// createReproducibleTarball archives a directory with normalized headers so that the same contents always produce the same archive.
+//
+// Entries are written in lexical order, owned by root, stamped with the given time and given permissions based only on whether they are executable.
+// Every entry is placed under the prefix (if set), and the archive is zstd compressed if the destination ends with .zst.
+func createReproducibleTarball(srcDir, prefix, dst string, modTime time.Time) (err error) {
+	if err := helpers.CreateDirectory(filepath.Dir(dst), helpers.ReadWriteExecuteUser); err != nil {
+		return err
+	}
+	f, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("error creating tarball: %w", err)
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	var w io.Writer = f
+	if strings.HasSuffix(dst, ".zst") {
+		// A single encoder goroutine keeps the compressed output independent of the machine it is created on.
+		zw, err := zstd.NewWriter(f, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := zw.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+		w = zw
+	}
+
+	tw := tar.NewWriter(w)
+	defer func() {
+		if closeErr := tw.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	return filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(filepath.Join(prefix, rel))
+		if name == "." {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		link := ""
+		if info.Mode().Type() == fs.ModeSymlink {
+			if link, err = os.Readlink(path); err != nil {
+				return fmt.Errorf("error reading symlink: %w", err)
+			}
+		}
+
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return fmt.Errorf("error creating tar header: %w", err)
+		}
+		header.Name = name
+		header.ModTime = modTime
+		header.AccessTime = time.Time{}
+		header.ChangeTime = time.Time{}
+		header.Uid = 0
+		header.Gid = 0
+		header.Uname = ""
+		header.Gname = ""
+		header.PAXRecords = nil
+		switch {
+		case info.IsDir():
+			header.Name += "/"
+			header.Mode = 0755
+		case info.Mode().Type() == fs.ModeSymlink:
+			header.Mode = 0777
+		case info.Mode()&0111 != 0:
+			header.Mode = 0755
+		default:
+			header.Mode = 0644
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("error writing header: %w", err)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("error opening file: %w", err)
+		}
+		defer file.Close()
+		if _, err := io.Copy(tw, file); err != nil {
+			return fmt.Errorf("error writing file to tarball: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package layout contains functions for interacting with Jackal's package layout on disk.
+package layout
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceDateEpoch(t *testing.T) {
+	t.Setenv(SourceDateEpochEnv, "")
+	epoch, err := SourceDateEpoch()
+	require.NoError(t, err)
+	require.Equal(t, int64(0), epoch.Unix())
+
+	t.Setenv(SourceDateEpochEnv, "1700000000")
+	epoch, err = SourceDateEpoch()
+	require.NoError(t, err)
+	require.Equal(t, time.Unix(1700000000, 0).UTC(), epoch)
+
+	t.Setenv(SourceDateEpochEnv, "yesterday")
+	_, err = SourceDateEpoch()
+	require.Error(t, err)
+}
+
+func TestSetReproducible(t *testing.T) {
+	pp := New(t.TempDir())
+	require.False(t, pp.AddSBOMs().SBOMs.IsReproducible())
+
+	// The SBOMs are added after the package is made reproducible when it is created
+	pp.SetReproducible(time.Unix(0, 0))
+	require.True(t, pp.AddSBOMs().SBOMs.IsReproducible())
+}
+
+func TestCreateReproducibleTarball(t *testing.T) {
+	t.Parallel()
+
+	modTime := time.Unix(1700000000, 0).UTC()
+	create := func(t *testing.T, umask os.FileMode, mtime time.Time) string {
+		t.Helper()
+
+		src := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(src, "b"), 0777&^umask))
+		require.NoError(t, os.WriteFile(filepath.Join(src, "b", "run.sh"), []byte("#!/bin/sh"), 0777&^umask))
+		require.NoError(t, os.WriteFile(filepath.Join(src, "a.yaml"), []byte("kind: ConfigMap"), 0666&^umask))
+		for _, path := range []string{"a.yaml", "b/run.sh", "b"} {
+			require.NoError(t, os.Chtimes(filepath.Join(src, path), mtime, mtime))
+		}
+
+		dst := filepath.Join(t.TempDir(), "package.tar.zst")
+		require.NoError(t, createReproducibleTarball(src, "", dst, modTime))
+		return dst
+	}
+
+	first := create(t, 0022, time.Now())
+	second := create(t, 0077, time.Now().Add(-time.Hour))
+
+	firstContent, err := os.ReadFile(first)
+	require.NoError(t, err)
+	secondContent, err := os.ReadFile(second)
+	require.NoError(t, err)
+	require.Equal(t, firstContent, secondContent)
+
+	f, err := os.Open(first)
+	require.NoError(t, err)
+	defer f.Close()
+	zr, err := zstd.NewReader(f)
+	require.NoError(t, err)
+	defer zr.Close()
+
+	headers := map[string]*tar.Header{}
+	names := []string{}
+	tr := tar.NewReader(zr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		headers[header.Name] = header
+		names = append(names, header.Name)
+	}
+
+	require.Equal(t, []string{"a.yaml", "b/", "b/run.sh"}, names)
+	require.Equal(t, int64(0644), headers["a.yaml"].Mode)
+	require.Equal(t, int64(0755), headers["b/"].Mode)
+	require.Equal(t, int64(0755), headers["b/run.sh"].Mode)
+	for _, header := range headers {
+		require.True(t, modTime.Equal(header.ModTime))
+		require.Zero(t, header.Uid)
+		require.Empty(t, header.Uname)
+	}
+}
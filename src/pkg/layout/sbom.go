@@ -9,6 +9,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/defenseunicorns/pkg/helpers"
 	"github.com/mholt/archiver/v3"
@@ -23,6 +24,9 @@ type ComponentSBOM struct {
 // SBOMs contains paths for SBOMs.
 type SBOMs struct {
 	Path string
+
+	// reproducible normalizes the permissions of the archived SBOMs so that they do not depend on the umask they were created with.
+	reproducible bool
 }
 
 // Unarchive unarchives the package's SBOMs.
@@ -61,7 +65,12 @@ func (s *SBOMs) Archive() (err error) {
 	dir := s.Path
 	tb := filepath.Join(filepath.Dir(dir), SBOMTar)
 
-	if err := helpers.CreateReproducibleTarballFromDir(dir, "", tb); err != nil {
+	if s.reproducible {
+		err = createReproducibleTarball(dir, "", tb, time.Time{})
+	} else {
+		err = helpers.CreateReproducibleTarballFromDir(dir, "", tb)
+	}
+	if err != nil {
 		return err
 	}
 	s.Path = tb
@@ -110,3 +119,8 @@ func (s *SBOMs) OutputSBOMFiles(outputDir, packageName string) (string, error) {
 func (s SBOMs) IsTarball() bool {
 	return !helpers.IsDir(s.Path) && filepath.Ext(s.Path) == ".tar"
 }
+
+// IsReproducible returns true if the SBOMs are created for a reproducible package.
+func (s SBOMs) IsReproducible() bool {
+	return s.reproducible
+}
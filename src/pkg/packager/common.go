@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"slices"
@@ -39,6 +40,10 @@ type Packager struct {
 	sbomViewFiles  []string
 	source         sources.PackageSource
 	generation     int
+	snapshot       *deploySnapshot
+
+	// mu guards state that is shared between concurrently deployed components
+	mu sync.Mutex
 }
 
 // Modifier is a function that modifies the packager.
@@ -268,3 +273,25 @@ func (p *Packager) validateLastNonBreakingVersion() (err error) {
 
 	return nil
 }
+
+// placeholderState returns a state filled with default values so that the builtin values exist for packages
+// that are rendered without a cluster.
+func placeholderState() (*types.JackalState, error) {
+	registryInfo := types.RegistryInfo{}
+	if err := registryInfo.FillInEmptyValues(); err != nil {
+		return nil, err
+	}
+	// The push user is otherwise defaulted by the `jackal init` flags
+	gitServer := types.GitServerInfo{PushUsername: types.JackalGitPushUser}
+	if err := gitServer.FillInEmptyValues(); err != nil {
+		return nil, err
+	}
+	artifactServer := types.ArtifactServerInfo{}
+	artifactServer.FillInEmptyValues()
+
+	return &types.JackalState{
+		RegistryInfo:   registryInfo,
+		GitServer:      gitServer,
+		ArtifactServer: artifactServer,
+	}, nil
+}
@@ -6,9 +6,11 @@ package composer
 
 import (
 	"github.com/racer159/jackal/src/extensions/bigbang"
+	"github.com/racer159/jackal/src/extensions/flux"
 	"github.com/racer159/jackal/src/types"
 )
 
 func composeExtensions(c *types.JackalComponent, override types.JackalComponent, relativeTo string) {
 	bigbang.Compose(c, override, relativeTo)
+	flux.Compose(c, override, relativeTo)
 }
@@ -14,6 +14,8 @@ func overrideMetadata(c *types.JackalComponent, override types.JackalComponent)
 	c.Name = override.Name
 	c.Default = override.Default
 	c.Required = override.Required
+	// Dependencies reference component names in the importing package, so they always come from the override.
+	c.DependsOn = override.DependsOn
 
 	// Override description if it was provided.
 	if override.Description != "" {
@@ -20,6 +20,8 @@ import (
 	"github.com/racer159/jackal/src/config"
 	"github.com/racer159/jackal/src/config/lang"
 	"github.com/racer159/jackal/src/extensions/bigbang"
+	"github.com/racer159/jackal/src/extensions/flux"
+	"github.com/racer159/jackal/src/internal/packager/cache"
 	"github.com/racer159/jackal/src/internal/packager/git"
 	"github.com/racer159/jackal/src/internal/packager/helm"
 	"github.com/racer159/jackal/src/internal/packager/images"
@@ -46,6 +48,11 @@ type PackageCreator struct {
 
 	// TODO: (@lucasrod16) remove PackagerConfig once actions do not depend on it: https://github.com/racer159/jackal/pull/2276
 	cfg *types.PackagerConfig
+
+	// cacheKeys are the build cache keys of the cacheable components by name.
+	cacheKeys map[string]string
+	// cachedComponents are the build cache tarballs of the components that were reused by name.
+	cachedComponents map[string]string
 }
 
 // NewPackageCreator returns a new PackageCreator.
@@ -54,7 +61,12 @@ func NewPackageCreator(createOpts types.JackalCreateOptions, cfg *types.Packager
 		createOpts.DifferentialPackagePath = filepath.Join(cwd, createOpts.DifferentialPackagePath)
 	}
 
-	return &PackageCreator{createOpts, cfg}
+	return &PackageCreator{
+		createOpts:       createOpts,
+		cfg:              cfg,
+		cacheKeys:        make(map[string]string),
+		cachedComponents: make(map[string]string),
+	}
 }
 
 // LoadPackageDefinition loads and configures a jackal.yaml file during package create.
@@ -125,6 +137,14 @@ func (pc *PackageCreator) Assemble(dst *layout.PackagePaths, components []types.
 	skipSBOMFlagUsed := pc.createOpts.SkipSBOM
 	componentSBOMs := map[string]*layout.ComponentSBOM{}
 
+	if pc.createOpts.Reproducible {
+		epoch, err := layout.SourceDateEpoch()
+		if err != nil {
+			return err
+		}
+		dst.SetReproducible(epoch)
+	}
+
 	for _, component := range components {
 		onCreate := component.Actions.OnCreate
 
@@ -134,7 +154,25 @@ func (pc *PackageCreator) Assemble(dst *layout.PackagePaths, components []types.
 			}
 		}
 
-		if err := pc.addComponent(component, dst); err != nil {
+		key, cacheable, err := cache.ComponentKey(component, arch, pc.createOpts.Reproducible)
+		if err != nil {
+			return fmt.Errorf("unable to compute the build cache key for component %q: %w", component.Name, err)
+		}
+		tarball, isCached := "", false
+		if cacheable {
+			pc.cacheKeys[component.Name] = key
+			tarball, isCached = cache.GetComponent(key)
+		}
+
+		// Components whose inputs have not changed since they were last built are reused verbatim.
+		if isCached {
+			message.HeaderInfof("📦 %s COMPONENT", strings.ToUpper(component.Name))
+			message.Successf("Reusing the unchanged component from the build cache (%s)", key[:12])
+			if err := archiver.Unarchive(tarball, dst.Components.Base); err != nil {
+				return fmt.Errorf("unable to restore component %q from the build cache: %w", component.Name, err)
+			}
+			pc.cachedComponents[component.Name] = tarball
+		} else if err := pc.addComponent(component, dst); err != nil {
 			onFailure()
 			return fmt.Errorf("unable to add component %q: %w", component.Name, err)
 		}
@@ -224,7 +262,9 @@ func (pc *PackageCreator) Assemble(dst *layout.PackagePaths, components []types.
 //
 // - writes the loaded jackal.yaml to disk
 //
-// - signs the package
+// - records the provenance of the package
+//
+// - signs the package and its provenance
 //
 // - writes the Jackal package as a tarball to a local directory,
 // or an OCI registry based on the --output flag
@@ -232,10 +272,27 @@ func (pc *PackageCreator) Output(dst *layout.PackagePaths, pkg *types.JackalPack
 	// Process the component directories into compressed tarballs
 	// NOTE: This is purposefully being done after the SBOM cataloging
 	for _, component := range pkg.Components {
+		// Reuse the tarball of a component that came from the build cache instead of archiving it again
+		if tarball, ok := pc.cachedComponents[component.Name]; ok {
+			if err := dst.Components.Reuse(component, tarball); err != nil {
+				return fmt.Errorf("unable to reuse component: %s", err.Error())
+			}
+			continue
+		}
+
 		// Make the component a tar archive
 		if err := dst.Components.Archive(component, true); err != nil {
 			return fmt.Errorf("unable to archive component: %s", err.Error())
 		}
+
+		// Add the component to the build cache so that the next create can reuse it
+		if key, ok := pc.cacheKeys[component.Name]; ok {
+			if tarball, ok := dst.Components.Tarballs[component.Name]; ok {
+				if err := cache.PutComponent(key, tarball); err != nil {
+					message.Debugf("Unable to add component %q to the build cache: %s", component.Name, err.Error())
+				}
+			}
+		}
 	}
 
 	// Calculate all the checksums
@@ -252,8 +309,13 @@ func (pc *PackageCreator) Output(dst *layout.PackagePaths, pkg *types.JackalPack
 		return fmt.Errorf("unable to write jackal.yaml: %w", err)
 	}
 
+	// Record the provenance of the package so that it can be signed alongside it
+	if err := writeProvenance(dst, pkg, pc.createOpts); err != nil {
+		return fmt.Errorf("unable to write the package provenance: %w", err)
+	}
+
 	// Sign the package if a key has been provided
-	if err := dst.SignPackage(pc.createOpts.SigningKeyPath, pc.createOpts.SigningKeyPassword, !config.CommonOptions.Confirm); err != nil {
+	if err := dst.SignPackage(pc.createOpts.SigningKeyPath, pc.createOpts.SigningKeyPassword, pc.createOpts.Keyless, !config.CommonOptions.Confirm); err != nil {
 		return err
 	}
 
@@ -336,6 +398,13 @@ func (pc *PackageCreator) processExtensions(components []types.JackalComponent,
 			}
 		}
 
+		// Flux
+		if c.Extensions.Flux != nil {
+			if c, err = flux.Run(isYOLO, componentPaths, c); err != nil {
+				return nil, fmt.Errorf("unable to process flux extension: %w", err)
+			}
+		}
+
 		processedComponents = append(processedComponents, c)
 	}
 
@@ -449,6 +518,16 @@ func (pc *PackageCreator) addComponent(component types.JackalComponent, dst *lay
 					return fmt.Errorf("unable to copy data injection %s: %s", data.Source, err.Error())
 				}
 			}
+
+			// Record the content of the data so that deployments only transfer files that have changed
+			spinner.Updatef("Hashing data injection %s", data.Target.Path)
+			manifest, err := layout.NewDataInjectionManifest(dst)
+			if err != nil {
+				return fmt.Errorf("unable to hash data injection %s: %w", data.Source, err)
+			}
+			if err := layout.WriteDataInjectionManifest(filepath.Join(filepath.Dir(dst), layout.DataInjectionManifestFile), manifest); err != nil {
+				return err
+			}
 		}
 		spinner.Success()
 	}
@@ -0,0 +1,193 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package creator contains functions for creating Jackal packages.
+package creator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/defenseunicorns/pkg/helpers"
+	"github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/common"
+	slsa "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v1"
+	"github.com/racer159/jackal/src/config"
+	"github.com/racer159/jackal/src/pkg/layout"
+	"github.com/racer159/jackal/src/pkg/transform"
+	"github.com/racer159/jackal/src/pkg/utils"
+	"github.com/racer159/jackal/src/types"
+)
+
+const (
+	// ProvenanceBuildType is the SLSA build type of Jackal package creates.
+	ProvenanceBuildType = "https://jackal.dev/package-create/v1"
+	// ProvenanceBuilderID is the SLSA builder identity of the Jackal CLI.
+	ProvenanceBuilderID = "https://github.com/racer159/jackal"
+)
+
+// gitCommitRegex matches git refs that are full commit SHAs.
+var gitCommitRegex = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// provenanceExternalParameters are the user controlled inputs of a package create.
+type provenanceExternalParameters struct {
+	Package           string            `json:"package"`
+	Flavor            string            `json:"flavor,omitempty"`
+	Architecture      string            `json:"architecture"`
+	SetVariables      map[string]string `json:"setVariables,omitempty"`
+	RegistryOverrides map[string]string `json:"registryOverrides,omitempty"`
+	Differential      bool              `json:"differential,omitempty"`
+	Reproducible      bool              `json:"reproducible,omitempty"`
+}
+
+// provenanceInternalParameters are the inputs of a package create that are set by the builder.
+type provenanceInternalParameters struct {
+	User     string `json:"user,omitempty"`
+	Terminal string `json:"terminal,omitempty"`
+}
+
+// writeProvenance writes an in-toto statement with a SLSA provenance predicate for the jackal.yaml of the package.
+//
+// The statement records the inputs of the create (flavor and set variables), the images, charts and repos that were resolved and the builder that created the package.
+func writeProvenance(dst *layout.PackagePaths, pkg *types.JackalPackage, createOpts types.JackalCreateOptions) error {
+	jackalYAMLDigest, err := helpers.GetSHA256OfFile(dst.JackalYAML)
+	if err != nil {
+		return err
+	}
+
+	dependencies, err := provenanceDependencies(dst, pkg)
+	if err != nil {
+		return err
+	}
+
+	var buildMetadata slsa.BuildMetadata
+	if builtOn, err := time.Parse(time.RFC1123Z, pkg.Build.Timestamp); err == nil {
+		builtOn = builtOn.UTC()
+		buildMetadata.FinishedOn = &builtOn
+	}
+
+	statement := in_toto.ProvenanceStatementSLSA1{
+		StatementHeader: in_toto.StatementHeader{
+			Type:          in_toto.StatementInTotoV01,
+			PredicateType: slsa.PredicateSLSAProvenance,
+			Subject: []in_toto.Subject{
+				{
+					Name:   layout.JackalYAML,
+					Digest: common.DigestSet{"sha256": jackalYAMLDigest},
+				},
+			},
+		},
+		Predicate: slsa.ProvenancePredicate{
+			BuildDefinition: slsa.ProvenanceBuildDefinition{
+				BuildType: ProvenanceBuildType,
+				ExternalParameters: provenanceExternalParameters{
+					Package:           fmt.Sprintf("%s:%s", pkg.Metadata.Name, pkg.Metadata.Version),
+					Flavor:            createOpts.Flavor,
+					Architecture:      pkg.Metadata.Architecture,
+					SetVariables:      createOpts.SetVariables,
+					RegistryOverrides: createOpts.RegistryOverrides,
+					Differential:      pkg.Build.Differential,
+					Reproducible:      createOpts.Reproducible,
+				},
+				InternalParameters: provenanceInternalParameters{
+					User:     pkg.Build.User,
+					Terminal: pkg.Build.Terminal,
+				},
+				ResolvedDependencies: dependencies,
+			},
+			RunDetails: slsa.ProvenanceRunDetails{
+				Builder: slsa.Builder{
+					ID:      ProvenanceBuilderID,
+					Version: map[string]string{"jackal": config.CLIVersion},
+				},
+				BuildMetadata: buildMetadata,
+			},
+		},
+	}
+
+	b, err := json.MarshalIndent(statement, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dst.Provenance = filepath.Join(dst.Base, layout.Provenance)
+	return os.WriteFile(dst.Provenance, b, helpers.ReadUser)
+}
+
+// provenanceDependencies returns the images, charts and repos of a package as SLSA resource descriptors.
+func provenanceDependencies(dst *layout.PackagePaths, pkg *types.JackalPackage) ([]slsa.ResourceDescriptor, error) {
+	digests := make(map[string]string)
+	if dst.Images.Index != "" && !helpers.InvalidPath(dst.Images.Index) {
+		var err error
+		if digests, err = utils.GetImageDigests(dst.Images.Base); err != nil {
+			return nil, err
+		}
+	}
+
+	dependencies := []slsa.ResourceDescriptor{}
+	for _, component := range pkg.Components {
+		for _, image := range component.Images {
+			dependency := slsa.ResourceDescriptor{
+				Name:        image,
+				URI:         image,
+				Annotations: map[string]interface{}{"component": component.Name, "kind": "image"},
+			}
+			digest, ok := digests[image]
+			if !ok {
+				if refInfo, err := transform.ParseImageRef(image); err == nil {
+					digest, ok = digests[refInfo.Reference]
+				}
+			}
+			if algorithm, hex, found := strings.Cut(digest, ":"); ok && found {
+				dependency.Digest = common.DigestSet{algorithm: hex}
+			}
+			dependencies = append(dependencies, dependency)
+		}
+
+		for _, chart := range component.Charts {
+			dependency := slsa.ResourceDescriptor{
+				Name:        chart.Name,
+				URI:         chart.URL,
+				Annotations: map[string]interface{}{"component": component.Name, "kind": "chart"},
+			}
+			if chart.LocalPath != "" {
+				dependency.URI = chart.LocalPath
+			}
+			if chart.Version != "" {
+				dependency.Annotations["version"] = chart.Version
+			}
+			if chart.RepoName != "" {
+				dependency.Annotations["repoName"] = chart.RepoName
+			}
+			if chart.GitPath != "" {
+				dependency.Annotations["gitPath"] = chart.GitPath
+			}
+			dependencies = append(dependencies, dependency)
+		}
+
+		for _, repo := range component.Repos {
+			dependency := slsa.ResourceDescriptor{
+				Name:        repo,
+				URI:         repo,
+				Annotations: map[string]interface{}{"component": component.Name, "kind": "repo"},
+			}
+			if url, ref, err := transform.GitURLSplitRef(repo); err == nil {
+				dependency.URI = url
+				if ref != "" {
+					dependency.Annotations["ref"] = ref
+				}
+				if gitCommitRegex.MatchString(ref) {
+					dependency.Digest = common.DigestSet{"gitCommit": ref}
+				}
+			}
+			dependencies = append(dependencies, dependency)
+		}
+	}
+
+	return dependencies, nil
+}
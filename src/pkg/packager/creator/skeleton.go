@@ -16,6 +16,7 @@ import (
 	"github.com/racer159/jackal/src/config"
 	"github.com/racer159/jackal/src/config/lang"
 	"github.com/racer159/jackal/src/extensions/bigbang"
+	"github.com/racer159/jackal/src/extensions/flux"
 	"github.com/racer159/jackal/src/internal/packager/helm"
 	"github.com/racer159/jackal/src/internal/packager/kustomize"
 	"github.com/racer159/jackal/src/pkg/layout"
@@ -76,6 +77,14 @@ func (sc *SkeletonCreator) LoadPackageDefinition(dst *layout.PackagePaths) (pkg
 //
 // It processes each component to ensure correct structure and resource locations.
 func (sc *SkeletonCreator) Assemble(dst *layout.PackagePaths, components []types.JackalComponent, _ string) error {
+	if sc.createOpts.Reproducible {
+		epoch, err := layout.SourceDateEpoch()
+		if err != nil {
+			return err
+		}
+		dst.SetReproducible(epoch)
+	}
+
 	for _, component := range components {
 		c, err := sc.addComponent(component, dst)
 		if err != nil {
@@ -117,7 +126,7 @@ func (sc *SkeletonCreator) Output(dst *layout.PackagePaths, pkg *types.JackalPac
 		return fmt.Errorf("unable to write jackal.yaml: %w", err)
 	}
 
-	return dst.SignPackage(sc.publishOpts.SigningKeyPath, sc.publishOpts.SigningKeyPassword, !config.CommonOptions.Confirm)
+	return dst.SignPackage(sc.publishOpts.SigningKeyPath, sc.publishOpts.SigningKeyPassword, sc.publishOpts.Keyless, !config.CommonOptions.Confirm)
 }
 
 func (sc *SkeletonCreator) processExtensions(components []types.JackalComponent, layout *layout.PackagePaths) (processedComponents []types.JackalComponent, err error) {
@@ -135,6 +144,13 @@ func (sc *SkeletonCreator) processExtensions(components []types.JackalComponent,
 			}
 		}
 
+		// Flux
+		if c.Extensions.Flux != nil {
+			if c, err = flux.Skeletonize(componentPaths, c); err != nil {
+				return nil, fmt.Errorf("unable to process flux extension: %w", err)
+			}
+		}
+
 		processedComponents = append(processedComponents, c)
 	}
 
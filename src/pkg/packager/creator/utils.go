@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/racer159/jackal/src/config"
+	"github.com/racer159/jackal/src/pkg/layout"
 	"github.com/racer159/jackal/src/pkg/packager/deprecated"
 	"github.com/racer159/jackal/src/types"
 )
@@ -43,6 +44,17 @@ func recordPackageMetadata(pkg *types.JackalPackage, createOpts types.JackalCrea
 	// Record the time of package creation.
 	pkg.Build.Timestamp = now.Format(time.RFC1123Z)
 
+	// Reproducible packages do not record anything about the machine or moment they were created on.
+	if createOpts.Reproducible {
+		epoch, err := layout.SourceDateEpoch()
+		if err != nil {
+			return err
+		}
+		pkg.Build.User = ""
+		pkg.Build.Terminal = ""
+		pkg.Build.Timestamp = epoch.Format(time.RFC1123Z)
+	}
+
 	// Record the migrations that will be ran on the package.
 	pkg.Build.Migrations = []string{
 		deprecated.ScriptsToActionsMigrated,
@@ -5,13 +5,15 @@
 package packager
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/defenseunicorns/pkg/helpers"
@@ -29,8 +31,11 @@ import (
 	"github.com/racer159/jackal/src/pkg/packager/filters"
 	"github.com/racer159/jackal/src/pkg/packager/variables"
 	"github.com/racer159/jackal/src/pkg/transform"
+	"github.com/racer159/jackal/src/pkg/utils"
 	"github.com/racer159/jackal/src/types"
+	"helm.sh/helm/v3/pkg/storage/driver"
 	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
 func (p *Packager) resetRegistryHPA() {
@@ -73,6 +78,10 @@ func (p *Packager) Deploy() (err error) {
 		return err
 	}
 
+	if p.cfg.DeployOpts.DryRun {
+		return p.dryRun(deployFilter, isInteractive)
+	}
+
 	var sbomWarnings []string
 	p.sbomViewFiles, sbomWarnings, err = p.layout.SBOMs.StageSBOMViewFiles()
 	if err != nil {
@@ -106,8 +115,21 @@ func (p *Packager) Deploy() (err error) {
 	// Get a list of all the components we are deploying and actually deploy them
 	deployedComponents, err := p.deployComponents()
 	if err != nil {
+		if p.cfg.DeployOpts.Atomic && p.snapshot != nil {
+			if rollbackErr := p.rollbackDeploy(); rollbackErr != nil {
+				return fmt.Errorf("%w (unable to roll back the deployment: %w)", err, rollbackErr)
+			}
+			return fmt.Errorf("%w (the deployment was rolled back)", err)
+		}
 		return err
 	}
+	// Keep a record of this generation so that the package can be rolled back to it later
+	if p.isConnectedToCluster() {
+		if err := p.recordDeployHistory(deployedComponents); err != nil {
+			message.Warnf("Unable to record the deploy history of package %q: %s", p.cfg.Pkg.Metadata.Name, err.Error())
+		}
+	}
+
 	if len(deployedComponents) == 0 {
 		message.Warn("No components were selected for deployment.  Inspect the package to view the available components and select components interactively or by name with \"--components\"")
 	}
@@ -120,7 +142,7 @@ func (p *Packager) Deploy() (err error) {
 	return nil
 }
 
-// deployComponents loops through a list of JackalComponents and deploys them.
+// deployComponents deploys a list of JackalComponents, concurrently where their dependencies allow it.
 func (p *Packager) deployComponents() (deployedComponents []types.DeployedComponent, err error) {
 	// Generate a value template
 	if p.valueTemplate, err = template.Generate(p.cfg); err != nil {
@@ -132,95 +154,364 @@ func (p *Packager) deployComponents() (deployedComponents []types.DeployedCompon
 		p.generation = 1 // If this is the first deployment, set the generation to 1
 	}
 
-	// Process all the components we are deploying
-	for _, component := range p.cfg.Pkg.Components {
+	dependencies, err := componentDependencies(p.cfg.Pkg.Components)
+	if err != nil {
+		return deployedComponents, err
+	}
 
-		deployedComponent := types.DeployedComponent{
-			Name:               component.Name,
-			Status:             types.ComponentStatusDeploying,
-			ObservedGeneration: p.generation,
+	concurrency := p.cfg.DeployOpts.Concurrency
+	// Init packages rely on an ordering (state, injector, registry, agent) that is not expressed with dependsOn
+	if concurrency < 1 || p.cfg.Pkg.IsInitConfig() {
+		concurrency = 1
+	}
+
+	// Connect before deploying concurrently so that components do not race to establish the connection
+	if concurrency > 1 && slices.ContainsFunc(p.cfg.Pkg.Components, types.JackalComponent.RequiresCluster) {
+		if err := p.connectToCluster(cluster.DefaultTimeout); err != nil {
+			return deployedComponents, fmt.Errorf("unable to connect to the Kubernetes cluster: %w", err)
 		}
+	}
 
-		// If this component requires a cluster, connect to one
-		if component.RequiresCluster() {
-			timeout := cluster.DefaultTimeout
-			if p.cfg.Pkg.IsInitConfig() {
-				timeout = 5 * time.Minute
-			}
+	err = deployInDependencyOrder(p.cfg.Pkg.Components, dependencies, concurrency, func(component types.JackalComponent) error {
+		return p.deployAndRecordComponent(component, &deployedComponents)
+	})
+
+	return deployedComponents, err
+}
 
-			if err := p.connectToCluster(timeout); err != nil {
-				return deployedComponents, fmt.Errorf("unable to connect to the Kubernetes cluster: %w", err)
+// deployInDependencyOrder runs deploy for each component once all of its dependencies have succeeded, with at most concurrency running at once.
+//
+// Once a component fails no new components are started, and the errors of all failed components are returned after in-flight components finish.
+func deployInDependencyOrder(components []types.JackalComponent, dependencies map[string][]string, concurrency int, deploy func(types.JackalComponent) error) error {
+	type result struct {
+		name string
+		err  error
+	}
+
+	var (
+		results  = make(chan result)
+		started  = make(map[string]bool)
+		deployed = make(map[string]bool)
+		inFlight = 0
+		errs     []error
+	)
+
+	isReady := func(component types.JackalComponent) bool {
+		for _, dependency := range dependencies[component.Name] {
+			if !deployed[dependency] {
+				return false
 			}
 		}
+		return true
+	}
 
-		// Ensure we don't overwrite any installedCharts data when updating the package secret
-		if p.isConnectedToCluster() {
-			deployedComponent.InstalledCharts, err = p.cluster.GetInstalledChartsForComponent(p.cfg.Pkg.Metadata.Name, component)
-			if err != nil {
-				message.Debugf("Unable to fetch installed Helm charts for component '%s': %s", component.Name, err.Error())
+	for {
+		// Stop starting new components as soon as one has failed
+		if len(errs) == 0 {
+			for _, component := range components {
+				if inFlight >= concurrency {
+					break
+				}
+				if started[component.Name] || !isReady(component) {
+					continue
+				}
+
+				started[component.Name] = true
+				inFlight++
+				go func(component types.JackalComponent) {
+					results <- result{name: component.Name, err: deploy(component)}
+				}(component)
 			}
 		}
 
-		deployedComponents = append(deployedComponents, deployedComponent)
-		idx := len(deployedComponents) - 1
+		if inFlight == 0 {
+			break
+		}
+
+		r := <-results
+		inFlight--
+		if r.err != nil {
+			errs = append(errs, r.err)
+			continue
+		}
+		deployed[r.name] = true
+	}
+
+	return errors.Join(errs...)
+}
 
-		// Update the package secret to indicate that we are attempting to deploy this component
-		if p.isConnectedToCluster() {
-			if _, err := p.cluster.RecordPackageDeploymentAndWait(p.cfg.Pkg, deployedComponents, p.connectStrings, p.generation, component, p.cfg.DeployOpts.SkipWebhooks); err != nil {
-				message.Debugf("Unable to record package deployment for component %s: this will affect features like `jackal package remove`: %s", component.Name, err.Error())
+// componentDependencies returns the components each component must wait on before it is deployed.
+//
+// If no component declares dependsOn, each component depends on the one before it to preserve the ordered deploy behavior.
+// Dependencies on components that are not being deployed are ignored.
+func componentDependencies(components []types.JackalComponent) (map[string][]string, error) {
+	dependencies := make(map[string][]string, len(components))
+
+	hasDependsOn := slices.ContainsFunc(components, func(c types.JackalComponent) bool {
+		return len(c.DependsOn) > 0
+	})
+
+	nodes := []utils.Dependency{}
+	for idx, component := range components {
+		var dependsOn []string
+		if hasDependsOn {
+			for _, dependency := range component.DependsOn {
+				if slices.ContainsFunc(components, func(c types.JackalComponent) bool { return c.Name == dependency }) {
+					dependsOn = append(dependsOn, dependency)
+				} else {
+					message.Debugf("Component %q depends on %q which is not being deployed, ignoring", component.Name, dependency)
+				}
 			}
+		} else if idx > 0 {
+			dependsOn = []string{components[idx-1].Name}
 		}
 
-		// Deploy the component
-		var charts []types.InstalledChart
-		var deployErr error
+		dependencies[component.Name] = dependsOn
+		nodes = append(nodes, utils.ComponentDependency{Component: component.Name, DependsOn: dependsOn})
+	}
+
+	if _, err := utils.SortDependencies(nodes); err != nil {
+		return nil, fmt.Errorf("unable to determine the component deploy order: %w", err)
+	}
+
+	return dependencies, nil
+}
+
+// deployAndRecordComponent deploys a single component and records its status in the package secret.
+func (p *Packager) deployAndRecordComponent(component types.JackalComponent, deployedComponents *[]types.DeployedComponent) (err error) {
+	deployedComponent := types.DeployedComponent{
+		Name:               component.Name,
+		Status:             types.ComponentStatusDeploying,
+		ObservedGeneration: p.generation,
+	}
+
+	// If this component requires a cluster, connect to one
+	if component.RequiresCluster() {
+		timeout := cluster.DefaultTimeout
 		if p.cfg.Pkg.IsInitConfig() {
-			charts, deployErr = p.deployInitComponent(component)
-		} else {
-			charts, deployErr = p.deployComponent(component, false /* keep img checksum */, false /* always push images */)
+			timeout = 5 * time.Minute
 		}
 
-		onDeploy := component.Actions.OnDeploy
+		if err := p.connectToCluster(timeout); err != nil {
+			return fmt.Errorf("unable to connect to the Kubernetes cluster: %w", err)
+		}
+	}
 
-		onFailure := func() {
-			if err := actions.Run(p.cfg, onDeploy.Defaults, onDeploy.OnFailure, p.valueTemplate); err != nil {
-				message.Debugf("unable to run component failure action: %s", err.Error())
-			}
+	// Ensure we don't overwrite any installedCharts data when updating the package secret
+	if p.isConnectedToCluster() {
+		deployedComponent.InstalledCharts, err = p.cluster.GetInstalledChartsForComponent(p.cfg.Pkg.Metadata.Name, component)
+		if err != nil {
+			message.Debugf("Unable to fetch installed Helm charts for component '%s': %s", component.Name, err.Error())
 		}
+	}
 
-		if deployErr != nil {
-			onFailure()
+	// Record the current release revisions before anything is changed so an atomic deploy can be undone
+	if p.cfg.DeployOpts.Atomic && p.isConnectedToCluster() {
+		p.mu.Lock()
+		err := p.snapshotComponent(component, deployedComponent.InstalledCharts)
+		p.mu.Unlock()
+		if err != nil {
+			return fmt.Errorf("unable to record the state of component %q for an atomic deploy: %w", component.Name, err)
+		}
+	}
 
-			// Update the package secret to indicate that we failed to deploy this component
-			deployedComponents[idx].Status = types.ComponentStatusFailed
-			if p.isConnectedToCluster() {
-				if _, err := p.cluster.RecordPackageDeploymentAndWait(p.cfg.Pkg, deployedComponents, p.connectStrings, p.generation, component, p.cfg.DeployOpts.SkipWebhooks); err != nil {
-					message.Debugf("Unable to record package deployment for component %q: this will affect features like `jackal package remove`: %s", component.Name, err.Error())
-				}
-			}
+	// Update the package secret to indicate that we are attempting to deploy this component
+	var idx int
+	p.recordComponent(component, deployedComponents, func() {
+		*deployedComponents = append(*deployedComponents, deployedComponent)
+		idx = len(*deployedComponents) - 1
+	})
+
+	// Deploy the component
+	var charts []types.InstalledChart
+	var images []types.DeployedImage
+	var deployErr error
+	if p.cfg.Pkg.IsInitConfig() {
+		charts, images, deployErr = p.deployInitComponent(component)
+	} else {
+		charts, images, deployErr = p.deployComponent(component, false /* keep img checksum */, false /* always push images */)
+	}
+
+	onDeploy := component.Actions.OnDeploy
+
+	onFailure := func() {
+		if err := actions.Run(p.cfg, onDeploy.Defaults, onDeploy.OnFailure, p.valueTemplate); err != nil {
+			message.Debugf("unable to run component failure action: %s", err.Error())
+		}
+	}
+
+	if deployErr != nil {
+		onFailure()
+
+		// Update the package secret to indicate that we failed to deploy this component
+		p.recordComponent(component, deployedComponents, func() {
+			(*deployedComponents)[idx].Status = types.ComponentStatusFailed
+		})
+
+		return fmt.Errorf("unable to deploy component %q: %w", component.Name, deployErr)
+	}
+
+	// Update the package secret to indicate that we successfully deployed this component
+	p.recordComponent(component, deployedComponents, func() {
+		(*deployedComponents)[idx].InstalledCharts = charts
+		(*deployedComponents)[idx].Images = images
+		(*deployedComponents)[idx].Status = types.ComponentStatusSucceeded
+	})
+
+	if err := actions.Run(p.cfg, onDeploy.Defaults, onDeploy.OnSuccess, p.valueTemplate); err != nil {
+		onFailure()
+		return fmt.Errorf("unable to run component success action: %w", err)
+	}
+
+	return nil
+}
+
+// recordComponent updates the list of deployed components and records it in the package secret, then waits for any webhooks running against the component.
+//
+// Components can finish concurrently, so the list is updated and the secret written under lock (keeping an older list from being written over a newer one),
+// while the webhook wait happens outside of it so that it does not hold up the other components.
+func (p *Packager) recordComponent(component types.JackalComponent, deployedComponents *[]types.DeployedComponent, update func()) {
+	p.mu.Lock()
+	update()
+	if !p.isConnectedToCluster() {
+		p.mu.Unlock()
+		return
+	}
+	deployedPackage, err := p.cluster.RecordPackageDeployment(p.cfg.Pkg, slices.Clone(*deployedComponents), p.connectStrings, p.generation)
+	p.mu.Unlock()
+
+	if err == nil {
+		_, err = p.cluster.WaitForPackageWebhooks(deployedPackage, component, p.cfg.DeployOpts.SkipWebhooks)
+	}
+	if err != nil {
+		message.Debugf("Unable to record package deployment for component %q: this will affect features like `jackal package remove`: %s", component.Name, err.Error())
+	}
+}
+
+// deploySnapshot records the state of the cluster prior to an atomic deploy.
+type deploySnapshot struct {
+	deployedPackage *types.DeployedPackage
+	releases        []releaseRevision
+}
+
+// releaseRevision is the revision a Helm release was at before it was touched by a deploy (0 if it was not installed).
+type releaseRevision struct {
+	types.InstalledChart
+	revision int
+}
+
+// componentReleases returns the Helm releases a component will touch when it is deployed.
+func componentReleases(packageName string, component types.JackalComponent, installedCharts []types.InstalledChart) []types.InstalledChart {
+	releases := []types.InstalledChart{}
+
+	for _, chart := range component.Charts {
+		releaseName := chart.ReleaseName
+		if releaseName == "" {
+			releaseName = chart.Name
+		}
+		releases = append(releases, types.InstalledChart{Namespace: chart.Namespace, ChartName: releaseName})
+	}
+
+	for _, manifest := range component.Manifests {
+		namespace := manifest.Namespace
+		if namespace == "" {
+			namespace = corev1.NamespaceDefault
+		}
+		releases = append(releases, types.InstalledChart{Namespace: namespace, ChartName: helm.ManifestReleaseName(packageName, component.Name, manifest.Name)})
+	}
+
+	return helpers.Unique(append(releases, installedCharts...))
+}
+
+// snapshotComponent records the package secret (once) and the current revision of every release the component will touch.
+func (p *Packager) snapshotComponent(component types.JackalComponent, installedCharts []types.InstalledChart) error {
+	if p.snapshot == nil {
+		deployedPackage, err := p.cluster.GetDeployedPackage(p.cfg.Pkg.Metadata.Name)
+		if err != nil && !kerrors.IsNotFound(err) {
+			return fmt.Errorf("unable to get the existing package secret: %w", err)
+		}
+		p.snapshot = &deploySnapshot{deployedPackage: deployedPackage}
+	}
+
+	spinner := message.NewProgressSpinner("Recording the current Helm release revisions for component %q", component.Name)
+	defer spinner.Stop()
+
+	helmCfg := helm.NewClusterOnly(p.cfg, p.cluster)
+	for _, release := range componentReleases(p.cfg.Pkg.Metadata.Name, component, installedCharts) {
+		if slices.ContainsFunc(p.snapshot.releases, func(r releaseRevision) bool { return r.InstalledChart == release }) {
+			continue
+		}
+
+		revision, err := helmCfg.GetReleaseRevision(release.Namespace, release.ChartName, spinner)
+		if err != nil {
+			return err
+		}
+		p.snapshot.releases = append(p.snapshot.releases, releaseRevision{InstalledChart: release, revision: revision})
+	}
+
+	spinner.Success()
+
+	return nil
+}
+
+// rollbackDeploy returns every release touched during an atomic deploy to its previous revision and restores the previous package secret.
+func (p *Packager) rollbackDeploy() error {
+	spinner := message.NewProgressSpinner("Rolling back the deployment of package %q", p.cfg.Pkg.Metadata.Name)
+	defer spinner.Stop()
+
+	var errs []error
+
+	helmCfg := helm.NewClusterOnly(p.cfg, p.cluster)
+	for _, release := range helpers.Reverse(p.snapshot.releases) {
+		current, err := helmCfg.GetReleaseRevision(release.Namespace, release.ChartName, spinner)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
 
-			return deployedComponents, fmt.Errorf("unable to deploy component %q: %w", component.Name, deployErr)
+		// Nothing to do if the release was not changed by this deployment
+		if current == release.revision {
+			continue
 		}
 
-		// Update the package secret to indicate that we successfully deployed this component
-		deployedComponents[idx].InstalledCharts = charts
-		deployedComponents[idx].Status = types.ComponentStatusSucceeded
-		if p.isConnectedToCluster() {
-			if _, err := p.cluster.RecordPackageDeploymentAndWait(p.cfg.Pkg, deployedComponents, p.connectStrings, p.generation, component, p.cfg.DeployOpts.SkipWebhooks); err != nil {
-				message.Debugf("Unable to record package deployment for component %q: this will affect features like `jackal package remove`: %s", component.Name, err.Error())
+		if release.revision == 0 {
+			spinner.Updatef("Uninstalling chart %q from the %q namespace", release.ChartName, release.Namespace)
+			if err := helmCfg.RemoveChart(release.Namespace, release.ChartName, spinner); err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+				errs = append(errs, fmt.Errorf("unable to uninstall the helm chart %s in the namespace %s: %w", release.ChartName, release.Namespace, err))
 			}
+			continue
 		}
 
-		if err := actions.Run(p.cfg, onDeploy.Defaults, onDeploy.OnSuccess, p.valueTemplate); err != nil {
-			onFailure()
-			return deployedComponents, fmt.Errorf("unable to run component success action: %w", err)
+		spinner.Updatef("Rolling back chart %q in the %q namespace to revision %d", release.ChartName, release.Namespace, release.revision)
+		if err := helmCfg.RollbackRelease(release.Namespace, release.ChartName, release.revision, spinner); err != nil {
+			errs = append(errs, fmt.Errorf("unable to roll back the helm chart %s in the namespace %s: %w", release.ChartName, release.Namespace, err))
 		}
 	}
 
-	return deployedComponents, nil
+	spinner.Updatef("Restoring the previous package secret")
+	if p.snapshot.deployedPackage != nil {
+		p.updatePackageSecret(*p.snapshot.deployedPackage)
+	} else {
+		secretName := config.JackalPackagePrefix + p.cfg.Pkg.Metadata.Name
+		packageSecret, err := p.cluster.GetSecret(cluster.JackalNamespaceName, secretName)
+		if err == nil {
+			err = p.cluster.DeleteSecret(packageSecret)
+		}
+		if err != nil && !kerrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("unable to delete the %s package secret: %w", secretName, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	spinner.Success()
+
+	return nil
 }
 
-func (p *Packager) deployInitComponent(component types.JackalComponent) (charts []types.InstalledChart, err error) {
+func (p *Packager) deployInitComponent(component types.JackalComponent) (charts []types.InstalledChart, images []types.DeployedImage, err error) {
 	hasExternalRegistry := p.cfg.InitOpts.RegistryInfo.Address != ""
 	isSeedRegistry := component.Name == "jackal-seed-registry"
 	isRegistry := component.Name == "jackal-registry"
@@ -236,13 +527,13 @@ func (p *Packager) deployInitComponent(component types.JackalComponent) (charts
 	if component.RequiresCluster() && p.cfg.State == nil {
 		err = p.cluster.InitJackalState(p.cfg.InitOpts)
 		if err != nil {
-			return charts, fmt.Errorf("unable to initialize Jackal state: %w", err)
+			return charts, images, fmt.Errorf("unable to initialize Jackal state: %w", err)
 		}
 	}
 
 	if hasExternalRegistry && (isSeedRegistry || isInjector || isRegistry) {
 		message.Notef("Not deploying the component (%s) since external registry information was provided during `jackal init`", component.Name)
-		return charts, nil
+		return charts, images, nil
 	}
 
 	if isRegistry {
@@ -252,26 +543,28 @@ func (p *Packager) deployInitComponent(component types.JackalComponent) (charts
 
 	// Before deploying the seed registry, start the injector
 	if isSeedRegistry {
-		p.cluster.StartInjectionMadness(p.layout.Base, p.layout.Images.Base, component.Images)
+		if err := p.cluster.StartInjectionMadness(p.layout.Base, p.layout.Images.Base, component.Images, p.cfg.Pkg.Metadata.Architecture); err != nil {
+			return charts, images, err
+		}
 	}
 
-	charts, err = p.deployComponent(component, isAgent /* skip img checksum if isAgent */, isSeedRegistry /* skip image push if isSeedRegistry */)
+	charts, images, err = p.deployComponent(component, isAgent /* skip img checksum if isAgent */, isSeedRegistry /* skip image push if isSeedRegistry */)
 	if err != nil {
-		return charts, err
+		return charts, images, err
 	}
 
 	// Do cleanup for when we inject the seed registry during initialization
 	if isSeedRegistry {
 		if err := p.cluster.StopInjectionMadness(); err != nil {
-			return charts, fmt.Errorf("unable to seed the Jackal Registry: %w", err)
+			return charts, images, fmt.Errorf("unable to seed the Jackal Registry: %w", err)
 		}
 	}
 
-	return charts, nil
+	return charts, images, nil
 }
 
 // Deploy a Jackal Component.
-func (p *Packager) deployComponent(component types.JackalComponent, noImgChecksum bool, noImgPush bool) (charts []types.InstalledChart, err error) {
+func (p *Packager) deployComponent(component types.JackalComponent, noImgChecksum bool, noImgPush bool) (charts []types.InstalledChart, images []types.DeployedImage, err error) {
 	// Toggles for general deploy operations
 	componentPath := p.layout.Components.Dirs[component.Name]
 
@@ -287,11 +580,14 @@ func (p *Packager) deployComponent(component types.JackalComponent, noImgChecksu
 
 	onDeploy := component.Actions.OnDeploy
 
+	// Concurrently deployed components share the state, values template and registry HPA
+	p.mu.Lock()
 	if !p.valueTemplate.Ready() && component.RequiresCluster() {
 		// Setup the state in the config and get the valuesTemplate
 		p.valueTemplate, err = p.setupStateValuesTemplate()
 		if err != nil {
-			return charts, err
+			p.mu.Unlock()
+			return charts, images, err
 		}
 
 		// Disable the registry HPA scale down if we are deploying images and it is not already disabled
@@ -303,50 +599,66 @@ func (p *Packager) deployComponent(component types.JackalComponent, noImgChecksu
 			}
 		}
 	}
+	p.mu.Unlock()
 
 	if err = actions.Run(p.cfg, onDeploy.Defaults, onDeploy.Before, p.valueTemplate); err != nil {
-		return charts, fmt.Errorf("unable to run component before action: %w", err)
+		return charts, images, fmt.Errorf("unable to run component before action: %w", err)
 	}
 
 	if hasFiles {
 		if err := p.processComponentFiles(component, componentPath.Files); err != nil {
-			return charts, fmt.Errorf("unable to process the component files: %w", err)
+			return charts, images, fmt.Errorf("unable to process the component files: %w", err)
 		}
 	}
 
 	if hasImages {
-		if err := p.pushImagesToRegistry(component.Images, noImgChecksum); err != nil {
-			return charts, fmt.Errorf("unable to push images to the registry: %w", err)
+		if images, err = p.pushImagesToRegistry(component.Images, noImgChecksum); err != nil {
+			return charts, images, fmt.Errorf("unable to push images to the registry: %w", err)
 		}
 	}
 
 	if hasRepos {
 		if err = p.pushReposToRepository(componentPath.Repos, component.Repos); err != nil {
-			return charts, fmt.Errorf("unable to push the repos to the repository: %w", err)
+			return charts, images, fmt.Errorf("unable to push the repos to the repository: %w", err)
 		}
 	}
 
+	// Data injections wait for the pods created by this component's charts and manifests, so they run alongside the install
+	injectionErrs := make(chan error, len(component.DataInjections))
 	if hasDataInjections {
-		waitGroup := sync.WaitGroup{}
-		defer waitGroup.Wait()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
 
 		for idx, data := range component.DataInjections {
-			waitGroup.Add(1)
-			go p.cluster.HandleDataInjection(&waitGroup, data, componentPath, idx)
+			go func(idx int, data types.JackalDataInjection) {
+				injectionErrs <- p.cluster.HandleDataInjection(ctx, data, componentPath, idx)
+			}(idx, data)
 		}
 	}
 
 	if hasCharts || hasManifests {
 		if charts, err = p.installChartAndManifests(componentPath, component); err != nil {
-			return charts, fmt.Errorf("unable to install helm chart(s): %w", err)
+			return charts, images, fmt.Errorf("unable to install helm chart(s): %w", err)
+		}
+	}
+
+	if hasDataInjections {
+		var errs []error
+		for range component.DataInjections {
+			if err := <-injectionErrs; err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if len(errs) > 0 {
+			return charts, images, fmt.Errorf("unable to inject data: %w", errors.Join(errs...))
 		}
 	}
 
 	if err = actions.Run(p.cfg, onDeploy.Defaults, onDeploy.After, p.valueTemplate); err != nil {
-		return charts, fmt.Errorf("unable to run component after action: %w", err)
+		return charts, images, fmt.Errorf("unable to run component after action: %w", err)
 	}
 
-	return charts, nil
+	return charts, images, nil
 }
 
 // Move files onto the host of the machine performing the deployment.
@@ -469,17 +781,17 @@ func (p *Packager) setupStateValuesTemplate() (values *template.Values, err erro
 	return values, nil
 }
 
-// Push all of the components images to the configured container registry.
-func (p *Packager) pushImagesToRegistry(componentImages []string, noImgChecksum bool) error {
+// Push all of the components images to the configured container registry, returning the images pushed.
+func (p *Packager) pushImagesToRegistry(componentImages []string, noImgChecksum bool) ([]types.DeployedImage, error) {
 	if len(componentImages) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	var combinedImageList []transform.Image
 	for _, src := range componentImages {
 		ref, err := transform.ParseImageRef(src)
 		if err != nil {
-			return fmt.Errorf("failed to create ref for image %s: %w", src, err)
+			return nil, fmt.Errorf("failed to create ref for image %s: %w", src, err)
 		}
 		combinedImageList = append(combinedImageList, ref)
 	}
@@ -493,11 +805,15 @@ func (p *Packager) pushImagesToRegistry(componentImages []string, noImgChecksum
 		RegInfo:       p.cfg.State.RegistryInfo,
 		Insecure:      config.CommonOptions.Insecure,
 		Architectures: []string{p.cfg.Pkg.Build.Architecture},
+		Concurrency:   config.CommonOptions.OCIConcurrency,
 	}
 
-	return helpers.Retry(func() error {
-		return imgConfig.PushToJackalRegistry()
+	var deployedImages []types.DeployedImage
+	err := helpers.Retry(func() (err error) {
+		deployedImages, err = imgConfig.PushToJackalRegistry()
+		return err
 	}, p.cfg.PkgOpts.Retries, 5*time.Second, message.Warnf)
+	return deployedImages, err
 }
 
 // Push all of the components git repos to the configured git server.
@@ -587,30 +903,17 @@ func (p *Packager) installChartAndManifests(componentPaths *layout.ComponentPath
 		installedCharts = append(installedCharts, types.InstalledChart{Namespace: chart.Namespace, ChartName: installedChartName})
 
 		// Iterate over any connectStrings and add to the main map
+		p.mu.Lock()
 		for name, description := range addedConnectStrings {
 			p.connectStrings[name] = description
 		}
+		p.mu.Unlock()
 	}
 
 	for _, manifest := range component.Manifests {
-		for idx := range manifest.Files {
-			if helpers.InvalidPath(filepath.Join(componentPaths.Manifests, manifest.Files[idx])) {
-				// The path is likely invalid because of how we compose OCI components, add an index suffix to the filename
-				manifest.Files[idx] = fmt.Sprintf("%s-%d.yaml", manifest.Name, idx)
-				if helpers.InvalidPath(filepath.Join(componentPaths.Manifests, manifest.Files[idx])) {
-					return installedCharts, fmt.Errorf("unable to find manifest file %s", manifest.Files[idx])
-				}
-			}
-		}
-		// Move kustomizations to files now
-		for idx := range manifest.Kustomizations {
-			kustomization := fmt.Sprintf("kustomization-%s-%d.yaml", manifest.Name, idx)
-			manifest.Files = append(manifest.Files, kustomization)
-		}
-
-		if manifest.Namespace == "" {
-			// Helm gets sad when you don't provide a namespace even though we aren't using helm templating
-			manifest.Namespace = corev1.NamespaceDefault
+		manifest, err := resolveManifestFiles(componentPaths, manifest)
+		if err != nil {
+			return installedCharts, err
 		}
 
 		// Create a chart and helm cfg from a given Jackal Manifest.
@@ -640,14 +943,44 @@ func (p *Packager) installChartAndManifests(componentPaths *layout.ComponentPath
 		installedCharts = append(installedCharts, types.InstalledChart{Namespace: manifest.Namespace, ChartName: installedChartName})
 
 		// Iterate over any connectStrings and add to the main map
+		p.mu.Lock()
 		for name, description := range addedConnectStrings {
 			p.connectStrings[name] = description
 		}
+		p.mu.Unlock()
 	}
 
 	return installedCharts, nil
 }
 
+// resolveManifestFiles points a manifest's files at where they are stored within a loaded component.
+func resolveManifestFiles(componentPaths *layout.ComponentPaths, manifest types.JackalManifest) (types.JackalManifest, error) {
+	// Copy the files so the component definition is left untouched
+	manifest.Files = slices.Clone(manifest.Files)
+
+	for idx := range manifest.Files {
+		if helpers.InvalidPath(filepath.Join(componentPaths.Manifests, manifest.Files[idx])) {
+			// The path is likely invalid because of how we compose OCI components, add an index suffix to the filename
+			manifest.Files[idx] = fmt.Sprintf("%s-%d.yaml", manifest.Name, idx)
+			if helpers.InvalidPath(filepath.Join(componentPaths.Manifests, manifest.Files[idx])) {
+				return manifest, fmt.Errorf("unable to find manifest file %s", manifest.Files[idx])
+			}
+		}
+	}
+	// Move kustomizations to files now
+	for idx := range manifest.Kustomizations {
+		kustomization := fmt.Sprintf("kustomization-%s-%d.yaml", manifest.Name, idx)
+		manifest.Files = append(manifest.Files, kustomization)
+	}
+
+	if manifest.Namespace == "" {
+		// Helm gets sad when you don't provide a namespace even though we aren't using helm templating
+		manifest.Namespace = corev1.NamespaceDefault
+	}
+
+	return manifest, nil
+}
+
 func (p *Packager) printTablesForDeployment(componentsToDeploy []types.DeployedComponent) {
 
 	// If not init config, print the application connection table
@@ -0,0 +1,220 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package packager contains functions for interacting with, managing and deploying Jackal packages.
+package packager
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/racer159/jackal/src/config"
+	"github.com/racer159/jackal/src/internal/packager/helm"
+	"github.com/racer159/jackal/src/pkg/cluster"
+	"github.com/racer159/jackal/src/pkg/k8s"
+	"github.com/racer159/jackal/src/types"
+	"github.com/stretchr/testify/require"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestComponentReleases(t *testing.T) {
+	t.Parallel()
+
+	component := types.JackalComponent{
+		Name: "podinfo",
+		Charts: []types.JackalChart{
+			{Name: "podinfo", Namespace: "podinfo"},
+			{Name: "podinfo", ReleaseName: "podinfo-two", Namespace: "podinfo"},
+		},
+		Manifests: []types.JackalManifest{
+			{Name: "extras"},
+			{Name: "config", Namespace: "podinfo"},
+		},
+	}
+	installed := []types.InstalledChart{
+		{Namespace: "podinfo", ChartName: "podinfo"},
+		{Namespace: "podinfo", ChartName: "removed-chart"},
+	}
+
+	expected := []types.InstalledChart{
+		{Namespace: "podinfo", ChartName: "podinfo"},
+		{Namespace: "podinfo", ChartName: "podinfo-two"},
+		{Namespace: "default", ChartName: helm.ManifestReleaseName("test", "podinfo", "extras")},
+		{Namespace: "podinfo", ChartName: helm.ManifestReleaseName("test", "podinfo", "config")},
+		{Namespace: "podinfo", ChartName: "removed-chart"},
+	}
+
+	require.Equal(t, expected, componentReleases("test", component, installed))
+}
+
+func TestRollbackDeployRestoresPackageSecret(t *testing.T) {
+	t.Parallel()
+
+	newPackager := func() *Packager {
+		return &Packager{
+			cluster: &cluster.Cluster{
+				K8s: &k8s.K8s{
+					Clientset: fake.NewSimpleClientset(),
+					Log:       func(string, ...interface{}) {},
+				},
+			},
+			cfg: &types.PackagerConfig{
+				Pkg: types.JackalPackage{Metadata: types.JackalMetadata{Name: "test"}},
+			},
+		}
+	}
+
+	t.Run("restores the previous secret", func(t *testing.T) {
+		t.Parallel()
+
+		p := newPackager()
+		previous := types.DeployedPackage{
+			Name:       "test",
+			Generation: 1,
+			DeployedComponents: []types.DeployedComponent{
+				{Name: "first", Status: types.ComponentStatusSucceeded, ObservedGeneration: 1},
+			},
+		}
+		p.updatePackageSecret(previous)
+		p.snapshot = &deploySnapshot{deployedPackage: &previous}
+
+		_, err := p.cluster.RecordPackageDeployment(p.cfg.Pkg, []types.DeployedComponent{
+			{Name: "first", Status: types.ComponentStatusSucceeded, ObservedGeneration: 2},
+			{Name: "second", Status: types.ComponentStatusFailed, ObservedGeneration: 2},
+		}, nil, 2)
+		require.NoError(t, err)
+
+		require.NoError(t, p.rollbackDeploy())
+
+		restored, err := p.cluster.GetDeployedPackage("test")
+		require.NoError(t, err)
+		require.Equal(t, 1, restored.Generation)
+		require.Equal(t, previous.DeployedComponents, restored.DeployedComponents)
+	})
+
+	t.Run("removes the secret of a first deploy", func(t *testing.T) {
+		t.Parallel()
+
+		p := newPackager()
+		p.snapshot = &deploySnapshot{}
+
+		_, err := p.cluster.RecordPackageDeployment(p.cfg.Pkg, []types.DeployedComponent{
+			{Name: "first", Status: types.ComponentStatusFailed, ObservedGeneration: 1},
+		}, nil, 1)
+		require.NoError(t, err)
+
+		require.NoError(t, p.rollbackDeploy())
+
+		_, err = p.cluster.GetSecret(cluster.JackalNamespaceName, config.JackalPackagePrefix+"test")
+		require.True(t, kerrors.IsNotFound(err))
+	})
+}
+
+func TestComponentDependencies(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ordered without dependsOn", func(t *testing.T) {
+		t.Parallel()
+
+		dependencies, err := componentDependencies([]types.JackalComponent{{Name: "a"}, {Name: "b"}, {Name: "c"}})
+		require.NoError(t, err)
+		require.Equal(t, map[string][]string{"a": nil, "b": {"a"}, "c": {"b"}}, dependencies)
+	})
+
+	t.Run("explicit dependsOn", func(t *testing.T) {
+		t.Parallel()
+
+		dependencies, err := componentDependencies([]types.JackalComponent{
+			{Name: "a"},
+			{Name: "b"},
+			{Name: "c", DependsOn: []string{"a", "b", "not-selected"}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, map[string][]string{"a": nil, "b": nil, "c": {"a", "b"}}, dependencies)
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := componentDependencies([]types.JackalComponent{
+			{Name: "a", DependsOn: []string{"b"}},
+			{Name: "b", DependsOn: []string{"a"}},
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestDeployInDependencyOrder(t *testing.T) {
+	t.Parallel()
+
+	components := []types.JackalComponent{
+		{Name: "a"},
+		{Name: "b"},
+		{Name: "c", DependsOn: []string{"a"}},
+		{Name: "d", DependsOn: []string{"b", "c"}},
+	}
+	dependencies, err := componentDependencies(components)
+	require.NoError(t, err)
+
+	t.Run("respects dependencies and concurrency", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			mu         sync.Mutex
+			running    int
+			maxSeen    int
+			finished   = map[string]bool{}
+			violations []string
+		)
+
+		// The deploy function runs on other goroutines, so violations are asserted once the deploy returns
+		err := deployInDependencyOrder(components, dependencies, 2, func(component types.JackalComponent) error {
+			mu.Lock()
+			for _, dependency := range dependencies[component.Name] {
+				if !finished[dependency] {
+					violations = append(violations, fmt.Sprintf("%s started before %s finished", component.Name, dependency))
+				}
+			}
+			running++
+			maxSeen = max(maxSeen, running)
+			mu.Unlock()
+
+			time.Sleep(10 * time.Millisecond)
+
+			mu.Lock()
+			running--
+			finished[component.Name] = true
+			mu.Unlock()
+			return nil
+		})
+		require.NoError(t, err)
+		require.Empty(t, violations)
+		require.Len(t, finished, 4)
+		require.LessOrEqual(t, maxSeen, 2)
+	})
+
+	t.Run("stops after a failure", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			mu      sync.Mutex
+			started []string
+		)
+
+		err := deployInDependencyOrder(components, dependencies, 1, func(component types.JackalComponent) error {
+			mu.Lock()
+			defer mu.Unlock()
+			started = append(started, component.Name)
+			if component.Name == "b" {
+				return errors.New("boom")
+			}
+			return nil
+		})
+		require.ErrorContains(t, err, "boom")
+		require.Equal(t, []string{"a", "b"}, started)
+	})
+}
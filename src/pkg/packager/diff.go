@@ -0,0 +1,805 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package packager contains functions for interacting with, managing and deploying Jackal packages.
+package packager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/defenseunicorns/pkg/helpers"
+	"github.com/racer159/jackal/src/config"
+	"github.com/racer159/jackal/src/extensions/bigbang"
+	"github.com/racer159/jackal/src/internal/packager/helm"
+	"github.com/racer159/jackal/src/internal/packager/images"
+	"github.com/racer159/jackal/src/internal/packager/template"
+	"github.com/racer159/jackal/src/pkg/layout"
+	"github.com/racer159/jackal/src/pkg/message"
+	"github.com/racer159/jackal/src/pkg/packager/filters"
+	"github.com/racer159/jackal/src/pkg/packager/sources"
+	"github.com/racer159/jackal/src/pkg/packager/variables"
+	"github.com/racer159/jackal/src/pkg/transform"
+	"github.com/racer159/jackal/src/pkg/utils"
+	"github.com/racer159/jackal/src/types"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"sigs.k8s.io/yaml"
+)
+
+// DiffChange is how an item differs between two packages.
+type DiffChange string
+
+const (
+	// DiffAdded is an item that only exists in the compared package.
+	DiffAdded DiffChange = "added"
+	// DiffRemoved is an item that only exists in the original package.
+	DiffRemoved DiffChange = "removed"
+	// DiffChanged is an item that exists in both packages with different contents.
+	DiffChanged DiffChange = "changed"
+)
+
+// The kinds of items compared between two packages.
+const (
+	DiffKindComponent = "component"
+	DiffKindVariable  = "variable"
+	DiffKindConstant  = "constant"
+	DiffKindImage     = "image"
+	DiffKindChart     = "chart"
+	DiffKindRepo      = "repo"
+	DiffKindResource  = "resource"
+	DiffKindBigBang   = "bigbang"
+	// DiffKindBigBangPackage is a Big Bang package (HelmRelease) that is enabled in only one of the packages.
+	DiffKindBigBangPackage = "bigbang package"
+)
+
+// PackageDiff is the set of differences between two packages.
+type PackageDiff struct {
+	From    PackageDiffSide   `json:"from"`
+	To      PackageDiffSide   `json:"to"`
+	Changes []PackageDiffItem `json:"changes"`
+}
+
+// PackageDiffSide describes one of the two packages that were compared.
+type PackageDiffSide struct {
+	Source   string `json:"source"`
+	Name     string `json:"name"`
+	Version  string `json:"version,omitempty"`
+	Deployed bool   `json:"deployed"`
+}
+
+// PackageDiffItem is a single difference between two packages.
+type PackageDiffItem struct {
+	Kind      string     `json:"kind"`
+	Component string     `json:"component,omitempty"`
+	Name      string     `json:"name"`
+	Change    DiffChange `json:"change"`
+	From      string     `json:"from,omitempty"`
+	To        string     `json:"to,omitempty"`
+	// Diff is a unified diff of the rendered resource
+	Diff string `json:"diff,omitempty"`
+
+	fromText string
+	toText   string
+}
+
+// packageSnapshot is the part of a package that is compared by Diff.
+type packageSnapshot struct {
+	side       PackageDiffSide
+	pkg        types.JackalPackage
+	components []componentSnapshot
+}
+
+// componentSnapshot is the part of a component that is compared by Diff.
+type componentSnapshot struct {
+	name string
+	// images maps image references to their digest (if known)
+	images map[string]string
+	// charts maps chart names to their version
+	charts map[string]string
+	repos  []string
+	// bigBang maps "version" to the Big Bang version of a component built with the Big Bang extension
+	bigBang map[string]string
+	// bigBangPackages maps the Big Bang packages the component deploys to "enabled"
+	bigBangPackages map[string]string
+	// resources maps resource identifiers (kind/namespace/name) to their rendered YAML
+	resources map[string]string
+}
+
+// Diff compares the package from the packager's source against p.cfg.DiffOpts.ComparePackageSource and prints the differences.
+//
+// Either package can be a package deployed to the cluster, in which case its rendered resources are read back from its Helm releases.
+func (p *Packager) Diff() (err error) {
+	compareOpts := p.cfg.PkgOpts
+	compareOpts.PackageSource = p.cfg.DiffOpts.ComparePackageSource
+	// The shasum only applies to the package being compared against
+	compareOpts.Shasum = ""
+
+	compare, err := newDiffSource(&compareOpts)
+	if err != nil {
+		return fmt.Errorf("unable to load %q: %w", compareOpts.PackageSource, err)
+	}
+
+	// Sources may update the package source as they load, so capture the requested sources first
+	fromSide := PackageDiffSide{Source: p.cfg.PkgOpts.PackageSource}
+	toSide := PackageDiffSide{Source: compareOpts.PackageSource}
+
+	if err := p.setDiffState(p.source, compare); err != nil {
+		return err
+	}
+
+	from, err := p.snapshotPackage(p.source, p.layout)
+	if err != nil {
+		return fmt.Errorf("unable to load %q: %w", fromSide.Source, err)
+	}
+	from.side.Source = fromSide.Source
+
+	tmp, err := utils.MakeTempDir(config.CommonOptions.TempDirectory)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	to, err := p.snapshotPackage(compare, layout.New(tmp))
+	if err != nil {
+		return fmt.Errorf("unable to load %q: %w", toSide.Source, err)
+	}
+	to.side.Source = toSide.Source
+
+	for _, warning := range p.warnings {
+		message.Warn(warning)
+	}
+
+	diff, err := diffPackages(from, to)
+	if err != nil {
+		return err
+	}
+
+	if p.cfg.DiffOpts.OutputFormat == "json" {
+		fmt.Println(message.JSONValue(diff))
+		return nil
+	}
+
+	printPackageDiff(diff)
+
+	return nil
+}
+
+// newDiffSource creates the source for a package, falling back to a deployed package of the same name.
+func newDiffSource(pkgOpts *types.JackalPackageOptions) (sources.PackageSource, error) {
+	if sources.Identify(pkgOpts.PackageSource) == "" {
+		return sources.NewClusterSource(pkgOpts)
+	}
+	return sources.New(pkgOpts)
+}
+
+// setDiffState sets the state both packages are rendered with.
+//
+// The cluster's state is used when either package is deployed so rendered resources match what was deployed,
+// otherwise a placeholder state is used so that the builtin values exist in case any charts rely on them.
+func (p *Packager) setDiffState(srcs ...sources.PackageSource) error {
+	for _, src := range srcs {
+		if cs, ok := src.(*sources.ClusterSource); ok {
+			state, err := cs.LoadJackalState()
+			if err != nil {
+				return fmt.Errorf("unable to load the Jackal state: %w", err)
+			}
+			p.cfg.State = state
+			return nil
+		}
+	}
+
+	state, err := placeholderState()
+	if err != nil {
+		return err
+	}
+	p.cfg.State = state
+
+	return nil
+}
+
+// snapshotPackage loads a package from a source and collects what is compared by Diff.
+func (p *Packager) snapshotPackage(src sources.PackageSource, dst *layout.PackagePaths) (snapshot packageSnapshot, err error) {
+	if cs, ok := src.(*sources.ClusterSource); ok {
+		return p.snapshotDeployedPackage(cs)
+	}
+
+	filter := filters.Combine(
+		filters.ByLocalOS(runtime.GOOS),
+		filters.ForDeploy(p.cfg.PkgOpts.OptionalComponents, false),
+	)
+
+	pkg, warnings, err := src.LoadPackage(dst, filter, true)
+	if err != nil {
+		return snapshot, err
+	}
+	p.warnings = append(p.warnings, warnings...)
+
+	snapshot.pkg = pkg
+	snapshot.side = PackageDiffSide{Name: pkg.Metadata.Name, Version: pkg.Metadata.Version}
+
+	digests := make(map[string]string)
+	if dst.Images.Index != "" {
+		digests, err = utils.GetImageDigests(filepath.Dir(dst.Images.Index))
+		if err != nil {
+			return snapshot, err
+		}
+	}
+
+	// Variables are resolved per package so that each package is rendered with its own defaults
+	p.cfg.Pkg = pkg
+	p.cfg.SetVariableMap = make(map[string]*types.JackalSetVariable)
+	if err := variables.SetVariableMapInConfig(p.cfg); err != nil {
+		return snapshot, err
+	}
+	p.valueTemplate, err = template.Generate(p.cfg)
+	if err != nil {
+		return snapshot, fmt.Errorf("unable to generate template values: %w", err)
+	}
+
+	for _, component := range pkg.Components {
+		componentSnapshot := newComponentSnapshot(component)
+
+		for _, image := range component.Images {
+			componentSnapshot.images[image] = digests[image]
+		}
+
+		componentPaths, ok := dst.Components.Dirs[component.Name]
+		if !ok {
+			snapshot.components = append(snapshot.components, componentSnapshot)
+			continue
+		}
+
+		releases, err := p.renderComponent(componentPaths, component)
+		if err != nil {
+			return snapshot, fmt.Errorf("unable to render component %q: %w", component.Name, err)
+		}
+		for _, release := range releases {
+			if err := indexResources(release.manifest, componentSnapshot.resources); err != nil {
+				return snapshot, fmt.Errorf("unable to parse the resources of component %q: %w", component.Name, err)
+			}
+		}
+
+		snapshot.components = append(snapshot.components, componentSnapshot)
+	}
+
+	return snapshot, nil
+}
+
+// snapshotDeployedPackage collects what is compared by Diff from a package deployed to the cluster.
+func (p *Packager) snapshotDeployedPackage(cs *sources.ClusterSource) (snapshot packageSnapshot, err error) {
+	deployedPackage, err := cs.GetDeployedPackage(cs.PackageSource)
+	if err != nil {
+		return snapshot, err
+	}
+
+	snapshot.pkg = deployedPackage.Data
+	snapshot.side = PackageDiffSide{
+		Name:     deployedPackage.Name,
+		Version:  deployedPackage.Data.Metadata.Version,
+		Deployed: true,
+	}
+
+	components := make(map[string]types.JackalComponent)
+	for _, component := range deployedPackage.Data.Components {
+		components[component.Name] = component
+	}
+
+	var imageList []transform.Image
+	for _, deployedComponent := range deployedPackage.DeployedComponents {
+		for _, image := range components[deployedComponent.Name].Images {
+			refInfo, err := transform.ParseImageRef(image)
+			if err != nil {
+				return snapshot, fmt.Errorf("failed to create ref for image %s: %w", image, err)
+			}
+			imageList = append(imageList, refInfo)
+		}
+	}
+
+	digests := make(map[string]string)
+	if len(imageList) > 0 {
+		imgConfig := images.ImageConfig{
+			ImageList:     helpers.Unique(imageList),
+			RegInfo:       p.cfg.State.RegistryInfo,
+			Insecure:      config.CommonOptions.Insecure,
+			Architectures: []string{deployedPackage.Data.Build.Architecture},
+		}
+		if digests, err = imgConfig.DigestsFromJackalRegistry(); err != nil {
+			// Images can still be compared by reference without their digests
+			p.warnings = append(p.warnings, fmt.Sprintf("Unable to resolve the digests of the deployed images: %s", err.Error()))
+			digests = make(map[string]string)
+		}
+	}
+
+	for _, deployedComponent := range deployedPackage.DeployedComponents {
+		component, ok := components[deployedComponent.Name]
+		if !ok {
+			continue
+		}
+
+		if deployedComponent.Status != types.ComponentStatusSucceeded {
+			p.warnings = append(p.warnings, fmt.Sprintf("Component %q was not successfully deployed and may not match what is in the cluster", component.Name))
+		}
+
+		componentSnapshot := newComponentSnapshot(component)
+
+		for _, image := range component.Images {
+			componentSnapshot.images[image] = digests[image]
+		}
+
+		for _, installedChart := range deployedComponent.InstalledCharts {
+			helmCfg := helm.New(types.JackalChart{Namespace: installedChart.Namespace}, "", "")
+			manifest, err := helmCfg.GetReleaseManifest(installedChart.ChartName)
+			if err != nil {
+				return snapshot, err
+			}
+			if err := indexResources(manifest, componentSnapshot.resources); err != nil {
+				return snapshot, fmt.Errorf("unable to parse the resources of release %q: %w", installedChart.ChartName, err)
+			}
+		}
+
+		snapshot.components = append(snapshot.components, componentSnapshot)
+	}
+
+	return snapshot, nil
+}
+
+// renderedRelease is the rendered manifest of a chart or manifest within a component.
+type renderedRelease struct {
+	name      string
+	namespace string
+	manifest  string
+}
+
+// renderComponent renders the charts and manifests of a loaded component the same way they would be deployed.
+func (p *Packager) renderComponent(componentPaths *layout.ComponentPaths, component types.JackalComponent) (releases []renderedRelease, err error) {
+	for _, chart := range component.Charts {
+		for idx := range chart.ValuesFiles {
+			chartValueName := helm.StandardValuesName(componentPaths.Values, chart, idx)
+			if err := p.valueTemplate.Apply(component, chartValueName, false); err != nil {
+				return nil, err
+			}
+		}
+
+		helmCfg := helm.New(
+			chart,
+			componentPaths.Charts,
+			componentPaths.Values,
+			helm.WithDeployInfo(component, p.cfg, nil, nil, p.cfg.DeployOpts.Timeout, p.cfg.PkgOpts.Retries),
+		)
+
+		manifest, _, err := helmCfg.TemplateChart()
+		if err != nil {
+			return nil, err
+		}
+
+		releaseName := chart.ReleaseName
+		if releaseName == "" {
+			releaseName = chart.Name
+		}
+		releases = append(releases, renderedRelease{name: releaseName, namespace: chart.Namespace, manifest: manifest})
+	}
+
+	for _, manifest := range component.Manifests {
+		manifest, err := resolveManifestFiles(componentPaths, manifest)
+		if err != nil {
+			return nil, err
+		}
+
+		helmCfg, err := helm.NewFromJackalManifest(
+			manifest,
+			componentPaths.Manifests,
+			p.cfg.Pkg.Metadata.Name,
+			component.Name,
+			helm.WithDeployInfo(component, p.cfg, nil, nil, p.cfg.DeployOpts.Timeout, p.cfg.PkgOpts.Retries),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		rendered, _, err := helmCfg.TemplateChart()
+		if err != nil {
+			return nil, err
+		}
+
+		releaseName := helm.ManifestReleaseName(p.cfg.Pkg.Metadata.Name, component.Name, manifest.Name)
+		releases = append(releases, renderedRelease{name: releaseName, namespace: manifest.Namespace, manifest: rendered})
+	}
+
+	return releases, nil
+}
+
+// newComponentSnapshot creates a snapshot of the parts of a component that do not need to be rendered.
+func newComponentSnapshot(component types.JackalComponent) componentSnapshot {
+	snapshot := componentSnapshot{
+		name:      component.Name,
+		images:    make(map[string]string),
+		charts:    make(map[string]string),
+		repos:     component.Repos,
+		resources: make(map[string]string),
+
+		bigBang:         make(map[string]string),
+		bigBangPackages: make(map[string]string),
+	}
+	for _, chart := range component.Charts {
+		snapshot.charts[chart.Name] = chart.Version
+	}
+	if component.Extensions.BigBang != nil {
+		snapshot.bigBang["version"] = component.Extensions.BigBang.Version
+		for _, name := range bigbang.EnabledPackages(component) {
+			snapshot.bigBangPackages[name] = "enabled"
+		}
+	}
+	return snapshot
+}
+
+// indexResources splits a rendered manifest into resources and adds them to the index by kind/namespace/name.
+func indexResources(manifest string, index map[string]string) error {
+	resources, err := utils.SplitYAML([]byte(manifest))
+	if err != nil {
+		return err
+	}
+
+	for _, resource := range resources {
+		id := resourceID(resource.GetKind(), resource.GetNamespace(), resource.GetName())
+
+		// Remarshal the resource so that formatting and comments do not show up as differences
+		normalized, err := yaml.Marshal(resource.Object)
+		if err != nil {
+			return err
+		}
+		index[id] = string(normalized)
+	}
+
+	return nil
+}
+
+// diffPackages returns the differences between two package snapshots.
+func diffPackages(from, to packageSnapshot) (PackageDiff, error) {
+	diff := PackageDiff{
+		From:    from.side,
+		To:      to.side,
+		Changes: []PackageDiffItem{},
+	}
+
+	// Jackal manages namespaces outside of Helm so they are never part of a deployed release
+	skipNamespaces := from.side.Deployed || to.side.Deployed
+
+	fromVariables := make(map[string]string)
+	toVariables := make(map[string]string)
+	fromVariableDefs := make(map[string]types.JackalPackageVariable)
+	for _, variable := range from.pkg.Variables {
+		fromVariables[variable.Name] = describeVariable(variable)
+		fromVariableDefs[variable.Name] = variable
+	}
+	changedVariables := make(map[string]bool)
+	for _, variable := range to.pkg.Variables {
+		toVariables[variable.Name] = describeVariable(variable)
+		if fromVariable, ok := fromVariableDefs[variable.Name]; ok && !reflect.DeepEqual(fromVariable, variable) {
+			changedVariables[variable.Name] = true
+		}
+	}
+	diff.Changes = append(diff.Changes, diffValues(DiffKindVariable, "", fromVariables, toVariables, changedVariables)...)
+
+	fromConstants := make(map[string]string)
+	toConstants := make(map[string]string)
+	for _, constant := range from.pkg.Constants {
+		fromConstants[constant.Name] = constant.Value
+	}
+	for _, constant := range to.pkg.Constants {
+		toConstants[constant.Name] = constant.Value
+	}
+	diff.Changes = append(diff.Changes, diffValues(DiffKindConstant, "", fromConstants, toConstants, nil)...)
+
+	fromComponents := make(map[string]componentSnapshot)
+	for _, component := range from.components {
+		fromComponents[component.name] = component
+	}
+	toComponents := make(map[string]componentSnapshot)
+	for _, component := range to.components {
+		toComponents[component.name] = component
+	}
+
+	for _, component := range from.components {
+		if _, ok := toComponents[component.name]; !ok {
+			diff.Changes = append(diff.Changes, PackageDiffItem{Kind: DiffKindComponent, Name: component.name, Change: DiffRemoved})
+		}
+	}
+
+	for _, toComponent := range to.components {
+		fromComponent, ok := fromComponents[toComponent.name]
+		if !ok {
+			diff.Changes = append(diff.Changes, PackageDiffItem{Kind: DiffKindComponent, Name: toComponent.name, Change: DiffAdded})
+			continue
+		}
+
+		// Digests are only compared when they are known for both images
+		changedImages := make(map[string]bool)
+		for image, toDigest := range toComponent.images {
+			if fromDigest, ok := fromComponent.images[image]; ok && fromDigest != "" && toDigest != "" && fromDigest != toDigest {
+				changedImages[image] = true
+			}
+		}
+		diff.Changes = append(diff.Changes, diffValues(DiffKindImage, toComponent.name, fromComponent.images, toComponent.images, changedImages)...)
+
+		diff.Changes = append(diff.Changes, diffValues(DiffKindChart, toComponent.name, fromComponent.charts, toComponent.charts, nil)...)
+
+		fromRepos := make(map[string]string)
+		for _, repo := range fromComponent.repos {
+			fromRepos[repo] = ""
+		}
+		toRepos := make(map[string]string)
+		for _, repo := range toComponent.repos {
+			toRepos[repo] = ""
+		}
+		diff.Changes = append(diff.Changes, diffValues(DiffKindRepo, toComponent.name, fromRepos, toRepos, nil)...)
+
+		diff.Changes = append(diff.Changes, diffValues(DiffKindBigBang, toComponent.name, fromComponent.bigBang, toComponent.bigBang, nil)...)
+		diff.Changes = append(diff.Changes, diffValues(DiffKindBigBangPackage, toComponent.name, fromComponent.bigBangPackages, toComponent.bigBangPackages, nil)...)
+
+		resources, err := diffResources(toComponent.name, fromComponent.resources, toComponent.resources, from.side.Source, to.side.Source, skipNamespaces)
+		if err != nil {
+			return diff, err
+		}
+		diff.Changes = append(diff.Changes, resources...)
+	}
+
+	return diff, nil
+}
+
+// diffValues compares two maps of named values.
+//
+// Values with different contents are changed unless changed is provided, in which case only the names within it are changed.
+func diffValues(kind, component string, from, to map[string]string, changed map[string]bool) (items []PackageDiffItem) {
+	for _, name := range sortedKeys(from) {
+		if _, ok := to[name]; !ok {
+			items = append(items, PackageDiffItem{Kind: kind, Component: component, Name: name, Change: DiffRemoved, From: from[name]})
+		}
+	}
+
+	for _, name := range sortedKeys(to) {
+		fromValue, ok := from[name]
+		switch {
+		case !ok:
+			items = append(items, PackageDiffItem{Kind: kind, Component: component, Name: name, Change: DiffAdded, To: to[name]})
+		case (changed == nil && fromValue != to[name]) || changed[name]:
+			items = append(items, PackageDiffItem{Kind: kind, Component: component, Name: name, Change: DiffChanged, From: fromValue, To: to[name]})
+		}
+	}
+
+	return items
+}
+
+// diffResources compares two indexes of rendered resources.
+func diffResources(component string, from, to map[string]string, fromLabel, toLabel string, skipNamespaces bool) (items []PackageDiffItem, err error) {
+	isSkipped := func(id string) bool {
+		return skipNamespaces && strings.HasPrefix(id, "Namespace/")
+	}
+
+	ids := make(map[string]string)
+	for id := range from {
+		ids[id] = ""
+	}
+	for id := range to {
+		ids[id] = ""
+	}
+
+	for _, id := range sortedKeys(ids) {
+		if isSkipped(id) {
+			continue
+		}
+
+		fromText, inFrom := from[id]
+		toText, inTo := to[id]
+
+		item := PackageDiffItem{Kind: DiffKindResource, Component: component, Name: id, fromText: fromText, toText: toText}
+		switch {
+		case !inFrom:
+			item.Change = DiffAdded
+		case !inTo:
+			item.Change = DiffRemoved
+		case fromText != toText:
+			item.Change = DiffChanged
+		default:
+			continue
+		}
+
+		item.Diff = unifiedDiff(fromText, toText, fromLabel, toLabel)
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// unifiedDiffContext is the number of unchanged lines shown around each change in a unified diff.
+const unifiedDiffContext = 3
+
+// unifiedDiff returns the line by line differences between two texts in the unified diff format.
+func unifiedDiff(fromText, toText, fromLabel, toLabel string) string {
+	// Diff the texts line by line by standing each distinct line in for a single rune
+	var lines []string
+	lineRunes := map[string]rune{}
+	toRunes := func(text string) []rune {
+		var runes []rune
+		for _, line := range strings.SplitAfter(text, "\n") {
+			if line == "" {
+				continue
+			}
+			if !strings.HasSuffix(line, "\n") {
+				line += "\n"
+			}
+			r, ok := lineRunes[line]
+			if !ok {
+				// Skip the surrogate range, which cannot be stored in the diffed strings
+				r = rune(len(lines) + 1)
+				if r >= 0xD800 {
+					r += 0x800
+				}
+				lineRunes[line] = r
+				lines = append(lines, line)
+			}
+			runes = append(runes, r)
+		}
+		return runes
+	}
+	lineIndex := func(r rune) int {
+		if r >= 0xD800 {
+			r -= 0x800
+		}
+		return int(r) - 1
+	}
+	fromLineRunes, toLineRunes := toRunes(fromText), toRunes(toText)
+	diffs := diffmatchpatch.New().DiffMainRunes(fromLineRunes, toLineRunes, false)
+
+	type diffLine struct {
+		op   diffmatchpatch.Operation
+		text string
+	}
+	var diffLines []diffLine
+	var changes []int
+	for _, diff := range diffs {
+		for _, r := range diff.Text {
+			if diff.Type != diffmatchpatch.DiffEqual {
+				changes = append(changes, len(diffLines))
+			}
+			diffLines = append(diffLines, diffLine{op: diff.Type, text: lines[lineIndex(r)]})
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	// formatRange formats the range of a hunk the way diff -u does, where an empty range starts on the line before it
+	formatRange := func(start, length int) string {
+		if length == 1 {
+			return fmt.Sprintf("%d", start+1)
+		}
+		if length == 0 {
+			return fmt.Sprintf("%d,0", start)
+		}
+		return fmt.Sprintf("%d,%d", start+1, length)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromLabel, toLabel)
+	for first := 0; first < len(changes); {
+		// Changes separated by no more than twice the context share a hunk
+		last := first
+		for last+1 < len(changes) && changes[last+1]-changes[last] <= 2*unifiedDiffContext+1 {
+			last++
+		}
+		start := max(changes[first]-unifiedDiffContext, 0)
+		end := min(changes[last]+unifiedDiffContext+1, len(diffLines))
+
+		fromStart, toStart := 0, 0
+		for _, line := range diffLines[:start] {
+			if line.op != diffmatchpatch.DiffInsert {
+				fromStart++
+			}
+			if line.op != diffmatchpatch.DiffDelete {
+				toStart++
+			}
+		}
+		fromLength, toLength := 0, 0
+		var hunk strings.Builder
+		for _, line := range diffLines[start:end] {
+			switch line.op {
+			case diffmatchpatch.DiffEqual:
+				fromLength++
+				toLength++
+				hunk.WriteString(" " + line.text)
+			case diffmatchpatch.DiffDelete:
+				fromLength++
+				hunk.WriteString("-" + line.text)
+			case diffmatchpatch.DiffInsert:
+				toLength++
+				hunk.WriteString("+" + line.text)
+			}
+		}
+
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n%s", formatRange(fromStart, fromLength), formatRange(toStart, toLength), hunk.String())
+		first = last + 1
+	}
+
+	return sb.String()
+}
+
+// describeVariable summarizes a variable definition for display, redacting sensitive defaults.
+func describeVariable(variable types.JackalPackageVariable) string {
+	value := variable.Default
+	if variable.Sensitive {
+		value = "**sanitized**"
+	}
+
+	var attributes []string
+	if variable.Prompt {
+		attributes = append(attributes, "prompt")
+	}
+	if variable.Sensitive {
+		attributes = append(attributes, "sensitive")
+	}
+	if variable.Pattern != "" {
+		attributes = append(attributes, fmt.Sprintf("pattern=%s", variable.Pattern))
+	}
+	if variable.Type != "" {
+		attributes = append(attributes, fmt.Sprintf("type=%s", variable.Type))
+	}
+
+	if len(attributes) > 0 {
+		return fmt.Sprintf("%q (%s)", value, strings.Join(attributes, ", "))
+	}
+	return fmt.Sprintf("%q", value)
+}
+
+// sortedKeys returns the keys of a map in sorted order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// printPackageDiff prints a table of the differences between two packages followed by the changes to each resource.
+func printPackageDiff(diff PackageDiff) {
+	describe := func(side PackageDiffSide) string {
+		description := fmt.Sprintf("%s (%s", side.Source, side.Name)
+		if side.Version != "" {
+			description += fmt.Sprintf(" %s", side.Version)
+		}
+		if side.Deployed {
+			description += ", deployed"
+		}
+		return description + ")"
+	}
+
+	message.HeaderInfof("📦 PACKAGE DIFF")
+	message.Notef("From: %s", describe(diff.From))
+	message.Notef("To: %s", describe(diff.To))
+
+	if len(diff.Changes) == 0 {
+		message.Success("The packages are the same")
+		return
+	}
+
+	data := [][]string{}
+	for _, item := range diff.Changes {
+		data = append(data, []string{item.Kind, item.Component, item.Name, string(item.Change), item.From, item.To})
+	}
+	message.Table([]string{"Kind", "Component", "Name", "Change", "From", "To"}, data)
+
+	for _, item := range diff.Changes {
+		if item.Kind != DiffKindResource {
+			continue
+		}
+		message.HorizontalRule()
+		message.Title(fmt.Sprintf("%s %s", item.Name, item.Change), fmt.Sprintf("in component %s", item.Component))
+		message.PrintDiff(item.fromText, item.toText)
+	}
+}
@@ -0,0 +1,169 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package packager contains functions for interacting with, managing and deploying Jackal packages.
+package packager
+
+import (
+	"testing"
+
+	"github.com/racer159/jackal/src/types"
+	"github.com/racer159/jackal/src/types/extensions"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexResources(t *testing.T) {
+	t.Parallel()
+
+	manifest := `---
+# Source: podinfo/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: podinfo
+  namespace: podinfo
+spec:
+  ports:
+  - port: 9898
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: podinfo
+`
+
+	index := make(map[string]string)
+	require.NoError(t, indexResources(manifest, index))
+
+	require.Len(t, index, 2)
+	require.Contains(t, index, "Service/podinfo/podinfo")
+	require.Contains(t, index, "Namespace/podinfo")
+	require.NotContains(t, index["Service/podinfo/podinfo"], "# Source")
+}
+
+func TestDiffPackages(t *testing.T) {
+	t.Parallel()
+
+	from := packageSnapshot{
+		side: PackageDiffSide{Source: "dos-games", Name: "dos-games", Version: "1.0.0", Deployed: true},
+		pkg: types.JackalPackage{
+			Variables: []types.JackalPackageVariable{
+				{Name: "REPLICAS", Default: "1"},
+				{Name: "PASSWORD", Default: "hunter2", Sensitive: true},
+				{Name: "REMOVED"},
+			},
+			Constants: []types.JackalPackageConstant{{Name: "DOMAIN", Value: "example.com"}},
+		},
+		components: []componentSnapshot{
+			{
+				name:   "baseline",
+				images: map[string]string{"ghcr.io/racer159/games:1.0.0": "sha256:aaa", "nginx:1.25": "sha256:bbb", "busybox:1.36": ""},
+				charts: map[string]string{"games": "1.0.0"},
+				repos:  []string{"https://github.com/racer159/games.git"},
+				resources: map[string]string{
+					"Namespace/dos-games":               "kind: Namespace\n",
+					"Service/dos-games/game":            "kind: Service\nport: 8000\n",
+					"ConfigMap/dos-games/removed-thing": "kind: ConfigMap\n",
+				},
+			},
+			{name: "removed-component", images: map[string]string{}, charts: map[string]string{}, resources: map[string]string{}},
+		},
+	}
+
+	to := packageSnapshot{
+		side: PackageDiffSide{Source: "dos-games.tar.zst", Name: "dos-games", Version: "1.1.0"},
+		pkg: types.JackalPackage{
+			Variables: []types.JackalPackageVariable{
+				{Name: "REPLICAS", Default: "2"},
+				{Name: "PASSWORD", Default: "hunter3", Sensitive: true},
+			},
+			Constants: []types.JackalPackageConstant{{Name: "DOMAIN", Value: "example.com"}},
+		},
+		components: []componentSnapshot{
+			{
+				name:   "baseline",
+				images: map[string]string{"ghcr.io/racer159/games:1.0.0": "sha256:ccc", "nginx:1.25": "sha256:bbb", "busybox:1.36": "sha256:ddd"},
+				charts: map[string]string{"games": "1.1.0"},
+				repos:  []string{"https://github.com/racer159/games.git"},
+				resources: map[string]string{
+					"Namespace/dos-games":        "kind: Namespace\nlabels: new\n",
+					"Service/dos-games/game":     "kind: Service\nport: 8080\n",
+					"Secret/dos-games/new-thing": "kind: Secret\n",
+				},
+			},
+			{name: "added-component", images: map[string]string{}, charts: map[string]string{}, resources: map[string]string{}},
+		},
+	}
+
+	diff, err := diffPackages(from, to)
+	require.NoError(t, err)
+
+	require.Equal(t, from.side, diff.From)
+	require.Equal(t, to.side, diff.To)
+
+	type change struct {
+		kind, component, name string
+		change                DiffChange
+		from, to              string
+	}
+	changes := []change{}
+	for _, item := range diff.Changes {
+		changes = append(changes, change{item.Kind, item.Component, item.Name, item.Change, item.From, item.To})
+	}
+
+	expected := []change{
+		{DiffKindVariable, "", "REMOVED", DiffRemoved, `""`, ""},
+		{DiffKindVariable, "", "PASSWORD", DiffChanged, `"**sanitized**" (sensitive)`, `"**sanitized**" (sensitive)`},
+		{DiffKindVariable, "", "REPLICAS", DiffChanged, `"1"`, `"2"`},
+		{DiffKindComponent, "", "removed-component", DiffRemoved, "", ""},
+		// Digests are only compared when both are known
+		{DiffKindImage, "baseline", "ghcr.io/racer159/games:1.0.0", DiffChanged, "sha256:aaa", "sha256:ccc"},
+		{DiffKindChart, "baseline", "games", DiffChanged, "1.0.0", "1.1.0"},
+		// Namespaces are skipped when comparing against a deployed package
+		{DiffKindResource, "baseline", "ConfigMap/dos-games/removed-thing", DiffRemoved, "", ""},
+		{DiffKindResource, "baseline", "Secret/dos-games/new-thing", DiffAdded, "", ""},
+		{DiffKindResource, "baseline", "Service/dos-games/game", DiffChanged, "", ""},
+		{DiffKindComponent, "", "added-component", DiffAdded, "", ""},
+	}
+	require.Equal(t, expected, changes)
+
+	for _, item := range diff.Changes {
+		require.NotContains(t, item.From+item.To, "hunter")
+		if item.Name == "Service/dos-games/game" {
+			require.Contains(t, item.Diff, "--- dos-games\n+++ dos-games.tar.zst\n")
+			require.Contains(t, item.Diff, "-port: 8000\n+port: 8080\n")
+		}
+	}
+}
+
+func TestDiffBigBangPackages(t *testing.T) {
+	t.Parallel()
+
+	bigBangComponent := func(version string, helmReleases ...string) types.JackalComponent {
+		component := types.JackalComponent{
+			Name:       "bigbang",
+			Extensions: extensions.JackalComponentExtensions{BigBang: &extensions.BigBang{Version: version}},
+		}
+		for _, name := range helmReleases {
+			component.Actions.OnDeploy.OnSuccess = append(component.Actions.OnDeploy.OnSuccess, types.JackalComponentAction{
+				Wait: &types.JackalComponentActionWait{
+					Cluster: &types.JackalComponentActionWaitCluster{Kind: "HelmRelease", Identifier: name, Namespace: "bigbang"},
+				},
+			})
+		}
+		return component
+	}
+
+	from := packageSnapshot{components: []componentSnapshot{newComponentSnapshot(bigBangComponent("2.19.0", "istio", "kiali"))}}
+	to := packageSnapshot{components: []componentSnapshot{newComponentSnapshot(bigBangComponent("2.20.0", "istio", "tempo"))}}
+
+	diff, err := diffPackages(from, to)
+	require.NoError(t, err)
+
+	expected := []PackageDiffItem{
+		{Kind: DiffKindBigBang, Component: "bigbang", Name: "version", Change: DiffChanged, From: "2.19.0", To: "2.20.0"},
+		{Kind: DiffKindBigBangPackage, Component: "bigbang", Name: "bigbang.kiali", Change: DiffRemoved, From: "enabled"},
+		{Kind: DiffKindBigBangPackage, Component: "bigbang", Name: "bigbang.tempo", Change: DiffAdded, To: "enabled"},
+	}
+	require.Equal(t, expected, diff.Changes)
+}
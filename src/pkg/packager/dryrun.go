@@ -0,0 +1,517 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package packager contains functions for interacting with, managing and deploying Jackal packages.
+package packager
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/defenseunicorns/pkg/helpers"
+	"github.com/racer159/jackal/src/config"
+	"github.com/racer159/jackal/src/internal/agent/mutate"
+	"github.com/racer159/jackal/src/internal/packager/template"
+	"github.com/racer159/jackal/src/pkg/cluster"
+	"github.com/racer159/jackal/src/pkg/message"
+	"github.com/racer159/jackal/src/pkg/packager/filters"
+	"github.com/racer159/jackal/src/pkg/packager/variables"
+	"github.com/racer159/jackal/src/pkg/transform"
+	"github.com/racer159/jackal/src/pkg/utils"
+	"github.com/racer159/jackal/src/types"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// DryRunPlanFile is the name of the file a dry run writes its plan to within the output directory.
+const DryRunPlanFile = "dry-run.yaml"
+
+// DryRunPlan is what a deployment would do, as recorded by a dry run.
+type DryRunPlan struct {
+	Package    string            `json:"package"`
+	Version    string            `json:"version,omitempty"`
+	Registry   string            `json:"registry"`
+	GitServer  string            `json:"gitServer"`
+	Variables  map[string]string `json:"variables,omitempty"`
+	Components []DryRunComponent `json:"components"`
+}
+
+// DryRunComponent is what a deployment would do for a single component.
+type DryRunComponent struct {
+	Name string `json:"name"`
+	// Images are pushed to the Jackal registry
+	Images []DryRunTransfer `json:"images,omitempty"`
+	// Repos are pushed to the Jackal git server
+	Repos []DryRunTransfer `json:"repos,omitempty"`
+	// Manifests are the rendered manifests for each chart and manifest relative to the output directory
+	Manifests []string `json:"manifests,omitempty"`
+	// Files are copied to these paths on the deploying machine
+	Files []string `json:"files,omitempty"`
+	// DataInjections are copied into these pods or persistent volume claims
+	DataInjections []string `json:"dataInjections,omitempty"`
+	// Actions are run on the deploying machine (they are not run during a dry run)
+	Actions []string `json:"actions,omitempty"`
+}
+
+// DryRunTransfer is an image or repo and where a deployment would push it.
+type DryRunTransfer struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// dryRun renders the selected components the same way they would be deployed and writes the results to
+// p.cfg.DeployOpts.DryRunOutputDir instead of applying them.
+func (p *Packager) dryRun(deployFilter filters.ComponentFilterStrategy, isInteractive bool) (err error) {
+	if isInteractive {
+		p.cfg.Pkg.Components, err = deployFilter.Apply(p.cfg.Pkg)
+		if err != nil {
+			return err
+		}
+
+		// Set variables and prompt if --confirm is not set
+		if err := variables.SetVariableMapInConfig(p.cfg); err != nil {
+			return err
+		}
+	}
+
+	if err := p.setDryRunState(); err != nil {
+		return err
+	}
+
+	if p.valueTemplate, err = template.Generate(p.cfg); err != nil {
+		return fmt.Errorf("unable to generate the value template: %w", err)
+	}
+
+	outputDir := p.cfg.DeployOpts.DryRunOutputDir
+	if outputDir == "" {
+		outputDir = fmt.Sprintf("jackal-dry-run-%s", p.cfg.Pkg.Metadata.Name)
+	}
+	if err := clearDryRunOutput(outputDir); err != nil {
+		return err
+	}
+	if err := helpers.CreateDirectory(outputDir, helpers.ReadWriteExecuteUser); err != nil {
+		return fmt.Errorf("unable to create the dry run output directory %s: %w", outputDir, err)
+	}
+
+	plan := DryRunPlan{
+		Package:   p.cfg.Pkg.Metadata.Name,
+		Version:   p.cfg.Pkg.Metadata.Version,
+		Registry:  p.cfg.State.RegistryInfo.Address,
+		GitServer: p.cfg.State.GitServer.Address,
+		Variables: redactedVariables(p.cfg.GetVariables()),
+	}
+
+	for _, component := range p.cfg.Pkg.Components {
+		planned, err := p.dryRunComponent(component, outputDir)
+		if err != nil {
+			return fmt.Errorf("unable to render component %q: %w", component.Name, err)
+		}
+		plan.Components = append(plan.Components, planned)
+	}
+
+	if err := utils.WriteYaml(filepath.Join(outputDir, DryRunPlanFile), plan, helpers.ReadWriteUser); err != nil {
+		return err
+	}
+
+	printDryRunPlan(plan)
+
+	message.Successf("Jackal dry run complete, the rendered deployment was written to %s", outputDir)
+
+	return nil
+}
+
+// clearDryRunOutput removes the files written by a previous dry run so that removed resources do not linger,
+// refusing to write into any other directory that is not empty.
+//
+// Only the manifests and plan recorded by the previous dry run are removed, anything else added to the directory is left in place.
+func clearDryRunOutput(outputDir string) error {
+	entries, err := os.ReadDir(outputDir)
+	if err != nil || len(entries) == 0 {
+		return nil
+	}
+
+	planPath := filepath.Join(outputDir, DryRunPlanFile)
+	if helpers.InvalidPath(planPath) {
+		return fmt.Errorf("the dry run output directory %s is not empty and does not contain a previous dry run", outputDir)
+	}
+
+	var plan DryRunPlan
+	if err := utils.ReadYaml(planPath, &plan); err != nil {
+		return fmt.Errorf("unable to read the previous dry run plan %s: %w", planPath, err)
+	}
+
+	for _, component := range plan.Components {
+		for _, manifest := range component.Manifests {
+			// The plan could have been edited, so never remove anything outside of the output directory
+			if !filepath.IsLocal(manifest) {
+				return fmt.Errorf("the previous dry run plan %s references the manifest %s outside of the output directory", planPath, manifest)
+			}
+			if err := os.Remove(filepath.Join(outputDir, manifest)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		}
+
+		// Remove the component directory unless something else was added to it
+		if filepath.IsLocal(component.Name) {
+			if entries, err := os.ReadDir(filepath.Join(outputDir, component.Name)); err == nil && len(entries) == 0 {
+				if err := os.Remove(filepath.Join(outputDir, component.Name)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return os.Remove(planPath)
+}
+
+// setDryRunState uses the cluster's state when a cluster is available so that the rendered deployment matches it,
+// otherwise a placeholder state is used.
+//
+// The cluster is only read from, a dry run never initializes or changes the state.
+func (p *Packager) setDryRunState() error {
+	if c, err := cluster.NewCluster(); err == nil {
+		if state, err := c.LoadJackalState(); err == nil {
+			p.cfg.State = state
+			return nil
+		}
+	}
+
+	message.Note("Unable to load the Jackal state from a cluster, rendering with placeholder registry and git server values")
+
+	state, err := placeholderState()
+	if err != nil {
+		return err
+	}
+	p.cfg.State = state
+
+	return nil
+}
+
+// dryRunComponent renders a single component and writes its manifests to the output directory.
+func (p *Packager) dryRunComponent(component types.JackalComponent, outputDir string) (planned DryRunComponent, err error) {
+	message.HeaderInfof("📦 %s COMPONENT", strings.ToUpper(component.Name))
+
+	planned.Name = component.Name
+
+	// Jackal init handles these components specially and does not push their images with a checksum (or at all)
+	noImgChecksum := p.cfg.Pkg.IsInitConfig() && component.Name == "jackal-agent"
+	noImgPush := p.cfg.Pkg.IsInitConfig() && component.Name == "jackal-seed-registry"
+
+	transformImage := transform.ImageTransformHost
+	if noImgChecksum {
+		transformImage = transform.ImageTransformHostWithoutChecksum
+	}
+
+	if !noImgPush {
+		for _, image := range helpers.Unique(component.Images) {
+			target, err := transformImage(p.cfg.State.RegistryInfo.Address, image)
+			if err != nil {
+				return planned, err
+			}
+			planned.Images = append(planned.Images, DryRunTransfer{Source: image, Target: target})
+		}
+	}
+
+	for _, repo := range component.Repos {
+		target, err := transform.GitURL(p.cfg.State.GitServer.Address, repo, p.cfg.State.GitServer.PushUsername)
+		if err != nil {
+			return planned, err
+		}
+		planned.Repos = append(planned.Repos, DryRunTransfer{Source: repo, Target: target.String()})
+	}
+
+	for _, file := range component.Files {
+		target := strings.Replace(file.Target, "###JACKAL_TEMP###", p.layout.Base, 1)
+		planned.Files = append(planned.Files, config.GetAbsHomePath(target))
+	}
+
+	for _, data := range component.DataInjections {
+		if data.Target.PersistentVolumeClaim != "" {
+			planned.DataInjections = append(planned.DataInjections, fmt.Sprintf("%s/persistentvolumeclaim/%s:%s", data.Target.Namespace, data.Target.PersistentVolumeClaim, data.Target.Path))
+			continue
+		}
+		planned.DataInjections = append(planned.DataInjections, fmt.Sprintf("%s/%s:%s", data.Target.Namespace, data.Target.Selector, data.Target.Path))
+	}
+
+	onDeploy := component.Actions.OnDeploy
+	for _, actions := range [][]types.JackalComponentAction{onDeploy.Before, onDeploy.After} {
+		for _, action := range actions {
+			if action.Cmd != "" {
+				planned.Actions = append(planned.Actions, action.Cmd)
+			}
+		}
+	}
+
+	componentPaths, ok := p.layout.Components.Dirs[component.Name]
+	if !ok || len(component.Charts)+len(component.Manifests) == 0 {
+		return planned, nil
+	}
+
+	releases, err := p.renderComponent(componentPaths, component)
+	if err != nil {
+		return planned, err
+	}
+
+	componentDir := filepath.Join(outputDir, component.Name)
+	if err := helpers.CreateDirectory(componentDir, helpers.ReadWriteExecuteUser); err != nil {
+		return planned, err
+	}
+
+	for _, release := range releases {
+		rendered, err := p.mutateLikeAgent(release.manifest, release.namespace)
+		if err != nil {
+			return planned, fmt.Errorf("unable to mutate the resources of %s: %w", release.name, err)
+		}
+
+		path := filepath.Join(component.Name, release.name+".yaml")
+		if err := os.WriteFile(filepath.Join(outputDir, path), []byte(rendered), helpers.ReadWriteUser); err != nil {
+			return planned, err
+		}
+		planned.Manifests = append(planned.Manifests, path)
+	}
+
+	return planned, nil
+}
+
+// mutateLikeAgent applies the image and git URL mutations the Jackal agent makes when resources are applied to the cluster.
+//
+// Images within pod templates are mutated as well so that the workloads that create pods show what their pods will run.
+func (p *Packager) mutateLikeAgent(manifest string, releaseNamespace string) (string, error) {
+	resources, err := utils.SplitYAML([]byte(manifest))
+	if err != nil {
+		return "", err
+	}
+
+	ignoredNamespaces := make(map[string]bool)
+	for _, resource := range resources {
+		if resource.GetKind() == "Namespace" && mutate.IsIgnored(resource.GetLabels()) {
+			ignoredNamespaces[resource.GetName()] = true
+		}
+	}
+
+	var mutated []string
+	for _, resource := range resources {
+		namespace := resource.GetNamespace()
+		if namespace == "" {
+			namespace = releaseNamespace
+		}
+
+		if !ignoredNamespaces[namespace] {
+			if err := p.mutateResource(resource); err != nil {
+				return "", err
+			}
+		}
+
+		content, err := yaml.Marshal(resource.Object)
+		if err != nil {
+			return "", err
+		}
+		mutated = append(mutated, string(content))
+	}
+
+	return "---\n" + strings.Join(mutated, "---\n"), nil
+}
+
+// mutateResource mutates a single resource in place the way the Jackal agent would.
+func (p *Packager) mutateResource(resource *unstructured.Unstructured) error {
+	jackalState := p.cfg.State
+
+	var podSpecPath []string
+	switch resource.GetKind() {
+	case "Pod":
+		if mutate.IsIgnored(resource.GetLabels()) {
+			return nil
+		}
+		podSpecPath = []string{"spec"}
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job":
+		podSpecPath = []string{"spec", "template", "spec"}
+	case "CronJob":
+		podSpecPath = []string{"spec", "jobTemplate", "spec", "template", "spec"}
+	case "GitRepository":
+		return mutateURLField(resource.Object, func(repoURL string) (string, bool, error) {
+			patchedURL, err := mutate.GitURL(jackalState, repoURL, false)
+			return patchedURL, true, err
+		}, "spec", "url")
+	case "HelmRepository":
+		repoType, _, _ := unstructured.NestedString(resource.Object, "spec", "type")
+		return mutateURLField(resource.Object, func(repoURL string) (string, bool, error) {
+			return mutate.FluxHelmRepositoryURL(jackalState, repoURL, repoType, false)
+		}, "spec", "url")
+	case "OCIRepository":
+		return mutateOCIRepository(jackalState, resource)
+	case "Application":
+		if source, found, _ := unstructured.NestedMap(resource.Object, "spec", "source"); found {
+			if err := mutateArgoSource(jackalState, source); err != nil {
+				return err
+			}
+			if err := unstructured.SetNestedMap(resource.Object, source, "spec", "source"); err != nil {
+				return err
+			}
+		}
+		sources, found, err := unstructured.NestedSlice(resource.Object, "spec", "sources")
+		if err != nil || !found {
+			return err
+		}
+		for _, source := range sources {
+			if source, ok := source.(map[string]any); ok {
+				if err := mutateArgoSource(jackalState, source); err != nil {
+					return err
+				}
+			}
+		}
+		return unstructured.SetNestedSlice(resource.Object, sources, "spec", "sources")
+	case "ApplicationSet":
+		// The template is left alone as the Applications it generates are mutated as they are admitted
+		generators, found, err := unstructured.NestedSlice(resource.Object, "spec", "generators")
+		if err != nil || !found {
+			return err
+		}
+		if err := mutateArgoGenerators(jackalState, generators); err != nil {
+			return err
+		}
+		return unstructured.SetNestedSlice(resource.Object, generators, "spec", "generators")
+	default:
+		return nil
+	}
+
+	if len(podSpecPath) > 1 {
+		// Pod templates carry the labels their pods will be created with
+		labelsPath := append(slices.Clone(podSpecPath[:len(podSpecPath)-1]), "metadata", "labels")
+		templateLabels, _, _ := unstructured.NestedStringMap(resource.Object, labelsPath...)
+		if mutate.IsIgnored(templateLabels) {
+			return nil
+		}
+	}
+
+	podSpecObject, found, err := unstructured.NestedMap(resource.Object, podSpecPath...)
+	if err != nil || !found {
+		return err
+	}
+	var podSpec corev1.PodSpec
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(podSpecObject, &podSpec); err != nil {
+		return fmt.Errorf("unable to read the pod spec of %s %s: %w", resource.GetKind(), resource.GetName(), err)
+	}
+
+	for _, image := range mutate.PodImages(jackalState.RegistryInfo.Address, podSpec) {
+		containers, _, _ := unstructured.NestedSlice(resource.Object, append(podSpecPath, image.Field)...)
+		container, ok := containers[image.Index].(map[string]any)
+		if !ok {
+			continue
+		}
+		container["image"] = image.Image
+		if err := unstructured.SetNestedSlice(resource.Object, containers, append(podSpecPath, image.Field)...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mutateURLField replaces the URL at the given fields of an object with the URL the agent would patch it to.
+//
+// A URL the agent would fail to mutate is left as-is with a warning so that the rest of the resources can still be shown.
+func mutateURLField(object map[string]any, patch func(string) (string, bool, error), fields ...string) error {
+	repoURL, found, err := unstructured.NestedString(object, fields...)
+	if err != nil || !found || repoURL == "" {
+		return err
+	}
+
+	patchedURL, ok, err := patch(repoURL)
+	if err != nil {
+		message.Warnf("Unable to mutate the url %s: %s", repoURL, err.Error())
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+
+	return unstructured.SetNestedField(object, patchedURL, fields...)
+}
+
+// mutateOCIRepository transforms the URL of a Flux OCIRepository, and the tag it is pinned to, to point at the Jackal registry.
+func mutateOCIRepository(jackalState *types.JackalState, resource *unstructured.Unstructured) error {
+	refFields, _, _ := unstructured.NestedStringMap(resource.Object, "spec", "ref")
+	ref := mutate.OCIReference{Digest: refFields["digest"], SemVer: refFields["semver"], Tag: refFields["tag"]}
+
+	var patchedTag string
+	err := mutateURLField(resource.Object, func(repoURL string) (patchedURL string, ok bool, err error) {
+		patchedURL, patchedTag, err = mutate.FluxOCIRepository(jackalState, repoURL, ref, false)
+		return patchedURL, true, err
+	}, "spec", "url")
+	if err != nil || patchedTag == "" {
+		return err
+	}
+
+	return unstructured.SetNestedField(resource.Object, patchedTag, "spec", "ref", "tag")
+}
+
+// mutateArgoSource transforms the repoURL of an Argo CD source in place.
+func mutateArgoSource(jackalState *types.JackalState, source map[string]any) error {
+	chart, _, _ := unstructured.NestedString(source, "chart")
+	return mutateURLField(source, func(repoURL string) (string, bool, error) {
+		patchedURL, ok := mutate.ArgoSourceURL(jackalState, repoURL, chart, false)
+		return patchedURL, ok, nil
+	}, "repoURL")
+}
+
+// mutateArgoGenerators transforms the repoURLs of Argo CD git generators in place, including those nested in matrix and merge generators.
+func mutateArgoGenerators(jackalState *types.JackalState, generators []any) error {
+	for _, generator := range generators {
+		generator, ok := generator.(map[string]any)
+		if !ok {
+			continue
+		}
+		err := mutateURLField(generator, func(repoURL string) (string, bool, error) {
+			patchedURL, ok := mutate.ArgoGeneratorURL(jackalState, repoURL, false)
+			return patchedURL, ok, nil
+		}, "git", "repoURL")
+		if err != nil {
+			return err
+		}
+		for _, nested := range []string{"matrix", "merge"} {
+			nestedGenerators, found, err := unstructured.NestedSlice(generator, nested, "generators")
+			if err != nil || !found {
+				continue
+			}
+			if err := mutateArgoGenerators(jackalState, nestedGenerators); err != nil {
+				return err
+			}
+			if err := unstructured.SetNestedSlice(generator, nestedGenerators, nested, "generators"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// printDryRunPlan prints the images and repos a deployment would push.
+func printDryRunPlan(plan DryRunPlan) {
+	images := [][]string{}
+	repos := [][]string{}
+	for _, component := range plan.Components {
+		for _, image := range component.Images {
+			images = append(images, []string{component.Name, image.Source, image.Target})
+		}
+		for _, repo := range component.Repos {
+			repos = append(repos, []string{component.Name, repo.Source, repo.Target})
+		}
+	}
+
+	if len(images) > 0 {
+		message.Title("Images", "would be pushed to the Jackal registry as")
+		message.Table([]string{"Component", "Image", "Target"}, images)
+	}
+	if len(repos) > 0 {
+		message.Title("Repos", "would be pushed to the Jackal git server as")
+		message.Table([]string{"Component", "Repo", "Target"}, repos)
+	}
+
+	message.Notef("%d components rendered, %d images and %d repos would be pushed", len(plan.Components), len(images), len(repos))
+}
@@ -0,0 +1,226 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package packager contains functions for interacting with, managing and deploying Jackal packages.
+package packager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/racer159/jackal/src/pkg/transform"
+	"github.com/racer159/jackal/src/pkg/utils"
+	"github.com/racer159/jackal/src/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMutateLikeAgent(t *testing.T) {
+	t.Parallel()
+
+	p := &Packager{
+		cfg: &types.PackagerConfig{
+			State: &types.JackalState{
+				RegistryInfo: types.RegistryInfo{Address: "127.0.0.1:31999"},
+				GitServer:    types.GitServerInfo{Address: "http://jackal-gitea-http.jackal.svc.cluster.local:3000", PushUsername: "jackal-git-user"},
+			},
+		},
+	}
+
+	manifest := `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: podinfo
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: busybox:1.36
+      containers:
+      - name: podinfo
+        image: ghcr.io/stefanprodan/podinfo:6.4.0
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: ignored
+spec:
+  template:
+    metadata:
+      labels:
+        jackal.dev/agent: ignore
+    spec:
+      containers:
+      - name: ignored
+        image: nginx:1.25
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: skipped
+  namespace: skipped
+spec:
+  containers:
+  - name: skipped
+    image: nginx:1.25
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: skipped
+  labels:
+    jackal.dev/agent: skip
+---
+apiVersion: source.toolkit.fluxcd.io/v1
+kind: GitRepository
+metadata:
+  name: podinfo
+spec:
+  url: https://github.com/stefanprodan/podinfo.git
+---
+apiVersion: source.toolkit.fluxcd.io/v1beta2
+kind: OCIRepository
+metadata:
+  name: podinfo
+spec:
+  url: oci://ghcr.io/stefanprodan/manifests/podinfo
+  ref:
+    tag: 6.4.0
+---
+apiVersion: source.toolkit.fluxcd.io/v1beta2
+kind: HelmRepository
+metadata:
+  name: bitnami
+spec:
+  url: https://charts.bitnami.com/bitnami
+---
+apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: podinfo
+spec:
+  sources:
+  - repoURL: https://github.com/stefanprodan/podinfo.git
+  - repoURL: ghcr.io/stefanprodan/charts
+    chart: podinfo
+  - repoURL: oci://ghcr.io/stefanprodan/charts
+    chart: podinfo
+  - repoURL: https://charts.bitnami.com/bitnami
+    chart: nginx
+---
+apiVersion: argoproj.io/v1alpha1
+kind: ApplicationSet
+metadata:
+  name: podinfo
+spec:
+  generators:
+  - matrix:
+      generators:
+      - git:
+          repoURL: https://github.com/stefanprodan/podinfo.git
+      - git:
+          repoURL: "{{url}}"
+  template:
+    spec:
+      source:
+        repoURL: https://github.com/stefanprodan/podinfo.git
+`
+
+	mutated, err := p.mutateLikeAgent(manifest, "podinfo")
+	require.NoError(t, err)
+
+	resources, err := utils.SplitYAML([]byte(mutated))
+	require.NoError(t, err)
+	require.Len(t, resources, 9)
+
+	busybox, err := transform.ImageTransformHost("127.0.0.1:31999", "busybox:1.36")
+	require.NoError(t, err)
+	podinfo, err := transform.ImageTransformHost("127.0.0.1:31999", "ghcr.io/stefanprodan/podinfo:6.4.0")
+	require.NoError(t, err)
+	ociArtifact, err := transform.ImageTransformHost("127.0.0.1:31999", "ghcr.io/stefanprodan/manifests/podinfo:6.4.0")
+	require.NoError(t, err)
+	gitURL, err := transform.GitURL("http://jackal-gitea-http.jackal.svc.cluster.local:3000", "https://github.com/stefanprodan/podinfo.git", "jackal-git-user")
+	require.NoError(t, err)
+
+	containerImage := func(idx int, containerType string) string {
+		t.Helper()
+		containers := resources[idx].Object["spec"].(map[string]any)["template"].(map[string]any)["spec"].(map[string]any)[containerType].([]any)
+		return containers[0].(map[string]any)["image"].(string)
+	}
+
+	require.Equal(t, busybox, containerImage(0, "initContainers"))
+	require.Equal(t, podinfo, containerImage(0, "containers"))
+	require.Equal(t, "nginx:1.25", containerImage(1, "containers"))
+
+	pod := resources[2].Object["spec"].(map[string]any)["containers"].([]any)[0].(map[string]any)
+	require.Equal(t, "nginx:1.25", pod["image"])
+
+	require.Equal(t, gitURL.String(), resources[4].Object["spec"].(map[string]any)["url"])
+	ociRepo := resources[5].Object["spec"].(map[string]any)
+	require.Equal(t, "oci://127.0.0.1:31999/stefanprodan/manifests/podinfo", ociRepo["url"])
+	require.Equal(t, ociArtifact, "127.0.0.1:31999/stefanprodan/manifests/podinfo:"+ociRepo["ref"].(map[string]any)["tag"].(string))
+	// Helm repositories that are not OCI are left untouched
+	require.Equal(t, "https://charts.bitnami.com/bitnami", resources[6].Object["spec"].(map[string]any)["url"])
+
+	sources := resources[7].Object["spec"].(map[string]any)["sources"].([]any)
+	require.Equal(t, gitURL.String(), sources[0].(map[string]any)["repoURL"])
+	// OCI helm charts keep the scheme (or lack of one) they were referenced with
+	require.Equal(t, "127.0.0.1:31999/stefanprodan/charts", sources[1].(map[string]any)["repoURL"])
+	require.Equal(t, "oci://127.0.0.1:31999/stefanprodan/charts", sources[2].(map[string]any)["repoURL"])
+	require.Equal(t, "https://charts.bitnami.com/bitnami", sources[3].(map[string]any)["repoURL"])
+
+	// Only the git generators are mutated, the template is mutated once the Applications are generated
+	appSet := resources[8].Object["spec"].(map[string]any)
+	generators := appSet["generators"].([]any)[0].(map[string]any)["matrix"].(map[string]any)["generators"].([]any)
+	require.Equal(t, gitURL.String(), generators[0].(map[string]any)["git"].(map[string]any)["repoURL"])
+	require.Equal(t, "{{url}}", generators[1].(map[string]any)["git"].(map[string]any)["repoURL"])
+	template := appSet["template"].(map[string]any)["spec"].(map[string]any)["source"].(map[string]any)
+	require.Equal(t, "https://github.com/stefanprodan/podinfo.git", template["repoURL"])
+}
+
+func TestClearDryRunOutput(t *testing.T) {
+	t.Parallel()
+
+	// Missing and empty directories are left alone
+	require.NoError(t, clearDryRunOutput(filepath.Join(t.TempDir(), "missing")))
+	require.NoError(t, clearDryRunOutput(t.TempDir()))
+
+	// Directories that were not written by a dry run are never removed
+	unrelated := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(unrelated, "important.txt"), []byte("keep me"), 0600))
+	require.Error(t, clearDryRunOutput(unrelated))
+	require.FileExists(t, filepath.Join(unrelated, "important.txt"))
+
+	// Only the files written by the previous dry run are removed
+	previous := t.TempDir()
+	plan := DryRunPlan{Package: "test", Components: []DryRunComponent{
+		{Name: "podinfo", Manifests: []string{filepath.Join("podinfo", "podinfo.yaml"), filepath.Join("podinfo", "removed.yaml")}},
+		{Name: "notes", Manifests: []string{filepath.Join("notes", "notes.yaml")}},
+	}}
+	require.NoError(t, utils.WriteYaml(filepath.Join(previous, DryRunPlanFile), plan, 0600))
+	for _, dir := range []string{"podinfo", "notes"} {
+		require.NoError(t, os.Mkdir(filepath.Join(previous, dir), 0700))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(previous, "podinfo", "podinfo.yaml"), []byte("kind: Deployment"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(previous, "notes", "notes.yaml"), []byte("kind: ConfigMap"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(previous, "notes", "review.md"), []byte("keep me"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(previous, "README.md"), []byte("keep me"), 0600))
+
+	require.NoError(t, clearDryRunOutput(previous))
+	require.NoFileExists(t, filepath.Join(previous, DryRunPlanFile))
+	require.NoDirExists(t, filepath.Join(previous, "podinfo"))
+	require.NoFileExists(t, filepath.Join(previous, "notes", "notes.yaml"))
+	require.FileExists(t, filepath.Join(previous, "notes", "review.md"))
+	require.FileExists(t, filepath.Join(previous, "README.md"))
+
+	// Manifests outside of the output directory are never removed
+	outside := filepath.Join(t.TempDir(), "outside.yaml")
+	require.NoError(t, os.WriteFile(outside, []byte("keep me"), 0600))
+	edited := t.TempDir()
+	plan = DryRunPlan{Package: "test", Components: []DryRunComponent{{Name: "podinfo", Manifests: []string{outside}}}}
+	require.NoError(t, utils.WriteYaml(filepath.Join(edited, DryRunPlanFile), plan, 0600))
+	require.Error(t, clearDryRunOutput(edited))
+	require.FileExists(t, outside)
+}
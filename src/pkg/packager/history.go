@@ -0,0 +1,252 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package packager contains functions for interacting with, managing and deploying Jackal packages.
+package packager
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/defenseunicorns/pkg/helpers"
+	"github.com/racer159/jackal/src/config"
+	"github.com/racer159/jackal/src/internal/packager/helm"
+	"github.com/racer159/jackal/src/pkg/message"
+	"github.com/racer159/jackal/src/pkg/packager/sources"
+	"github.com/racer159/jackal/src/types"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// History prints the recorded generations of a deployed package.
+func (p *Packager) History() error {
+	if err := p.useClusterSource(); err != nil {
+		return err
+	}
+	packageName := p.cfg.PkgOpts.PackageSource
+
+	history, err := p.cluster.GetPackageHistory(packageName)
+	if err != nil {
+		return fmt.Errorf("unable to get the deploy history of package %q: %w", packageName, err)
+	}
+	if len(history) == 0 {
+		return fmt.Errorf("no deploy history was found for package %q", packageName)
+	}
+
+	if p.cfg.HistoryOpts.OutputFormat == "json" {
+		fmt.Println(message.JSONValue(history))
+		return nil
+	}
+
+	historyData := [][]string{}
+	for _, generation := range history {
+		var components []string
+		for _, component := range generation.DeployedComponents {
+			components = append(components, component.Name)
+		}
+
+		var releases []string
+		for _, release := range generation.Releases {
+			releases = append(releases, fmt.Sprintf("%s/%s@%d", release.Namespace, release.ChartName, release.Revision))
+		}
+
+		historyData = append(historyData, []string{
+			fmt.Sprintf("%d", generation.Generation),
+			generation.DeployedAt.Local().Format(time.DateTime),
+			generation.Data.Metadata.Version,
+			fmt.Sprintf("%v", components),
+			strings.Join(releases, ", "),
+			generation.Description,
+		})
+	}
+
+	header := []string{"Generation", "Deployed", "Version", "Components", "Helm Releases", "Description"}
+	message.Table(header, historyData)
+
+	return nil
+}
+
+// Rollback reinstates the Helm release revisions recorded for a previous generation of a deployed package.
+//
+// Releases installed since that generation are uninstalled. Images, repos, files, data injections and actions are not re-run.
+func (p *Packager) Rollback() error {
+	if err := p.useClusterSource(); err != nil {
+		return err
+	}
+	packageName := p.cfg.PkgOpts.PackageSource
+
+	deployedPackage, err := p.cluster.GetDeployedPackage(packageName)
+	if err != nil {
+		return fmt.Errorf("unable to load the secret for the package we are attempting to roll back: %w", err)
+	}
+
+	target, err := p.cluster.GetPackageGeneration(packageName, p.cfg.RollbackOpts.Generation)
+	if err != nil {
+		return err
+	}
+
+	// Helm is configured from the package that is being rolled back to
+	p.cfg.Pkg = target.Data
+
+	spinner := message.NewProgressSpinner("Rolling back package %q from generation %d to generation %d", packageName, deployedPackage.Generation, target.Generation)
+	defer spinner.Stop()
+
+	rollback, remove := planRollback(deployedPackage, target)
+
+	helmCfg := helm.NewClusterOnly(p.cfg, p.cluster)
+	for _, chart := range helpers.Reverse(remove) {
+		spinner.Updatef("Uninstalling chart %q from the %q namespace", chart.ChartName, chart.Namespace)
+		if err := helmCfg.RemoveChart(chart.Namespace, chart.ChartName, spinner); err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+			return fmt.Errorf("unable to uninstall the helm chart %s in the namespace %s: %w", chart.ChartName, chart.Namespace, err)
+		}
+	}
+
+	for _, release := range rollback {
+		current, err := helmCfg.GetReleaseRevision(release.Namespace, release.ChartName, spinner)
+		if err != nil {
+			return err
+		}
+
+		// Nothing to do if the release is already at the recorded revision
+		if current == release.Revision {
+			continue
+		}
+
+		if current == 0 {
+			return fmt.Errorf("the helm chart %s in the namespace %s has been uninstalled and can no longer be rolled back to revision %d", release.ChartName, release.Namespace, release.Revision)
+		}
+
+		spinner.Updatef("Rolling back chart %q in the %q namespace to revision %d", release.ChartName, release.Namespace, release.Revision)
+		if err := helmCfg.RollbackRelease(release.Namespace, release.ChartName, release.Revision, spinner); err != nil {
+			return fmt.Errorf("unable to roll back the helm chart %s in the namespace %s: %w", release.ChartName, release.Namespace, err)
+		}
+	}
+
+	// A rollback is recorded as a new generation, in the same way Helm records a rollback as a new revision
+	p.generation = deployedPackage.Generation + 1
+
+	components := []types.DeployedComponent{}
+	for _, component := range target.DeployedComponents {
+		component.ObservedGeneration = p.generation
+		components = append(components, component)
+	}
+
+	spinner.Updatef("Updating the package secret")
+	if _, err := p.cluster.RecordPackageDeployment(target.Data, components, target.ConnectStrings, p.generation); err != nil {
+		return err
+	}
+
+	generation := types.DeployedPackageGeneration{
+		Name:               packageName,
+		Generation:         p.generation,
+		Description:        fmt.Sprintf("Rollback to %d", target.Generation),
+		Data:               target.Data,
+		DeployedComponents: components,
+		ConnectStrings:     target.ConnectStrings,
+		Variables:          target.Variables,
+	}
+	if err := p.recordGeneration(generation, spinner); err != nil {
+		return fmt.Errorf("unable to record the deploy history of package %q: %w", packageName, err)
+	}
+
+	spinner.Successf("Rolled back package %q to generation %d", packageName, target.Generation)
+
+	return nil
+}
+
+// useClusterSource connects the packager to the cluster of a deployed package source.
+func (p *Packager) useClusterSource() error {
+	clusterSource, ok := p.source.(*sources.ClusterSource)
+	if !ok {
+		return fmt.Errorf("%q is not the name of a package deployed to the cluster", p.cfg.PkgOpts.PackageSource)
+	}
+	p.cluster = clusterSource.Cluster
+	return nil
+}
+
+// planRollback returns the release revisions that must be reinstated to roll a deployed package back to a recorded generation,
+// and the releases that were installed since that generation and must be uninstalled.
+func planRollback(deployedPackage *types.DeployedPackage, target *types.DeployedPackageGeneration) (rollback []types.DeployedRelease, remove []types.InstalledChart) {
+	recorded := map[types.InstalledChart]bool{}
+	for _, release := range target.Releases {
+		recorded[types.InstalledChart{Namespace: release.Namespace, ChartName: release.ChartName}] = true
+
+		// Releases that were not installed when the generation was recorded have nothing to reinstate
+		if release.Revision > 0 {
+			rollback = append(rollback, release)
+		}
+	}
+
+	for _, component := range deployedPackage.DeployedComponents {
+		for _, chart := range component.InstalledCharts {
+			if !recorded[chart] {
+				remove = append(remove, chart)
+			}
+		}
+	}
+
+	return rollback, remove
+}
+
+// recordGeneration saves a generation of the package, along with the current revision of each of its Helm releases, to the package's deploy history.
+func (p *Packager) recordGeneration(generation types.DeployedPackageGeneration, spinner *message.Spinner) error {
+	spinner.Updatef("Recording generation %d of package %q in its deploy history", generation.Generation, generation.Name)
+
+	helmCfg := helm.NewClusterOnly(p.cfg, p.cluster)
+	for _, component := range generation.DeployedComponents {
+		for _, chart := range component.InstalledCharts {
+			revision, err := helmCfg.GetReleaseRevision(chart.Namespace, chart.ChartName, spinner)
+			if err != nil {
+				return err
+			}
+			generation.Releases = append(generation.Releases, types.DeployedRelease{
+				Component: component.Name,
+				Namespace: chart.Namespace,
+				ChartName: chart.ChartName,
+				Revision:  revision,
+			})
+		}
+	}
+
+	generation.DeployedAt = time.Now()
+	generation.CLIVersion = config.CLIVersion
+
+	return p.cluster.RecordPackageGeneration(generation)
+}
+
+// recordDeployHistory saves the generation that was just deployed to the package's deploy history.
+func (p *Packager) recordDeployHistory(deployedComponents []types.DeployedComponent) error {
+	spinner := message.NewProgressSpinner("Recording the deploy history of package %q", p.cfg.Pkg.Metadata.Name)
+	defer spinner.Stop()
+
+	generation := types.DeployedPackageGeneration{
+		Name:               p.cfg.Pkg.Metadata.Name,
+		Generation:         p.generation,
+		Description:        "Deploy complete",
+		Data:               p.cfg.Pkg,
+		DeployedComponents: deployedComponents,
+		ConnectStrings:     p.connectStrings,
+		Variables:          redactedVariables(p.cfg.GetVariables()),
+	}
+	if err := p.recordGeneration(generation, spinner); err != nil {
+		return err
+	}
+
+	spinner.Success()
+
+	return nil
+}
+
+// redactedVariables returns the values of the variables set for a deployment with sensitive values redacted.
+func redactedVariables(setVariableMap map[string]types.JackalSetVariable) map[string]string {
+	variables := make(map[string]string)
+	for name, variable := range setVariableMap {
+		value := variable.Value
+		if variable.Sensitive {
+			value = "**sanitized**"
+		}
+		variables[name] = value
+	}
+	return variables
+}
@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package packager contains functions for interacting with, managing and deploying Jackal packages.
+package packager
+
+import (
+	"testing"
+
+	"github.com/racer159/jackal/src/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanRollback(t *testing.T) {
+	t.Parallel()
+
+	deployedPackage := &types.DeployedPackage{
+		Name:       "test",
+		Generation: 3,
+		DeployedComponents: []types.DeployedComponent{
+			{Name: "baseline", InstalledCharts: []types.InstalledChart{{Namespace: "test", ChartName: "podinfo"}, {Namespace: "test", ChartName: "added-chart"}}},
+			{Name: "added-component", InstalledCharts: []types.InstalledChart{{Namespace: "other", ChartName: "added"}}},
+		},
+	}
+
+	target := &types.DeployedPackageGeneration{
+		Name:       "test",
+		Generation: 1,
+		Releases: []types.DeployedRelease{
+			{Component: "baseline", Namespace: "test", ChartName: "podinfo", Revision: 2},
+			{Component: "removed-component", Namespace: "test", ChartName: "removed", Revision: 1},
+			{Component: "removed-component", Namespace: "test", ChartName: "never-installed", Revision: 0},
+		},
+	}
+
+	rollback, remove := planRollback(deployedPackage, target)
+
+	require.Equal(t, []types.DeployedRelease{
+		{Component: "baseline", Namespace: "test", ChartName: "podinfo", Revision: 2},
+		{Component: "removed-component", Namespace: "test", ChartName: "removed", Revision: 1},
+	}, rollback)
+	require.Equal(t, []types.InstalledChart{
+		{Namespace: "test", ChartName: "added-chart"},
+		{Namespace: "other", ChartName: "added"},
+	}, remove)
+}
+
+func TestRedactedVariables(t *testing.T) {
+	t.Parallel()
+
+	variables := redactedVariables(map[string]types.JackalSetVariable{
+		"REPLICAS": {Name: "REPLICAS", Value: "2"},
+		"PASSWORD": {Name: "PASSWORD", Value: "hunter2", Sensitive: true},
+	})
+
+	require.Equal(t, map[string]string{"REPLICAS": "2", "PASSWORD": "**sanitized**"}, variables)
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/defenseunicorns/pkg/helpers"
 	"github.com/racer159/jackal/src/config"
 	"github.com/racer159/jackal/src/config/lang"
+	"github.com/racer159/jackal/src/internal/packager/validate"
 	"github.com/racer159/jackal/src/pkg/layout"
 	"github.com/racer159/jackal/src/pkg/packager/composer"
 	"github.com/racer159/jackal/src/pkg/packager/creator"
@@ -93,11 +94,18 @@ func lintComponents(validator *Validator, createOpts *types.JackalCreateOptions)
 			})
 		}
 
+		// The images of every component in the import chain are merged into the component that is deployed
+		var images []string
+		for node := baseComponent; node != nil; node = node.Next() {
+			images = append(images, node.Images...)
+		}
+
 		node := baseComponent
 		for node != nil {
 			checkForVarInComponentImport(validator, node)
 			fillComponentTemplate(validator, node, createOpts)
 			lintComponent(validator, node)
+			checkForInvalidDataInjections(validator, node, images)
 			node = node.Next()
 		}
 	}
@@ -232,6 +240,19 @@ func checkForUnpinnedFiles(validator *Validator, node *composer.Node) {
 	}
 }
 
+func checkForInvalidDataInjections(validator *Validator, node *composer.Node, images []string) {
+	for j, data := range node.DataInjections {
+		if err := validate.DataInjection(images, data); err != nil {
+			validator.addError(validatorMessage{
+				yqPath:         fmt.Sprintf(".components.[%d].dataInjections.[%d].target", node.Index(), j),
+				packageRelPath: node.ImportLocation(),
+				packageName:    node.OriginalPackageName(),
+				description:    err.Error(),
+			})
+		}
+	}
+}
+
 func checkForVarInComponentImport(validator *Validator, node *composer.Node) {
 	if strings.Contains(node.Import.Path, types.JackalPackageTemplatePrefix) {
 		validator.addWarning(validatorMessage{
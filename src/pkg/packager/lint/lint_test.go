@@ -159,6 +159,27 @@ func TestValidateSchema(t *testing.T) {
 		require.Equal(t, 1, len(validator.findings))
 	})
 
+	t.Run("Invalid data injection error", func(t *testing.T) {
+		validator := Validator{}
+		loaderImage := "busybox:1.36"
+		component := types.JackalComponent{DataInjections: []types.JackalDataInjection{
+			{Target: types.JackalContainerTarget{Selector: "app=data", Container: "data", Path: "/data"}},
+			{Target: types.JackalContainerTarget{PersistentVolumeClaim: "data", LoaderImage: loaderImage, Path: "/data"}},
+			{Target: types.JackalContainerTarget{Selector: "app=data", Path: "/missing-container"}},
+			{Target: types.JackalContainerTarget{Selector: "app=data", Container: "data", PersistentVolumeClaim: "data", LoaderImage: loaderImage, Path: "/both"}},
+			{Target: types.JackalContainerTarget{Selector: "app=data", Container: "data", LoaderImage: loaderImage, Path: "/loader-without-claim"}},
+			{Target: types.JackalContainerTarget{PersistentVolumeClaim: "data", LoaderImage: "alpine:3.19", Path: "/unknown-loader"}},
+		}}
+		checkForInvalidDataInjections(&validator, &composer.Node{JackalComponent: component}, []string{loaderImage})
+		require.Equal(t, 4, len(validator.findings))
+		require.Equal(t, ".components.[0].dataInjections.[2].target", validator.findings[0].yqPath)
+		require.Contains(t, validator.findings[0].description, "/missing-container")
+		require.Contains(t, validator.findings[1].description, "/both")
+		require.Contains(t, validator.findings[2].description, "/loader-without-claim")
+		require.Contains(t, validator.findings[3].description, "alpine:3.19")
+		require.Equal(t, categoryError, validator.findings[3].category)
+	})
+
 	t.Run("Wrap standalone numbers in bracket", func(t *testing.T) {
 		input := "components12.12.import.path"
 		expected := ".components12.[12].import.path"
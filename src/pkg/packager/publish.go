@@ -27,7 +27,7 @@ import (
 // Publish publishes the package to a registry
 func (p *Packager) Publish() (err error) {
 	_, isOCISource := p.source.(*sources.OCISource)
-	if isOCISource && p.cfg.PublishOpts.SigningKeyPath == "" {
+	if isOCISource && p.cfg.PublishOpts.SigningKeyPath == "" && !p.cfg.PublishOpts.Keyless {
 		ctx := context.TODO()
 		// oci --> oci is a special case, where we will use oci.CopyPackage so that we can transfer the package
 		// w/o layers touching the filesystem
@@ -79,7 +79,7 @@ func (p *Packager) Publish() (err error) {
 		}
 
 		// Sign the package if a key has been provided
-		if err := p.layout.SignPackage(p.cfg.PublishOpts.SigningKeyPath, p.cfg.PublishOpts.SigningKeyPassword, !config.CommonOptions.Confirm); err != nil {
+		if err := p.layout.SignPackage(p.cfg.PublishOpts.SigningKeyPath, p.cfg.PublishOpts.SigningKeyPassword, p.cfg.PublishOpts.Keyless, !config.CommonOptions.Confirm); err != nil {
 			return err
 		}
 	}
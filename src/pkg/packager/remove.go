@@ -15,6 +15,7 @@ import (
 	"github.com/defenseunicorns/pkg/helpers"
 	"github.com/racer159/jackal/src/config"
 	"github.com/racer159/jackal/src/internal/packager/helm"
+	"github.com/racer159/jackal/src/internal/packager/images"
 	"github.com/racer159/jackal/src/pkg/cluster"
 	"github.com/racer159/jackal/src/pkg/message"
 	"github.com/racer159/jackal/src/pkg/packager/actions"
@@ -87,6 +88,7 @@ func (p *Packager) Remove() (err error) {
 		}
 	}
 
+	removedDigests := []string{}
 	for _, dc := range helpers.Reverse(deployedPackage.DeployedComponents) {
 		// Only remove the component if it was requested or if we are removing the whole package
 		if !slices.Contains(componentsToRemove, dc.Name) {
@@ -96,11 +98,66 @@ func (p *Packager) Remove() (err error) {
 		if deployedPackage, err = p.removeComponent(deployedPackage, dc, spinner); err != nil {
 			return fmt.Errorf("unable to remove the component '%s': %w", dc.Name, err)
 		}
+
+		for _, image := range dc.Images {
+			removedDigests = append(removedDigests, image.Digest)
+		}
+	}
+
+	if len(removedDigests) > 0 && !p.cfg.RemoveOpts.KeepImages && p.cluster != nil {
+		spinner.Updatef("Removing the images no other package references from the registry")
+		// The package is already removed so a failure to clean up the registry is not fatal
+		if err := p.removeUnreferencedImages(removedDigests); err != nil {
+			message.Warnf("Unable to remove the images of package '%s' from the registry, run `jackal tools registry gc` to try again: %s", packageName, err.Error())
+		}
 	}
 
 	return nil
 }
 
+// removeUnreferencedImages deletes the manifests of the given digests from the registry unless a deployed package (or its deploy history) still references them.
+//
+// Their blobs are only reclaimed by the registry's blob garbage collection, which is not run as it is unsafe while images are being pushed.
+func (p *Packager) removeUnreferencedImages(digests []string) error {
+	state, err := p.cluster.LoadJackalState()
+	if err != nil {
+		return err
+	}
+
+	deployedPackages, errs := p.cluster.GetDeployedJackalPackages()
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	var history []types.DeployedPackageGeneration
+	for _, deployedPackage := range deployedPackages {
+		generations, err := p.cluster.GetPackageHistory(deployedPackage.Name)
+		if err != nil {
+			return err
+		}
+		history = append(history, generations...)
+	}
+
+	gc := images.RegistryGC{
+		RegInfo:  state.RegistryInfo,
+		Insecure: config.CommonOptions.Insecure,
+		Packages: deployedPackages,
+		History:  history,
+		Digests:  digests,
+		// Other packages may be pushing images while this one is removed, so their blobs are left for `jackal tools registry gc`
+		SkipBlobGC: true,
+	}
+	report, err := gc.Run()
+	if err != nil {
+		return err
+	}
+
+	if report.Deleted {
+		message.Successf("Removed %d images from the registry, run `jackal tools registry gc` to reclaim their storage", len(report.Manifests))
+	}
+	return nil
+}
+
 func (p *Packager) updatePackageSecret(deployedPackage types.DeployedPackage) {
 	// Only attempt to update the package secret if we are actually connected to a cluster
 	if p.cluster != nil {
@@ -195,6 +252,11 @@ func (p *Packager) removeComponent(deployedPackage *types.DeployedPackage, deplo
 				message.Warnf("Unable to delete the '%s' package secret: '%s' (this may be normal if the cluster was removed)", secretName, err.Error())
 			}
 		}
+
+		// The deploy history cannot be rolled back to once the package is gone
+		if err := p.cluster.DeletePackageHistory(deployedPackage.Name); err != nil {
+			message.Warnf("Unable to delete the deploy history of package '%s': '%s'", deployedPackage.Name, err.Error())
+		}
 	} else {
 		p.updatePackageSecret(*deployedPackage)
 	}
@@ -6,14 +6,24 @@ package sources
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 
 	"github.com/defenseunicorns/pkg/helpers"
+	"github.com/racer159/jackal/src/config"
+	"github.com/racer159/jackal/src/internal/packager/git"
+	"github.com/racer159/jackal/src/internal/packager/helm"
+	"github.com/racer159/jackal/src/internal/packager/images"
 	"github.com/racer159/jackal/src/internal/packager/validate"
 	"github.com/racer159/jackal/src/pkg/cluster"
+	"github.com/racer159/jackal/src/pkg/k8s"
 	"github.com/racer159/jackal/src/pkg/layout"
+	"github.com/racer159/jackal/src/pkg/message"
 	"github.com/racer159/jackal/src/pkg/packager/filters"
+	"github.com/racer159/jackal/src/pkg/transform"
 	"github.com/racer159/jackal/src/pkg/utils"
 	"github.com/racer159/jackal/src/types"
+	corev1 "k8s.io/api/core/v1"
 )
 
 var (
@@ -39,18 +49,236 @@ type ClusterSource struct {
 	*cluster.Cluster
 }
 
-// LoadPackage loads a package from a cluster.
+// LoadPackage rebuilds a package from a cluster.
 //
-// This is not implemented.
-func (s *ClusterSource) LoadPackage(_ *layout.PackagePaths, _ filters.ComponentFilterStrategy, _ bool) (types.JackalPackage, []string, error) {
-	return types.JackalPackage{}, nil, fmt.Errorf("not implemented")
+// Charts and manifests are exported from their Helm releases, images are pulled from the Jackal registry and
+// repos are cloned from the Jackal git server. Files and data injections cannot be recovered from a cluster and are dropped.
+func (s *ClusterSource) LoadPackage(dst *layout.PackagePaths, filter filters.ComponentFilterStrategy, unarchiveAll bool) (pkg types.JackalPackage, warnings []string, err error) {
+	dpkg, err := s.GetDeployedPackage(s.PackageSource)
+	if err != nil {
+		return pkg, nil, err
+	}
+	pkg = dpkg.Data
+
+	// Only components that finished deploying have their artifacts in the cluster
+	deployed := []types.JackalComponent{}
+	for _, component := range pkg.Components {
+		succeeded := false
+		for _, deployedComponent := range dpkg.DeployedComponents {
+			if deployedComponent.Name == component.Name && deployedComponent.Status == types.ComponentStatusSucceeded {
+				succeeded = true
+				break
+			}
+		}
+		if succeeded {
+			deployed = append(deployed, component)
+		} else {
+			warnings = append(warnings, fmt.Sprintf("Component %q was not successfully deployed and will not be included in the package", component.Name))
+		}
+	}
+	pkg.Components = deployed
+
+	pkg.Components, err = filter.Apply(pkg)
+	if err != nil {
+		return pkg, nil, err
+	}
+
+	state, err := s.LoadJackalState()
+	if err != nil {
+		return pkg, nil, err
+	}
+
+	var imageList []transform.Image
+
+	for idx, component := range pkg.Components {
+		if len(component.Files) > 0 {
+			warnings = append(warnings, fmt.Sprintf("Files for component %q cannot be recovered from the cluster and will not be included in the package", component.Name))
+			component.Files = nil
+		}
+		if len(component.DataInjections) > 0 {
+			warnings = append(warnings, fmt.Sprintf("Data injections for component %q cannot be recovered from the cluster and will not be included in the package", component.Name))
+			component.DataInjections = nil
+		}
+
+		// Releases store a single set of merged values and a single rendered manifest
+		for chartIdx, chart := range component.Charts {
+			component.Charts[chartIdx].ValuesFiles = []string{fmt.Sprintf("%s-values.yaml", chart.Name)}
+		}
+		for manifestIdx, manifest := range component.Manifests {
+			component.Manifests[manifestIdx].Files = []string{fmt.Sprintf("%s-0.yaml", manifest.Name)}
+			component.Manifests[manifestIdx].Kustomizations = nil
+		}
+
+		componentPaths, err := dst.Components.Create(component)
+		if err != nil {
+			return pkg, nil, err
+		}
+
+		if err := s.exportComponent(pkg.Metadata.Name, component, componentPaths, state.GitServer); err != nil {
+			return pkg, nil, fmt.Errorf("unable to export component %q: %w", component.Name, err)
+		}
+
+		for _, src := range component.Images {
+			refInfo, err := transform.ParseImageRef(src)
+			if err != nil {
+				return pkg, nil, fmt.Errorf("failed to create ref for image %s: %w", src, err)
+			}
+			imageList = append(imageList, refInfo)
+		}
+
+		pkg.Components[idx] = component
+	}
+
+	imageList = helpers.Unique(imageList)
+	if len(imageList) > 0 {
+		dst.AddImages()
+
+		imgConfig := images.ImageConfig{
+			ImagesPath:    dst.Images.Base,
+			ImageList:     imageList,
+			RegInfo:       state.RegistryInfo,
+			Insecure:      config.CommonOptions.Insecure,
+			Architectures: []string{pkg.Build.Architecture},
+		}
+
+		pulled, err := imgConfig.PullFromJackalRegistry()
+		if err != nil {
+			return pkg, nil, err
+		}
+
+		for _, imgInfo := range pulled {
+			if err := dst.Images.AddV1Image(imgInfo.Img); err != nil {
+				return pkg, nil, err
+			}
+		}
+	}
+
+	// The rebuilt package no longer matches the original checksums or signature
+	pkg.Metadata.AggregateChecksum = ""
+
+	if err := utils.WriteYaml(dst.JackalYAML, pkg, helpers.ReadUser); err != nil {
+		return pkg, nil, err
+	}
+
+	if !unarchiveAll {
+		for _, component := range pkg.Components {
+			if err := dst.Components.Archive(component, true); err != nil {
+				return pkg, nil, err
+			}
+		}
+	}
+
+	return pkg, warnings, nil
 }
 
-// Collect collects a package from a cluster.
-//
-// This is not implemented.
-func (s *ClusterSource) Collect(_ string) (string, error) {
-	return "", fmt.Errorf("not implemented")
+// Collect rebuilds a package from a cluster and writes it to a tarball.
+func (s *ClusterSource) Collect(dir string) (string, error) {
+	tmp, err := utils.MakeTempDir(config.CommonOptions.TempDirectory)
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	loaded := layout.New(tmp)
+
+	pkg, warnings, err := s.LoadPackage(loaded, filters.Empty(), false)
+	if err != nil {
+		return "", err
+	}
+	for _, warning := range warnings {
+		message.Warn(warning)
+	}
+
+	pkg.Metadata.AggregateChecksum, err = loaded.GenerateChecksums()
+	if err != nil {
+		return "", fmt.Errorf("unable to generate checksums for the package: %w", err)
+	}
+
+	// Rewrite the jackal.yaml now that the checksums are known
+	_ = os.Remove(loaded.JackalYAML)
+	if err := utils.WriteYaml(loaded.JackalYAML, pkg, helpers.ReadUser); err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("%s%s", NameFromMetadata(&pkg, false), PkgSuffix(pkg.Metadata.Uncompressed))
+	dstTarball := filepath.Join(dir, name)
+
+	_ = os.Remove(dstTarball)
+
+	return dstTarball, loaded.ArchivePackage(dstTarball, 0)
+}
+
+// exportComponent writes the charts, manifests and repos of a deployed component into its component paths.
+func (s *ClusterSource) exportComponent(packageName string, component types.JackalComponent, componentPaths *layout.ComponentPaths, gitServer types.GitServerInfo) error {
+	for _, chart := range component.Charts {
+		releaseName := chart.ReleaseName
+		if releaseName == "" {
+			releaseName = chart.Name
+		}
+
+		helmCfg := helm.New(chart, componentPaths.Charts, componentPaths.Values)
+		if err := helmCfg.SaveChartFromRelease(releaseName); err != nil {
+			return err
+		}
+	}
+
+	for _, manifest := range component.Manifests {
+		namespace := manifest.Namespace
+		if namespace == "" {
+			// Manifests without a namespace are deployed to the default namespace
+			namespace = corev1.NamespaceDefault
+		}
+
+		helmCfg := helm.New(types.JackalChart{Namespace: namespace}, "", "")
+		releaseManifest, err := helmCfg.GetReleaseManifest(helm.ManifestReleaseName(packageName, component.Name, manifest.Name))
+		if err != nil {
+			return err
+		}
+
+		dst := filepath.Join(componentPaths.Manifests, manifest.Files[0])
+		if err := os.WriteFile(dst, []byte(releaseManifest), helpers.ReadWriteUser); err != nil {
+			return fmt.Errorf("unable to write manifest %s: %w", manifest.Name, err)
+		}
+	}
+
+	if len(component.Repos) > 0 {
+		spinner := message.NewProgressSpinner("Loading %d git repos from the git server", len(component.Repos))
+		defer spinner.Stop()
+
+		for _, repoURL := range component.Repos {
+			pullRepo := func() error {
+				gitClient := git.NewWithSpinner(gitServer, spinner)
+				svcInfo, _ := k8s.ServiceInfoFromServiceURL(gitClient.Server.Address)
+
+				// If this is a service (svcInfo is not nil), create a port-forward tunnel to that resource
+				if svcInfo != nil {
+					tunnel, err := s.NewTunnel(svcInfo.Namespace, k8s.SvcResource, svcInfo.Name, "", 0, svcInfo.Port)
+					if err != nil {
+						return err
+					}
+
+					_, err = tunnel.Connect()
+					if err != nil {
+						return err
+					}
+					defer tunnel.Close()
+					gitClient.Server.Address = tunnel.HTTPEndpoint()
+
+					return tunnel.Wrap(func() error { return gitClient.PullFromServer(repoURL, componentPaths.Repos) })
+				}
+
+				return gitClient.PullFromServer(repoURL, componentPaths.Repos)
+			}
+
+			if err := pullRepo(); err != nil {
+				return fmt.Errorf("unable to pull repo %s from the git server: %w", repoURL, err)
+			}
+		}
+
+		spinner.Success()
+	}
+
+	return nil
 }
 
 // LoadPackageMetadata loads package metadata from a cluster.
@@ -7,7 +7,9 @@ package sources
 import (
 	"fmt"
 	"net/url"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/defenseunicorns/pkg/helpers"
 	"github.com/defenseunicorns/pkg/oci"
@@ -39,8 +41,64 @@ type PackageSource interface {
 	Collect(destinationDirectory string) (tarball string, err error)
 }
 
+// Constructor creates a PackageSource from the provided package options.
+type Constructor func(pkgOpts *types.JackalPackageOptions) (PackageSource, error)
+
+// builtinSchemes are the source types handled directly by New and cannot be registered.
+var builtinSchemes = []string{"oci", "tarball", "http", "https", "sget", "split"}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Constructor{}
+)
+
+// Register registers a PackageSource constructor for the given URL scheme (e.g. "s3" for s3://bucket/key).
+//
+// Registering a scheme that is already registered replaces the previous constructor, built-in schemes cannot be overridden.
+func Register(scheme string, constructor Constructor) error {
+	scheme = strings.ToLower(scheme)
+	if scheme == "" {
+		return fmt.Errorf("a scheme is required to register a package source")
+	}
+	if constructor == nil {
+		return fmt.Errorf("a constructor is required to register a package source for %q", scheme)
+	}
+	if slices.Contains(builtinSchemes, scheme) {
+		return fmt.Errorf("unable to register package source for built-in scheme %q", scheme)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[scheme] = constructor
+
+	return nil
+}
+
+// Unregister removes a previously registered PackageSource constructor for the given URL scheme.
+func Unregister(scheme string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	delete(registry, strings.ToLower(scheme))
+}
+
+// registered returns the registered constructor for the given scheme if one exists.
+func registered(scheme string) (Constructor, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	constructor, ok := registry[scheme]
+	return constructor, ok
+}
+
 // Identify returns the type of package source based on the provided package source string.
 func Identify(pkgSrc string) string {
+	// Registered schemes are checked first since they may not have a host (e.g. file:///path/to/package)
+	if parsed, err := url.Parse(pkgSrc); err == nil && parsed.Scheme != "" {
+		scheme := strings.ToLower(parsed.Scheme)
+		if _, ok := registered(scheme); ok {
+			return scheme
+		}
+	}
+
 	if helpers.IsURL(pkgSrc) {
 		parsed, _ := url.Parse(pkgSrc)
 		return parsed.Scheme
@@ -63,7 +121,9 @@ func New(pkgOpts *types.JackalPackageOptions) (PackageSource, error) {
 
 	pkgSrc := pkgOpts.PackageSource
 
-	switch Identify(pkgSrc) {
+	srcType := Identify(pkgSrc)
+
+	switch srcType {
 	case "oci":
 		if pkgOpts.Shasum != "" {
 			pkgSrc = fmt.Sprintf("%s@sha256:%s", pkgSrc, pkgOpts.Shasum)
@@ -81,7 +141,15 @@ func New(pkgOpts *types.JackalPackageOptions) (PackageSource, error) {
 	case "split":
 		source = &SplitTarballSource{pkgOpts}
 	default:
-		return nil, fmt.Errorf("could not identify source type for %q", pkgSrc)
+		constructor, ok := registered(srcType)
+		if !ok {
+			return nil, fmt.Errorf("could not identify source type for %q", pkgSrc)
+		}
+		var err error
+		source, err = constructor(pkgOpts)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	message.Debugf("Using %T for %q", source, pkgSrc)
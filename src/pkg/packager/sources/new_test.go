@@ -6,6 +6,7 @@ package sources
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/racer159/jackal/src/types"
@@ -16,6 +17,7 @@ var ociS *OCISource
 var urlS *URLSource
 var tarballS *TarballSource
 var splitS *SplitTarballSource
+var s3S *S3Source
 var packageS *PackageSource
 
 type source struct {
@@ -34,6 +36,7 @@ var sources = []source{
 	{pkgSrc: "jackal-package-manifests-amd64-v1.0.0.tar", srcType: "tarball", source: tarballS},
 	{pkgSrc: "jackal-package-manifests-amd64-v1.0.0.tar.zst", srcType: "tarball", source: tarballS},
 	{pkgSrc: "some-dir/.part000", srcType: "split", source: splitS},
+	{pkgSrc: "s3://jackal-packages/jackal-init-amd64-v1.0.0.tar.zst", srcType: "s3", source: s3S},
 }
 
 func Test_identifySourceType(t *testing.T) {
@@ -51,3 +54,37 @@ func TestNew(t *testing.T) {
 		require.Implements(t, packageS, actual)
 	}
 }
+
+type fileSource struct {
+	TarballSource
+}
+
+func TestRegister(t *testing.T) {
+	constructor := func(pkgOpts *types.JackalPackageOptions) (PackageSource, error) {
+		pkgOpts.PackageSource = strings.TrimPrefix(pkgOpts.PackageSource, "file://")
+		return &fileSource{TarballSource{pkgOpts}}, nil
+	}
+
+	require.Error(t, Register("", constructor))
+	require.Error(t, Register("file", nil))
+	for _, scheme := range builtinSchemes {
+		require.Error(t, Register(scheme, constructor))
+	}
+
+	pkgSrc := "file:///srv/packages/manifests"
+	require.Equal(t, "", Identify(pkgSrc))
+	_, err := New(&types.JackalPackageOptions{PackageSource: pkgSrc})
+	require.Error(t, err)
+
+	require.NoError(t, Register("file", constructor))
+	t.Cleanup(func() { Unregister("file") })
+
+	require.Equal(t, "file", Identify(pkgSrc))
+	actual, err := New(&types.JackalPackageOptions{PackageSource: pkgSrc})
+	require.NoError(t, err)
+	require.IsType(t, &fileSource{}, actual)
+	require.Equal(t, "/srv/packages/manifests", actual.(*fileSource).PackageSource)
+
+	Unregister("file")
+	require.Equal(t, "", Identify(pkgSrc))
+}
@@ -82,7 +82,7 @@ func (s *OCISource) LoadPackage(dst *layout.PackagePaths, filter filters.Compone
 
 		spinner.Success()
 
-		if err := ValidatePackageSignature(dst, s.PublicKeyPath); err != nil {
+		if err := ValidatePackageSignature(dst, SignatureVerifyOptions(s.JackalPackageOptions)); err != nil {
 			return pkg, nil, err
 		}
 	}
@@ -145,7 +145,7 @@ func (s *OCISource) LoadPackageMetadata(dst *layout.PackagePaths, wantSBOM bool,
 			spinner.Success()
 		}
 
-		if err := ValidatePackageSignature(dst, s.PublicKeyPath); err != nil {
+		if err := ValidatePackageSignature(dst, SignatureVerifyOptions(s.JackalPackageOptions)); err != nil {
 			if errors.Is(err, ErrPkgSigButNoKey) && skipValidation {
 				message.Warn("The package was signed but no public key was provided, skipping signature validation")
 			} else {
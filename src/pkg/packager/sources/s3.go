@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package sources contains core implementations of the PackageSource interface.
+package sources
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/defenseunicorns/pkg/helpers"
+	"github.com/racer159/jackal/src/config"
+	"github.com/racer159/jackal/src/pkg/layout"
+	"github.com/racer159/jackal/src/pkg/message"
+	"github.com/racer159/jackal/src/pkg/packager/filters"
+	"github.com/racer159/jackal/src/pkg/utils"
+	"github.com/racer159/jackal/src/types"
+)
+
+var (
+	// verify that S3Source implements PackageSource
+	_ PackageSource = (*S3Source)(nil)
+)
+
+// S3Scheme is the URL scheme for packages stored in S3-compatible object stores.
+const S3Scheme = "s3"
+
+func init() {
+	if err := Register(S3Scheme, NewS3Source); err != nil {
+		panic(err)
+	}
+}
+
+// S3Source is a package source for packages stored in S3-compatible object stores (s3://bucket/path/to/package.tar.zst).
+//
+// Credentials and region are resolved using the standard AWS environment variables and shared config,
+// a custom endpoint (e.g. MinIO) can be set with AWS_ENDPOINT_URL_S3 or AWS_ENDPOINT_URL.
+type S3Source struct {
+	*types.JackalPackageOptions
+	Bucket   string
+	Key      string
+	Endpoint string
+}
+
+// NewS3Source creates a new S3Source from the provided package options.
+func NewS3Source(pkgOpts *types.JackalPackageOptions) (PackageSource, error) {
+	parsed, err := url.Parse(pkgOpts.PackageSource)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse S3 package source %q: %w", pkgOpts.PackageSource, err)
+	}
+
+	key := strings.TrimPrefix(parsed.Path, "/")
+	if parsed.Host == "" || key == "" {
+		return nil, fmt.Errorf("invalid S3 package source %q, expected s3://bucket/path/to/package", pkgOpts.PackageSource)
+	}
+
+	endpoint := os.Getenv("AWS_ENDPOINT_URL_S3")
+	if endpoint == "" {
+		endpoint = os.Getenv("AWS_ENDPOINT_URL")
+	}
+
+	return &S3Source{
+		JackalPackageOptions: pkgOpts,
+		Bucket:               parsed.Host,
+		Key:                  key,
+		Endpoint:             endpoint,
+	}, nil
+}
+
+// newSession creates an AWS session for the source's bucket.
+func (s *S3Source) newSession() (*session.Session, error) {
+	cfg := aws.NewConfig()
+	if s.Endpoint != "" {
+		// Most S3-compatible stores (e.g. MinIO) do not support virtual-hosted-style bucket addressing
+		cfg = cfg.WithEndpoint(s.Endpoint).WithS3ForcePathStyle(true)
+	}
+	if os.Getenv("AWS_REGION") == "" && os.Getenv("AWS_DEFAULT_REGION") == "" {
+		cfg = cfg.WithRegion("us-east-1")
+	}
+
+	return session.NewSessionWithOptions(session.Options{
+		Config:            *cfg,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+}
+
+// Collect downloads a package from an S3 bucket.
+func (s *S3Source) Collect(dir string) (string, error) {
+	sess, err := s.newSession()
+	if err != nil {
+		return "", fmt.Errorf("unable to create S3 session: %w", err)
+	}
+
+	dstTarball := filepath.Join(dir, filepath.Base(s.Key))
+
+	spinner := message.NewProgressSpinner("Downloading package from %q", s.PackageSource)
+	defer spinner.Stop()
+
+	f, err := os.Create(dstTarball)
+	if err != nil {
+		return "", err
+	}
+
+	downloader := s3manager.NewDownloader(sess)
+	_, err = downloader.Download(f, &s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(s.Key),
+	})
+	f.Close()
+	if err != nil {
+		return "", fmt.Errorf("unable to download %q: %w", s.PackageSource, err)
+	}
+
+	if s.Shasum != "" {
+		if err := helpers.SHAsMatch(dstTarball, s.Shasum); err != nil {
+			return "", err
+		}
+	}
+
+	spinner.Success()
+
+	return RenameFromMetadata(dstTarball)
+}
+
+// LoadPackage loads a package from an S3 bucket.
+func (s *S3Source) LoadPackage(dst *layout.PackagePaths, filter filters.ComponentFilterStrategy, unarchiveAll bool) (pkg types.JackalPackage, warnings []string, err error) {
+	tmp, err := utils.MakeTempDir(config.CommonOptions.TempDirectory)
+	if err != nil {
+		return pkg, nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	dstTarball, err := s.Collect(tmp)
+	if err != nil {
+		return pkg, nil, err
+	}
+
+	s.PackageSource = dstTarball
+	// Clear the shasum so that it doesn't get used again
+	s.Shasum = ""
+
+	ts := &TarballSource{
+		s.JackalPackageOptions,
+	}
+
+	return ts.LoadPackage(dst, filter, unarchiveAll)
+}
+
+// LoadPackageMetadata loads a package's metadata from an S3 bucket.
+func (s *S3Source) LoadPackageMetadata(dst *layout.PackagePaths, wantSBOM bool, skipValidation bool) (pkg types.JackalPackage, warnings []string, err error) {
+	tmp, err := utils.MakeTempDir(config.CommonOptions.TempDirectory)
+	if err != nil {
+		return pkg, nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	dstTarball, err := s.Collect(tmp)
+	if err != nil {
+		return pkg, nil, err
+	}
+
+	s.PackageSource = dstTarball
+	// Clear the shasum so that it doesn't get used again
+	s.Shasum = ""
+
+	ts := &TarballSource{
+		s.JackalPackageOptions,
+	}
+
+	return ts.LoadPackageMetadata(dst, wantSBOM, skipValidation)
+}
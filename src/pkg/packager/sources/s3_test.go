@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package sources contains core implementations of the PackageSource interface.
+package sources
+
+import (
+	"archive/tar"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/racer159/jackal/src/pkg/layout"
+	"github.com/racer159/jackal/src/types"
+	"github.com/stretchr/testify/require"
+)
+
+// newS3StandIn returns a minimal path-style S3 server (like MinIO) that serves the given objects.
+func newS3StandIn(t *testing.T, objects map[string][]byte) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		b, ok := objects[r.URL.Path]
+		if !ok || r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		http.ServeContent(w, r, filepath.Base(r.URL.Path), time.Time{}, bytes.NewReader(b))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func testPackageTarball(t *testing.T) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	jackalYAML := []byte("kind: JackalPackageConfig\nmetadata:\n  name: s3-test\n  architecture: amd64\n")
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: layout.JackalYAML, Mode: 0644, Size: int64(len(jackalYAML))}))
+	_, err := tw.Write(jackalYAML)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	return buf.Bytes()
+}
+
+func TestS3SourceCollect(t *testing.T) {
+	srv := newS3StandIn(t, map[string][]byte{
+		"/packages/nested/s3-test.tar": testPackageTarball(t),
+	})
+
+	t.Setenv("AWS_ENDPOINT_URL_S3", srv.URL)
+	t.Setenv("AWS_ACCESS_KEY_ID", "minioadmin")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "minioadmin")
+	t.Setenv("AWS_REGION", "us-east-1")
+
+	source, err := New(&types.JackalPackageOptions{PackageSource: "s3://packages/nested/s3-test.tar"})
+	require.NoError(t, err)
+	s3Source, ok := source.(*S3Source)
+	require.True(t, ok)
+	require.Equal(t, "packages", s3Source.Bucket)
+	require.Equal(t, "nested/s3-test.tar", s3Source.Key)
+	require.Equal(t, srv.URL, s3Source.Endpoint)
+
+	dir := t.TempDir()
+	tarball, err := source.Collect(dir)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "jackal-package-s3-test-amd64.tar"), tarball)
+	require.FileExists(t, tarball)
+
+	missing, err := New(&types.JackalPackageOptions{PackageSource: "s3://packages/missing.tar"})
+	require.NoError(t, err)
+	_, err = missing.Collect(t.TempDir())
+	require.Error(t, err)
+
+	_, err = New(&types.JackalPackageOptions{PackageSource: "s3://packages"})
+	require.Error(t, err)
+}
@@ -105,7 +105,7 @@ func (s *TarballSource) LoadPackage(dst *layout.PackagePaths, filter filters.Com
 
 		spinner.Success()
 
-		if err := ValidatePackageSignature(dst, s.PublicKeyPath); err != nil {
+		if err := ValidatePackageSignature(dst, SignatureVerifyOptions(s.JackalPackageOptions)); err != nil {
 			return pkg, nil, err
 		}
 	}
@@ -183,7 +183,7 @@ func (s *TarballSource) LoadPackageMetadata(dst *layout.PackagePaths, wantSBOM b
 			spinner.Success()
 		}
 
-		if err := ValidatePackageSignature(dst, s.PublicKeyPath); err != nil {
+		if err := ValidatePackageSignature(dst, SignatureVerifyOptions(s.JackalPackageOptions)); err != nil {
 			if errors.Is(err, ErrPkgSigButNoKey) && skipValidation {
 				message.Warn("The package was signed but no public key was provided, skipping signature validation")
 			} else {
@@ -6,6 +6,7 @@ package sources
 
 import (
 	"bufio"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/fs"
@@ -14,49 +15,110 @@ import (
 	"strings"
 
 	"github.com/defenseunicorns/pkg/helpers"
+	"github.com/in-toto/in-toto-golang/in_toto"
 	"github.com/racer159/jackal/src/config"
 	"github.com/racer159/jackal/src/pkg/layout"
 	"github.com/racer159/jackal/src/pkg/message"
 	"github.com/racer159/jackal/src/pkg/utils"
+	"github.com/racer159/jackal/src/types"
 )
 
 var (
 	// ErrPkgKeyButNoSig is returned when a key was provided but the package is not signed
 	ErrPkgKeyButNoSig = errors.New("a key was provided but the package is not signed - the package may be corrupted or the --key flag was erroneously specified")
 	// ErrPkgSigButNoKey is returned when a package is signed but no key was provided
-	ErrPkgSigButNoKey = errors.New("package is signed but no key was provided - add a key with the --key flag or use the --insecure flag and run the command again")
+	ErrPkgSigButNoKey = errors.New("package is signed but no key was provided - add a key with the --key flag, a certificate identity with the --certificate-identity flag or use the --insecure flag and run the command again")
+	// ErrProvenanceButNoSig is returned when a key was provided but the package provenance is not signed
+	ErrProvenanceButNoSig = errors.New("a key was provided but the package provenance is not signed - the package may be corrupted")
 )
 
+// SignatureVerifyOptions returns what the signatures of a package are verified against from the package options.
+func SignatureVerifyOptions(pkgOpts *types.JackalPackageOptions) utils.CosignVerifyOptions {
+	return utils.CosignVerifyOptions{
+		KeyRef:         pkgOpts.PublicKeyPath,
+		CertIdentity:   pkgOpts.CertificateIdentity,
+		CertOIDCIssuer: pkgOpts.CertificateOIDCIssuer,
+		CertChainPath:  pkgOpts.CertificateChainPath,
+	}
+}
+
 // ValidatePackageSignature validates the signature of a package
-func ValidatePackageSignature(paths *layout.PackagePaths, publicKeyPath string) error {
+func ValidatePackageSignature(paths *layout.PackagePaths, verifyOpts utils.CosignVerifyOptions) error {
 	// If the insecure flag was provided ignore the signature validation
 	if config.CommonOptions.Insecure {
 		return nil
 	}
 
-	if publicKeyPath != "" {
-		message.Debugf("Using public key %q for signature validation", publicKeyPath)
+	if verifyOpts.KeyRef != "" {
+		message.Debugf("Using public key %q for signature validation", verifyOpts.KeyRef)
+	} else if verifyOpts.CertIdentity != "" {
+		message.Debugf("Using certificate identity %q from %q for signature validation", verifyOpts.CertIdentity, verifyOpts.CertOIDCIssuer)
 	}
 
 	// Handle situations where there is no signature within the package
 	sigExist := paths.Signature != ""
-	if !sigExist && publicKeyPath == "" {
-		// Nobody was expecting a signature, so we can just return
-		return nil
-	} else if sigExist && publicKeyPath == "" {
+	if !sigExist && !verifyOpts.IsSet() {
+		// Nobody was expecting a signature, but the provenance must still match the package
+		return ValidatePackageProvenance(paths, verifyOpts)
+	} else if sigExist && !verifyOpts.IsSet() {
 		// The package is signed but no key was provided
 		return ErrPkgSigButNoKey
-	} else if !sigExist && publicKeyPath != "" {
+	} else if !sigExist && verifyOpts.IsSet() {
 		// A key was provided but there is no signature
 		return ErrPkgKeyButNoSig
 	}
 
-	// Validate the signature with the key we were provided
-	if err := utils.CosignVerifyBlob(paths.JackalYAML, paths.Signature, publicKeyPath); err != nil {
-		return fmt.Errorf("package signature did not match the provided key: %w", err)
+	// Validate the signature with the key or certificate identity we were provided
+	if err := utils.CosignVerifyBlob(paths.JackalYAML, paths.Signature, paths.SignatureBundle, verifyOpts); err != nil {
+		return fmt.Errorf("package signature did not match the provided key or certificate identity: %w", err)
 	}
+	message.Successf("Package signature validated!")
 
-	return nil
+	return ValidatePackageProvenance(paths, verifyOpts)
+}
+
+// ValidatePackageProvenance validates that the provenance of a package (if it has one) was signed with the same key as the package and describes its jackal.yaml
+func ValidatePackageProvenance(paths *layout.PackagePaths, verifyOpts utils.CosignVerifyOptions) error {
+	// If the insecure flag was provided ignore the provenance validation
+	if config.CommonOptions.Insecure {
+		return nil
+	}
+
+	// Packages created before provenance was recorded do not have one
+	if paths.Provenance == "" {
+		return nil
+	}
+
+	if verifyOpts.IsSet() {
+		if paths.ProvenanceSignature == "" {
+			return ErrProvenanceButNoSig
+		}
+		if err := utils.CosignVerifyBlob(paths.Provenance, paths.ProvenanceSignature, paths.ProvenanceSignatureBundle, verifyOpts); err != nil {
+			return fmt.Errorf("package provenance signature did not match the provided key or certificate identity: %w", err)
+		}
+	}
+
+	b, err := os.ReadFile(paths.Provenance)
+	if err != nil {
+		return err
+	}
+	var statement in_toto.Statement
+	if err := json.Unmarshal(b, &statement); err != nil {
+		return fmt.Errorf("unable to read the package provenance: %w", err)
+	}
+
+	jackalYAMLDigest, err := helpers.GetSHA256OfFile(paths.JackalYAML)
+	if err != nil {
+		return err
+	}
+	for _, subject := range statement.Subject {
+		if subject.Name == layout.JackalYAML && subject.Digest["sha256"] == jackalYAMLDigest {
+			message.Successf("Package provenance validated!")
+			return nil
+		}
+	}
+
+	return fmt.Errorf("package provenance does not describe this package's %s", layout.JackalYAML)
 }
 
 // ValidatePackageIntegrity validates the integrity of a package by comparing checksums
@@ -82,6 +144,10 @@ func ValidatePackageIntegrity(loaded *layout.PackagePaths, aggregateChecksum str
 	checkedMap[loaded.JackalYAML] = true
 	checkedMap[loaded.Checksums] = true
 	checkedMap[loaded.Signature] = true
+	checkedMap[loaded.SignatureBundle] = true
+	checkedMap[loaded.Provenance] = true
+	checkedMap[loaded.ProvenanceSignature] = true
+	checkedMap[loaded.ProvenanceSignatureBundle] = true
 
 	err = lineByLine(checksumPath, func(line string) error {
 		// If the line is empty (i.e. there is no checksum) simply skip it - this can result from a package with no images/components
@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package sources contains core implementations of the PackageSource interface.
+package sources
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/defenseunicorns/pkg/helpers"
+	"github.com/racer159/jackal/src/pkg/layout"
+	"github.com/racer159/jackal/src/pkg/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePackageProvenance(t *testing.T) {
+	t.Parallel()
+
+	// newPackage returns a package with a provenance statement for the jackal.yaml with the given content.
+	newPackage := func(t *testing.T, attested string) *layout.PackagePaths {
+		t.Helper()
+
+		pp := layout.New(t.TempDir())
+		require.NoError(t, os.WriteFile(pp.JackalYAML, []byte(attested), 0600))
+		digest, err := helpers.GetSHA256OfFile(pp.JackalYAML)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(pp.JackalYAML, []byte("kind: JackalPackageConfig\n"), 0600))
+
+		pp.Provenance = filepath.Join(pp.Base, layout.Provenance)
+		statement := fmt.Sprintf(`{"_type":"https://in-toto.io/Statement/v0.1","subject":[{"name":"jackal.yaml","digest":{"sha256":%q}}]}`, digest)
+		require.NoError(t, os.WriteFile(pp.Provenance, []byte(statement), 0600))
+		return pp
+	}
+
+	t.Run("no provenance", func(t *testing.T) {
+		t.Parallel()
+
+		pp := layout.New(t.TempDir())
+		require.NoError(t, ValidatePackageProvenance(pp, utils.CosignVerifyOptions{}))
+	})
+
+	t.Run("provenance of the package", func(t *testing.T) {
+		t.Parallel()
+
+		pp := newPackage(t, "kind: JackalPackageConfig\n")
+		require.NoError(t, ValidatePackageProvenance(pp, utils.CosignVerifyOptions{}))
+	})
+
+	t.Run("provenance of another package", func(t *testing.T) {
+		t.Parallel()
+
+		pp := newPackage(t, "kind: JackalInitConfig\n")
+		require.ErrorContains(t, ValidatePackageProvenance(pp, utils.CosignVerifyOptions{}), layout.JackalYAML)
+	})
+
+	t.Run("unsigned provenance with a key", func(t *testing.T) {
+		t.Parallel()
+
+		pp := newPackage(t, "kind: JackalPackageConfig\n")
+		require.ErrorIs(t, ValidatePackageProvenance(pp, utils.CosignVerifyOptions{KeyRef: "cosign.pub"}), ErrProvenanceButNoSig)
+	})
+}
@@ -0,0 +1,484 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package packager contains functions for interacting with, managing and deploying Jackal packages.
+package packager
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/defenseunicorns/pkg/helpers"
+	"github.com/racer159/jackal/src/internal/agent/mutate"
+	"github.com/racer159/jackal/src/internal/packager/helm"
+	"github.com/racer159/jackal/src/pkg/k8s"
+	"github.com/racer159/jackal/src/pkg/message"
+	"github.com/racer159/jackal/src/pkg/transform"
+	"github.com/racer159/jackal/src/pkg/utils"
+	"github.com/racer159/jackal/src/types"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// DriftKind is the way in which a deployed component no longer matches what was deployed.
+type DriftKind string
+
+const (
+	// DriftComponentNotSucceeded means the component did not finish deploying.
+	DriftComponentNotSucceeded DriftKind = "component-not-succeeded"
+	// DriftReleaseMissing means a Helm release installed by the component no longer exists.
+	DriftReleaseMissing DriftKind = "release-missing"
+	// DriftResourceMissing means a resource in a Helm release no longer exists in the cluster.
+	DriftResourceMissing DriftKind = "resource-missing"
+	// DriftResourceModified means a resource in the cluster no longer matches its Helm release.
+	DriftResourceModified DriftKind = "resource-modified"
+	// DriftUnknownImage means a pod is running an image that is not in the package.
+	DriftUnknownImage DriftKind = "unknown-image"
+)
+
+// maxDriftedFields is the number of drifted fields listed for a single modified resource.
+const maxDriftedFields = 5
+
+// PackageStatus is the drift of each component of a deployed package from the live state of the cluster.
+type PackageStatus struct {
+	Package    string           `json:"package"`
+	Version    string           `json:"version"`
+	Generation int              `json:"generation"`
+	Components []ComponentDrift `json:"components"`
+}
+
+// ComponentDrift is the drift of a single deployed component.
+type ComponentDrift struct {
+	Name   string                `json:"name"`
+	Status types.ComponentStatus `json:"status"`
+	Drift  []Drift               `json:"drift"`
+}
+
+// Drift is a single difference between a deployed component and the live state of the cluster.
+type Drift struct {
+	Kind     DriftKind `json:"kind"`
+	Release  string    `json:"release,omitempty"`
+	Resource string    `json:"resource,omitempty"`
+	Detail   string    `json:"detail,omitempty"`
+}
+
+// Drifted returns whether any component of the package has drifted.
+func (s PackageStatus) Drifted() bool {
+	for _, component := range s.Components {
+		if len(component.Drift) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Status compares each component of a deployed package against the live state of the cluster and reports any drift.
+func (p *Packager) Status() (status PackageStatus, err error) {
+	if err := p.useClusterSource(); err != nil {
+		return status, err
+	}
+	packageName := p.cfg.PkgOpts.PackageSource
+
+	deployedPackage, err := p.cluster.GetDeployedPackage(packageName)
+	if err != nil {
+		return status, fmt.Errorf("unable to load the secret for package %q: %w", packageName, err)
+	}
+	p.cfg.Pkg = deployedPackage.Data
+
+	// Pods and git sources are mutated by the agent, so they are compared against where the agent would have pointed them
+	if state, err := p.cluster.LoadJackalState(); err == nil {
+		p.cfg.State = state
+	} else {
+		message.Debugf("Unable to load the Jackal state, images and git sources are compared without agent mutations: %s", err.Error())
+	}
+
+	resourceClient, err := p.cluster.NewResourceClient()
+	if err != nil {
+		return status, fmt.Errorf("unable to discover the resources served by the cluster: %w", err)
+	}
+
+	ignoredNamespaces := map[string]bool{}
+	namespaces, err := p.cluster.GetNamespaces()
+	if err != nil {
+		return status, fmt.Errorf("unable to get the namespaces in the cluster: %w", err)
+	}
+	for _, namespace := range namespaces.Items {
+		ignoredNamespaces[namespace.Name] = mutate.IsIgnored(namespace.Labels)
+	}
+
+	status = PackageStatus{
+		Package:    deployedPackage.Name,
+		Version:    deployedPackage.Data.Metadata.Version,
+		Generation: deployedPackage.Generation,
+		Components: []ComponentDrift{},
+	}
+
+	packageImages := p.packageImages()
+	for _, deployedComponent := range deployedPackage.DeployedComponents {
+		componentDrift := ComponentDrift{
+			Name:   deployedComponent.Name,
+			Status: deployedComponent.Status,
+			Drift:  []Drift{},
+		}
+
+		if deployedComponent.Status != types.ComponentStatusSucceeded {
+			componentDrift.Drift = append(componentDrift.Drift, Drift{
+				Kind:   DriftComponentNotSucceeded,
+				Detail: fmt.Sprintf("the component status is %s", deployedComponent.Status),
+			})
+		}
+
+		for _, installedChart := range deployedComponent.InstalledCharts {
+			helmCfg := helm.New(types.JackalChart{Namespace: installedChart.Namespace}, "", "")
+			manifest, err := helmCfg.GetReleaseResourcesManifest(installedChart.ChartName)
+			if errors.Is(err, driver.ErrReleaseNotFound) {
+				componentDrift.Drift = append(componentDrift.Drift, Drift{
+					Kind:    DriftReleaseMissing,
+					Release: installedChart.ChartName,
+					Detail:  fmt.Sprintf("the release is not installed in the %s namespace", installedChart.Namespace),
+				})
+				continue
+			}
+			if err != nil {
+				return status, err
+			}
+
+			drift, err := p.releaseDrift(resourceClient, installedChart, manifest, packageImages, ignoredNamespaces)
+			if err != nil {
+				return status, fmt.Errorf("unable to check release %q for drift: %w", installedChart.ChartName, err)
+			}
+			componentDrift.Drift = append(componentDrift.Drift, drift...)
+		}
+
+		status.Components = append(status.Components, componentDrift)
+	}
+
+	if p.cfg.StatusOpts.OutputFormat == "json" {
+		fmt.Println(message.JSONValue(status))
+		return status, nil
+	}
+
+	printPackageStatus(status)
+
+	return status, nil
+}
+
+// releaseDrift compares the resources of a Helm release, and the images of the pods they own, against the live state of the cluster.
+func (p *Packager) releaseDrift(resourceClient *k8s.ResourceClient, release types.InstalledChart, manifest string, packageImages map[string]bool, ignoredNamespaces map[string]bool) ([]Drift, error) {
+	resources, err := utils.SplitYAML([]byte(manifest))
+	if err != nil {
+		return nil, err
+	}
+
+	scaled := autoscaledResources(resources, release.Namespace)
+
+	drift := []Drift{}
+	owned := map[string]bool{}
+	namespaces := []string{}
+	for _, desired := range resources {
+		namespace := desired.GetNamespace()
+		if namespace == "" {
+			namespace = release.Namespace
+		}
+		id := resourceID(desired.GetKind(), desired.GetNamespace(), desired.GetName())
+
+		live, err := resourceClient.Get(desired.GroupVersionKind(), desired.GetNamespace(), release.Namespace, desired.GetName())
+		if kerrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			drift = append(drift, Drift{Kind: DriftResourceMissing, Release: release.ChartName, Resource: id})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to get %s: %w", id, err)
+		}
+
+		// Cluster-scoped resources are identified without a namespace
+		id = resourceID(live.GetKind(), live.GetNamespace(), live.GetName())
+		owned[id] = true
+		if live.GetNamespace() != "" {
+			namespaces = append(namespaces, live.GetNamespace())
+		}
+
+		// The agent only mutates these resources as they are admitted, every other resource is stored as it was rendered
+		switch desired.GetKind() {
+		case "Pod", "GitRepository", "HelmRepository", "OCIRepository", "Application", "ApplicationSet":
+			if p.cfg.State != nil && !ignoredNamespaces[namespace] {
+				if err := p.mutateResource(desired); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		// The replicas of autoscaled workloads are managed by their HorizontalPodAutoscaler
+		if scaled[id] {
+			unstructured.RemoveNestedField(desired.Object, "spec", "replicas")
+		}
+
+		if fields := driftedFields(normalizeResource(desired).Object, live.Object); len(fields) > 0 {
+			detail := strings.Join(fields, ", ")
+			if len(fields) > maxDriftedFields {
+				detail = fmt.Sprintf("%s and %d more", strings.Join(fields[:maxDriftedFields], ", "), len(fields)-maxDriftedFields)
+			}
+			drift = append(drift, Drift{Kind: DriftResourceModified, Release: release.ChartName, Resource: id, Detail: detail})
+		}
+	}
+
+	for _, namespace := range helpers.Unique(namespaces) {
+		pods, err := p.cluster.GetPods(namespace)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the pods in the %s namespace: %w", namespace, err)
+		}
+
+		for _, pod := range pods.Items {
+			podID := resourceID("Pod", pod.Namespace, pod.Name)
+			if !owned[podID] && !isOwnedBy(resourceClient, pod.Namespace, pod.OwnerReferences, owned) {
+				continue
+			}
+
+			containers := []corev1.Container{}
+			containers = append(containers, pod.Spec.InitContainers...)
+			containers = append(containers, pod.Spec.Containers...)
+			for _, container := range containers {
+				if !packageImages[normalizeImageRef(container.Image)] {
+					drift = append(drift, Drift{
+						Kind:     DriftUnknownImage,
+						Release:  release.ChartName,
+						Resource: podID,
+						Detail:   fmt.Sprintf("container %s runs %s", container.Name, container.Image),
+					})
+				}
+			}
+		}
+	}
+
+	return drift, nil
+}
+
+// packageImages returns every normalized reference a pod could use to run an image from the package.
+func (p *Packager) packageImages() map[string]bool {
+	images := map[string]bool{}
+	for _, component := range p.cfg.Pkg.Components {
+		for _, image := range component.Images {
+			images[normalizeImageRef(image)] = true
+
+			if p.cfg.State == nil {
+				continue
+			}
+			for _, transformImage := range []func(string, string) (string, error){transform.ImageTransformHost, transform.ImageTransformHostWithoutChecksum} {
+				if transformed, err := transformImage(p.cfg.State.RegistryInfo.Address, image); err == nil {
+					images[normalizeImageRef(transformed)] = true
+				}
+			}
+		}
+	}
+	return images
+}
+
+// normalizeImageRef returns the fully qualified form of an image reference so that equivalent references compare as equal.
+func normalizeImageRef(image string) string {
+	ref, err := transform.ParseImageRef(image)
+	if err != nil {
+		return image
+	}
+	return ref.Reference
+}
+
+// isOwnedBy returns whether any controller in the owner chain of a resource is one of the owned resources.
+func isOwnedBy(resourceClient *k8s.ResourceClient, namespace string, ownerReferences []metav1.OwnerReference, owned map[string]bool) bool {
+	for _, ownerReference := range ownerReferences {
+		if ownerReference.Controller == nil || !*ownerReference.Controller {
+			continue
+		}
+		if owned[resourceID(ownerReference.Kind, namespace, ownerReference.Name)] {
+			return true
+		}
+
+		groupVersion, err := schema.ParseGroupVersion(ownerReference.APIVersion)
+		if err != nil {
+			continue
+		}
+		owner, err := resourceClient.Get(groupVersion.WithKind(ownerReference.Kind), namespace, namespace, ownerReference.Name)
+		if err != nil {
+			continue
+		}
+		if isOwnedBy(resourceClient, namespace, owner.GetOwnerReferences(), owned) {
+			return true
+		}
+	}
+	return false
+}
+
+// autoscaledResources returns the IDs of the resources targeted by the HorizontalPodAutoscalers in a set of resources.
+func autoscaledResources(resources []*unstructured.Unstructured, defaultNamespace string) map[string]bool {
+	scaled := map[string]bool{}
+	for _, resource := range resources {
+		if resource.GetKind() != "HorizontalPodAutoscaler" {
+			continue
+		}
+		namespace := resource.GetNamespace()
+		if namespace == "" {
+			namespace = defaultNamespace
+		}
+		kind, _, _ := unstructured.NestedString(resource.Object, "spec", "scaleTargetRef", "kind")
+		name, _, _ := unstructured.NestedString(resource.Object, "spec", "scaleTargetRef", "name")
+		scaled[resourceID(kind, namespace, name)] = true
+	}
+	return scaled
+}
+
+// resourceID returns the ID of a resource, as used when diffing packages.
+func resourceID(kind, namespace, name string) string {
+	if namespace == "" {
+		return fmt.Sprintf("%s/%s", kind, name)
+	}
+	return fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+}
+
+// normalizeResource returns a copy of a rendered resource in the form the API server stores it.
+func normalizeResource(desired *unstructured.Unstructured) *unstructured.Unstructured {
+	normalized := desired.DeepCopy()
+
+	// The status of a resource is never part of what was deployed
+	unstructured.RemoveNestedField(normalized.Object, "status")
+
+	// The API server merges the stringData of a secret into its data
+	if normalized.GetKind() == "Secret" {
+		stringData, found, _ := unstructured.NestedStringMap(normalized.Object, "stringData")
+		if found {
+			data, _, _ := unstructured.NestedMap(normalized.Object, "data")
+			if data == nil {
+				data = map[string]any{}
+			}
+			for key, value := range stringData {
+				data[key] = base64.StdEncoding.EncodeToString([]byte(value))
+			}
+			unstructured.RemoveNestedField(normalized.Object, "stringData")
+			_ = unstructured.SetNestedMap(normalized.Object, data, "data")
+		}
+	}
+
+	return normalized
+}
+
+// driftedFields returns the paths of the fields that are set in desired but are missing or different in live.
+//
+// Fields that only exist in live are ignored as they are defaulted or added by the cluster.
+func driftedFields(desired, live map[string]any) []string {
+	fields := []string{}
+	compareFields("", desired, live, &fields)
+	sort.Strings(fields)
+	return fields
+}
+
+func compareFields(path string, desired, live any, fields *[]string) {
+	switch desiredValue := desired.(type) {
+	case map[string]any:
+		liveValue, ok := live.(map[string]any)
+		if !ok {
+			*fields = append(*fields, path)
+			return
+		}
+		for key, value := range desiredValue {
+			fieldPath := key
+			if path != "" {
+				fieldPath = fmt.Sprintf("%s.%s", path, key)
+			}
+			liveField, found := liveValue[key]
+			if !found {
+				if !isEmptyValue(value) {
+					*fields = append(*fields, fieldPath)
+				}
+				continue
+			}
+			compareFields(fieldPath, value, liveField, fields)
+		}
+	case []any:
+		liveValue, ok := live.([]any)
+		if !ok || len(liveValue) < len(desiredValue) {
+			*fields = append(*fields, path)
+			return
+		}
+		for idx, value := range desiredValue {
+			compareFields(fmt.Sprintf("%s[%d]", path, idx), value, liveValue[idx], fields)
+		}
+	default:
+		if !equalScalars(desired, live) {
+			*fields = append(*fields, path)
+		}
+	}
+}
+
+// equalScalars returns whether two scalar values are equal, treating equivalent resource quantities (i.e. 0.5 and 500m) as equal.
+func equalScalars(desired, live any) bool {
+	desiredText, liveText := fmt.Sprint(desired), fmt.Sprint(live)
+	if desiredText == liveText {
+		return true
+	}
+
+	desiredQuantity, err := resource.ParseQuantity(desiredText)
+	if err != nil {
+		return false
+	}
+	liveQuantity, err := resource.ParseQuantity(liveText)
+	if err != nil {
+		return false
+	}
+	return desiredQuantity.Cmp(liveQuantity) == 0
+}
+
+// isEmptyValue returns whether a rendered value is empty, and so may be dropped by the API server.
+func isEmptyValue(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case int64:
+		return v == 0
+	case float64:
+		return v == 0
+	case map[string]any:
+		return len(v) == 0
+	case []any:
+		return len(v) == 0
+	}
+	return false
+}
+
+// printPackageStatus prints a summary of the drift of each component followed by every drifted resource.
+func printPackageStatus(status PackageStatus) {
+	summary := [][]string{}
+	drift := [][]string{}
+	for _, component := range status.Components {
+		driftCount := "none"
+		if len(component.Drift) > 0 {
+			driftCount = fmt.Sprintf("%d", len(component.Drift))
+		}
+		summary = append(summary, []string{component.Name, string(component.Status), driftCount})
+
+		for _, item := range component.Drift {
+			drift = append(drift, []string{component.Name, string(item.Kind), item.Release, item.Resource, item.Detail})
+		}
+	}
+
+	message.Title(fmt.Sprintf("%s (generation %d)", status.Package, status.Generation), "compared against the live state of the cluster")
+	message.Table([]string{"Component", "Status", "Drift"}, summary)
+
+	if len(drift) == 0 {
+		message.Successf("No drift detected in package %q", status.Package)
+		return
+	}
+
+	message.Table([]string{"Component", "Drift", "Release", "Resource", "Detail"}, drift)
+	message.Warnf("Package %q has drifted from what was deployed", status.Package)
+}
@@ -0,0 +1,153 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package packager contains functions for interacting with, managing and deploying Jackal packages.
+package packager
+
+import (
+	"testing"
+
+	"github.com/racer159/jackal/src/pkg/transform"
+	"github.com/racer159/jackal/src/pkg/utils"
+	"github.com/racer159/jackal/src/types"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDriftedFields(t *testing.T) {
+	t.Parallel()
+
+	desired := map[string]any{
+		"metadata": map[string]any{
+			"name":        "podinfo",
+			"labels":      map[string]any{"app": "podinfo"},
+			"annotations": map[string]any{},
+		},
+		"spec": map[string]any{
+			"replicas": int64(2),
+			"paused":   false,
+			"template": map[string]any{
+				"spec": map[string]any{
+					"containers": []any{
+						map[string]any{
+							"name":      "podinfo",
+							"image":     "ghcr.io/stefanprodan/podinfo:6.4.0",
+							"resources": map[string]any{"limits": map[string]any{"cpu": "0.5", "memory": "64Mi"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// Fields added by the cluster and equivalent quantities are not drift
+	live := map[string]any{
+		"metadata": map[string]any{
+			"name":            "podinfo",
+			"uid":             "1234",
+			"labels":          map[string]any{"app": "podinfo", "extra": "label"},
+			"resourceVersion": "1",
+		},
+		"spec": map[string]any{
+			"replicas": int64(2),
+			"template": map[string]any{
+				"spec": map[string]any{
+					"containers": []any{
+						map[string]any{
+							"name":                     "podinfo",
+							"image":                    "ghcr.io/stefanprodan/podinfo:6.4.0",
+							"imagePullPolicy":          "IfNotPresent",
+							"resources":                map[string]any{"limits": map[string]any{"cpu": "500m", "memory": "64Mi"}},
+							"terminationMessagePath":   "/dev/termination-log",
+							"terminationMessagePolicy": "File",
+						},
+						map[string]any{"name": "sidecar", "image": "istio/proxyv2:1.20.0"},
+					},
+				},
+			},
+		},
+		"status": map[string]any{"replicas": int64(2)},
+	}
+	require.Empty(t, driftedFields(desired, live))
+
+	live["metadata"].(map[string]any)["labels"] = map[string]any{"app": "edited"}
+	live["spec"].(map[string]any)["replicas"] = int64(3)
+	live["spec"].(map[string]any)["template"].(map[string]any)["spec"].(map[string]any)["containers"] = []any{}
+
+	require.Equal(t, []string{"metadata.labels.app", "spec.replicas", "spec.template.spec.containers"}, driftedFields(desired, live))
+}
+
+func TestNormalizeResource(t *testing.T) {
+	t.Parallel()
+
+	resources, err := utils.SplitYAML([]byte(`---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: credentials
+data:
+  username: YWRtaW4=
+stringData:
+  password: hunter2
+status:
+  ignored: true
+`))
+	require.NoError(t, err)
+
+	normalized := normalizeResource(resources[0])
+
+	require.NotContains(t, normalized.Object, "stringData")
+	require.NotContains(t, normalized.Object, "status")
+	data, _, err := unstructured.NestedStringMap(normalized.Object, "data")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"username": "YWRtaW4=", "password": "aHVudGVyMg=="}, data)
+
+	// The rendered resource is left untouched
+	require.Contains(t, resources[0].Object, "stringData")
+}
+
+func TestAutoscaledResources(t *testing.T) {
+	t.Parallel()
+
+	resources, err := utils.SplitYAML([]byte(`---
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: registry
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: jackal-docker-registry
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: jackal-docker-registry
+`))
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]bool{"Deployment/jackal/jackal-docker-registry": true}, autoscaledResources(resources, "jackal"))
+}
+
+func TestPackageImages(t *testing.T) {
+	t.Parallel()
+
+	p := &Packager{
+		cfg: &types.PackagerConfig{
+			Pkg: types.JackalPackage{
+				Components: []types.JackalComponent{{Name: "baseline", Images: []string{"nginx:1.25"}}},
+			},
+			State: &types.JackalState{RegistryInfo: types.RegistryInfo{Address: "127.0.0.1:31999"}},
+		},
+	}
+
+	transformed, err := transform.ImageTransformHost("127.0.0.1:31999", "nginx:1.25")
+	require.NoError(t, err)
+
+	images := p.packageImages()
+	require.True(t, images[normalizeImageRef("docker.io/library/nginx:1.25")])
+	require.True(t, images[normalizeImageRef(transformed)])
+	require.True(t, images[normalizeImageRef("127.0.0.1:31999/library/nginx:1.25")])
+	require.False(t, images[normalizeImageRef("nginx:1.26")])
+}
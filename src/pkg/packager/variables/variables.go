@@ -17,13 +17,11 @@ func SetVariableMapInConfig(cfg *types.PackagerConfig) error {
 	}
 
 	for _, variable := range cfg.Pkg.Variables {
-		_, present := cfg.SetVariableMap[variable.Name]
+		setVariable, present := cfg.GetVariable(variable.Name)
 
 		// Variable is present, no need to continue checking
 		if present {
-			cfg.SetVariableMap[variable.Name].Sensitive = variable.Sensitive
-			cfg.SetVariableMap[variable.Name].AutoIndent = variable.AutoIndent
-			cfg.SetVariableMap[variable.Name].Type = variable.Type
+			cfg.SetVariable(variable.Name, setVariable.Value, variable.Sensitive, variable.AutoIndent, variable.Type)
 			if err := cfg.CheckVariablePattern(variable.Name, variable.Pattern); err != nil {
 				return err
 			}
@@ -61,6 +61,16 @@ func ImageTransformHostWithoutChecksum(targetHost, srcReference string) (string,
 	return fmt.Sprintf("%s/%s%s", targetHost, image.Path, image.TagOrDigest), nil
 }
 
+// OCIURLTransformHost replaces the host of an oci:// URL, preserving the repository path.
+func OCIURLTransformHost(targetHost, srcURL string) (string, error) {
+	image, err := ParseImageRef(strings.TrimPrefix(srcURL, helpers.OCIURLPrefix))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s%s/%s", helpers.OCIURLPrefix, targetHost, image.Path), nil
+}
+
 // ParseImageRef parses a source reference into an Image struct
 func ParseImageRef(srcReference string) (out Image, err error) {
 	ref, err := reference.ParseAnyReference(srcReference)
@@ -76,6 +76,25 @@ func TestImageTransformHostWithoutChecksum(t *testing.T) {
 	}
 }
 
+func TestOCIURLTransformHost(t *testing.T) {
+	var ociURLs = map[string]string{
+		"oci://ghcr.io/stefanprodan/charts":            "oci://gitlab.com/project/stefanprodan/charts",
+		"oci://ghcr.io/stefanprodan/manifests/podinfo": "oci://gitlab.com/project/stefanprodan/manifests/podinfo",
+		"oci://nginx": "oci://gitlab.com/project/library/nginx",
+	}
+
+	for ociURL, expected := range ociURLs {
+		newURL, err := OCIURLTransformHost("gitlab.com/project", ociURL)
+		require.NoError(t, err)
+		require.Equal(t, expected, newURL)
+	}
+
+	for _, ref := range badImageRefs {
+		_, err := OCIURLTransformHost("gitlab.com/project", "oci://"+ref)
+		require.Error(t, err)
+	}
+}
+
 func TestParseImageRef(t *testing.T) {
 	var expectedResult = [][]string{
 		{"docker.io/", "library/nginx", "latest", ""},
@@ -29,6 +29,9 @@ import (
 	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
 	sigs "github.com/sigstore/cosign/v2/pkg/signature"
 
+	// Register the OIDC token providers for keyless signing
+	_ "github.com/sigstore/cosign/v2/pkg/providers/all"
+
 	// Register the provider-specific plugins
 	_ "github.com/sigstore/sigstore/pkg/signature/kms/aws"
 	_ "github.com/sigstore/sigstore/pkg/signature/kms/azure"
@@ -179,34 +182,88 @@ func Sget(ctx context.Context, image, key string, out io.Writer) error {
 	return err
 }
 
-// CosignVerifyBlob verifies the jackal.yaml.sig was signed with the key provided by the flag
-func CosignVerifyBlob(blobRef string, sigRef string, keyPath string) error {
-	keyOptions := options.KeyOpts{KeyRef: keyPath}
+// CosignVerifyOptions are what a blob signature is verified against, either a public key or the identity in a signing certificate.
+type CosignVerifyOptions struct {
+	// KeyRef is the path or KMS URI (awskms://, gcpkms://, azurekms://, hashivault://) of the public key.
+	KeyRef string
+	// CertIdentity is the identity (e.g. an email or workflow URI) the signing certificate must be issued to.
+	CertIdentity string
+	// CertOIDCIssuer is the OIDC issuer that must have vouched for the identity of the signing certificate.
+	CertOIDCIssuer string
+	// CertChainPath is the PEM certificate chain (ending with the root) the signing certificate must chain to instead of the public Fulcio roots.
+	CertChainPath string
+}
+
+// IsSet returns whether a key or certificate identity to verify against was provided.
+func (o CosignVerifyOptions) IsSet() bool {
+	return o.KeyRef != "" || o.CertIdentity != ""
+}
+
+// CosignVerifyBlob verifies a blob (e.g. the jackal.yaml) was signed with the key or by the certificate identity provided by the flags.
+//
+// Certificate identities are verified against the certificate within the bundle written by a keyless signature.
+func CosignVerifyBlob(blobRef string, sigRef string, bundleRef string, opts CosignVerifyOptions) error {
 	cmd := &verify.VerifyBlobCmd{
-		KeyOpts:    keyOptions,
+		KeyOpts:    options.KeyOpts{KeyRef: opts.KeyRef},
 		SigRef:     sigRef,
 		IgnoreSCT:  true,
 		Offline:    true,
 		IgnoreTlog: true,
 	}
-	err := cmd.Exec(context.TODO(), blobRef)
-	if err == nil {
-		message.Successf("Package signature validated!")
+
+	if opts.KeyRef == "" {
+		if bundleRef == "" {
+			return errors.New("the signature has no certificate to verify the identity of - verify it with the --key flag instead")
+		}
+		cmd.BundlePath = bundleRef
+		cmd.CertVerifyOptions = options.CertVerifyOptions{
+			CertIdentity:   opts.CertIdentity,
+			CertOidcIssuer: opts.CertOIDCIssuer,
+		}
+		// Certificates from a private chain are long lived, while those from Fulcio are only trusted through their transparency log entry
+		cmd.CertChain = opts.CertChainPath
+		cmd.IgnoreTlog = opts.CertChainPath != ""
 	}
 
-	return err
+	return cmd.Exec(context.TODO(), blobRef)
+}
+
+// CosignSignOptions are what a blob is signed with, either a private key or a keyless certificate.
+type CosignSignOptions struct {
+	// KeyRef is the path or KMS URI (awskms://, gcpkms://, azurekms://, hashivault://) of the private key.
+	KeyRef string
+	// Keyless signs with a short-lived Fulcio certificate for the OIDC identity of the signer and records the signature in Rekor.
+	Keyless bool
+	// PasswordFunc returns the password of a private key file.
+	PasswordFunc func(bool) ([]byte, error)
 }
 
 // CosignSignBlob signs the provide binary and returns the signature
-func CosignSignBlob(blobPath string, outputSigPath string, keyPath string, passwordFunc func(bool) ([]byte, error)) ([]byte, error) {
+//
+// Keyless signatures also write a bundle with the signing certificate and transparency log entry to the bundle path.
+func CosignSignBlob(blobPath string, outputSigPath string, outputBundlePath string, opts CosignSignOptions) ([]byte, error) {
 	rootOptions := &options.RootOptions{Verbose: false, Timeout: options.DefaultTimeout}
 
-	keyOptions := options.KeyOpts{KeyRef: keyPath,
-		PassFunc: passwordFunc}
+	keyOptions := options.KeyOpts{KeyRef: opts.KeyRef,
+		PassFunc: opts.PasswordFunc}
 	b64 := true
 	outputCertificate := ""
 	tlogUpload := false
 
+	if opts.Keyless {
+		if opts.KeyRef != "" {
+			return nil, errors.New("a package cannot be signed with both a key and keyless")
+		}
+		keyOptions.FulcioURL = options.DefaultFulcioURL
+		keyOptions.RekorURL = options.DefaultRekorURL
+		keyOptions.OIDCIssuer = options.DefaultOIDCIssuerURL
+		keyOptions.OIDCClientID = "sigstore"
+		keyOptions.BundlePath = outputBundlePath
+		// The signer opted into the transparency log by signing keyless
+		keyOptions.SkipConfirmation = true
+		tlogUpload = true
+	}
+
 	sig, err := sign.SignBlobCmd(rootOptions,
 		keyOptions,
 		blobPath,
@@ -0,0 +1,187 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package utils provides generic utility functions.
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/stretchr/testify/require"
+)
+
+// newVaultTransitStandIn returns a server that implements the parts of the Vault transit secrets engine used to sign and verify with a hashivault:// key.
+func newVaultTransitStandIn(t *testing.T, keyName string) *httptest.Server {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+	publicKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	respond := func(w http.ResponseWriter, data map[string]interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		var body struct {
+			Input     string `json:"input"`
+			Signature string `json:"signature"`
+		}
+		if r.Method != http.MethodGet {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+		digest, _ := base64.StdEncoding.DecodeString(body.Input)
+
+		switch r.URL.Path {
+		case "/v1/transit/keys/" + keyName:
+			respond(w, map[string]interface{}{
+				"latest_version": 1,
+				"keys":           map[string]interface{}{"1": map[string]interface{}{"public_key": publicKey}},
+			})
+		case "/v1/transit/sign/" + keyName + "/sha2-256":
+			sig, err := ecdsa.SignASN1(rand.Reader, key, digest)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			respond(w, map[string]interface{}{"signature": "vault:v1:" + base64.StdEncoding.EncodeToString(sig)})
+		case "/v1/transit/verify/" + keyName + "/sha2-256":
+			sig, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(body.Signature, "vault:v1:"))
+			respond(w, map[string]interface{}{"valid": ecdsa.VerifyASN1(&key.PublicKey, digest, sig)})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestCosignKMS(t *testing.T) {
+	srv := newVaultTransitStandIn(t, "jackal")
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_TOKEN", "root")
+
+	dir := t.TempDir()
+	blob := filepath.Join(dir, "jackal.yaml")
+	sig := filepath.Join(dir, "jackal.yaml.sig")
+	require.NoError(t, os.WriteFile(blob, []byte("kind: JackalPackageConfig\n"), 0600))
+
+	_, err := CosignSignBlob(blob, sig, "", CosignSignOptions{KeyRef: "hashivault://jackal"})
+	require.NoError(t, err)
+	require.NoError(t, CosignVerifyBlob(blob, sig, "", CosignVerifyOptions{KeyRef: "hashivault://jackal"}))
+
+	require.NoError(t, os.WriteFile(blob, []byte("kind: JackalInitConfig\n"), 0600))
+	require.Error(t, CosignVerifyBlob(blob, sig, "", CosignVerifyOptions{KeyRef: "hashivault://jackal"}))
+
+	_, err = CosignSignBlob(blob, sig, "", CosignSignOptions{KeyRef: "hashivault://jackal", Keyless: true})
+	require.Error(t, err)
+}
+
+func TestCosignCertificateIdentity(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	now := time.Now()
+
+	// A private certificate authority stands in for Fulcio
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "jackal-test-ca"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, caKey.Public(), caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+	chain := filepath.Join(dir, "chain.pem")
+	require.NoError(t, os.WriteFile(chain, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), 0600))
+
+	// The signing certificate carries the identity and the OIDC issuer that vouched for it
+	issuer := "https://issuer.jackal.dev"
+	signerKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	signerTemplate := &x509.Certificate{
+		SerialNumber:   big.NewInt(2),
+		NotBefore:      now.Add(-time.Hour),
+		NotAfter:       now.Add(time.Hour),
+		EmailAddresses: []string{"packager@jackal.dev"},
+		KeyUsage:       x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:    []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		ExtraExtensions: []pkix.Extension{
+			{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}, Value: []byte(issuer)},
+		},
+	}
+	signerDER, err := x509.CreateCertificate(rand.Reader, signerTemplate, caCert, signerKey.Public(), caKey)
+	require.NoError(t, err)
+
+	blob := filepath.Join(dir, "jackal.yaml")
+	content := []byte("kind: JackalPackageConfig\n")
+	require.NoError(t, os.WriteFile(blob, content, 0600))
+	digest := sha256.Sum256(content)
+	sig, err := signerKey.Sign(rand.Reader, digest[:], crypto.SHA256)
+	require.NoError(t, err)
+
+	// Write the bundle the same way a keyless signature does
+	bundle := filepath.Join(dir, "jackal.yaml.bundle")
+	payload, err := json.Marshal(cosign.LocalSignedPayload{
+		Base64Signature: base64.StdEncoding.EncodeToString(sig),
+		Cert:            base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: signerDER})),
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(bundle, payload, 0600))
+
+	verifyOpts := CosignVerifyOptions{
+		CertIdentity:   "packager@jackal.dev",
+		CertOIDCIssuer: issuer,
+		CertChainPath:  chain,
+	}
+	require.NoError(t, CosignVerifyBlob(blob, "", bundle, verifyOpts))
+
+	otherIdentity := verifyOpts
+	otherIdentity.CertIdentity = "intruder@jackal.dev"
+	require.Error(t, CosignVerifyBlob(blob, "", bundle, otherIdentity))
+
+	otherIssuer := verifyOpts
+	otherIssuer.CertOIDCIssuer = "https://issuer.example.com"
+	require.Error(t, CosignVerifyBlob(blob, "", bundle, otherIssuer))
+
+	// Signatures made with a key have no certificate to verify the identity of
+	require.Error(t, CosignVerifyBlob(blob, "", "", verifyOpts))
+}
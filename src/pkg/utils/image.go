@@ -9,6 +9,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/defenseunicorns/pkg/helpers"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
@@ -58,6 +60,13 @@ func AddImageNameAnnotation(ociPath string, referenceToDigest map[string]string)
 		return fmt.Errorf("unable to process the contents of the file (%s): %w", indexPath, err)
 	}
 
+	// Match references in a stable order so that images sharing a digest are always annotated the same way
+	references := make([]string, 0, len(referenceToDigest))
+	for reference := range referenceToDigest {
+		references = append(references, reference)
+	}
+	slices.Sort(references)
+
 	// Loop through the manifests and add the appropriate OCI Base Image Name Annotation
 	for idx, manifest := range index.Manifests {
 		if manifest.Annotations == nil {
@@ -66,9 +75,10 @@ func AddImageNameAnnotation(ociPath string, referenceToDigest map[string]string)
 
 		var baseImageName string
 
-		for reference, digest := range referenceToDigest {
-			if digest == manifest.Digest.String() {
+		for _, reference := range references {
+			if referenceToDigest[reference] == manifest.Digest.String() {
 				baseImageName = reference
+				break
 			}
 		}
 
@@ -79,6 +89,11 @@ func AddImageNameAnnotation(ociPath string, referenceToDigest map[string]string)
 		}
 	}
 
+	// Images are pulled concurrently, so order the index by image to keep it the same across package creates
+	slices.SortStableFunc(index.Manifests, func(a, b ocispec.Descriptor) int {
+		return strings.Compare(a.Annotations[ocispec.AnnotationBaseImageName], b.Annotations[ocispec.AnnotationBaseImageName])
+	})
+
 	// Write the file back to the package
 	indexJSONBytes, err := json.Marshal(index)
 	if err != nil {
@@ -87,6 +102,29 @@ func AddImageNameAnnotation(ociPath string, referenceToDigest map[string]string)
 	return os.WriteFile(indexPath, indexJSONBytes, helpers.ReadWriteUser)
 }
 
+// GetImageDigests returns the digest of each image within the index.json file keyed by its OCI Base Image Name Annotation.
+func GetImageDigests(ociPath string) (map[string]string, error) {
+	indexPath := filepath.Join(ociPath, "index.json")
+
+	var index ocispec.Index
+	byteValue, err := os.ReadFile(indexPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the contents of the file (%s): %w", indexPath, err)
+	}
+	if err = json.Unmarshal(byteValue, &index); err != nil {
+		return nil, fmt.Errorf("unable to process the contents of the file (%s): %w", indexPath, err)
+	}
+
+	referenceToDigest := make(map[string]string)
+	for _, manifest := range index.Manifests {
+		if baseImageName, ok := manifest.Annotations[ocispec.AnnotationBaseImageName]; ok {
+			referenceToDigest[baseImageName] = manifest.Digest.String()
+		}
+	}
+
+	return referenceToDigest, nil
+}
+
 // HasImageLayers checks if any layers in the v1.Image are known image layers.
 func HasImageLayers(img v1.Image) (bool, error) {
 	layers, err := img.Layers()
@@ -12,6 +12,22 @@ type Dependency interface {
 	Dependencies() []string
 }
 
+// ComponentDependency is a component within the dependency graph of a package, built from its dependsOn.
+type ComponentDependency struct {
+	Component string
+	DependsOn []string
+}
+
+// Name returns the name of the component.
+func (c ComponentDependency) Name() string {
+	return c.Component
+}
+
+// Dependencies returns the names of the components that must be deployed before this component.
+func (c ComponentDependency) Dependencies() []string {
+	return c.DependsOn
+}
+
 // SortDependencies performs a topological sort on a dependency graph and
 // returns a slice of the nodes in order of their precedence.
 // The input data is a map of nodes to a slice of its dependencies.
@@ -18,6 +18,10 @@ const (
 	JackalConfigMediaType = "application/vnd.jackal.config.v1+json"
 	// JackalLayerMediaTypeBlob is the media type for all Jackal layers due to the range of possible content
 	JackalLayerMediaTypeBlob = "application/vnd.jackal.layer.v1.blob"
+	// JackalProvenanceMediaType is the media type for the in-toto provenance statement of a package
+	JackalProvenanceMediaType = "application/vnd.in-toto+json"
+	// JackalProvenanceSignatureAnnotation is the annotation that holds the cosign signature of a provenance statement
+	JackalProvenanceSignatureAnnotation = "dev.cosignproject.cosign/signature"
 	// SkeletonArch is the architecture used for skeleton packages
 	SkeletonArch = "skeleton"
 )
@@ -21,7 +21,7 @@ import (
 
 var (
 	// PackageAlwaysPull is a list of paths that will always be pulled from the remote repository.
-	PackageAlwaysPull = []string{layout.JackalYAML, layout.Checksums, layout.Signature}
+	PackageAlwaysPull = []string{layout.JackalYAML, layout.Checksums, layout.Signature, layout.SignatureBundle, layout.Provenance, layout.ProvenanceSignature, layout.ProvenanceSignatureBundle}
 )
 
 // PullPackage pulls the package from the remote repository and saves it to the given path.
@@ -32,6 +32,10 @@ var (
 //   - jackal.yaml
 //   - checksums.txt
 //   - jackal.yaml.sig
+//   - jackal.yaml.bundle
+//   - jackal.provenance.json
+//   - jackal.provenance.json.sig
+//   - jackal.provenance.json.bundle
 func (r *Remote) PullPackage(ctx context.Context, destinationDir string, concurrency int, layersToPull ...ocispec.Descriptor) ([]ocispec.Descriptor, error) {
 	isPartialPull := len(layersToPull) > 0
 	r.Log().Debug(fmt.Sprintf("Pulling %s", r.Repo().Reference))
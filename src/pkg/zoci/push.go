@@ -5,8 +5,11 @@
 package zoci
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/defenseunicorns/pkg/helpers"
 	"github.com/defenseunicorns/pkg/oci"
@@ -15,6 +18,7 @@ import (
 	"github.com/racer159/jackal/src/pkg/message"
 	"github.com/racer159/jackal/src/types"
 	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/content/file"
 )
 
@@ -82,6 +86,46 @@ func (r *Remote) PublishPackage(ctx context.Context, pkg *types.JackalPackage, p
 	}
 
 	progressBar.Successf("Published %s [%s]", r.Repo().Reference, JackalLayerMediaTypeBlob)
+
+	if paths.Provenance != "" {
+		if err := r.PublishProvenance(ctx, paths, publishedDesc); err != nil {
+			return fmt.Errorf("unable to publish the package provenance: %w", err)
+		}
+	}
+	return nil
+}
+
+// PublishProvenance publishes the provenance of a package as an OCI referrer of the package manifest so that it can be discovered without pulling the package.
+func (r *Remote) PublishProvenance(ctx context.Context, paths *layout.PackagePaths, subject ocispec.Descriptor) error {
+	b, err := os.ReadFile(paths.Provenance)
+	if err != nil {
+		return err
+	}
+	statementDesc := content.NewDescriptorFromBytes(JackalProvenanceMediaType, b)
+	statementDesc.Annotations = map[string]string{
+		ocispec.AnnotationTitle: layout.Provenance,
+	}
+	if paths.ProvenanceSignature != "" {
+		sig, err := os.ReadFile(paths.ProvenanceSignature)
+		if err != nil {
+			return err
+		}
+		statementDesc.Annotations[JackalProvenanceSignatureAnnotation] = strings.TrimSpace(string(sig))
+	}
+	if err := r.Repo().Push(ctx, statementDesc, bytes.NewReader(b)); err != nil {
+		return err
+	}
+
+	packOpts := oras.PackManifestOptions{
+		Subject: &subject,
+		Layers:  []ocispec.Descriptor{statementDesc},
+	}
+	referrer, err := oras.PackManifest(ctx, r.Repo(), oras.PackManifestVersion1_1, JackalProvenanceMediaType, packOpts)
+	if err != nil {
+		return err
+	}
+
+	message.Successf("Published provenance %s for %s", referrer.Digest, r.Repo().Reference)
 	return nil
 }
 
@@ -34,6 +34,9 @@ type JackalComponent struct {
 	// DeprecatedCosignKeyPath to cosign public key for signed online resources
 	DeprecatedCosignKeyPath string `json:"cosignKeyPath,omitempty" jsonschema:"description=[Deprecated] Specify a path to a public key to validate signed online resources. This will be removed in Jackal v1.0.0.,deprecated=true"`
 
+	// DependsOn lists the components that must finish deploying before this component is deployed
+	DependsOn []string `json:"dependsOn,omitempty" jsonschema:"description=Names of components that must be deployed before this component. Components without dependencies may be deployed concurrently once any component in the package declares dependsOn"`
+
 	// Import refers to another jackal.yaml package component.
 	Import JackalComponentImport `json:"import,omitempty" jsonschema:"description=Import a component from another Jackal package"`
 
@@ -210,17 +213,21 @@ type JackalComponentActionWaitNetwork struct {
 
 // JackalContainerTarget defines the destination info for a JackalData target
 type JackalContainerTarget struct {
-	Namespace string `json:"namespace" jsonschema:"description=The namespace to target for data injection"`
-	Selector  string `json:"selector" jsonschema:"description=The K8s selector to target for data injection,example=app&#61;data-injection"`
-	Container string `json:"container" jsonschema:"description=The container name to target for data injection"`
-	Path      string `json:"path" jsonschema:"description=The path within the container to copy the data into"`
+	Namespace             string `json:"namespace" jsonschema:"description=The namespace to target for data injection"`
+	Selector              string `json:"selector,omitempty" jsonschema:"description=The K8s selector to target for data injection (required unless persistentVolumeClaim is set),example=app&#61;data-injection"`
+	Container             string `json:"container,omitempty" jsonschema:"description=The container name to target for data injection (required unless persistentVolumeClaim is set)"`
+	Path                  string `json:"path" jsonschema:"description=The path within the container to copy the data into"`
+	PersistentVolumeClaim string `json:"persistentVolumeClaim,omitempty" jsonschema:"description=The PersistentVolumeClaim to inject the data into instead of a running container. A short-lived loader pod mounts the claim at path to receive the data"`
+	LoaderImage           string `json:"loaderImage,omitempty" jsonschema:"description=An image from this component's images to run the loader pod for a persistentVolumeClaim target. Note: this image must contain tar and sha256sum"`
 }
 
 // JackalDataInjection is a data-injection definition.
 type JackalDataInjection struct {
-	Source   string                `json:"source" jsonschema:"description=Either a path to a local folder/file or a remote URL of a file to inject into the given target pod + container"`
-	Target   JackalContainerTarget `json:"target" jsonschema:"description=The target pod + container to inject the data into"`
-	Compress bool                  `json:"compress,omitempty" jsonschema:"description=Compress the data before transmitting using gzip.  Note: this requires support for tar/gzip locally and in the target image."`
+	Source          string                `json:"source" jsonschema:"description=Either a path to a local folder/file or a remote URL of a file to inject into the given target pod + container"`
+	Target          JackalContainerTarget `json:"target" jsonschema:"description=The target pod + container to inject the data into"`
+	Compress        bool                  `json:"compress,omitempty" jsonschema:"description=Compress the data before transmitting using gzip.  Note: this requires support for tar/gzip in the target image."`
+	MaxTotalSeconds *int                  `json:"maxTotalSeconds,omitempty" jsonschema:"description=Timeout in seconds for the data to be injected into every target pod (default 900 seconds or 0 for no timeout)"`
+	MaxRetries      *int                  `json:"maxRetries,omitempty" jsonschema:"description=Retry the injection given number of times if it fails (default 3)"`
 }
 
 // JackalComponentImport structure for including imported Jackal components.
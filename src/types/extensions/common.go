@@ -8,4 +8,6 @@ package extensions
 type JackalComponentExtensions struct {
 	// Big Bang Configurations
 	BigBang *BigBang `json:"bigbang,omitempty" jsonschema:"description=Configurations for installing Big Bang and Flux in the cluster"`
+	// Flux Configurations
+	Flux *Flux `json:"flux,omitempty" jsonschema:"description=Configurations for packaging and deploying Flux HelmReleases from GitRepositories"`
 }
@@ -0,0 +1,13 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Jackal Authors
+
+// Package extensions contains the types for all official extensions.
+package extensions
+
+// Flux defines a platform delivered as Flux HelmReleases from GitRepositories.
+type Flux struct {
+	Manifests      []string `json:"manifests" jsonschema:"description=Local paths or URLs to the Flux GitRepository and HelmRelease manifests (and the Secrets and ConfigMaps they take values from) to package and deploy"`
+	Namespace      string   `json:"namespace,omitempty" jsonschema:"description=The namespace to deploy manifests that do not set one into; Defaults to flux-system"`
+	FluxInstall    string   `json:"fluxInstall,omitempty" jsonschema:"description=A kustomization (local path or remote URL) that installs Flux; Flux is not installed when this is empty"`
+	FluxPatchFiles []string `json:"fluxPatchFiles,omitempty" jsonschema:"description=Optional paths to Flux kustomize strategic merge patch files"`
+}
@@ -43,6 +43,10 @@ const (
 	JackalRegistryPushUser                   = "jackal-push"
 	JackalRegistryPullUser                   = "jackal-pull"
 
+	// The name and port of the internal registry's service, these must match the fullnameOverride and service.port of packages/jackal-registry
+	JackalInClusterContainerRegistryName = "jackal-docker-registry"
+	JackalInClusterContainerRegistryPort = 5000
+
 	JackalGitPushUser = "jackal-git-user"
 	JackalGitReadUser = "jackal-git-read-user"
 
@@ -50,6 +54,9 @@ const (
 	JackalInClusterArtifactServiceURL = JackalInClusterGitServiceURL + "/api/packages/" + JackalGitPushUser
 )
 
+// JackalInClusterContainerRegistryAddress is the address in-cluster clients (that cannot use the NodePort) reach the internal registry at.
+var JackalInClusterContainerRegistryAddress = fmt.Sprintf("%s.jackal.svc.cluster.local:%d", JackalInClusterContainerRegistryName, JackalInClusterContainerRegistryPort)
+
 // JackalState is maintained as a secret in the Jackal namespace to track Jackal init data.
 type JackalState struct {
 	JackalAppliance bool             `json:"jackalAppliance" jsonschema:"description=Indicates if Jackal was initialized while deploying its own k8s cluster"`
@@ -62,6 +69,55 @@ type JackalState struct {
 	RegistryInfo   RegistryInfo       `json:"registryInfo" jsonschema:"description=Information about the container registry Jackal is configured to use"`
 	ArtifactServer ArtifactServerInfo `json:"artifactServer" jsonschema:"description=Information about the artifact registry Jackal is configured to use"`
 	LoggingSecret  string             `json:"loggingSecret" jsonschema:"description=Secret value that the internal Grafana server was seeded with"`
+	AgentPolicy    AgentPolicy        `json:"agentPolicy,omitempty" jsonschema:"description=Policy the Jackal agent enforces when validating resources"`
+
+	CredentialRotation CredentialRotation `json:"credentialRotation,omitempty" jsonschema:"description=When the credentials of the services Jackal manages were last rotated"`
+	CredentialStore    CredentialStore    `json:"credentialStore,omitempty" jsonschema:"description=External store that holds the credentials of this state, the state only holds references to them when set"`
+}
+
+// CredentialStore contains where the credentials of the Jackal state are kept when they are not stored in the state itself.
+type CredentialStore struct {
+	Provider string `json:"provider,omitempty" jsonschema:"description=Provider that stores the credentials (e.g. file or vault). The credentials are stored in the state itself if empty"`
+	Address  string `json:"address,omitempty" jsonschema:"description=Address of the provider such as the URL of a Vault server. Providers fall back to their own defaults (e.g. VAULT_ADDR) if empty"`
+	Path     string `json:"path,omitempty" jsonschema:"description=Location of the credentials within the provider (the file for the file provider, the KV v2 mount and path for the vault provider)"`
+}
+
+// CredentialRotation records when the credentials of each service Jackal manages were last rotated.
+type CredentialRotation struct {
+	LastRotated map[string]time.Time `json:"lastRotated,omitempty" jsonschema:"description=Time the credentials of each service (registry, git, artifact or agent) were last rotated"`
+	Pending     *PendingCredentials  `json:"pending,omitempty" jsonschema:"description=New credentials that a rotation in progress is switching the services to"`
+}
+
+// PendingCredentials are the new credentials of a rotation that has not completed, kept so that an interrupted rotation can be recovered.
+type PendingCredentials struct {
+	Services       []string           `json:"services" jsonschema:"description=Services (registry, git or artifact) being switched to the new credentials"`
+	RegistryInfo   RegistryInfo       `json:"registryInfo,omitempty" jsonschema:"description=New credentials of the registry"`
+	GitServer      GitServerInfo      `json:"gitServer,omitempty" jsonschema:"description=New credentials of the git server"`
+	ArtifactServer ArtifactServerInfo `json:"artifactServer,omitempty" jsonschema:"description=New credentials of the artifact server"`
+}
+
+// Record marks the credentials of the given services as rotated at a time.
+func (cr *CredentialRotation) Record(at time.Time, services ...string) {
+	if cr.LastRotated == nil {
+		cr.LastRotated = make(map[string]time.Time)
+	}
+	for _, service := range services {
+		cr.LastRotated[service] = at.UTC()
+	}
+}
+
+// IsDue returns whether the credentials of a service are older than maxAge, credentials with no recorded rotation are always due.
+func (cr CredentialRotation) IsDue(service string, maxAge time.Duration, now time.Time) bool {
+	lastRotated, ok := cr.LastRotated[service]
+	if !ok {
+		return true
+	}
+	return now.Sub(lastRotated) >= maxAge
+}
+
+// AgentPolicy contains the policy the Jackal agent enforces when it validates resources (if the validating webhook is deployed).
+type AgentPolicy struct {
+	AllowedRegistries []string `json:"allowedRegistries,omitempty" jsonschema:"description=Registries other than the Jackal registry that pods are allowed to pull images from"`
 }
 
 // DeployedPackage contains information about a Jackal Package that has been deployed to a cluster
@@ -76,12 +132,43 @@ type DeployedPackage struct {
 	ConnectStrings     ConnectStrings                `json:"connectStrings,omitempty"`
 }
 
+// DeployedPackageGeneration contains information about a single deployment (generation) of a Jackal Package.
+// Each generation is saved as the data of its own k8s secret within the 'Jackal' namespace so that it can be inspected and rolled back to.
+type DeployedPackageGeneration struct {
+	Name               string              `json:"name"`
+	Generation         int                 `json:"generation"`
+	Description        string              `json:"description"`
+	DeployedAt         time.Time           `json:"deployedAt"`
+	CLIVersion         string              `json:"cliVersion"`
+	Data               JackalPackage       `json:"data"`
+	DeployedComponents []DeployedComponent `json:"deployedComponents"`
+	ConnectStrings     ConnectStrings      `json:"connectStrings,omitempty"`
+	Variables          map[string]string   `json:"variables,omitempty"`
+	Releases           []DeployedRelease   `json:"releases,omitempty"`
+}
+
+// DeployedRelease contains the revision a Helm release was left at by a component during a deployment.
+type DeployedRelease struct {
+	Component string `json:"component"`
+	Namespace string `json:"namespace"`
+	ChartName string `json:"chartName"`
+	Revision  int    `json:"revision"`
+}
+
 // DeployedComponent contains information about a Jackal Package Component that has been deployed to a cluster.
 type DeployedComponent struct {
 	Name               string           `json:"name"`
 	InstalledCharts    []InstalledChart `json:"installedCharts"`
 	Status             ComponentStatus  `json:"status"`
 	ObservedGeneration int              `json:"observedGeneration"`
+	Images             []DeployedImage  `json:"images,omitempty"`
+}
+
+// DeployedImage contains information about an image a Jackal Package Component pushed to the Jackal registry.
+type DeployedImage struct {
+	Source     string   `json:"source"`
+	Digest     string   `json:"digest"`
+	References []string `json:"references"`
 }
 
 // Webhook contains information about a Component Webhook operating on a Jackal package secret.
@@ -183,6 +270,16 @@ type RegistryInfo struct {
 	Secret string `json:"secret" jsonschema:"description=Secret value that the registry was seeded with"`
 }
 
+// InClusterAddress returns the address that workloads running in the cluster use to reach the registry.
+//
+// The node port address of the internal registry is only reachable by the kubelet, so in-cluster clients (such as the Flux source-controller) use its service instead.
+func (ri RegistryInfo) InClusterAddress() string {
+	if ri.InternalRegistry {
+		return JackalInClusterContainerRegistryAddress
+	}
+	return ri.Address
+}
+
 // FillInEmptyValues sets every necessary value not already set to a reasonable default
 func (ri *RegistryInfo) FillInEmptyValues() error {
 	var err error
@@ -7,6 +7,7 @@ package types
 import (
 	"fmt"
 	"regexp"
+	"sync"
 )
 
 // PackagerConfig is the main struct that the packager uses to hold high-level options.
@@ -29,6 +30,21 @@ type PackagerConfig struct {
 	// InspectOpts tracks user-defined options used to inspect the package
 	InspectOpts JackalInspectOptions
 
+	// DiffOpts tracks user-defined options used to diff packages
+	DiffOpts JackalDiffOptions
+
+	// HistoryOpts tracks user-defined options used to view the deploy history of a package
+	HistoryOpts JackalHistoryOptions
+
+	// RollbackOpts tracks user-defined options used to roll back a deployed package
+	RollbackOpts JackalRollbackOptions
+
+	// RemoveOpts tracks user-defined options used to remove a deployed package
+	RemoveOpts JackalRemoveOptions
+
+	// StatusOpts tracks user-defined options used to check a deployed package for drift
+	StatusOpts JackalStatusOptions
+
 	// PublishOpts tracks user-defined options used to publish the package
 	PublishOpts JackalPublishOptions
 
@@ -49,10 +65,15 @@ type PackagerConfig struct {
 
 	// Variables set by the user
 	SetVariableMap map[string]*JackalSetVariable
+
+	// variablesMu guards SetVariableMap as components deployed concurrently read and set variables
+	variablesMu sync.RWMutex
 }
 
 // SetVariable sets a value for a variable in PackagerConfig.SetVariableMap.
 func (cfg *PackagerConfig) SetVariable(name, value string, sensitive bool, autoIndent bool, varType VariableType) {
+	cfg.variablesMu.Lock()
+	defer cfg.variablesMu.Unlock()
 	cfg.SetVariableMap[name] = &JackalSetVariable{
 		Name:       name,
 		Value:      value,
@@ -62,9 +83,32 @@ func (cfg *PackagerConfig) SetVariable(name, value string, sensitive bool, autoI
 	}
 }
 
+// GetVariable returns a copy of a variable in PackagerConfig.SetVariableMap.
+func (cfg *PackagerConfig) GetVariable(name string) (JackalSetVariable, bool) {
+	cfg.variablesMu.RLock()
+	defer cfg.variablesMu.RUnlock()
+	variable, ok := cfg.SetVariableMap[name]
+	if !ok {
+		return JackalSetVariable{}, false
+	}
+	return *variable, true
+}
+
+// GetVariables returns a copy of PackagerConfig.SetVariableMap that is safe to read while variables are being set.
+func (cfg *PackagerConfig) GetVariables() map[string]JackalSetVariable {
+	cfg.variablesMu.RLock()
+	defer cfg.variablesMu.RUnlock()
+	variables := make(map[string]JackalSetVariable, len(cfg.SetVariableMap))
+	for name, variable := range cfg.SetVariableMap {
+		variables[name] = *variable
+	}
+	return variables
+}
+
 // CheckVariablePattern checks to see if a variable is set to a value that matches its pattern.
 func (cfg *PackagerConfig) CheckVariablePattern(name, pattern string) error {
-	if regexp.MustCompile(pattern).MatchString(cfg.SetVariableMap[name].Value) {
+	variable, _ := cfg.GetVariable(name)
+	if regexp.MustCompile(pattern).MatchString(variable.Value) {
 		return nil
 	}
 	return fmt.Errorf("provided value for variable %q does not match pattern \"%s\"", name, pattern)
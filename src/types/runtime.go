@@ -30,20 +30,23 @@ type VariableType string
 type JackalCommonOptions struct {
 	Confirm        bool   `json:"confirm" jsonschema:"description=Verify that Jackal should perform an action"`
 	Insecure       bool   `json:"insecure" jsonschema:"description=Allow insecure connections for remote packages"`
-	CachePath      string `json:"cachePath" jsonschema:"description=Path to use to cache images and git repos on package create"`
+	CachePath      string `json:"cachePath" jsonschema:"description=Path to use to cache images, Helm repositories and built components on package create"`
 	TempDirectory  string `json:"tempDirectory" jsonschema:"description=Location Jackal should use as a staging ground when managing files and images for package creation and deployment"`
-	OCIConcurrency int    `jsonschema:"description=Number of concurrent layer operations to perform when interacting with a remote package"`
+	OCIConcurrency int    `jsonschema:"description=Number of concurrent layer operations to perform when interacting with a remote package and of images to push to the Jackal registry at once"`
 }
 
 // JackalPackageOptions tracks the user-defined preferences during common package operations.
 type JackalPackageOptions struct {
-	Shasum             string            `json:"shasum" jsonschema:"description=The SHA256 checksum of the package"`
-	PackageSource      string            `json:"packageSource" jsonschema:"description=Location where a Jackal package can be found"`
-	OptionalComponents string            `json:"optionalComponents" jsonschema:"description=Comma separated list of optional components"`
-	SGetKeyPath        string            `json:"sGetKeyPath" jsonschema:"description=Location where the public key component of a cosign key-pair can be found"`
-	SetVariables       map[string]string `json:"setVariables" jsonschema:"description=Key-Value map of variable names and their corresponding values that will be used to template manifests and files in the Jackal package"`
-	PublicKeyPath      string            `json:"publicKeyPath" jsonschema:"description=Location where the public key component of a cosign key-pair can be found"`
-	Retries            int               `json:"retries" jsonschema:"description=The number of retries to perform for Jackal deploy operations like image pushes or Helm installs"`
+	Shasum                string            `json:"shasum" jsonschema:"description=The SHA256 checksum of the package"`
+	PackageSource         string            `json:"packageSource" jsonschema:"description=Location where a Jackal package can be found"`
+	OptionalComponents    string            `json:"optionalComponents" jsonschema:"description=Comma separated list of optional components"`
+	SGetKeyPath           string            `json:"sGetKeyPath" jsonschema:"description=Location where the public key component of a cosign key-pair can be found"`
+	SetVariables          map[string]string `json:"setVariables" jsonschema:"description=Key-Value map of variable names and their corresponding values that will be used to template manifests and files in the Jackal package"`
+	PublicKeyPath         string            `json:"publicKeyPath" jsonschema:"description=Location or KMS URI where the public key component of a cosign key-pair can be found"`
+	CertificateIdentity   string            `json:"certificateIdentity" jsonschema:"description=The identity the certificate of a keyless package signature must be issued to"`
+	CertificateOIDCIssuer string            `json:"certificateOIDCIssuer" jsonschema:"description=The OIDC issuer that must have vouched for the certificate identity of a keyless package signature"`
+	CertificateChainPath  string            `json:"certificateChainPath" jsonschema:"description=Location of the PEM certificate chain package signing certificates must chain to instead of the public Fulcio roots"`
+	Retries               int               `json:"retries" jsonschema:"description=The number of retries to perform for Jackal deploy operations like image pushes or Helm installs"`
 }
 
 // JackalInspectOptions tracks the user-defined preferences during a package inspection.
@@ -52,6 +55,32 @@ type JackalInspectOptions struct {
 	SBOMOutputDir string `json:"sbomOutput" jsonschema:"description=Location to output an SBOM into after package inspection"`
 }
 
+// JackalDiffOptions tracks the user-defined preferences during a package diff.
+type JackalDiffOptions struct {
+	ComparePackageSource string `json:"comparePackageSource" jsonschema:"description=Location of the Jackal package (or the name of a deployed package) to compare against"`
+	OutputFormat         string `json:"outputFormat" jsonschema:"description=Format to print the differences in (table or json)"`
+}
+
+// JackalHistoryOptions tracks the user-defined preferences when viewing the deploy history of a package.
+type JackalHistoryOptions struct {
+	OutputFormat string `json:"outputFormat" jsonschema:"description=Format to print the deploy history in (table or json)"`
+}
+
+// JackalStatusOptions tracks the user-defined preferences when checking a deployed package for drift.
+type JackalStatusOptions struct {
+	OutputFormat string `json:"outputFormat" jsonschema:"description=Format to print the drift of the package in (table or json)"`
+}
+
+// JackalRollbackOptions tracks the user-defined preferences during a package rollback.
+type JackalRollbackOptions struct {
+	Generation int `json:"generation" jsonschema:"description=Generation of the deployed package to roll back to"`
+}
+
+// JackalRemoveOptions tracks the user-defined preferences during a package removal.
+type JackalRemoveOptions struct {
+	KeepImages bool `json:"keepImages" jsonschema:"description=Keep the images of the removed components in the Jackal registry even if no other package references them"`
+}
+
 // JackalFindImagesOptions tracks the user-defined preferences during a prepare find-images search.
 type JackalFindImagesOptions struct {
 	RepoHelmChartPath   string `json:"repoHelmChartPath" jsonschema:"description=Path to the helm chart directory"`
@@ -65,6 +94,10 @@ type JackalDeployOptions struct {
 	AdoptExistingResources bool          `json:"adoptExistingResources" jsonschema:"description=Whether to adopt any pre-existing K8s resources into the Helm charts managed by Jackal"`
 	SkipWebhooks           bool          `json:"componentWebhooks" jsonschema:"description=Skip waiting for external webhooks to execute as each package component is deployed"`
 	Timeout                time.Duration `json:"timeout" jsonschema:"description=Timeout for performing Helm operations"`
+	Concurrency            int           `json:"concurrency" jsonschema:"description=Maximum number of components without outstanding dependencies to deploy at the same time"`
+	Atomic                 bool          `json:"atomic" jsonschema:"description=Whether to roll back every Helm release touched by this deployment and restore the previous package secret if any component fails"`
+	DryRun                 bool          `json:"dryRun" jsonschema:"description=Whether to render the deployment to DryRunOutputDir instead of applying it to the cluster"`
+	DryRunOutputDir        string        `json:"dryRunOutputDir" jsonschema:"description=Location to write the rendered manifests and deploy plan to during a dry run"`
 
 	// TODO (@WSTARR): This is a library only addition to Jackal and should be refactored in the future (potentially to utilize component composability). As is it should NOT be exposed directly on the CLI
 	ValuesOverridesMap map[string]map[string]map[string]interface{} `json:"valuesOverridesMap" jsonschema:"description=[Library Only] A map of component names to chart names containing Helm Chart values to override values on deploy"`
@@ -79,7 +112,8 @@ type JackalMirrorOptions struct {
 type JackalPublishOptions struct {
 	PackageDestination string `json:"packageDestination" jsonschema:"description=Location where the Jackal package will be published to"`
 	SigningKeyPassword string `json:"signingKeyPassword" jsonschema:"description=Password to the private key signature file that will be used to sign the published package"`
-	SigningKeyPath     string `json:"signingKeyPath" jsonschema:"description=Location where the private key component of a cosign key-pair can be found"`
+	SigningKeyPath     string `json:"signingKeyPath" jsonschema:"description=Location or KMS URI where the private key component of a cosign key-pair can be found"`
+	Keyless            bool   `json:"keyless" jsonschema:"description=Whether to sign the published package with a short-lived certificate for the OIDC identity of the signer"`
 }
 
 // JackalPullOptions tracks the user-defined preferences during a package pull.
@@ -107,6 +141,12 @@ type JackalInitOptions struct {
 	ArtifactServer ArtifactServerInfo `json:"artifactServer" jsonschema:"description=Information about the artifact registry Jackal is going to be using"`
 
 	StorageClass string `json:"storageClass" jsonschema:"description=StorageClass of the k8s cluster Jackal is initializing"`
+
+	AllowedRegistries []string `json:"allowedRegistries" jsonschema:"description=Registries other than the Jackal registry that the agent allows pods to pull images from"`
+
+	CredentialStore CredentialStore `json:"credentialStore" jsonschema:"description=External store to keep the credentials of the Jackal state in"`
+
+	CredentialStoreToken string `json:"credentialStoreToken" jsonschema:"description=Token the Jackal components in the cluster authenticate to the credential store with"`
 }
 
 // JackalCreateOptions tracks the user-defined options used to create the package.
@@ -118,13 +158,15 @@ type JackalCreateOptions struct {
 	SBOMOutputDir           string            `json:"sbomOutput" jsonschema:"description=Location to output an SBOM into after package creation"`
 	SetVariables            map[string]string `json:"setVariables" jsonschema:"description=Key-Value map of variable names and their corresponding values that will be used to template against the Jackal package being used"`
 	MaxPackageSizeMB        int               `json:"maxPackageSizeMB" jsonschema:"description=Size of chunks to use when splitting a jackal package into multiple files in megabytes"`
-	SigningKeyPath          string            `json:"signingKeyPath" jsonschema:"description=Location where the private key component of a cosign key-pair can be found"`
+	SigningKeyPath          string            `json:"signingKeyPath" jsonschema:"description=Location or KMS URI where the private key component of a cosign key-pair can be found"`
+	Keyless                 bool              `json:"keyless" jsonschema:"description=Whether to sign the package with a short-lived certificate for the OIDC identity of the signer"`
 	SigningKeyPassword      string            `json:"signingKeyPassword" jsonschema:"description=Password to the private key signature file that will be used to sigh the created package"`
 	DifferentialPackagePath string            `json:"differentialPackagePath" jsonschema:"description=Path to a previously built package used as the basis for creating a differential package"`
 	RegistryOverrides       map[string]string `json:"registryOverrides" jsonschema:"description=A map of domains to override on package create when pulling images"`
 	Flavor                  string            `json:"flavor" jsonschema:"description=An optional variant that controls which components will be included in a package"`
 	IsSkeleton              bool              `json:"isSkeleton" jsonschema:"description=Whether to create a skeleton package"`
 	NoYOLO                  bool              `json:"noYOLO" jsonschema:"description=Whether to create a YOLO package"`
+	Reproducible            bool              `json:"reproducible" jsonschema:"description=Whether to create a byte-for-byte reproducible package, stamped with SOURCE_DATE_EPOCH"`
 }
 
 // JackalSplitPackageData contains info about a split package.